@@ -0,0 +1,312 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+// conversionDataAnnotation is the annotation on a v1beta1 object in which the
+// values of the v1alpha1 fields that were removed in v1beta1 are preserved, so
+// that the object can be converted back to v1alpha1 without losing them.
+const conversionDataAnnotation = "openebs.io/v1alpha1-conversion-data"
+
+// blockDeviceConversionData contains the BlockDevice fields which are not
+// present in v1beta1
+type blockDeviceConversionData struct {
+	AggregateDevice string `json:"aggregateDevice,omitempty"`
+	ParentDevice    string `json:"parentDevice,omitempty"`
+	// Partitioned is set only if it does not match the partitions in the
+	// dependents of the BD
+	Partitioned *string `json:"partitioned,omitempty"`
+}
+
+// blockDeviceClaimConversionData contains the BlockDeviceClaim fields which
+// are not present in v1beta1
+type blockDeviceClaimConversionData struct {
+	HostName string `json:"hostName,omitempty"`
+	// NodeAttributesDefaulted is set if the hostname in the node attributes
+	// was copied from the deprecated hostName field during conversion
+	NodeAttributesDefaulted bool `json:"nodeAttributesDefaulted,omitempty"`
+}
+
+// ConvertTo converts this BlockDevice to the hub version (v1beta1)
+func (src *BlockDevice) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.BlockDevice)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 BlockDevice but got a %T", dstRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = v1beta1.DeviceSpec{
+		Capacity:       v1beta1.DeviceCapacity(src.Spec.Capacity),
+		ClaimRef:       src.Spec.ClaimRef,
+		Dependents:     (*v1beta1.DependentDevices)(src.Spec.Dependents),
+		Details:        v1beta1.DeviceDetails(src.Spec.Details),
+		DeviceMapper:   (*v1beta1.DeviceMapperInfo)(src.Spec.DeviceMapper),
+		FileSystem:     v1beta1.FileSystemInfo(src.Spec.FileSystem),
+		LVM:            (*v1beta1.LVMInfo)(src.Spec.LVM),
+		NodeAttributes: v1beta1.NodeAttribute(src.Spec.NodeAttributes),
+		Partition:      (*v1beta1.PartitionInfo)(src.Spec.Partition),
+		Path:           src.Spec.Path,
+		Queue:          (*v1beta1.QueueInfo)(src.Spec.Queue),
+		Transport:      (*v1beta1.TransportInfo)(src.Spec.Transport),
+		Zoned:          (*v1beta1.ZonedInfo)(src.Spec.Zoned),
+	}
+	if src.Spec.DevLinks != nil {
+		dst.Spec.DevLinks = make([]v1beta1.DeviceDevLink, len(src.Spec.DevLinks))
+		for i := range src.Spec.DevLinks {
+			dst.Spec.DevLinks[i] = v1beta1.DeviceDevLink(src.Spec.DevLinks[i])
+		}
+	}
+	if src.Spec.Multipath != nil {
+		dst.Spec.Multipath = &v1beta1.MultipathInfo{Name: src.Spec.Multipath.Name}
+		if src.Spec.Multipath.Paths != nil {
+			dst.Spec.Multipath.Paths = make([]v1beta1.MultipathPath, len(src.Spec.Multipath.Paths))
+			for i := range src.Spec.Multipath.Paths {
+				dst.Spec.Multipath.Paths[i] = v1beta1.MultipathPath(src.Spec.Multipath.Paths[i])
+			}
+		}
+	}
+
+	dst.Status = v1beta1.DeviceStatus{
+		ClaimState:    v1beta1.DeviceClaimState(src.Status.ClaimState),
+		State:         v1beta1.BlockDeviceState(src.Status.State),
+		Conditions:    src.Status.Conditions,
+		CleanupMethod: v1beta1.CleanupMethod(src.Status.CleanupMethod),
+		Health:        v1beta1.BlockDeviceHealth(src.Status.Health),
+		HealthReasons: src.Status.HealthReasons,
+		Usage:         (*v1beta1.DeviceUsage)(src.Status.Usage),
+		SMART:         (*v1beta1.SMARTSnapshot)(src.Status.SMART),
+	}
+
+	data := blockDeviceConversionData{
+		AggregateDevice: src.Spec.AggregateDevice,
+		ParentDevice:    src.Spec.ParentDevice,
+	}
+	if src.Spec.Partitioned != partitioned(src.Spec.Dependents) {
+		data.Partitioned = &src.Spec.Partitioned
+	}
+	return setConversionData(&dst.ObjectMeta, data, data != blockDeviceConversionData{})
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this BlockDevice
+func (dst *BlockDevice) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.BlockDevice)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 BlockDevice but got a %T", srcRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = DeviceSpec{
+		Capacity:       DeviceCapacity(src.Spec.Capacity),
+		ClaimRef:       src.Spec.ClaimRef,
+		Dependents:     (*DependentDevices)(src.Spec.Dependents),
+		Details:        DeviceDetails(src.Spec.Details),
+		DeviceMapper:   (*DeviceMapperInfo)(src.Spec.DeviceMapper),
+		FileSystem:     FileSystemInfo(src.Spec.FileSystem),
+		LVM:            (*LVMInfo)(src.Spec.LVM),
+		NodeAttributes: NodeAttribute(src.Spec.NodeAttributes),
+		Partition:      (*PartitionInfo)(src.Spec.Partition),
+		Partitioned:    partitioned((*DependentDevices)(src.Spec.Dependents)),
+		Path:           src.Spec.Path,
+		Queue:          (*QueueInfo)(src.Spec.Queue),
+		Transport:      (*TransportInfo)(src.Spec.Transport),
+		Zoned:          (*ZonedInfo)(src.Spec.Zoned),
+	}
+	if src.Spec.DevLinks != nil {
+		dst.Spec.DevLinks = make([]DeviceDevLink, len(src.Spec.DevLinks))
+		for i := range src.Spec.DevLinks {
+			dst.Spec.DevLinks[i] = DeviceDevLink(src.Spec.DevLinks[i])
+		}
+	}
+	if src.Spec.Multipath != nil {
+		dst.Spec.Multipath = &MultipathInfo{Name: src.Spec.Multipath.Name}
+		if src.Spec.Multipath.Paths != nil {
+			dst.Spec.Multipath.Paths = make([]MultipathPath, len(src.Spec.Multipath.Paths))
+			for i := range src.Spec.Multipath.Paths {
+				dst.Spec.Multipath.Paths[i] = MultipathPath(src.Spec.Multipath.Paths[i])
+			}
+		}
+	}
+
+	dst.Status = DeviceStatus{
+		ClaimState:    DeviceClaimState(src.Status.ClaimState),
+		State:         BlockDeviceState(src.Status.State),
+		Conditions:    src.Status.Conditions,
+		CleanupMethod: CleanupMethod(src.Status.CleanupMethod),
+		Health:        BlockDeviceHealth(src.Status.Health),
+		HealthReasons: src.Status.HealthReasons,
+		Usage:         (*DeviceUsage)(src.Status.Usage),
+		SMART:         (*SMARTSnapshot)(src.Status.SMART),
+	}
+
+	data := blockDeviceConversionData{}
+	ok, err := getConversionData(&dst.ObjectMeta, &data)
+	if err != nil || !ok {
+		return err
+	}
+	dst.Spec.AggregateDevice = data.AggregateDevice
+	dst.Spec.ParentDevice = data.ParentDevice
+	if data.Partitioned != nil {
+		dst.Spec.Partitioned = *data.Partitioned
+	}
+	return nil
+}
+
+// ConvertTo converts this BlockDeviceClaim to the hub version (v1beta1)
+func (src *BlockDeviceClaim) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 BlockDeviceClaim but got a %T", dstRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = v1beta1.DeviceClaimSpec{
+		Selector:   src.Spec.Selector,
+		Resources:  v1beta1.DeviceClaimResources(src.Spec.Resources),
+		DeviceType: src.Spec.DeviceType,
+		Details: v1beta1.DeviceClaimDetails{
+			BlockVolumeMode: v1beta1.BlockDeviceVolumeMode(src.Spec.Details.BlockVolumeMode),
+			DeviceFormat:    src.Spec.Details.DeviceFormat,
+			AllowPartition:  src.Spec.Details.AllowPartition,
+			ZonedModel:      v1beta1.DeviceZonedModel(src.Spec.Details.ZonedModel),
+			Capabilities:    v1beta1.DeviceCapabilityRequirements(src.Spec.Details.Capabilities),
+		},
+		BlockDeviceName:           src.Spec.BlockDeviceName,
+		BlockDeviceNodeAttributes: v1beta1.BlockDeviceNodeAttributes(src.Spec.BlockDeviceNodeAttributes),
+		SelectionStrategy:         v1beta1.SelectionStrategy(src.Spec.SelectionStrategy),
+		Count:                     src.Spec.Count,
+		Placement:                 v1beta1.DevicePlacement(src.Spec.Placement),
+		BlockDeviceNames:          src.Spec.BlockDeviceNames,
+		CleanupMethod:             v1beta1.CleanupMethod(src.Spec.CleanupMethod),
+		ReclaimPolicy:             v1beta1.ReclaimPolicy(src.Spec.ReclaimPolicy),
+	}
+
+	dst.Status = v1beta1.DeviceClaimStatus{
+		Phase:      v1beta1.DeviceClaimPhase(src.Status.Phase),
+		Conditions: src.Status.Conditions,
+	}
+
+	// the deprecated hostName is moved to the node attributes, unless the
+	// node attributes already specify a hostname
+	data := blockDeviceClaimConversionData{HostName: src.Spec.HostName}
+	if src.Spec.HostName != "" && dst.Spec.BlockDeviceNodeAttributes.HostName == "" {
+		dst.Spec.BlockDeviceNodeAttributes.HostName = src.Spec.HostName
+		data.NodeAttributesDefaulted = true
+	}
+	return setConversionData(&dst.ObjectMeta, data, data.HostName != "")
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this BlockDeviceClaim
+func (dst *BlockDeviceClaim) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 BlockDeviceClaim but got a %T", srcRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = DeviceClaimSpec{
+		Selector:   src.Spec.Selector,
+		Resources:  DeviceClaimResources(src.Spec.Resources),
+		DeviceType: src.Spec.DeviceType,
+		Details: DeviceClaimDetails{
+			BlockVolumeMode: BlockDeviceVolumeMode(src.Spec.Details.BlockVolumeMode),
+			DeviceFormat:    src.Spec.Details.DeviceFormat,
+			AllowPartition:  src.Spec.Details.AllowPartition,
+			ZonedModel:      DeviceZonedModel(src.Spec.Details.ZonedModel),
+			Capabilities:    DeviceCapabilityRequirements(src.Spec.Details.Capabilities),
+		},
+		BlockDeviceName:           src.Spec.BlockDeviceName,
+		BlockDeviceNodeAttributes: BlockDeviceNodeAttributes(src.Spec.BlockDeviceNodeAttributes),
+		SelectionStrategy:         SelectionStrategy(src.Spec.SelectionStrategy),
+		Count:                     src.Spec.Count,
+		Placement:                 DevicePlacement(src.Spec.Placement),
+		BlockDeviceNames:          src.Spec.BlockDeviceNames,
+		CleanupMethod:             CleanupMethod(src.Spec.CleanupMethod),
+		ReclaimPolicy:             ReclaimPolicy(src.Spec.ReclaimPolicy),
+	}
+
+	dst.Status = DeviceClaimStatus{
+		Phase:      DeviceClaimPhase(src.Status.Phase),
+		Conditions: src.Status.Conditions,
+	}
+
+	data := blockDeviceClaimConversionData{}
+	ok, err := getConversionData(&dst.ObjectMeta, &data)
+	if err != nil || !ok {
+		return err
+	}
+	dst.Spec.HostName = data.HostName
+	// the hostname copied from hostName is removed from the node attributes,
+	// unless it was changed after the conversion
+	if data.NodeAttributesDefaulted && dst.Spec.BlockDeviceNodeAttributes.HostName == data.HostName {
+		dst.Spec.BlockDeviceNodeAttributes.HostName = ""
+	}
+	return nil
+}
+
+// partitioned returns the value of the deprecated Partitioned field which
+// corresponds to the dependents of a BD
+func partitioned(dependents *DependentDevices) string {
+	if dependents != nil && len(dependents.Partitions) > 0 {
+		return "Yes"
+	}
+	return "No"
+}
+
+// setConversionData stores the conversion data in the annotations of the
+// object. Any existing conversion data is removed if there is no data to store.
+func setConversionData(meta *metav1.ObjectMeta, data interface{}, store bool) error {
+	if !store {
+		delete(meta.Annotations, conversionDataAnnotation)
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("unable to marshal conversion data: %v", err)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[conversionDataAnnotation] = string(raw)
+	return nil
+}
+
+// getConversionData reads the conversion data from the annotations of the
+// object, and removes the annotation. It returns false if the object does not
+// have any conversion data.
+func getConversionData(meta *metav1.ObjectMeta, data interface{}) (bool, error) {
+	raw, ok := meta.Annotations[conversionDataAnnotation]
+	if !ok {
+		return false, nil
+	}
+	delete(meta.Annotations, conversionDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	if err := json.Unmarshal([]byte(raw), data); err != nil {
+		return false, fmt.Errorf("unable to unmarshal conversion data: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+// fuzzIterations is the number of random objects converted in each round trip test
+const fuzzIterations = 1000
+
+// newFuzzer returns a fuzzer which fills the fields of the objects that are
+// converted. The type meta is not fuzzed as it is set by the conversion webhook.
+func newFuzzer() *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.2).Funcs(
+		func(tm *metav1.TypeMeta, c fuzz.Continue) {
+			*tm = metav1.TypeMeta{}
+		},
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1<<40), resource.BinarySI)
+		},
+	)
+}
+
+func TestFuzzyConversion(t *testing.T) {
+	tests := map[string]struct {
+		hub   conversion.Hub
+		spoke conversion.Convertible
+	}{
+		"BlockDevice": {
+			hub:   &v1beta1.BlockDevice{},
+			spoke: &BlockDevice{},
+		},
+		"BlockDeviceClaim": {
+			hub:   &v1beta1.BlockDeviceClaim{},
+			spoke: &BlockDeviceClaim{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name+" spoke-hub-spoke", func(t *testing.T) {
+			fuzzer := newFuzzer()
+			for i := 0; i < fuzzIterations; i++ {
+				spokeBefore := test.spoke.DeepCopyObject().(conversion.Convertible)
+				fuzzer.Fuzz(spokeBefore)
+
+				hub := test.hub.DeepCopyObject().(conversion.Hub)
+				require.NoError(t, spokeBefore.DeepCopyObject().(conversion.Convertible).ConvertTo(hub))
+				spokeAfter := test.spoke.DeepCopyObject().(conversion.Convertible)
+				require.NoError(t, spokeAfter.ConvertFrom(hub))
+
+				if !apiequality.Semantic.DeepEqual(spokeBefore, spokeAfter) {
+					t.Fatalf("round trip changed the object:\n%s", diff.ObjectReflectDiff(spokeBefore, spokeAfter))
+				}
+			}
+		})
+		t.Run(name+" hub-spoke-hub", func(t *testing.T) {
+			fuzzer := newFuzzer()
+			for i := 0; i < fuzzIterations; i++ {
+				hubBefore := test.hub.DeepCopyObject().(conversion.Hub)
+				fuzzer.Fuzz(hubBefore)
+
+				spoke := test.spoke.DeepCopyObject().(conversion.Convertible)
+				require.NoError(t, spoke.ConvertFrom(hubBefore.DeepCopyObject().(conversion.Hub)))
+				hubAfter := test.hub.DeepCopyObject().(conversion.Hub)
+				require.NoError(t, spoke.ConvertTo(hubAfter))
+
+				if !apiequality.Semantic.DeepEqual(hubBefore, hubAfter) {
+					t.Fatalf("round trip changed the object:\n%s", diff.ObjectReflectDiff(hubBefore, hubAfter))
+				}
+			}
+		})
+	}
+}
+
+func TestBlockDeviceConvertTo(t *testing.T) {
+	tests := map[string]struct {
+		spec            DeviceSpec
+		wantAnnotations map[string]string
+	}{
+		"partitioned matches the dependents": {
+			spec: DeviceSpec{
+				Path:        "/dev/nvme0n1",
+				Partitioned: "Yes",
+				Dependents:  &DependentDevices{Partitions: []string{"/dev/nvme0n1p1"}},
+			},
+		},
+		"deprecated fields are preserved": {
+			spec: DeviceSpec{
+				Path:         "/dev/sda1",
+				ParentDevice: "/dev/sda",
+				Partitioned:  "Yes",
+			},
+			wantAnnotations: map[string]string{
+				conversionDataAnnotation: `{"parentDevice":"/dev/sda","partitioned":"Yes"}`,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := &BlockDevice{Spec: test.spec}
+			dst := &v1beta1.BlockDevice{}
+			require.NoError(t, src.ConvertTo(dst))
+			assert.Equal(t, test.spec.Path, dst.Spec.Path)
+			assert.Equal(t, test.wantAnnotations, dst.Annotations)
+		})
+	}
+}
+
+func TestBlockDeviceClaimConvertTo(t *testing.T) {
+	tests := map[string]struct {
+		spec             DeviceClaimSpec
+		wantNodeHostName string
+		wantAnnotations  map[string]string
+	}{
+		"hostname is moved to node attributes": {
+			spec:             DeviceClaimSpec{HostName: "host1"},
+			wantNodeHostName: "host1",
+			wantAnnotations: map[string]string{
+				conversionDataAnnotation: `{"hostName":"host1","nodeAttributesDefaulted":true}`,
+			},
+		},
+		"node attributes hostname is not overwritten": {
+			spec: DeviceClaimSpec{
+				HostName:                  "host1",
+				BlockDeviceNodeAttributes: BlockDeviceNodeAttributes{HostName: "host2"},
+			},
+			wantNodeHostName: "host2",
+			wantAnnotations: map[string]string{
+				conversionDataAnnotation: `{"hostName":"host1"}`,
+			},
+		},
+		"only node attributes hostname": {
+			spec: DeviceClaimSpec{
+				BlockDeviceNodeAttributes: BlockDeviceNodeAttributes{HostName: "host2"},
+			},
+			wantNodeHostName: "host2",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := &BlockDeviceClaim{Spec: test.spec}
+			dst := &v1beta1.BlockDeviceClaim{}
+			require.NoError(t, src.ConvertTo(dst))
+			assert.Equal(t, test.wantNodeHostName, dst.Spec.BlockDeviceNodeAttributes.HostName)
+			assert.Equal(t, test.wantAnnotations, dst.Annotations)
+		})
+	}
+}
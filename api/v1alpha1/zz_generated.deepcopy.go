@@ -16,7 +16,7 @@ func (in *BlockDevice) DeepCopyInto(out *BlockDevice) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDevice.
@@ -43,7 +43,7 @@ func (in *BlockDeviceClaim) DeepCopyInto(out *BlockDeviceClaim) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceClaim.
@@ -143,6 +143,76 @@ func (in *BlockDeviceNodeAttributes) DeepCopy() *BlockDeviceNodeAttributes {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependentDevices) DeepCopyInto(out *DependentDevices) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Holders != nil {
+		in, out := &in.Holders, &out.Holders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Slaves != nil {
+		in, out := &in.Slaves, &out.Slaves
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependentDevices.
+func (in *DependentDevices) DeepCopy() *DependentDevices {
+	if in == nil {
+		return nil
+	}
+	out := new(DependentDevices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceCapabilityRequirements) DeepCopyInto(out *DeviceCapabilityRequirements) {
+	*out = *in
+	if in.Discard != nil {
+		in, out := &in.Discard, &out.Discard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VolatileWriteCache != nil {
+		in, out := &in.VolatileWriteCache, &out.VolatileWriteCache
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FUA != nil {
+		in, out := &in.FUA, &out.FUA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DAX != nil {
+		in, out := &in.DAX, &out.DAX
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WriteZeroes != nil {
+		in, out := &in.WriteZeroes, &out.WriteZeroes
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceCapabilityRequirements.
+func (in *DeviceCapabilityRequirements) DeepCopy() *DeviceCapabilityRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceCapabilityRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceCapacity) DeepCopyInto(out *DeviceCapacity) {
 	*out = *in
@@ -161,6 +231,7 @@ func (in *DeviceCapacity) DeepCopy() *DeviceCapacity {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceClaimDetails) DeepCopyInto(out *DeviceClaimDetails) {
 	*out = *in
+	in.Capabilities.DeepCopyInto(&out.Capabilities)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimDetails.
@@ -204,8 +275,13 @@ func (in *DeviceClaimSpec) DeepCopyInto(out *DeviceClaimSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	out.Details = in.Details
+	in.Details.DeepCopyInto(&out.Details)
 	out.BlockDeviceNodeAttributes = in.BlockDeviceNodeAttributes
+	if in.BlockDeviceNames != nil {
+		in, out := &in.BlockDeviceNames, &out.BlockDeviceNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimSpec.
@@ -221,6 +297,13 @@ func (in *DeviceClaimSpec) DeepCopy() *DeviceClaimSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceClaimStatus) DeepCopyInto(out *DeviceClaimStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimStatus.
@@ -268,6 +351,21 @@ func (in *DeviceDevLink) DeepCopy() *DeviceDevLink {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceMapperInfo) DeepCopyInto(out *DeviceMapperInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceMapperInfo.
+func (in *DeviceMapperInfo) DeepCopy() *DeviceMapperInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceMapperInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceSpec) DeepCopyInto(out *DeviceSpec) {
 	*out = *in
@@ -277,6 +375,11 @@ func (in *DeviceSpec) DeepCopyInto(out *DeviceSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.Dependents != nil {
+		in, out := &in.Dependents, &out.Dependents
+		*out = new(DependentDevices)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Details = in.Details
 	if in.DevLinks != nil {
 		in, out := &in.DevLinks, &out.DevLinks
@@ -285,8 +388,43 @@ func (in *DeviceSpec) DeepCopyInto(out *DeviceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DeviceMapper != nil {
+		in, out := &in.DeviceMapper, &out.DeviceMapper
+		*out = new(DeviceMapperInfo)
+		**out = **in
+	}
 	out.FileSystem = in.FileSystem
+	if in.LVM != nil {
+		in, out := &in.LVM, &out.LVM
+		*out = new(LVMInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Multipath != nil {
+		in, out := &in.Multipath, &out.Multipath
+		*out = new(MultipathInfo)
+		(*in).DeepCopyInto(*out)
+	}
 	out.NodeAttributes = in.NodeAttributes
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(PartitionInfo)
+		**out = **in
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = new(QueueInfo)
+		**out = **in
+	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(TransportInfo)
+		**out = **in
+	}
+	if in.Zoned != nil {
+		in, out := &in.Zoned, &out.Zoned
+		*out = new(ZonedInfo)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceSpec.
@@ -302,6 +440,28 @@ func (in *DeviceSpec) DeepCopy() *DeviceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthReasons != nil {
+		in, out := &in.HealthReasons, &out.HealthReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(DeviceUsage)
+		**out = **in
+	}
+	if in.SMART != nil {
+		in, out := &in.SMART, &out.SMART
+		*out = new(SMARTSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceStatus.
@@ -314,6 +474,21 @@ func (in *DeviceStatus) DeepCopy() *DeviceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceUsage) DeepCopyInto(out *DeviceUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceUsage.
+func (in *DeviceUsage) DeepCopy() *DeviceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FileSystemInfo) DeepCopyInto(out *FileSystemInfo) {
 	*out = *in
@@ -329,6 +504,66 @@ func (in *FileSystemInfo) DeepCopy() *FileSystemInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LVMInfo) DeepCopyInto(out *LVMInfo) {
+	*out = *in
+	if in.LogicalVolumes != nil {
+		in, out := &in.LogicalVolumes, &out.LogicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PhysicalVolumes != nil {
+		in, out := &in.PhysicalVolumes, &out.PhysicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LVMInfo.
+func (in *LVMInfo) DeepCopy() *LVMInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(LVMInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultipathInfo) DeepCopyInto(out *MultipathInfo) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]MultipathPath, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultipathInfo.
+func (in *MultipathInfo) DeepCopy() *MultipathInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MultipathInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultipathPath) DeepCopyInto(out *MultipathPath) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultipathPath.
+func (in *MultipathPath) DeepCopy() *MultipathPath {
+	if in == nil {
+		return nil
+	}
+	out := new(MultipathPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAttribute) DeepCopyInto(out *NodeAttribute) {
 	*out = *in
@@ -343,3 +578,83 @@ func (in *NodeAttribute) DeepCopy() *NodeAttribute {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionInfo) DeepCopyInto(out *PartitionInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionInfo.
+func (in *PartitionInfo) DeepCopy() *PartitionInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueInfo) DeepCopyInto(out *QueueInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueInfo.
+func (in *QueueInfo) DeepCopy() *QueueInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMARTSnapshot) DeepCopyInto(out *SMARTSnapshot) {
+	*out = *in
+	if in.Temperature != nil {
+		in, out := &in.Temperature, &out.Temperature
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMARTSnapshot.
+func (in *SMARTSnapshot) DeepCopy() *SMARTSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(SMARTSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportInfo) DeepCopyInto(out *TransportInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportInfo.
+func (in *TransportInfo) DeepCopy() *TransportInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZonedInfo) DeepCopyInto(out *ZonedInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZonedInfo.
+func (in *ZonedInfo) DeepCopy() *ZonedInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ZonedInfo)
+	in.DeepCopyInto(out)
+	return out
+}
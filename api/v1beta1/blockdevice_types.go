@@ -0,0 +1,767 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceSpec defines the properties and runtime status of a BlockDevice
+type DeviceSpec struct {
+
+	// Capacity
+	Capacity DeviceCapacity `json:"capacity"`
+
+	// ClaimRef is the reference to the BDC which has claimed this BD
+	// +optional
+	ClaimRef *v1.ObjectReference `json:"claimRef,omitempty"`
+
+	// Dependents contains the devices related to this BD in the device
+	// hierarchy, like the parent, partitions, holders and slaves
+	// +optional
+	Dependents *DependentDevices `json:"dependents,omitempty"`
+
+	// Details contain static attributes of BD like model,serial, and so forth
+	// +optional
+	Details DeviceDetails `json:"details"`
+
+	// DevLinks contains soft links of a block device like
+	// /dev/disk/by-id/...
+	// /dev/disk/by-path/...
+	DevLinks []DeviceDevLink `json:"devlinks"`
+
+	// DeviceMapper contains the details of the device mapper device,
+	// if the BD is a DM device like lvm, crypt or mpath
+	// +optional
+	DeviceMapper *DeviceMapperInfo `json:"deviceMapper,omitempty"`
+
+	// FileSystem contains mountpoint and filesystem type
+	// +optional
+	FileSystem FileSystemInfo `json:"filesystem,omitempty"`
+
+	// LVM contains the details of the LVM volume group to which the BD
+	// belongs, if the BD is a physical volume or a logical volume
+	// +optional
+	LVM *LVMInfo `json:"lvm,omitempty"`
+
+	// Multipath contains the paths of the BD, if the BD is a multipath device
+	// +optional
+	Multipath *MultipathInfo `json:"multipath,omitempty"`
+
+	// NodeAttributes has the details of the node on which BD is attached
+	NodeAttributes NodeAttribute `json:"nodeAttributes"`
+
+	// Partition contains the partition table details of the device, and the
+	// partition entry details if the BD is a partition
+	// +optional
+	Partition *PartitionInfo `json:"partition,omitempty"`
+
+	// Path is the absolute path of the device (e.g. /dev/sdb, /dev/nvme0n1)
+	// or of the sparse file backing the BD
+	// +kubebuilder:validation:Pattern=`^/\S+$`
+	Path string `json:"path"`
+
+	// Queue contains the IO capabilities and the queue settings of the BD
+	// +optional
+	Queue *QueueInfo `json:"queue,omitempty"`
+
+	// Transport contains the bus or transport through which the BD is attached,
+	// along with the details of the target if the BD is attached over a fabric
+	// +optional
+	Transport *TransportInfo `json:"transport,omitempty"`
+
+	// Zoned contains the zoned model and the zone details of the BD, if the
+	// BD is a zoned device like a host managed SMR HDD or a ZNS SSD
+	// +optional
+	Zoned *ZonedInfo `json:"zoned,omitempty"`
+}
+
+// NodeAttribute defines the attributes of a node where
+// the block device is attached.
+//
+// Note: Prior to introducing NodeAttributes, the BD would
+// only support gathering hostname and add it as a label
+// to the BD resource.
+//
+// In some use cases, the caller has access only to node name, not
+// the hostname. node name and hostname are different in certain
+// Kubernetes clusters.
+//
+// NodeAttributes is added to contain attributes that are not
+// available on the labels like - node name, uuid, etc.
+//
+// The node attributes are helpful in querying for block devices
+// based on node attributes.  Also, adding this in the spec allows for
+// displaying in node name in the `kubectl get bd`
+//
+// The system UUID of the node is captured, to determine if the node
+// was recreated with same node name.
+type NodeAttribute struct {
+	// NodeName is the name of the Kubernetes node resource on which the device is attached
+	// +optional
+	NodeName string `json:"nodeName"`
+
+	// Zone is the failure domain zone of the node, from the
+	// topology.kubernetes.io/zone label on the node
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Region is the failure domain region of the node, from the
+	// topology.kubernetes.io/region label on the node
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// SystemUUID is the system UUID reported by the node, which changes when
+	// the node is recreated on a different machine with the same name
+	// +optional
+	SystemUUID string `json:"systemUUID,omitempty"`
+
+	// BootID is the boot ID reported by the node, which changes on every
+	// reboot of the node
+	// +optional
+	BootID string `json:"bootID,omitempty"`
+}
+
+// DeviceCapacity defines the physical and logical size of the block device
+type DeviceCapacity struct {
+	// Storage is the blockdevice capacity in bytes
+	Storage uint64 `json:"storage"`
+
+	// PhysicalSectorSize is blockdevice physical-Sector size in bytes
+	// +optional
+	PhysicalSectorSize uint32 `json:"physicalSectorSize"`
+
+	// LogicalSectorSize is blockdevice logical-sector size in bytes
+	// +optional
+	LogicalSectorSize uint32 `json:"logicalSectorSize"`
+}
+
+// DeviceDetails represent certain hardware/static attributes of the block device
+type DeviceDetails struct {
+	// DeviceType represents the type of device like
+	// sparse, disk, partition, lvm, crypt
+	// +kubebuilder:validation:Enum:=disk;partition;sparse;loop;lvm;crypt;dm;mpath
+	// +optional
+	DeviceType string `json:"deviceType"`
+
+	// DriveType is the type of backing drive, HDD/SSD
+	// +kubebuilder:validation:Enum:=HDD;SSD;Unknown;""
+	// +optional
+	DriveType string `json:"driveType"`
+
+	// LogicalBlockSize is the logical block size in bytes
+	// reported by /sys/class/block/sda/queue/logical_block_size
+	// +optional
+	LogicalBlockSize uint32 `json:"logicalBlockSize"`
+
+	// PhysicalBlockSize is the physical block size in bytes
+	// reported by /sys/class/block/sda/queue/physical_block_size
+	// +optional
+	PhysicalBlockSize uint32 `json:"physicalBlockSize"`
+
+	// HardwareSectorSize is the hardware sector size in bytes
+	// +optional
+	HardwareSectorSize uint32 `json:"hardwareSectorSize"`
+
+	// Model is model of disk
+	// +optional
+	Model string `json:"model"`
+
+	// Compliance is standards/specifications version implemented by device firmware
+	//  such as SPC-1, SPC-2, etc
+	// +optional
+	Compliance string `json:"compliance"`
+
+	// Serial is serial number of disk
+	// +optional
+	Serial string `json:"serial"`
+
+	// Vendor is vendor of disk
+	// +optional
+	Vendor string `json:"vendor"`
+
+	// FirmwareRevision is the disk firmware revision
+	// +optional
+	FirmwareRevision string `json:"firmwareRevision"`
+}
+
+// FileSystemInfo defines the filesystem type and mountpoint of the device if it exists
+type FileSystemInfo struct {
+	// Type represents the FileSystem type of the block device
+	// +optional
+	Type string `json:"fsType,omitempty"`
+
+	//MountPoint represents the mountpoint of the block device.
+	// +optional
+	Mountpoint string `json:"mountPoint,omitempty"`
+}
+
+// DependentDevices contains the paths of the devices related to the block device
+type DependentDevices struct {
+	// Parent is the device of which this device is a partition
+	// +optional
+	Parent string `json:"parent,omitempty"`
+
+	// Partitions are the partitions on this device
+	// +optional
+	Partitions []string `json:"partitions,omitempty"`
+
+	// Holders are the devices which are held by this device.
+	// eg: /dev/dm-0 created on /dev/sda1 is a holder of /dev/sda1
+	// +optional
+	Holders []string `json:"holders,omitempty"`
+
+	// Slaves are the devices on which this device is created.
+	// eg: /dev/sda1 is a slave of /dev/dm-0 created on it
+	// +optional
+	Slaves []string `json:"slaves,omitempty"`
+}
+
+// PartitionInfo contains the partition table and partition entry details of a block device
+type PartitionInfo struct {
+	// TableType is the type of the partition table (dos/gpt)
+	// +optional
+	TableType string `json:"tableType,omitempty"`
+
+	// TableUUID is the UUID of the partition table
+	// +optional
+	TableUUID string `json:"tableUUID,omitempty"`
+
+	// Number is the partition number, if the block device is a partition
+	// +optional
+	Number uint32 `json:"number,omitempty"`
+
+	// EntryUUID is the UUID of the partition, if the block device is a partition
+	// +optional
+	EntryUUID string `json:"entryUUID,omitempty"`
+}
+
+// DeviceMapperInfo contains the details of a device mapper block device
+type DeviceMapperInfo struct {
+	// UUID is the DM UUID of the device, as present in /sys/block/dm-X/dm/uuid
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+
+	// MapperPath is the /dev/mapper/<name> path of the device
+	// +optional
+	MapperPath string `json:"mapperPath,omitempty"`
+}
+
+// LVMInfo contains the details of the LVM volume group to which a BD belongs
+type LVMInfo struct {
+	// VolumeGroup is the name of the volume group
+	// +optional
+	VolumeGroup string `json:"volumeGroup,omitempty"`
+
+	// VolumeGroupUUID is the UUID of the volume group
+	// +optional
+	VolumeGroupUUID string `json:"volumeGroupUUID,omitempty"`
+
+	// VolumeGroupSize is the total size of the volume group in bytes
+	// +optional
+	VolumeGroupSize uint64 `json:"volumeGroupSize,omitempty"`
+
+	// VolumeGroupFree is the space in the volume group which is not allocated
+	// to any logical volume, in bytes
+	// +optional
+	VolumeGroupFree uint64 `json:"volumeGroupFree,omitempty"`
+
+	// ExtentSize is the size of a physical extent of the volume group in bytes
+	// +optional
+	ExtentSize uint64 `json:"extentSize,omitempty"`
+
+	// PhysicalVolumeUUID is the UUID of the physical volume, if the BD is a PV
+	// +optional
+	PhysicalVolumeUUID string `json:"physicalVolumeUUID,omitempty"`
+
+	// LogicalVolumes are the names of the logical volumes allocated on the
+	// physical volume, if the BD is a PV
+	// +optional
+	LogicalVolumes []string `json:"logicalVolumes,omitempty"`
+
+	// LogicalVolume is the name of the logical volume, if the BD is an LV
+	// +optional
+	LogicalVolume string `json:"logicalVolume,omitempty"`
+
+	// LogicalVolumeUUID is the UUID of the logical volume, if the BD is an LV
+	// +optional
+	LogicalVolumeUUID string `json:"logicalVolumeUUID,omitempty"`
+
+	// PhysicalVolumes are the paths of the physical volumes on which the
+	// logical volume is allocated, if the BD is an LV
+	// +optional
+	PhysicalVolumes []string `json:"physicalVolumes,omitempty"`
+}
+
+// MultipathInfo contains the details of a multipath device
+type MultipathInfo struct {
+	// Name is the name of the multipath map, eg: mpatha
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Paths are the devices through which the multipath device is accessed
+	// +optional
+	Paths []MultipathPath `json:"paths,omitempty"`
+}
+
+// MultipathPath is a path of a multipath device
+type MultipathPath struct {
+	// Path is the dev path of the device, eg: /dev/sdb
+	Path string `json:"path"`
+
+	// State is the state of the SCSI device of the path, eg: running, offline
+	// +optional
+	State string `json:"state,omitempty"`
+}
+
+// QueueInfo contains the IO capabilities and the queue settings of a BD,
+// as reported by /sys/class/block/<device>/queue
+type QueueInfo struct {
+	// DiscardGranularity is the size in bytes of the internal allocation
+	// unit, in which discard requests are handled by the BD
+	// +optional
+	DiscardGranularity uint64 `json:"discardGranularity,omitempty"`
+
+	// DiscardMaxBytes is the maximum size in bytes of a discard request.
+	// Discard is not supported by the BD if it is not set.
+	// +optional
+	DiscardMaxBytes uint64 `json:"discardMaxBytes,omitempty"`
+
+	// WriteCache is the type of the write cache of the BD, write back if the
+	// BD has a volatile write cache, else write through
+	// +optional
+	WriteCache string `json:"writeCache,omitempty"`
+
+	// OptimalIOSize is the optimal IO size in bytes reported by the BD
+	// +optional
+	OptimalIOSize uint32 `json:"optimalIOSize,omitempty"`
+
+	// MinimumIOSize is the minimum IO size in bytes preferred by the BD
+	// +optional
+	MinimumIOSize uint32 `json:"minimumIOSize,omitempty"`
+
+	// Rotational is set if the BD is a rotational device
+	// +optional
+	Rotational bool `json:"rotational,omitempty"`
+
+	// Scheduler is the IO scheduler in use for the BD, eg: mq-deadline, none
+	// +optional
+	Scheduler string `json:"scheduler,omitempty"`
+
+	// NumberOfRequests is the number of requests that can be queued to the BD
+	// +optional
+	NumberOfRequests uint32 `json:"numberOfRequests,omitempty"`
+
+	// DAX is set if the BD supports direct access
+	// +optional
+	DAX bool `json:"dax,omitempty"`
+
+	// FUA is set if the BD supports forced unit access writes
+	// +optional
+	FUA bool `json:"fua,omitempty"`
+
+	// WriteZeroesMaxBytes is the maximum size in bytes of a write zeroes
+	// request. Write zeroes is not supported by the BD if it is not set.
+	// +optional
+	WriteZeroesMaxBytes uint64 `json:"writeZeroesMaxBytes,omitempty"`
+}
+
+// TransportInfo contains the transport through which a BD is attached
+type TransportInfo struct {
+	// Type is the bus or transport of the BD, eg: sata, sas, nvme-pcie,
+	// nvme-tcp, nvme-rdma, nvme-fc, iscsi, fc, usb, virtio
+	Type string `json:"type"`
+
+	// Target is the IQN of the iSCSI target, or the NQN of the NVMe subsystem
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// Portal is the address of the target, eg: 10.0.0.1:3260
+	// +optional
+	Portal string `json:"portal,omitempty"`
+
+	// SessionID is the iSCSI session ID, or the NVMe controller ID
+	// +optional
+	SessionID string `json:"sessionID,omitempty"`
+
+	// WWPN is the world wide port name of the remote FC port
+	// +optional
+	WWPN string `json:"wwpn,omitempty"`
+}
+
+// ZonedInfo contains the zoned model and the zone details of a BD
+type ZonedInfo struct {
+	// Model is the zoned model of the BD
+	// +kubebuilder:validation:Enum:=host-aware;host-managed
+	Model string `json:"model"`
+
+	// NumberOfZones is the number of zones of the BD
+	// +optional
+	NumberOfZones uint32 `json:"numberOfZones,omitempty"`
+
+	// ZoneSize is the size of a zone in bytes
+	// +optional
+	ZoneSize uint64 `json:"zoneSize,omitempty"`
+
+	// MaxOpenZones is the maximum number of zones that can be open at a time.
+	// Not set if there is no limit.
+	// +optional
+	MaxOpenZones uint32 `json:"maxOpenZones,omitempty"`
+
+	// MaxActiveZones is the maximum number of zones that can be open or
+	// closed at a time. Not set if there is no limit.
+	// +optional
+	MaxActiveZones uint32 `json:"maxActiveZones,omitempty"`
+}
+
+// DeviceDevLink holds the mapping between type and links like by-id type or by-path type link
+type DeviceDevLink struct {
+	// Kind is the type of link like by-id, by-path, by-uuid or by-partuuid.
+	// It is the name of the /dev/disk directory containing the links.
+	Kind string `json:"kind,omitempty"`
+
+	// Links are the soft links
+	Links []string `json:"links,omitempty"`
+}
+
+// DeviceStatus defines the observed state of BlockDevice
+type DeviceStatus struct {
+	// ClaimState represents the claim state of the block device
+	// +kubebuilder:validation:Enum:=Claimed;Unclaimed;Released;CleanupFailed;Retained
+	ClaimState DeviceClaimState `json:"claimState"`
+
+	// State is the current state of the blockdevice (Active/Inactive/Unknown)
+	// +kubebuilder:validation:Enum:=Active;Inactive;Unknown
+	State BlockDeviceState `json:"state"`
+
+	// Conditions are the latest available observations of the blockdevice's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// CleanupMethod is the cleanup method requested by the claim which released
+	// the blockdevice. It is used while cleaning up the released blockdevice, and
+	// is cleared once the blockdevice is Unclaimed.
+	// +optional
+	// +kubebuilder:validation:Enum:=wipefs;discard;zero;ata-secure-erase;nvme-format
+	CleanupMethod CleanupMethod `json:"cleanupMethod,omitempty"`
+
+	// Health is the health of the blockdevice evaluated by NDM from the SMART
+	// data reported by the device (Healthy/Warning/Failing/Unknown)
+	// +optional
+	// +kubebuilder:validation:Enum:=Healthy;Warning;Failing;Unknown
+	Health BlockDeviceHealth `json:"health,omitempty"`
+
+	// HealthReasons are the checks which caused the blockdevice to be
+	// evaluated as not healthy
+	// +optional
+	HealthReasons []string `json:"healthReasons,omitempty"`
+
+	// Usage represents whether the block device is in use by a storage engine
+	// +optional
+	Usage *DeviceUsage `json:"usage,omitempty"`
+
+	// SMART is the latest snapshot of the SMART data reported by the device
+	// +optional
+	SMART *SMARTSnapshot `json:"smart,omitempty"`
+}
+
+// DeviceUsage represents the usage of the block device by storage engines
+type DeviceUsage struct {
+	// InUse is set if the device is in use by a storage engine
+	InUse bool `json:"inUse"`
+
+	// UsedBy is the storage engine using the device, like cstor, zfs-localpv,
+	// mayastor, localpv, jiva, ceph, lvm, md-raid, luks, bcache, vdo or longhorn
+	// +optional
+	UsedBy string `json:"usedBy,omitempty"`
+
+	// Owner identifies the entity of the storage engine to which the device
+	// belongs, like the LVM volume group or the md RAID array
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// ZPoolName is the name of the zpool, if the device is used by ZFS
+	// +optional
+	ZPoolName string `json:"zpoolName,omitempty"`
+}
+
+// SMARTSnapshot contains the SMART data of the block device which is relevant
+// for determining its health
+type SMARTSnapshot struct {
+	// OverallHealth is the overall health assessment reported by the device (PASSED/FAILED)
+	// +optional
+	OverallHealth string `json:"overallHealth,omitempty"`
+
+	// Temperature is the current temperature of the device in celsius
+	// +optional
+	Temperature *int32 `json:"temperature,omitempty"`
+
+	// PowerOnHours is the number of hours the device has been powered on
+	// +optional
+	PowerOnHours uint64 `json:"powerOnHours,omitempty"`
+
+	// PercentEnduranceUsed is the estimate of the life of the device used, in percent
+	// +optional
+	PercentEnduranceUsed uint32 `json:"percentEnduranceUsed,omitempty"`
+
+	// MediaErrors is the number of unrecovered data integrity errors
+	// +optional
+	MediaErrors uint64 `json:"mediaErrors,omitempty"`
+
+	// ReallocatedSectors is the number of sectors remapped to the spare area
+	// +optional
+	ReallocatedSectors uint64 `json:"reallocatedSectors,omitempty"`
+
+	// PendingSectors is the number of unstable sectors waiting to be remapped
+	// +optional
+	PendingSectors uint64 `json:"pendingSectors,omitempty"`
+
+	// GrownDefects is the number of entries in the grown defect list
+	// +optional
+	GrownDefects uint64 `json:"grownDefects,omitempty"`
+
+	// UncorrectedErrors is the number of read, write and verify errors which
+	// could not be corrected by the device
+	// +optional
+	UncorrectedErrors uint64 `json:"uncorrectedErrors,omitempty"`
+
+	// CRCErrors is the number of CRC errors during interface transfers
+	// +optional
+	CRCErrors uint64 `json:"crcErrors,omitempty"`
+
+	// UnsafeShutdowns is the number of times the device lost power without
+	// being notified
+	// +optional
+	UnsafeShutdowns uint64 `json:"unsafeShutdowns,omitempty"`
+
+	// FailedSelfTests is the number of failed self-tests in the self-test log
+	// +optional
+	FailedSelfTests uint64 `json:"failedSelfTests,omitempty"`
+}
+
+// CleanupMethod specifies how the data on a released blockdevice is removed
+// before the blockdevice is made available for claiming again
+type CleanupMethod string
+
+const (
+	// CleanupMethodWipefs removes the filesystem, partition table and LVM
+	// signatures from the device. The data on the device is not erased.
+	CleanupMethodWipefs CleanupMethod = "wipefs"
+
+	// CleanupMethodDiscard discards all the sectors on the device using blkdiscard
+	CleanupMethodDiscard CleanupMethod = "discard"
+
+	// CleanupMethodZero overwrites the complete device with zeroes
+	CleanupMethodZero CleanupMethod = "zero"
+
+	// CleanupMethodATASecureErase issues an ATA SECURITY ERASE UNIT command
+	// to the drive using hdparm
+	CleanupMethodATASecureErase CleanupMethod = "ata-secure-erase"
+
+	// CleanupMethodNVMeFormat formats the NVMe namespace with user data erase
+	// using nvme-cli
+	CleanupMethodNVMeFormat CleanupMethod = "nvme-format"
+)
+
+// DeviceClaimState defines the observed state of BlockDevice
+type DeviceClaimState string
+
+const (
+	// BlockDeviceUnclaimed represents that the block device is not bound to any BDC,
+	// all cleanup jobs have been completed and is available for claiming.
+	BlockDeviceUnclaimed DeviceClaimState = "Unclaimed"
+
+	// BlockDeviceReleased represents that the block device is released from the BDC,
+	// pending cleanup jobs
+	BlockDeviceReleased DeviceClaimState = "Released"
+
+	// BlockDeviceClaimed represents that the block device is bound to a BDC
+	BlockDeviceClaimed DeviceClaimState = "Claimed"
+
+	// BlockDeviceCleanupFailed represents that the block device was released from
+	// the BDC, but the cleanup job failed. The cleanup can be retried using the
+	// openebs.io/retry-cleanup annotation.
+	BlockDeviceCleanupFailed DeviceClaimState = "CleanupFailed"
+
+	// BlockDeviceRetained represents that the block device was released from a BDC
+	// with Retain reclaim policy. The data on the block device is retained, and it
+	// cannot be claimed till the state is cleared by the administrator.
+	BlockDeviceRetained DeviceClaimState = "Retained"
+)
+
+// BlockDeviceState defines the observed state of the disk
+type BlockDeviceState string
+
+const (
+	// BlockDeviceActive is the state for a block device that is connected to the node
+	BlockDeviceActive BlockDeviceState = "Active"
+
+	// BlockDeviceInactive is the state for a block device that is disconnected from a node
+	BlockDeviceInactive BlockDeviceState = "Inactive"
+
+	// BlockDeviceUnknown is the state for a block device whose state (attached/detached) cannot
+	// be determined at this time.
+	BlockDeviceUnknown BlockDeviceState = "Unknown"
+)
+
+// BlockDeviceHealth defines the health of the disk evaluated from its SMART data
+type BlockDeviceHealth string
+
+const (
+	// BlockDeviceHealthy is the health of a block device whose SMART data is
+	// within all the configured thresholds
+	BlockDeviceHealthy BlockDeviceHealth = "Healthy"
+
+	// BlockDeviceHealthWarning is the health of a block device whose SMART data
+	// has crossed one of the warning thresholds
+	BlockDeviceHealthWarning BlockDeviceHealth = "Warning"
+
+	// BlockDeviceHealthFailing is the health of a block device which reports a
+	// predicted failure, or whose SMART data has crossed one of the failure thresholds
+	BlockDeviceHealthFailing BlockDeviceHealth = "Failing"
+
+	// BlockDeviceHealthUnknown is the health of a block device which does not
+	// report any SMART data
+	BlockDeviceHealthUnknown BlockDeviceHealth = "Unknown"
+)
+
+// Condition types and reasons used in the status of a BlockDevice
+const (
+	// BlockDeviceConditionCleanupInProgress indicates whether a cleanup job is
+	// running on the released BlockDevice
+	BlockDeviceConditionCleanupInProgress = "CleanupInProgress"
+
+	// BlockDeviceConditionCleanupFailed indicates that the cleanup of the released
+	// BlockDevice could not be performed
+	BlockDeviceConditionCleanupFailed = "CleanupFailed"
+
+	// BlockDeviceConditionDeviceMissing indicates that the BlockDevice is not
+	// currently attached to the node
+	BlockDeviceConditionDeviceMissing = "DeviceMissing"
+
+	// BlockDeviceConditionNodeHeartbeatLost indicates that the NDM daemon on the
+	// node of the BlockDevice has stopped renewing its heartbeat
+	BlockDeviceConditionNodeHeartbeatLost = "NodeHeartbeatLost"
+
+	// BlockDeviceConditionNodeDeleted indicates that the node of the BlockDevice
+	// has been deleted
+	BlockDeviceConditionNodeDeleted = "NodeDeleted"
+
+	// BlockDeviceConditionNodeRecreated indicates that the node of the BlockDevice
+	// has been recreated on a different machine with the same name, and the
+	// BlockDevice is yet to be found on the new machine
+	BlockDeviceConditionNodeRecreated = "NodeRecreated"
+
+	// BlockDeviceReasonCleanupJobRunning is used when the cleanup job is running
+	BlockDeviceReasonCleanupJobRunning = "CleanupJobRunning"
+
+	// BlockDeviceReasonCleanupCompleted is used when the cleanup job has completed
+	BlockDeviceReasonCleanupCompleted = "CleanupCompleted"
+
+	// BlockDeviceReasonCleanupCancelled is used when the cleanup job was cancelled
+	// because the BlockDevice is no longer active
+	BlockDeviceReasonCleanupCancelled = "CleanupCancelled"
+
+	// BlockDeviceReasonCleanupJobCreationFailed is used when the cleanup job could not
+	// be created
+	BlockDeviceReasonCleanupJobCreationFailed = "CleanupJobCreationFailed"
+
+	// BlockDeviceReasonCleanupJobFailed is used when the cleanup job failed after
+	// all the retries
+	BlockDeviceReasonCleanupJobFailed = "CleanupJobFailed"
+
+	// BlockDeviceReasonCleanupRetried is used when the cleanup of a BD whose cleanup
+	// had failed is retried
+	BlockDeviceReasonCleanupRetried = "CleanupRetried"
+
+	// BlockDeviceReasonUnsupportedCleanupMethod is used when the requested cleanup
+	// method is invalid or cannot be used on the BlockDevice
+	BlockDeviceReasonUnsupportedCleanupMethod = "UnsupportedCleanupMethod"
+
+	// BlockDeviceReasonDeviceActive is used when the BlockDevice is attached to the node
+	BlockDeviceReasonDeviceActive = "DeviceActive"
+
+	// BlockDeviceReasonDeviceInactive is used when the BlockDevice is detached from the node
+	BlockDeviceReasonDeviceInactive = "DeviceInactive"
+
+	// BlockDeviceReasonDeviceStateUnknown is used when the state of the BlockDevice
+	// cannot be determined
+	BlockDeviceReasonDeviceStateUnknown = "DeviceStateUnknown"
+
+	// BlockDeviceReasonHeartbeatExpired is used when the heartbeat of the node
+	// has not been renewed within the lease duration
+	BlockDeviceReasonHeartbeatExpired = "HeartbeatExpired"
+
+	// BlockDeviceReasonHeartbeatRenewed is used when the heartbeat of the node
+	// is being renewed
+	BlockDeviceReasonHeartbeatRenewed = "HeartbeatRenewed"
+
+	// BlockDeviceReasonNodeNotFound is used when the node of the BlockDevice does not exist
+	BlockDeviceReasonNodeNotFound = "NodeNotFound"
+
+	// BlockDeviceReasonNodeFound is used when the node of the BlockDevice exists
+	BlockDeviceReasonNodeFound = "NodeFound"
+
+	// BlockDeviceReasonDeviceStale is used when the BlockDevice was attached to
+	// the previous machine of a recreated node, and has not been found on the
+	// new machine
+	BlockDeviceReasonDeviceStale = "DeviceStale"
+
+	// BlockDeviceReasonDeviceMoved is used when the BlockDevice was attached to
+	// the previous machine of a recreated node, and has been found on the new
+	// machine
+	BlockDeviceReasonDeviceMoved = "DeviceMoved"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced,shortName=bd
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+
+// BlockDevice is the Schema for the blockdevices API
+// +kubebuilder:printcolumn:name="NodeName",type="string",JSONPath=`.spec.nodeAttributes.nodeName`
+// +kubebuilder:printcolumn:name="Path",type="string",JSONPath=`.spec.path`,priority=1
+// +kubebuilder:printcolumn:name="FSType",type="string",JSONPath=`.spec.filesystem.fsType`,priority=1
+// +kubebuilder:printcolumn:name="Size",type="string",JSONPath=`.spec.capacity.storage`
+// +kubebuilder:printcolumn:name="ClaimState",type="string",JSONPath=`.status.claimState`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Health",type=string,JSONPath=`.status.health`,priority=1
+// +kubebuilder:printcolumn:name="UsedBy",type=string,JSONPath=`.status.usage.usedBy`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type BlockDevice struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceSpec   `json:"spec,omitempty"`
+	Status DeviceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BlockDeviceList contains a list of BlockDevice
+type BlockDeviceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlockDevice `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BlockDevice{}, &BlockDeviceList{})
+}
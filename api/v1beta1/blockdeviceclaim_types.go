@@ -0,0 +1,383 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceClaimSpec defines the request details for a BlockDevice
+type DeviceClaimSpec struct {
+
+	// Selector is used to find block devices to be considered for claiming
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Resources will help with placing claims on Capacity, IOPS
+	// +optional
+	Resources DeviceClaimResources `json:"resources"`
+
+	// DeviceType represents the type of drive like SSD, HDD etc.,
+	// +optional
+	// +nullable
+	DeviceType string `json:"deviceType"`
+
+	// Details of the device to be claimed
+	// +optional
+	Details DeviceClaimDetails `json:"deviceClaimDetails,omitempty"`
+
+	// BlockDeviceName is the reference to the block-device backing this claim
+	// +optional
+	BlockDeviceName string `json:"blockDeviceName,omitempty"`
+
+	// BlockDeviceNodeAttributes is the attributes on the node from which a BD should
+	// be selected for this claim. It can include nodename, failure domain etc.
+	// +optional
+	BlockDeviceNodeAttributes BlockDeviceNodeAttributes `json:"blockDeviceNodeAttributes,omitempty"`
+
+	// SelectionStrategy is the strategy used to pick a BD when more than one
+	// BD matches the claim. If not specified, the default strategy configured
+	// on the NDM operator is used.
+	// +kubebuilder:validation:Enum:=BestFit;WorstFit;SpreadByNode;PreferSSD
+	// +optional
+	SelectionStrategy SelectionStrategy `json:"selectionStrategy,omitempty"`
+
+	// Count is the number of BDs to be claimed. All the BDs are selected using
+	// the same criteria and are bound atomically, i.e. either all the BDs are
+	// claimed or none. Count is not used when BlockDeviceName is specified.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count,omitempty"`
+
+	// Placement specifies how the BDs should be placed across nodes or zones when
+	// more than one BD is claimed. If not specified, BDs can be selected
+	// from any of the nodes.
+	// +optional
+	// +kubebuilder:validation:Enum=SameNode;SpreadAcrossNodes;SpreadAcrossZones
+	Placement DevicePlacement `json:"placement,omitempty"`
+
+	// BlockDeviceNames is the reference to all the block-devices backing this
+	// claim, when more than one BD is claimed
+	// +optional
+	BlockDeviceNames []string `json:"blockDeviceNames,omitempty"`
+
+	// CleanupMethod is the method used to clean up the BDs when they are released
+	// by this claim. The cleanup method set on the BD using the openebs.io/cleanup-method
+	// annotation takes precedence over this. If neither is specified, the default
+	// method configured on the NDM operator is used. CleanupMethod can be used only
+	// with the Recycle reclaim policy.
+	// +optional
+	// +kubebuilder:validation:Enum=wipefs;discard;zero;ata-secure-erase;nvme-format
+	CleanupMethod CleanupMethod `json:"cleanupMethod,omitempty"`
+
+	// ReclaimPolicy specifies what happens to the BDs when they are released by
+	// this claim. The reclaim policy set on the BD using the openebs.io/reclaim-policy
+	// annotation takes precedence over this. Defaults to Recycle if a cleanup method
+	// is specified, else Delete.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Retain;Recycle
+	ReclaimPolicy ReclaimPolicy `json:"reclaimPolicy,omitempty"`
+}
+
+// DeviceClaimResources defines the request by the claim, eg, Capacity, IOPS
+type DeviceClaimResources struct {
+	// Requests describes the minimum resources required. eg: if storage resource of 10G is
+	// requested minimum capacity of 10G should be available
+	//TODO for validating
+	Requests v1.ResourceList `json:"requests"`
+}
+
+const (
+	// ResourceStorage defines the storage required as v1.Quantity
+	ResourceStorage v1.ResourceName = "storage"
+)
+
+// DeviceClaimDetails defines the details of the block device that should be claimed
+type DeviceClaimDetails struct {
+	// BlockVolumeMode represents whether to claim a device in Block mode or Filesystem mode.
+	// These are use cases of BlockVolumeMode:
+	// 1) Not specified: VolumeMode check will not be effective
+	// 2) VolumeModeBlock: BD should not have any filesystem or mountpoint
+	// 3) VolumeModeFileSystem: BD should have a filesystem and mountpoint. If DeviceFormat is
+	//    specified then the format should match with the FSType in BD
+	BlockVolumeMode BlockDeviceVolumeMode `json:"blockVolumeMode,omitempty"`
+
+	//Format of the device required, eg:ext4, xfs
+	DeviceFormat string `json:"formatType,omitempty"`
+
+	//AllowPartition represents whether to claim a full block device or a device that is a partition
+	AllowPartition bool `json:"allowPartition,omitempty"`
+
+	// ZonedModel is the zoned model of the device to be claimed. If not specified,
+	// any device other than a host-managed zoned device can be claimed, since
+	// host-managed devices can be used only by engines which support zones.
+	// +kubebuilder:validation:Enum:=none;host-aware;host-managed
+	// +optional
+	ZonedModel DeviceZonedModel `json:"zonedModel,omitempty"`
+
+	// Capabilities are the IO capabilities required from the device to be claimed
+	// +optional
+	Capabilities DeviceCapabilityRequirements `json:"capabilities,omitempty"`
+}
+
+// DeviceCapabilityRequirements are the IO capabilities required from a BlockDevice.
+// A capability which is not specified is not considered. If a capability is set
+// to true, the BlockDevice should support it, and if set to false, it should not.
+type DeviceCapabilityRequirements struct {
+	// Discard specifies whether the device should support discard
+	// +optional
+	Discard *bool `json:"discard,omitempty"`
+
+	// VolatileWriteCache specifies whether the device should have a volatile
+	// write cache enabled, i.e. write back caching
+	// +optional
+	VolatileWriteCache *bool `json:"volatileWriteCache,omitempty"`
+
+	// FUA specifies whether the device should support forced unit access writes
+	// +optional
+	FUA *bool `json:"fua,omitempty"`
+
+	// DAX specifies whether the device should support direct access
+	// +optional
+	DAX *bool `json:"dax,omitempty"`
+
+	// WriteZeroes specifies whether the device should support write zeroes
+	// +optional
+	WriteZeroes *bool `json:"writeZeroes,omitempty"`
+}
+
+// BlockDeviceVolumeMode specifies the type in which the BlockDevice can be used
+type BlockDeviceVolumeMode string
+
+const (
+	// VolumeModeBlock specifies that the block device needs to be used as a raw block
+	VolumeModeBlock BlockDeviceVolumeMode = "Block"
+
+	// VolumeModeFileSystem specifies that block device will be used with a filesystem
+	// already existing
+	VolumeModeFileSystem BlockDeviceVolumeMode = "FileSystem"
+)
+
+// DeviceZonedModel is the zoned model of a BlockDevice
+type DeviceZonedModel string
+
+const (
+	// ZonedModelNone is a conventional device which is not zoned
+	ZonedModelNone DeviceZonedModel = "none"
+
+	// ZonedModelHostAware is a zoned device which can also be written randomly
+	ZonedModelHostAware DeviceZonedModel = "host-aware"
+
+	// ZonedModelHostManaged is a zoned device which can only be written
+	// sequentially within a zone
+	ZonedModelHostManaged DeviceZonedModel = "host-managed"
+)
+
+// SelectionStrategy specifies how a BlockDevice is selected among all the
+// BlockDevices that match a BlockDeviceClaim
+type SelectionStrategy string
+
+const (
+	// SelectionStrategyBestFit selects the smallest BD that satisfies the
+	// requested capacity
+	SelectionStrategyBestFit SelectionStrategy = "BestFit"
+
+	// SelectionStrategyWorstFit selects the largest BD that satisfies the
+	// requested capacity
+	SelectionStrategyWorstFit SelectionStrategy = "WorstFit"
+
+	// SelectionStrategySpreadByNode selects a BD from the node which has the
+	// least number of claimed BDs
+	SelectionStrategySpreadByNode SelectionStrategy = "SpreadByNode"
+
+	// SelectionStrategyPreferSSD selects an SSD if available, else falls back
+	// to other drive types
+	SelectionStrategyPreferSSD SelectionStrategy = "PreferSSD"
+)
+
+// DevicePlacement specifies how the BlockDevices claimed by a single
+// BlockDeviceClaim are placed across nodes or zones
+type DevicePlacement string
+
+const (
+	// PlacementSameNode selects all the BDs from the same node
+	PlacementSameNode DevicePlacement = "SameNode"
+
+	// PlacementSpreadAcrossNodes selects each BD from a different node
+	PlacementSpreadAcrossNodes DevicePlacement = "SpreadAcrossNodes"
+
+	// PlacementSpreadAcrossZones selects each BD from a node in a different
+	// zone. BDs on nodes without a zone are not selected.
+	PlacementSpreadAcrossZones DevicePlacement = "SpreadAcrossZones"
+)
+
+// ReclaimPolicy specifies what happens to a BlockDevice when it is released
+// by the BlockDeviceClaim
+type ReclaimPolicy string
+
+const (
+	// ReclaimPolicyDelete cleans up the BD using the cleanup method configured
+	// by the administrator, on the BD or on the NDM operator
+	ReclaimPolicyDelete ReclaimPolicy = "Delete"
+
+	// ReclaimPolicyRetain keeps the data on the BD. The BD is moved to Retained
+	// state and cannot be claimed till the administrator clears the state.
+	ReclaimPolicyRetain ReclaimPolicy = "Retain"
+
+	// ReclaimPolicyRecycle cleans up the BD using the cleanup method requested
+	// by the claim
+	ReclaimPolicyRecycle ReclaimPolicy = "Recycle"
+)
+
+// BlockDeviceNodeAttributes contains the attributes of the node from which the BD should
+// be selected for claiming. A BDC can specify one or more attributes. When multiple values
+// are specified, the NDM Operator will claim a Block Device that matches all
+// the requested attributes.
+type BlockDeviceNodeAttributes struct {
+	// NodeName represents the name of the Kubernetes node resource
+	// where the BD should be present
+	NodeName string `json:"nodeName,omitempty"`
+
+	// HostName represents the hostname of the Kubernetes node resource
+	// where the BD should be present
+	HostName string `json:"hostName,omitempty"`
+
+	// Zone represents the failure domain zone of the node where the
+	// BD should be present
+	Zone string `json:"zone,omitempty"`
+
+	// Region represents the failure domain region of the node where the
+	// BD should be present
+	Region string `json:"region,omitempty"`
+}
+
+// DeviceClaimStatus defines the observed state of BlockDeviceClaim
+type DeviceClaimStatus struct {
+	// Phase represents the current phase of the claim
+	Phase DeviceClaimPhase `json:"phase"`
+
+	// Conditions are the latest available observations of the claim's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// DeviceClaimPhase is a typed string for phase field of BlockDeviceClaim.
+type DeviceClaimPhase string
+
+// BlockDeviceClaim CR, when created pass through phases before it got some Devices Assigned.
+// Given below table, have all phases which BlockDeviceClaim CR can go before it is marked done.
+const (
+	// BlockDeviceClaimStatusEmpty represents that the BlockDeviceClaim was just created.
+	BlockDeviceClaimStatusEmpty DeviceClaimPhase = ""
+
+	// BlockDeviceClaimStatusPending represents BlockDeviceClaim has not been assigned devices yet. Rather
+	// search is going on for matching devices.
+	BlockDeviceClaimStatusPending DeviceClaimPhase = "Pending"
+
+	// BlockDeviceClaimStatusDone represents BlockDeviceClaim has been assigned backing blockdevice and ready for use.
+	BlockDeviceClaimStatusDone DeviceClaimPhase = "Bound"
+)
+
+// Condition types and reasons used in the status of a BlockDeviceClaim
+const (
+	// BlockDeviceClaimConditionBound indicates whether the claim is bound to a BlockDevice
+	BlockDeviceClaimConditionBound = "Bound"
+
+	// BlockDeviceClaimConditionSelectionFailed indicates that a BlockDevice could not be
+	// selected for the claim. The reason specifies why the selection failed.
+	BlockDeviceClaimConditionSelectionFailed = "SelectionFailed"
+
+	// BlockDeviceClaimConditionDryRun indicates whether a BlockDevice would be selected
+	// for a claim that is being evaluated in dry run mode
+	BlockDeviceClaimConditionDryRun = "DryRun"
+
+	// BlockDeviceClaimReasonInvalidCapacity is used when the capacity requested by the
+	// claim is invalid
+	BlockDeviceClaimReasonInvalidCapacity = "InvalidCapacity"
+
+	// BlockDeviceClaimReasonNoMatchingDevices is used when none of the BlockDevices
+	// match the criteria specified in the claim
+	BlockDeviceClaimReasonNoMatchingDevices = "NoMatchingDevices"
+
+	// BlockDeviceClaimReasonNodeMismatch is used when no BlockDevices are available
+	// on the node requested by the claim
+	BlockDeviceClaimReasonNodeMismatch = "NodeMismatch"
+
+	// BlockDeviceClaimReasonTopologyMismatch is used when no BlockDevices are available
+	// in the zone or region requested by the claim
+	BlockDeviceClaimReasonTopologyMismatch = "TopologyMismatch"
+
+	// BlockDeviceClaimReasonNodeHeartbeatLost is used when the BlockDevices matching
+	// the claim are on nodes whose NDM daemon has stopped renewing its heartbeat
+	BlockDeviceClaimReasonNodeHeartbeatLost = "NodeHeartbeatLost"
+
+	// BlockDeviceClaimReasonNodeRecreated is used when the BlockDevices matching
+	// the claim are on nodes which have been recreated on a different machine
+	BlockDeviceClaimReasonNodeRecreated = "NodeRecreated"
+
+	// BlockDeviceClaimReasonInsufficientDevices is used when the number of BlockDevices
+	// matching the claim is less than the count requested by the claim
+	BlockDeviceClaimReasonInsufficientDevices = "InsufficientDevices"
+
+	// BlockDeviceClaimReasonUnsupportedSelectionStrategy is used when the selection
+	// strategy requested by the claim is not supported
+	BlockDeviceClaimReasonUnsupportedSelectionStrategy = "UnsupportedSelectionStrategy"
+
+	// BlockDeviceClaimReasonBlockDeviceClaimed is used when a BlockDevice has been
+	// selected and claimed
+	BlockDeviceClaimReasonBlockDeviceClaimed = "BlockDeviceClaimed"
+
+	// BlockDeviceClaimReasonPending is used when the claim is not yet bound
+	BlockDeviceClaimReasonPending = "Pending"
+
+	// BlockDeviceClaimReasonDeviceAvailable is used when a BlockDevice is available
+	// for a claim that is being evaluated in dry run mode
+	BlockDeviceClaimReasonDeviceAvailable = "DeviceAvailable"
+)
+
+// BlockDeviceClaim is the Schema for the blockdeviceclaims API
+//+kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=bdc
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="BlockDeviceName",type="string",JSONPath=`.spec.blockDeviceName`
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type BlockDeviceClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceClaimSpec   `json:"spec,omitempty"`
+	Status DeviceClaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BlockDeviceClaimList contains a list of BlockDeviceClaim
+type BlockDeviceClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlockDeviceClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BlockDeviceClaim{}, &BlockDeviceClaimList{})
+}
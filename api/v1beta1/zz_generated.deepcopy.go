@@ -0,0 +1,660 @@
+// +build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDevice) DeepCopyInto(out *BlockDevice) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDevice.
+func (in *BlockDevice) DeepCopy() *BlockDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockDevice) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceClaim) DeepCopyInto(out *BlockDeviceClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceClaim.
+func (in *BlockDeviceClaim) DeepCopy() *BlockDeviceClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockDeviceClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceClaimList) DeepCopyInto(out *BlockDeviceClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlockDeviceClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceClaimList.
+func (in *BlockDeviceClaimList) DeepCopy() *BlockDeviceClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockDeviceClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceList) DeepCopyInto(out *BlockDeviceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlockDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceList.
+func (in *BlockDeviceList) DeepCopy() *BlockDeviceList {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockDeviceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceNodeAttributes) DeepCopyInto(out *BlockDeviceNodeAttributes) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceNodeAttributes.
+func (in *BlockDeviceNodeAttributes) DeepCopy() *BlockDeviceNodeAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceNodeAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependentDevices) DeepCopyInto(out *DependentDevices) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Holders != nil {
+		in, out := &in.Holders, &out.Holders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Slaves != nil {
+		in, out := &in.Slaves, &out.Slaves
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependentDevices.
+func (in *DependentDevices) DeepCopy() *DependentDevices {
+	if in == nil {
+		return nil
+	}
+	out := new(DependentDevices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceCapabilityRequirements) DeepCopyInto(out *DeviceCapabilityRequirements) {
+	*out = *in
+	if in.Discard != nil {
+		in, out := &in.Discard, &out.Discard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VolatileWriteCache != nil {
+		in, out := &in.VolatileWriteCache, &out.VolatileWriteCache
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FUA != nil {
+		in, out := &in.FUA, &out.FUA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DAX != nil {
+		in, out := &in.DAX, &out.DAX
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WriteZeroes != nil {
+		in, out := &in.WriteZeroes, &out.WriteZeroes
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceCapabilityRequirements.
+func (in *DeviceCapabilityRequirements) DeepCopy() *DeviceCapabilityRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceCapabilityRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceCapacity) DeepCopyInto(out *DeviceCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceCapacity.
+func (in *DeviceCapacity) DeepCopy() *DeviceCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClaimDetails) DeepCopyInto(out *DeviceClaimDetails) {
+	*out = *in
+	in.Capabilities.DeepCopyInto(&out.Capabilities)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimDetails.
+func (in *DeviceClaimDetails) DeepCopy() *DeviceClaimDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClaimDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClaimResources) DeepCopyInto(out *DeviceClaimResources) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimResources.
+func (in *DeviceClaimResources) DeepCopy() *DeviceClaimResources {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClaimResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClaimSpec) DeepCopyInto(out *DeviceClaimSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.Details.DeepCopyInto(&out.Details)
+	out.BlockDeviceNodeAttributes = in.BlockDeviceNodeAttributes
+	if in.BlockDeviceNames != nil {
+		in, out := &in.BlockDeviceNames, &out.BlockDeviceNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimSpec.
+func (in *DeviceClaimSpec) DeepCopy() *DeviceClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClaimStatus) DeepCopyInto(out *DeviceClaimStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimStatus.
+func (in *DeviceClaimStatus) DeepCopy() *DeviceClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDetails) DeepCopyInto(out *DeviceDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDetails.
+func (in *DeviceDetails) DeepCopy() *DeviceDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDevLink) DeepCopyInto(out *DeviceDevLink) {
+	*out = *in
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDevLink.
+func (in *DeviceDevLink) DeepCopy() *DeviceDevLink {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDevLink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceMapperInfo) DeepCopyInto(out *DeviceMapperInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceMapperInfo.
+func (in *DeviceMapperInfo) DeepCopy() *DeviceMapperInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceMapperInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceSpec) DeepCopyInto(out *DeviceSpec) {
+	*out = *in
+	out.Capacity = in.Capacity
+	if in.ClaimRef != nil {
+		in, out := &in.ClaimRef, &out.ClaimRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Dependents != nil {
+		in, out := &in.Dependents, &out.Dependents
+		*out = new(DependentDevices)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Details = in.Details
+	if in.DevLinks != nil {
+		in, out := &in.DevLinks, &out.DevLinks
+		*out = make([]DeviceDevLink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeviceMapper != nil {
+		in, out := &in.DeviceMapper, &out.DeviceMapper
+		*out = new(DeviceMapperInfo)
+		**out = **in
+	}
+	out.FileSystem = in.FileSystem
+	if in.LVM != nil {
+		in, out := &in.LVM, &out.LVM
+		*out = new(LVMInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Multipath != nil {
+		in, out := &in.Multipath, &out.Multipath
+		*out = new(MultipathInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	out.NodeAttributes = in.NodeAttributes
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(PartitionInfo)
+		**out = **in
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = new(QueueInfo)
+		**out = **in
+	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(TransportInfo)
+		**out = **in
+	}
+	if in.Zoned != nil {
+		in, out := &in.Zoned, &out.Zoned
+		*out = new(ZonedInfo)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceSpec.
+func (in *DeviceSpec) DeepCopy() *DeviceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthReasons != nil {
+		in, out := &in.HealthReasons, &out.HealthReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(DeviceUsage)
+		**out = **in
+	}
+	if in.SMART != nil {
+		in, out := &in.SMART, &out.SMART
+		*out = new(SMARTSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceStatus.
+func (in *DeviceStatus) DeepCopy() *DeviceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceUsage) DeepCopyInto(out *DeviceUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceUsage.
+func (in *DeviceUsage) DeepCopy() *DeviceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileSystemInfo) DeepCopyInto(out *FileSystemInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSystemInfo.
+func (in *FileSystemInfo) DeepCopy() *FileSystemInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FileSystemInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LVMInfo) DeepCopyInto(out *LVMInfo) {
+	*out = *in
+	if in.LogicalVolumes != nil {
+		in, out := &in.LogicalVolumes, &out.LogicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PhysicalVolumes != nil {
+		in, out := &in.PhysicalVolumes, &out.PhysicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LVMInfo.
+func (in *LVMInfo) DeepCopy() *LVMInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(LVMInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultipathInfo) DeepCopyInto(out *MultipathInfo) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]MultipathPath, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultipathInfo.
+func (in *MultipathInfo) DeepCopy() *MultipathInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MultipathInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultipathPath) DeepCopyInto(out *MultipathPath) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultipathPath.
+func (in *MultipathPath) DeepCopy() *MultipathPath {
+	if in == nil {
+		return nil
+	}
+	out := new(MultipathPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAttribute) DeepCopyInto(out *NodeAttribute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAttribute.
+func (in *NodeAttribute) DeepCopy() *NodeAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionInfo) DeepCopyInto(out *PartitionInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionInfo.
+func (in *PartitionInfo) DeepCopy() *PartitionInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueInfo) DeepCopyInto(out *QueueInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueInfo.
+func (in *QueueInfo) DeepCopy() *QueueInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMARTSnapshot) DeepCopyInto(out *SMARTSnapshot) {
+	*out = *in
+	if in.Temperature != nil {
+		in, out := &in.Temperature, &out.Temperature
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMARTSnapshot.
+func (in *SMARTSnapshot) DeepCopy() *SMARTSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(SMARTSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportInfo) DeepCopyInto(out *TransportInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportInfo.
+func (in *TransportInfo) DeepCopy() *TransportInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZonedInfo) DeepCopyInto(out *ZonedInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZonedInfo.
+func (in *ZonedInfo) DeepCopy() *ZonedInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ZonedInfo)
+	in.DeepCopyInto(out)
+	return out
+}
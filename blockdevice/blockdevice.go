@@ -118,6 +118,17 @@ type BlockDevice struct {
 	// DMInfo is filled if the device is a DM device
 	DMInfo DeviceMapperInformation
 
+	// LVMInfo is filled if the device is an LVM physical volume or logical volume
+	LVMInfo LVMInformation
+
+	// MultipathInfo is filled if the device is a multipath device, or a path
+	// of a multipath device
+	MultipathInfo MultipathInformation
+
+	// TransportInfo contains the transport through which the device is
+	// attached, and the details of the fabric for network attached devices
+	TransportInfo TransportInformation
+
 	DevUse DeviceUsage
 
 	// PartitionInfo contains details if this blockdevice is a partition
@@ -155,6 +166,94 @@ type SMARTStats struct {
 
 	// PercentEnduranceUsed stores the endurance used in percent
 	PercentEnduranceUsed float64
+
+	// MediaErrors stores the number of unrecovered data integrity errors
+	// detected by the controller
+	MediaErrors uint64
+
+	// PowerOnHours stores the number of hours the device has been powered on
+	PowerOnHours uint64
+
+	// CriticalWarning stores the critical warnings for the state of the
+	// controller, only applicable for NVMe devices
+	CriticalWarning uint8
+
+	// UnsafeShutdowns stores the number of times the device was shutdown
+	// without notifying it before power was lost
+	UnsafeShutdowns uint64
+
+	// ReallocatedSectors stores the number of sectors remapped to the spare area
+	ReallocatedSectors uint64
+
+	// PendingSectors stores the number of unstable sectors waiting to be remapped
+	PendingSectors uint64
+
+	// CRCErrors stores the number of CRC errors during interface transfers
+	CRCErrors uint64
+
+	// GrownDefects stores the number of entries in the grown defect list,
+	// only applicable for SCSI devices
+	GrownDefects uint64
+
+	// UncorrectedReadErrors stores the number of read errors that could not
+	// be corrected by the device, only applicable for SCSI devices
+	UncorrectedReadErrors uint64
+
+	// UncorrectedWriteErrors stores the number of write errors that could not
+	// be corrected by the device, only applicable for SCSI devices
+	UncorrectedWriteErrors uint64
+
+	// UncorrectedVerifyErrors stores the number of verify errors that could not
+	// be corrected by the device, only applicable for SCSI devices
+	UncorrectedVerifyErrors uint64
+
+	// NonMediumErrors stores the number of recoverable errors other than
+	// read, write or verify errors, only applicable for SCSI devices
+	NonMediumErrors uint64
+
+	// StartStopCycles stores the number of start-stop cycles over the device
+	// lifetime, only applicable for SCSI devices
+	StartStopCycles uint64
+
+	// FailedSelfTests stores the number of failed entries in the self-test
+	// results log
+	FailedSelfTests uint64
+
+	// OverallHealth stores the overall health self-assessment reported by the
+	// device, PASSED or FAILED. Empty if the device does not report it.
+	OverallHealth string
+
+	// Attributes stores the SMART attribute table, only applicable for ATA devices
+	Attributes []SMARTAttribute
+}
+
+// SMARTAttribute is an entry in the vendor specific SMART attribute table of an
+// ATA device
+type SMARTAttribute struct {
+	// ID is the attribute ID
+	ID uint8
+
+	// Name is the commonly used name of the attribute
+	Name string
+
+	// Value is the current normalized value of the attribute
+	Value uint8
+
+	// Worst is the worst normalized value of the attribute seen so far
+	Worst uint8
+
+	// Threshold is the normalized value at or below which the attribute is
+	// considered failing
+	Threshold uint8
+
+	// Raw is the vendor specific raw value of the attribute
+	Raw uint64
+
+	// Prefailure is set if the attribute failing indicates an imminent failure
+	Prefailure bool
+
+	// FailingNow is set if the value is at or below the threshold
+	FailingNow bool
 }
 
 // Identifier represents the various identifiers that can be used to
@@ -223,6 +322,69 @@ const (
 
 	// BlockDeviceTypeMultiPath is a multipath device
 	BlockDeviceTypeMultiPath = "mpath"
+
+	// MultipathDMUUIDPrefix is the prefix of the DM UUID of a multipath device
+	MultipathDMUUIDPrefix = BlockDeviceTypeMultiPath + "-"
+
+	// MultipathPathStateUnknown is used when the state of a path of a
+	// multipath device cannot be determined
+	MultipathPathStateUnknown = "unknown"
+)
+
+// The following are the transports through which a device can be attached
+const (
+	// TransportSATA is a device attached to a SATA controller
+	TransportSATA = "sata"
+
+	// TransportSAS is a device attached to a SAS controller
+	TransportSAS = "sas"
+
+	// TransportNVMePCIe is an NVMe device on the PCIe bus
+	TransportNVMePCIe = "nvme-pcie"
+
+	// TransportNVMeTCP is an NVMe over fabrics device using TCP
+	TransportNVMeTCP = "nvme-tcp"
+
+	// TransportNVMeRDMA is an NVMe over fabrics device using RDMA
+	TransportNVMeRDMA = "nvme-rdma"
+
+	// TransportNVMeFC is an NVMe over fabrics device using fibre channel
+	TransportNVMeFC = "nvme-fc"
+
+	// TransportISCSI is an iSCSI device
+	TransportISCSI = "iscsi"
+
+	// TransportFC is a SCSI device attached through fibre channel
+	TransportFC = "fc"
+
+	// TransportUSB is a USB mass storage device
+	TransportUSB = "usb"
+
+	// TransportVirtio is a virtio-blk or virtio-scsi device
+	TransportVirtio = "virtio"
+)
+
+// The following are the zoned models of a device, as reported by the kernel
+const (
+	// ZonedModelNone is a conventional device which is not zoned
+	ZonedModelNone = "none"
+
+	// ZonedModelHostAware is a zoned device which can also be written randomly,
+	// like a conventional device
+	ZonedModelHostAware = "host-aware"
+
+	// ZonedModelHostManaged is a zoned device which can only be written
+	// sequentially within a zone, like host managed SMR HDDs and ZNS SSDs
+	ZonedModelHostManaged = "host-managed"
+)
+
+const (
+	// WriteCacheWriteBack is used when the device has a volatile write cache
+	WriteCacheWriteBack = "write back"
+
+	// WriteCacheWriteThrough is used when the device does not have a volatile
+	// write cache, or the write cache is disabled
+	WriteCacheWriteThrough = "write through"
 )
 
 // DeviceMapperDeviceTypes is the slice of device types that uses a device mapper
@@ -244,6 +406,16 @@ const (
 	DriveTypeUnknown = "Unknown"
 )
 
+const (
+	// SMARTHealthPassed is the overall health reported by a device which does
+	// not predict a failure
+	SMARTHealthPassed = "PASSED"
+
+	// SMARTHealthFailed is the overall health reported by a device which
+	// predicts a failure
+	SMARTHealthFailed = "FAILED"
+)
+
 // FileSystemInformation contains the filesystem and mount information of blockdevice, if present
 type FileSystemInformation struct {
 	// FileSystemUUID is the UUID of the filesystem on the blockdevice
@@ -305,6 +477,14 @@ type DeviceAttribute struct {
 	// This is the actual sector size of the disk
 	HardwareSectorSize uint32
 
+	// ZonedInfo contains the zoned model and the zone limits of the device,
+	// reported by /sys/class/block/sda/queue/zoned and related files
+	ZonedInfo ZonedInformation
+
+	// QueueInfo contains the IO capabilities and the queue settings of the
+	// device, reported by the files in /sys/class/block/sda/queue
+	QueueInfo QueueInformation
+
 	// WWN
 	WWN string
 
@@ -323,6 +503,15 @@ type DeviceAttribute struct {
 
 	// Compliance is implemented specifications version i.e. SPC-1, SPC-2, etc
 	Compliance string
+
+	// NamespaceID is the ID of the namespace, only applicable for NVMe devices
+	NamespaceID uint32
+
+	// EUI64 is the IEEE extended unique identifier of the NVMe namespace
+	EUI64 string
+
+	// NGUID is the namespace globally unique identifier of the NVMe namespace
+	NGUID string
 }
 
 // DevLink represents a type of dev link for a device. A device can have multiple
@@ -385,6 +574,151 @@ type DeviceMapperInformation struct {
 	DevMapperPath string
 }
 
+// LVMInformation contains the details of the LVM volume group to which the
+// device belongs, either as a physical volume or as a logical volume
+type LVMInformation struct {
+	// VGName is the name of the volume group
+	VGName string
+
+	// VGUUID is the UUID of the volume group
+	VGUUID string
+
+	// VGSize is the total size of the volume group in bytes
+	VGSize uint64
+
+	// VGFree is the space in the volume group which is not allocated to any
+	// logical volume, in bytes
+	VGFree uint64
+
+	// ExtentSize is the size of a physical extent of the volume group in bytes
+	ExtentSize uint64
+
+	// PVUUID is the UUID of the physical volume, if the device is a PV
+	PVUUID string
+
+	// LogicalVolumes are the names of the logical volumes allocated on the
+	// physical volume, if the device is a PV
+	LogicalVolumes []string
+
+	// LVName is the name of the logical volume, if the device is an LV
+	LVName string
+
+	// LVUUID is the UUID of the logical volume, if the device is an LV
+	LVUUID string
+
+	// PhysicalVolumes are the paths of the physical volumes on which the
+	// logical volume is allocated, if the device is an LV
+	PhysicalVolumes []string
+}
+
+// MultipathInformation contains the details of a multipath device, or of the
+// multipath device of which the device is a path
+type MultipathInformation struct {
+	// Name is the name of the multipath map as present in <dev-sys-path>/dm/name,
+	// if the device is a multipath device
+	Name string
+
+	// Paths are the paths of the multipath device, if the device is a
+	// multipath device
+	Paths []MultipathPath
+
+	// MultipathDevice is the path of the multipath device, if the device
+	// is a path of a multipath device. eg: /dev/dm-0
+	MultipathDevice string
+}
+
+// MultipathPath is a path of a multipath device
+type MultipathPath struct {
+	// DevPath is the path of the device. eg: /dev/sdb
+	DevPath string
+
+	// State is the state of the SCSI device as present in <dev-sys-path>/device/state.
+	// eg: running, offline, transport-offline
+	State string
+}
+
+// ZonedInformation contains the zoned model of the device, and the zone
+// details if the device is zoned
+type ZonedInformation struct {
+	// Model is the zoned model of the device. eg: none, host-aware, host-managed
+	Model string
+
+	// NumberOfZones is the number of zones reported by queue/nr_zones
+	NumberOfZones uint32
+
+	// ZoneSize is the size of a zone in bytes
+	ZoneSize uint64
+
+	// MaxOpenZones is the maximum number of zones that can be open at a time.
+	// 0 if there is no limit
+	MaxOpenZones uint32
+
+	// MaxActiveZones is the maximum number of zones that can be open or closed
+	// at a time. 0 if there is no limit
+	MaxActiveZones uint32
+}
+
+// QueueInformation contains the IO capabilities and the queue settings of the
+// device, which can be used to tune the storage engine using the device
+type QueueInformation struct {
+	// DiscardGranularity is the size of the internal allocation unit of the
+	// device in bytes, in which discard requests are handled
+	DiscardGranularity uint64
+
+	// DiscardMaxBytes is the maximum size of a discard request in bytes.
+	// Discard is not supported if it is 0
+	DiscardMaxBytes uint64
+
+	// WriteCache is the type of the write cache. eg: write back, write through
+	WriteCache string
+
+	// OptimalIOSize is the optimal IO size in bytes reported by the device
+	OptimalIOSize uint32
+
+	// MinimumIOSize is the minimum IO size in bytes preferred by the device
+	MinimumIOSize uint32
+
+	// Rotational is set if the device is a rotational device
+	Rotational bool
+
+	// Scheduler is the IO scheduler in use for the device. eg: mq-deadline, none
+	Scheduler string
+
+	// NumberOfRequests is the number of requests that can be queued to the device
+	NumberOfRequests uint32
+
+	// DAX is set if the device supports direct access
+	DAX bool
+
+	// FUA is set if the device supports forced unit access writes
+	FUA bool
+
+	// WriteZeroesMaxBytes is the maximum size of a write zeroes request in
+	// bytes. Write zeroes is not supported if it is 0
+	WriteZeroesMaxBytes uint64
+}
+
+// TransportInformation contains the transport through which the device is
+// attached. The details of the target are filled only for fabric devices.
+type TransportInformation struct {
+	// Transport is the bus or transport of the device. eg: sata, nvme-tcp, iscsi
+	Transport string
+
+	// Target is the IQN of the iSCSI target, or the NQN of the NVMe subsystem
+	Target string
+
+	// Portal is the address of the target. eg: 10.0.0.1:3260 for iSCSI,
+	// traddr=10.0.0.1,trsvcid=4420 for NVMe over TCP
+	Portal string
+
+	// SessionID is the iSCSI session ID, or the controller ID of the NVMe
+	// over fabrics controller
+	SessionID string
+
+	// WWPN is the world wide port name of the remote port of an FC device
+	WWPN string
+}
+
 // DependentBlockDevices contains path of all devices that are
 // related to this BlockDevice
 type DependentBlockDevices struct {
@@ -411,6 +745,11 @@ type DependentBlockDevices struct {
 type DeviceUsage struct {
 	InUse  bool
 	UsedBy StorageEngine
+
+	// Owner identifies the entity of the storage engine to which the device
+	// belongs, if it is known. eg: the volume group name for LVM, the array
+	// name or uuid for md RAID or the OSD uuid for ceph.
+	Owner string
 }
 
 // StorageEngine is a typed string for the storage engine
@@ -431,6 +770,27 @@ const (
 
 	// Jiva
 	Jiva StorageEngine = "jiva"
+
+	// Ceph
+	Ceph StorageEngine = "ceph"
+
+	// LVM
+	LVM StorageEngine = "lvm"
+
+	// MDRaid
+	MDRaid StorageEngine = "md-raid"
+
+	// LUKS
+	LUKS StorageEngine = "luks"
+
+	// BCache
+	BCache StorageEngine = "bcache"
+
+	// VDO
+	VDO StorageEngine = "vdo"
+
+	// Longhorn
+	Longhorn StorageEngine = "longhorn"
 )
 
 // Status is used to represent the status of the blockdevice
@@ -456,6 +816,12 @@ const (
 	// Released means the blockdevice is not in use, but cannot be claimed,
 	// because of some pending cleanup tasks
 	Released string = "Released"
+	// CleanupFailed means the blockdevice was released, but the cleanup
+	// tasks failed and need to be retried
+	CleanupFailed string = "CleanupFailed"
+	// Retained means the blockdevice is not in use, but the data on it has
+	// been retained and it cannot be claimed
+	Retained string = "Retained"
 	// Unclaimed means the blockdevice is free and is available for
 	// claiming
 	Unclaimed string = "Unclaimed"
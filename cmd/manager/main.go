@@ -18,6 +18,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	goruntime "runtime"
 	"time"
@@ -28,8 +29,10 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
@@ -37,9 +40,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	openebsv1alpha1 "github.com/openebs/node-disk-manager/api/v1alpha1"
+	openebsv1beta1 "github.com/openebs/node-disk-manager/api/v1beta1"
 	"github.com/openebs/node-disk-manager/pkg/controllers/blockdevice"
 	"github.com/openebs/node-disk-manager/pkg/controllers/blockdeviceclaim"
+	"github.com/openebs/node-disk-manager/pkg/controllers/node"
+	"github.com/openebs/node-disk-manager/pkg/controllers/nodeheartbeat"
 	"github.com/openebs/node-disk-manager/pkg/version"
+	"github.com/openebs/node-disk-manager/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -54,7 +61,10 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
 	utilruntime.Must(openebsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(openebsv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -69,11 +79,19 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var webhookCertDir string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8484", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8585", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the admission and conversion webhooks for BlockDevice and BlockDeviceClaim. "+
+			"Enabling this also migrates the objects to v1beta1, if the CRDs store them in v1beta1.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"The directory that contains the webhook server key and certificate. "+
+			"If not set, the default directory of the webhook server is used.")
 	klog.InitFlags(nil)
 
 	flag.Parse()
@@ -91,6 +109,7 @@ func main() {
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   8787,
+		CertDir:                webhookCertDir,
 		SyncPeriod:             &reconInterval,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -110,15 +129,62 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "BlockDeviceClaim")
 		os.Exit(1)
 	}
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
 	if err = (&blockdevice.BlockDeviceReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("BlockDevice"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("blockdevice-controller"),
+		Clientset: clientset,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BlockDevice")
+		os.Exit(1)
+	}
+	if err = (&nodeheartbeat.NodeHeartbeatReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("NodeHeartbeat"),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("nodeheartbeat-controller"),
+		GracePeriod: nodeheartbeat.GetGracePeriod(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NodeHeartbeat")
+		os.Exit(1)
+	}
+	if err = (&node.NodeReconciler{
 		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("BlockDevice"),
+		Log:      ctrl.Log.WithName("controllers").WithName("Node"),
 		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("blockdevice-controller"),
+		Recorder: mgr.GetEventRecorderFor("node-controller"),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "BlockDevice")
+		setupLog.Error(err, "unable to create controller", "controller", "Node")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		serviceAccount := env.GetServiceAccount()
+		if serviceAccount == "" {
+			setupLog.Error(fmt.Errorf("%s env not set", env.SERVICE_ACCOUNT_ENV), "unable to get service account")
+			os.Exit(1)
+		}
+		if err = webhook.SetupWebhookWithManager(mgr, webhook.ServiceAccountUsername(ns, serviceAccount)); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+		if err = webhook.SetupConversionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook")
+			os.Exit(1)
+		}
+		if err = mgr.Add(&webhook.StorageVersionMigrator{
+			Client: mgr.GetClient(),
+			Reader: mgr.GetAPIReader(),
+		}); err != nil {
+			setupLog.Error(err, "unable to add storage version migrator")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	printVersion()
@@ -17,6 +17,7 @@ limitations under the License.
 package command
 
 import (
+	"context"
 	goflag "flag"
 	"fmt"
 	"os"
@@ -56,6 +57,13 @@ func NewCmdStart() *cobra.Command {
 				fmt.Println(err)
 				os.Exit(1)
 			}
+			// start the cache of the blockdevices on this node, before the
+			// probes start processing the devices
+			err = ctrl.StartBlockDeviceCache(context.Background())
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
 			// Broadcast starts broadcasting controller pointer. Using this
 			// each probe and filter registers themselves.
 			ctrl.Broadcast()
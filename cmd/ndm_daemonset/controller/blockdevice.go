@@ -18,6 +18,7 @@ package controller
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 
@@ -58,6 +59,22 @@ type DeviceInfo struct {
 	DriveType          string   // DriveType represents the type of backing drive HDD/SSD
 	PartitionType      string   // Partition type if the blockdevice is a partition
 	FileSystemInfo     FSInfo   // FileSystem info of the blockdevice like FSType and MountPoint
+	// DependentDevices are the devices related to the blockdevice in the device hierarchy
+	DependentDevices bd.DependentBlockDevices
+	PartitionInfo    bd.PartitionInformation    // PartitionInfo contains the partition table and partition details
+	DMInfo           bd.DeviceMapperInformation // DMInfo contains the details of device mapper devices
+	LVMInfo          bd.LVMInformation          // LVMInfo contains the details of the LVM volume group of the device
+	MultipathInfo    bd.MultipathInformation    // MultipathInfo contains the paths of the device, if it is a multipath device
+	TransportInfo    bd.TransportInformation    // TransportInfo contains the transport through which the device is attached
+	ZonedInfo        bd.ZonedInformation        // ZonedInfo contains the zoned model and zone details of the device
+	QueueInfo        bd.QueueInformation        // QueueInfo contains the IO capabilities and queue settings of the device
+	DevUse           bd.DeviceUsage             // DevUse is the usage of the blockdevice by storage engines
+	ZPoolName        string                     // ZPoolName is the zpool on the blockdevice, if used by ZFS
+	SMARTInfo        bd.SMARTStats              // SMARTInfo is the SMART data reported by the blockdevice
+	// Health is the health of the blockdevice evaluated from the SMART data
+	Health        apis.BlockDeviceHealth
+	HealthReasons []string // HealthReasons are the checks due to which the blockdevice is not healthy
+	Quarantined   bool     // Quarantined is set if the blockdevice is quarantined due to its health
 }
 
 // NewDeviceInfo returns a pointer of empty DeviceInfo
@@ -200,14 +217,24 @@ func (di *DeviceInfo) getObjectMeta() metav1.ObjectMeta {
 	}
 	//objectMeta.Labels[KubernetesHostNameLabel] = di.NodeAttributes[HostNameKey]
 	for k, v := range di.NodeAttributes {
-		if k == HostNameKey {
+		switch k {
+		case HostNameKey:
 			objectMeta.Labels[KubernetesHostNameLabel] = v
-		} else {
+		case ZoneKey:
+			objectMeta.Labels[KubernetesZoneLabel] = v
+		case RegionKey:
+			objectMeta.Labels[KubernetesRegionLabel] = v
+		case SystemUUIDKey, BootIDKey:
+			// recorded only in the node attributes of the spec
+		default:
 			objectMeta.Labels[k] = v
 		}
 	}
 	objectMeta.Labels[NDMDeviceTypeKey] = NDMDefaultDeviceType
 	objectMeta.Labels[NDMManagedKey] = TrueString
+	if di.Quarantined {
+		objectMeta.Labels[NDMQuarantinedKey] = TrueString
+	}
 	// adding custom labels
 	for k, v := range di.Labels {
 		objectMeta.Labels[k] = v
@@ -231,8 +258,12 @@ func (di *DeviceInfo) getTypeMeta() metav1.TypeMeta {
 // of BlockDevice struct of BlockDevice CR.
 func (di *DeviceInfo) getStatus() apis.DeviceStatus {
 	deviceStatus := apis.DeviceStatus{
-		ClaimState: apis.BlockDeviceUnclaimed,
-		State:      NDMActive,
+		ClaimState:    apis.BlockDeviceUnclaimed,
+		State:         NDMActive,
+		Health:        di.Health,
+		HealthReasons: di.HealthReasons,
+		Usage:         di.getDeviceUsage(),
+		SMART:         di.getSMARTSnapshot(),
 	}
 	return deviceStatus
 }
@@ -245,12 +276,27 @@ func (di *DeviceInfo) getStatus() apis.DeviceStatus {
 func (di *DeviceInfo) getDeviceSpec() apis.DeviceSpec {
 	deviceSpec := apis.DeviceSpec{}
 	deviceSpec.NodeAttributes.NodeName = di.NodeAttributes[NodeNameKey]
+	deviceSpec.NodeAttributes.Zone = di.NodeAttributes[ZoneKey]
+	deviceSpec.NodeAttributes.Region = di.NodeAttributes[RegionKey]
+	deviceSpec.NodeAttributes.SystemUUID = di.NodeAttributes[SystemUUIDKey]
+	deviceSpec.NodeAttributes.BootID = di.NodeAttributes[BootIDKey]
 	deviceSpec.Path = di.getPath()
 	deviceSpec.Details = di.getDeviceDetails()
 	deviceSpec.Capacity = di.getDeviceCapacity()
 	deviceSpec.DevLinks = di.getDeviceLinks()
 	deviceSpec.Partitioned = NDMNotPartitioned
+	if len(di.DependentDevices.Partitions) != 0 {
+		deviceSpec.Partitioned = NDMPartitioned
+	}
 	deviceSpec.FileSystem = di.FileSystemInfo.getFileSystemInfo()
+	deviceSpec.Dependents = di.getDependentDevices()
+	deviceSpec.Partition = di.getPartitionInfo()
+	deviceSpec.DeviceMapper = di.getDeviceMapperInfo()
+	deviceSpec.LVM = di.getLVMInfo()
+	deviceSpec.Multipath = di.getMultipathInfo()
+	deviceSpec.Transport = di.getTransportInfo()
+	deviceSpec.Zoned = di.getZonedInfo()
+	deviceSpec.Queue = di.getQueueInfo()
 	return deviceSpec
 }
 
@@ -320,3 +366,176 @@ func (fs *FSInfo) getFileSystemInfo() apis.FileSystemInfo {
 	fsInfo.Mountpoint = fs.MountPoint
 	return fsInfo
 }
+
+// getDependentDevices returns the devices related to the blockdevice in the
+// device hierarchy. nil is returned if the blockdevice has no dependents.
+func (di *DeviceInfo) getDependentDevices() *apis.DependentDevices {
+	dependents := di.DependentDevices
+	if dependents.Parent == "" && len(dependents.Partitions) == 0 &&
+		len(dependents.Holders) == 0 && len(dependents.Slaves) == 0 {
+		return nil
+	}
+	return &apis.DependentDevices{
+		Parent:     dependents.Parent,
+		Partitions: dependents.Partitions,
+		Holders:    dependents.Holders,
+		Slaves:     dependents.Slaves,
+	}
+}
+
+// getPartitionInfo returns the partition table and partition entry details of
+// the blockdevice. nil is returned if the device does not have a partition table.
+func (di *DeviceInfo) getPartitionInfo() *apis.PartitionInfo {
+	if di.PartitionInfo == (bd.PartitionInformation{}) {
+		return nil
+	}
+	return &apis.PartitionInfo{
+		TableType: di.PartitionInfo.PartitionTableType,
+		TableUUID: di.PartitionInfo.PartitionTableUUID,
+		Number:    uint32(di.PartitionInfo.PartitionNumber),
+		EntryUUID: di.PartitionInfo.PartitionEntryUUID,
+	}
+}
+
+// getDeviceMapperInfo returns the device mapper details of the blockdevice.
+// nil is returned if the blockdevice is not a DM device.
+func (di *DeviceInfo) getDeviceMapperInfo() *apis.DeviceMapperInfo {
+	if di.DMInfo == (bd.DeviceMapperInformation{}) {
+		return nil
+	}
+	return &apis.DeviceMapperInfo{
+		UUID:       di.DMInfo.DMUUID,
+		MapperPath: di.DMInfo.DevMapperPath,
+	}
+}
+
+// getLVMInfo returns the details of the LVM volume group of the blockdevice.
+// nil is returned if the blockdevice is neither a PV nor an LV.
+func (di *DeviceInfo) getLVMInfo() *apis.LVMInfo {
+	if di.LVMInfo.VGUUID == "" && di.LVMInfo.PVUUID == "" {
+		return nil
+	}
+	return &apis.LVMInfo{
+		VolumeGroup:        di.LVMInfo.VGName,
+		VolumeGroupUUID:    di.LVMInfo.VGUUID,
+		VolumeGroupSize:    di.LVMInfo.VGSize,
+		VolumeGroupFree:    di.LVMInfo.VGFree,
+		ExtentSize:         di.LVMInfo.ExtentSize,
+		PhysicalVolumeUUID: di.LVMInfo.PVUUID,
+		LogicalVolumes:     di.LVMInfo.LogicalVolumes,
+		LogicalVolume:      di.LVMInfo.LVName,
+		LogicalVolumeUUID:  di.LVMInfo.LVUUID,
+		PhysicalVolumes:    di.LVMInfo.PhysicalVolumes,
+	}
+}
+
+// getMultipathInfo returns the paths of the blockdevice along with their state.
+// nil is returned if the blockdevice is not a multipath device.
+func (di *DeviceInfo) getMultipathInfo() *apis.MultipathInfo {
+	if di.DeviceType != bd.BlockDeviceTypeMultiPath {
+		return nil
+	}
+	multipathInfo := &apis.MultipathInfo{
+		Name: di.MultipathInfo.Name,
+	}
+	for _, path := range di.MultipathInfo.Paths {
+		multipathInfo.Paths = append(multipathInfo.Paths, apis.MultipathPath{
+			Path:  path.DevPath,
+			State: path.State,
+		})
+	}
+	return multipathInfo
+}
+
+// getTransportInfo returns the transport of the blockdevice along with the
+// details of the target. nil is returned if the transport is not known.
+func (di *DeviceInfo) getTransportInfo() *apis.TransportInfo {
+	if di.TransportInfo.Transport == "" {
+		return nil
+	}
+	return &apis.TransportInfo{
+		Type:      di.TransportInfo.Transport,
+		Target:    di.TransportInfo.Target,
+		Portal:    di.TransportInfo.Portal,
+		SessionID: di.TransportInfo.SessionID,
+		WWPN:      di.TransportInfo.WWPN,
+	}
+}
+
+// getZonedInfo returns the zoned model and zone details of the blockdevice.
+// nil is returned if the blockdevice is not a zoned device.
+func (di *DeviceInfo) getZonedInfo() *apis.ZonedInfo {
+	if di.ZonedInfo.Model == "" || di.ZonedInfo.Model == bd.ZonedModelNone {
+		return nil
+	}
+	return &apis.ZonedInfo{
+		Model:          di.ZonedInfo.Model,
+		NumberOfZones:  di.ZonedInfo.NumberOfZones,
+		ZoneSize:       di.ZonedInfo.ZoneSize,
+		MaxOpenZones:   di.ZonedInfo.MaxOpenZones,
+		MaxActiveZones: di.ZonedInfo.MaxActiveZones,
+	}
+}
+
+// getQueueInfo returns the IO capabilities and the queue settings of the
+// blockdevice. nil is returned if they could not be read from sysfs.
+func (di *DeviceInfo) getQueueInfo() *apis.QueueInfo {
+	if di.QueueInfo == (bd.QueueInformation{}) {
+		return nil
+	}
+	return &apis.QueueInfo{
+		DiscardGranularity:  di.QueueInfo.DiscardGranularity,
+		DiscardMaxBytes:     di.QueueInfo.DiscardMaxBytes,
+		WriteCache:          di.QueueInfo.WriteCache,
+		OptimalIOSize:       di.QueueInfo.OptimalIOSize,
+		MinimumIOSize:       di.QueueInfo.MinimumIOSize,
+		Rotational:          di.QueueInfo.Rotational,
+		Scheduler:           di.QueueInfo.Scheduler,
+		NumberOfRequests:    di.QueueInfo.NumberOfRequests,
+		DAX:                 di.QueueInfo.DAX,
+		FUA:                 di.QueueInfo.FUA,
+		WriteZeroesMaxBytes: di.QueueInfo.WriteZeroesMaxBytes,
+	}
+}
+
+// getDeviceUsage returns the usage of the blockdevice by storage engines.
+// nil is returned if the blockdevice is not in use.
+func (di *DeviceInfo) getDeviceUsage() *apis.DeviceUsage {
+	if !di.DevUse.InUse {
+		return nil
+	}
+	return &apis.DeviceUsage{
+		InUse:     di.DevUse.InUse,
+		UsedBy:    string(di.DevUse.UsedBy),
+		Owner:     di.DevUse.Owner,
+		ZPoolName: di.ZPoolName,
+	}
+}
+
+// getSMARTSnapshot returns the SMART data of the blockdevice which is used for
+// evaluating its health. nil is returned if the device does not report SMART data.
+func (di *DeviceInfo) getSMARTSnapshot() *apis.SMARTSnapshot {
+	smartInfo := di.SMARTInfo
+	if !hasHealthData(smartInfo) {
+		return nil
+	}
+	snapshot := &apis.SMARTSnapshot{
+		OverallHealth:        smartInfo.OverallHealth,
+		PowerOnHours:         smartInfo.PowerOnHours,
+		PercentEnduranceUsed: uint32(math.Round(smartInfo.PercentEnduranceUsed)),
+		MediaErrors:          smartInfo.MediaErrors,
+		ReallocatedSectors:   smartInfo.ReallocatedSectors,
+		PendingSectors:       smartInfo.PendingSectors,
+		GrownDefects:         smartInfo.GrownDefects,
+		UncorrectedErrors: smartInfo.UncorrectedReadErrors + smartInfo.UncorrectedWriteErrors +
+			smartInfo.UncorrectedVerifyErrors,
+		CRCErrors:       smartInfo.CRCErrors,
+		UnsafeShutdowns: smartInfo.UnsafeShutdowns,
+		FailedSelfTests: smartInfo.FailedSelfTests,
+	}
+	if smartInfo.TemperatureInfo.CurrentTemperatureDataValid {
+		temperature := int32(smartInfo.TemperatureInfo.CurrentTemperature)
+		snapshot.Temperature = &temperature
+	}
+	return snapshot
+}
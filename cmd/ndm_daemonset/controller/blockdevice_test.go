@@ -0,0 +1,476 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	bd "github.com/openebs/node-disk-manager/blockdevice"
+)
+
+func TestToDeviceHierarchyAndUsage(t *testing.T) {
+	temperature := int32(41)
+
+	tests := map[string]struct {
+		blockDevice      bd.BlockDevice
+		wantPartitioned  string
+		wantDependents   *apis.DependentDevices
+		wantPartition    *apis.PartitionInfo
+		wantDeviceMapper *apis.DeviceMapperInfo
+		wantLVM          *apis.LVMInfo
+		wantMultipath    *apis.MultipathInfo
+		wantTransport    *apis.TransportInfo
+		wantZoned        *apis.ZonedInfo
+		wantQueue        *apis.QueueInfo
+		wantUsage        *apis.DeviceUsage
+		wantSMART        *apis.SMARTSnapshot
+	}{
+		"device without any hierarchy or usage": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-1", DevPath: "/dev/sdb"},
+			},
+			wantPartitioned: NDMNotPartitioned,
+		},
+		"partitioned disk with SMART data": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-2", DevPath: "/dev/sdc"},
+				PartitionInfo: bd.PartitionInformation{
+					PartitionTableType: "gpt",
+					PartitionTableUUID: "5d9b5e4c-0c6e-4b8a-9d38-1f3e0a7c2b19",
+				},
+				DependentDevices: bd.DependentBlockDevices{
+					Partitions: []string{"/dev/sdc1", "/dev/sdc2"},
+				},
+				SMARTInfo: bd.SMARTStats{
+					OverallHealth: bd.SMARTHealthPassed,
+					TemperatureInfo: bd.TemperatureInformation{
+						CurrentTemperatureDataValid: true,
+						CurrentTemperature:          41,
+					},
+					PowerOnHours:           17520,
+					PercentEnduranceUsed:   12.6,
+					ReallocatedSectors:     8,
+					UncorrectedReadErrors:  1,
+					UncorrectedWriteErrors: 2,
+					TotalBytesRead:         1 << 40,
+				},
+			},
+			wantPartitioned: NDMPartitioned,
+			wantDependents: &apis.DependentDevices{
+				Partitions: []string{"/dev/sdc1", "/dev/sdc2"},
+			},
+			wantPartition: &apis.PartitionInfo{
+				TableType: "gpt",
+				TableUUID: "5d9b5e4c-0c6e-4b8a-9d38-1f3e0a7c2b19",
+			},
+			wantSMART: &apis.SMARTSnapshot{
+				OverallHealth:        bd.SMARTHealthPassed,
+				Temperature:          &temperature,
+				PowerOnHours:         17520,
+				PercentEnduranceUsed: 13,
+				ReallocatedSectors:   8,
+				UncorrectedErrors:    3,
+			},
+		},
+		"partition used by zfs": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-3", DevPath: "/dev/sdd1"},
+				Labels:     map[string]string{NDMZpoolName: "zfspv-pool"},
+				PartitionInfo: bd.PartitionInformation{
+					PartitionNumber:    1,
+					PartitionEntryUUID: "8a1f6c3e-2b7d-4e59-a0c4-6d2e9f1b3a75",
+					PartitionTableType: "gpt",
+					PartitionTableUUID: "0f4e2d1c-9b8a-4765-8c3d-2e1f0a9b8c7d",
+				},
+				DependentDevices: bd.DependentBlockDevices{
+					Parent: "/dev/sdd",
+				},
+				DevUse: bd.DeviceUsage{
+					InUse:  true,
+					UsedBy: bd.ZFSLocalPV,
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantDependents: &apis.DependentDevices{
+				Parent: "/dev/sdd",
+			},
+			wantPartition: &apis.PartitionInfo{
+				TableType: "gpt",
+				TableUUID: "0f4e2d1c-9b8a-4765-8c3d-2e1f0a9b8c7d",
+				Number:    1,
+				EntryUUID: "8a1f6c3e-2b7d-4e59-a0c4-6d2e9f1b3a75",
+			},
+			wantUsage: &apis.DeviceUsage{
+				InUse:     true,
+				UsedBy:    string(bd.ZFSLocalPV),
+				ZPoolName: "zfspv-pool",
+			},
+		},
+		"lvm logical volume": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-4", DevPath: "/dev/dm-0"},
+				DMInfo: bd.DeviceMapperInformation{
+					DMUUID:        "LVM-OSlVs5gIXuqSKVPukc2aGPh0AeJw31TJqYIRuRHoodYg9Jwkmyvvk0QNYK4YulHt",
+					DevMapperPath: "/dev/mapper/vg_data-lv0",
+				},
+				LVMInfo: bd.LVMInformation{
+					VGName:          "vg_data",
+					VGUUID:          "OSlVs5-gIXu-qSKV-Pukc-2aGP-h0Ae-Jw31TJ",
+					VGSize:          20 << 30,
+					VGFree:          12 << 30,
+					ExtentSize:      4 << 20,
+					LVName:          "lv0",
+					LVUUID:          "qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt",
+					PhysicalVolumes: []string{"/dev/sde", "/dev/sdf"},
+				},
+				DependentDevices: bd.DependentBlockDevices{
+					Slaves: []string{"/dev/sde", "/dev/sdf"},
+				},
+				DevUse: bd.DeviceUsage{
+					InUse:  true,
+					UsedBy: bd.LVM,
+					Owner:  "vg_data",
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantDependents: &apis.DependentDevices{
+				Slaves: []string{"/dev/sde", "/dev/sdf"},
+			},
+			wantDeviceMapper: &apis.DeviceMapperInfo{
+				UUID:       "LVM-OSlVs5gIXuqSKVPukc2aGPh0AeJw31TJqYIRuRHoodYg9Jwkmyvvk0QNYK4YulHt",
+				MapperPath: "/dev/mapper/vg_data-lv0",
+			},
+			wantLVM: &apis.LVMInfo{
+				VolumeGroup:       "vg_data",
+				VolumeGroupUUID:   "OSlVs5-gIXu-qSKV-Pukc-2aGP-h0Ae-Jw31TJ",
+				VolumeGroupSize:   20 << 30,
+				VolumeGroupFree:   12 << 30,
+				ExtentSize:        4 << 20,
+				LogicalVolume:     "lv0",
+				LogicalVolumeUUID: "qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt",
+				PhysicalVolumes:   []string{"/dev/sde", "/dev/sdf"},
+			},
+			wantUsage: &apis.DeviceUsage{
+				InUse:  true,
+				UsedBy: string(bd.LVM),
+				Owner:  "vg_data",
+			},
+		},
+		"multipath device with a failed path": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-5", DevPath: "/dev/dm-2"},
+				DeviceAttributes: bd.DeviceAttribute{
+					DeviceType: bd.BlockDeviceTypeMultiPath,
+				},
+				DMInfo: bd.DeviceMapperInformation{
+					DMUUID:        "mpath-360014050000000000000000000000a1",
+					DevMapperPath: "/dev/mapper/mpatha",
+				},
+				MultipathInfo: bd.MultipathInformation{
+					Name: "mpatha",
+					Paths: []bd.MultipathPath{
+						{DevPath: "/dev/sdg", State: "running"},
+						{DevPath: "/dev/sdh", State: "transport-offline"},
+					},
+				},
+				DependentDevices: bd.DependentBlockDevices{
+					Slaves: []string{"/dev/sdg", "/dev/sdh"},
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantDependents: &apis.DependentDevices{
+				Slaves: []string{"/dev/sdg", "/dev/sdh"},
+			},
+			wantDeviceMapper: &apis.DeviceMapperInfo{
+				UUID:       "mpath-360014050000000000000000000000a1",
+				MapperPath: "/dev/mapper/mpatha",
+			},
+			wantMultipath: &apis.MultipathInfo{
+				Name: "mpatha",
+				Paths: []apis.MultipathPath{
+					{Path: "/dev/sdg", State: "running"},
+					{Path: "/dev/sdh", State: "transport-offline"},
+				},
+			},
+		},
+		"iSCSI disk": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-6", DevPath: "/dev/sdi"},
+				TransportInfo: bd.TransportInformation{
+					Transport: bd.TransportISCSI,
+					Target:    "iqn.2016-09.com.openebs.cstor:vol1",
+					Portal:    "10.0.0.1:3260",
+					SessionID: "1",
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantTransport: &apis.TransportInfo{
+				Type:      bd.TransportISCSI,
+				Target:    "iqn.2016-09.com.openebs.cstor:vol1",
+				Portal:    "10.0.0.1:3260",
+				SessionID: "1",
+			},
+		},
+		"host managed SMR disk": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-7", DevPath: "/dev/sdj"},
+				DeviceAttributes: bd.DeviceAttribute{
+					ZonedInfo: bd.ZonedInformation{
+						Model:         bd.ZonedModelHostManaged,
+						NumberOfZones: 55880,
+						ZoneSize:      268435456,
+						MaxOpenZones:  128,
+					},
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantZoned: &apis.ZonedInfo{
+				Model:         bd.ZonedModelHostManaged,
+				NumberOfZones: 55880,
+				ZoneSize:      268435456,
+				MaxOpenZones:  128,
+			},
+		},
+		"conventional disk": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-8", DevPath: "/dev/sdk"},
+				DeviceAttributes: bd.DeviceAttribute{
+					ZonedInfo: bd.ZonedInformation{Model: bd.ZonedModelNone},
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+		},
+		"SSD with discard and volatile write cache": {
+			blockDevice: bd.BlockDevice{
+				Identifier: bd.Identifier{UUID: "blockdevice-9", DevPath: "/dev/nvme0n1"},
+				DeviceAttributes: bd.DeviceAttribute{
+					QueueInfo: bd.QueueInformation{
+						DiscardGranularity:  512,
+						DiscardMaxBytes:     2199023255040,
+						WriteCache:          bd.WriteCacheWriteBack,
+						MinimumIOSize:       512,
+						Scheduler:           "none",
+						NumberOfRequests:    1023,
+						FUA:                 true,
+						WriteZeroesMaxBytes: 131072,
+					},
+				},
+			},
+			wantPartitioned: NDMNotPartitioned,
+			wantQueue: &apis.QueueInfo{
+				DiscardGranularity:  512,
+				DiscardMaxBytes:     2199023255040,
+				WriteCache:          bd.WriteCacheWriteBack,
+				MinimumIOSize:       512,
+				Scheduler:           "none",
+				NumberOfRequests:    1023,
+				FUA:                 true,
+				WriteZeroesMaxBytes: 131072,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Controller{}
+			deviceInfo := c.NewDeviceInfoFromBlockDevice(&test.blockDevice)
+			got, err := deviceInfo.ToDevice(c)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantPartitioned, got.Spec.Partitioned)
+			assert.Equal(t, test.wantDependents, got.Spec.Dependents)
+			assert.Equal(t, test.wantPartition, got.Spec.Partition)
+			assert.Equal(t, test.wantDeviceMapper, got.Spec.DeviceMapper)
+			assert.Equal(t, test.wantLVM, got.Spec.LVM)
+			assert.Equal(t, test.wantMultipath, got.Spec.Multipath)
+			assert.Equal(t, test.wantTransport, got.Spec.Transport)
+			assert.Equal(t, test.wantZoned, got.Spec.Zoned)
+			assert.Equal(t, test.wantQueue, got.Spec.Queue)
+			assert.Equal(t, test.wantUsage, got.Status.Usage)
+			assert.Equal(t, test.wantSMART, got.Status.SMART)
+		})
+	}
+}
+
+func TestMergeBlockDeviceDataInUse(t *testing.T) {
+	oldBD := mockEmptyDeviceCr()
+	oldBD.Status.ClaimState = apis.BlockDeviceClaimed
+	oldBD.Spec.Details.Model = "old model"
+
+	newBD := mockEmptyDeviceCr()
+	newBD.Spec.Details.Model = "new model"
+	newBD.Spec.Dependents = &apis.DependentDevices{Holders: []string{"/dev/dm-1"}}
+	newBD.Spec.LVM = &apis.LVMInfo{VolumeGroup: "vg_data", VolumeGroupFree: 12 << 30}
+	newBD.Spec.Multipath = &apis.MultipathInfo{Name: "mpatha", Paths: []apis.MultipathPath{{Path: "/dev/sdg", State: "offline"}}}
+	newBD.Spec.Transport = &apis.TransportInfo{Type: "iscsi", Portal: "10.0.0.1:3260", SessionID: "2"}
+	newBD.Spec.Queue = &apis.QueueInfo{WriteCache: "write through", Scheduler: "mq-deadline"}
+	newBD.Status.Usage = &apis.DeviceUsage{InUse: true, UsedBy: string(bd.LVM), Owner: "vg_data"}
+	newBD.Status.SMART = &apis.SMARTSnapshot{OverallHealth: bd.SMARTHealthPassed, PowerOnHours: 100}
+
+	got := mergeBlockDeviceData(newBD, oldBD)
+	// static details of a claimed device are not updated, but the observed
+	// hierarchy, usage and SMART data are
+	assert.Equal(t, "old model", got.Spec.Details.Model)
+	assert.Equal(t, newBD.Spec.Dependents, got.Spec.Dependents)
+	assert.Equal(t, newBD.Spec.LVM, got.Spec.LVM)
+	assert.Equal(t, newBD.Spec.Multipath, got.Spec.Multipath)
+	assert.Equal(t, newBD.Spec.Transport, got.Spec.Transport)
+	assert.Equal(t, newBD.Spec.Queue, got.Spec.Queue)
+	assert.Equal(t, newBD.Status.Usage, got.Status.Usage)
+	assert.Equal(t, newBD.Status.SMART, got.Status.SMART)
+	assert.Equal(t, apis.BlockDeviceClaimed, got.Status.ClaimState)
+}
+
+func TestMergeBlockDeviceDataOperatorFields(t *testing.T) {
+	conditions := []metav1.Condition{{
+		Type:   apis.BlockDeviceConditionCleanupFailed,
+		Status: metav1.ConditionFalse,
+		Reason: apis.BlockDeviceReasonCleanupRetried,
+	}}
+	claimRef := &v1.ObjectReference{Kind: "BlockDeviceClaim", Name: "bdc-1"}
+
+	tests := map[string]struct {
+		oldStatus      apis.DeviceStatus
+		oldClaimRef    *v1.ObjectReference
+		wantClaimState apis.DeviceClaimState
+	}{
+		"unclaimed device with conditions": {
+			oldStatus: apis.DeviceStatus{
+				ClaimState: apis.BlockDeviceUnclaimed,
+				Conditions: conditions,
+			},
+			wantClaimState: apis.BlockDeviceUnclaimed,
+		},
+		"device being claimed by the operator": {
+			oldStatus: apis.DeviceStatus{
+				ClaimState:    apis.BlockDeviceUnclaimed,
+				CleanupMethod: apis.CleanupMethodZero,
+				Conditions:    conditions,
+			},
+			oldClaimRef:    claimRef,
+			wantClaimState: apis.BlockDeviceUnclaimed,
+		},
+		"device whose status was not set on creation": {
+			wantClaimState: apis.BlockDeviceUnclaimed,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			oldBD := mockEmptyDeviceCr()
+			oldBD.Spec.ClaimRef = test.oldClaimRef
+			oldBD.Status = test.oldStatus
+
+			newBD := mockEmptyDeviceCr()
+			newBD.Spec.Details.Model = "new model"
+			newBD.Status.ClaimState = apis.BlockDeviceUnclaimed
+			newBD.Status.State = NDMActive
+
+			got := mergeBlockDeviceData(newBD, oldBD)
+			// the fields discovered by the daemon are updated, while the fields
+			// maintained by the operator are retained
+			assert.Equal(t, "new model", got.Spec.Details.Model)
+			assert.Equal(t, apis.BlockDeviceState(NDMActive), got.Status.State)
+			assert.Equal(t, test.oldClaimRef, got.Spec.ClaimRef)
+			assert.Equal(t, test.wantClaimState, got.Status.ClaimState)
+			assert.Equal(t, test.oldStatus.CleanupMethod, got.Status.CleanupMethod)
+			assert.Equal(t, test.oldStatus.Conditions, got.Status.Conditions)
+		})
+	}
+}
+
+func TestMergeBlockDeviceDataMovedDevice(t *testing.T) {
+	tests := map[string]struct {
+		oldNodeName   string
+		oldSystemUUID string
+		newSystemUUID string
+		wantMoved     bool
+	}{
+		"device on the same machine": {
+			oldNodeName:   "node-1",
+			oldSystemUUID: "system-uuid-1",
+			newSystemUUID: "system-uuid-1",
+		},
+		"system UUID not recorded": {
+			oldNodeName:   "node-1",
+			newSystemUUID: "system-uuid-1",
+		},
+		"device moved to a recreated node": {
+			oldNodeName:   "node-1",
+			oldSystemUUID: "system-uuid-1",
+			newSystemUUID: "system-uuid-2",
+			wantMoved:     true,
+		},
+		"device moved to a different node": {
+			oldNodeName:   "node-2",
+			oldSystemUUID: "system-uuid-1",
+			newSystemUUID: "system-uuid-2",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			oldBD := mockEmptyDeviceCr()
+			oldBD.Spec.NodeAttributes.NodeName = test.oldNodeName
+			oldBD.Spec.NodeAttributes.SystemUUID = test.oldSystemUUID
+			oldBD.Status.ClaimState = apis.BlockDeviceClaimed
+
+			newBD := mockEmptyDeviceCr()
+			newBD.Spec.NodeAttributes.NodeName = "node-1"
+			newBD.Spec.NodeAttributes.SystemUUID = test.newSystemUUID
+
+			got := mergeBlockDeviceData(newBD, oldBD)
+			assert.Equal(t, test.newSystemUUID, got.Spec.NodeAttributes.SystemUUID)
+			// the conditions are set by the operator from the recorded system UUID
+			assert.Nil(t, meta.FindStatusCondition(got.Status.Conditions, apis.BlockDeviceConditionNodeRecreated))
+			if !test.wantMoved {
+				assert.NotContains(t, got.Annotations, OpenEBSPreviousSystemUUID)
+				return
+			}
+			assert.Equal(t, test.oldSystemUUID, got.Annotations[OpenEBSPreviousSystemUUID])
+		})
+	}
+}
+
+func TestToDeviceNodeAttributes(t *testing.T) {
+	c := &Controller{}
+	blockDevice := bd.BlockDevice{
+		Identifier: bd.Identifier{UUID: "blockdevice-1", DevPath: "/dev/sdb"},
+		NodeAttributes: bd.NodeAttribute{
+			NodeNameKey:   "node1",
+			HostNameKey:   "host1",
+			ZoneKey:       "zone-a",
+			RegionKey:     "region-1",
+			SystemUUIDKey: "system-uuid-1",
+			BootIDKey:     "boot-id-1",
+		},
+	}
+	deviceInfo := c.NewDeviceInfoFromBlockDevice(&blockDevice)
+	got, err := deviceInfo.ToDevice(c)
+	require.NoError(t, err)
+	assert.Equal(t, apis.NodeAttribute{NodeName: "node1", Zone: "zone-a", Region: "region-1",
+		SystemUUID: "system-uuid-1", BootID: "boot-id-1"}, got.Spec.NodeAttributes)
+	assert.Equal(t, "host1", got.Labels[KubernetesHostNameLabel])
+	assert.Equal(t, "zone-a", got.Labels[KubernetesZoneLabel])
+	assert.Equal(t, "region-1", got.Labels[KubernetesRegionLabel])
+	assert.NotContains(t, got.Labels, ZoneKey)
+	assert.NotContains(t, got.Labels, SystemUUIDKey)
+	assert.NotContains(t, got.Labels, BootIDKey)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+// StartBlockDeviceCache starts an informer backed cache of the BlockDevices
+// present on this node. Once the cache is synced, the BlockDevices of this node
+// are looked up from the cache, instead of querying the API server on every event.
+// The cache is stopped when the context is cancelled.
+func (c *Controller) StartBlockDeviceCache(ctx context.Context) error {
+	// field selectors are not supported on custom resources. The informer is
+	// scoped to this node using the hostname label, which the daemon sets on all
+	// the BlockDevices it creates.
+	nodeSelector := labels.SelectorFromSet(labels.Set{
+		KubernetesHostNameLabel: c.NodeAttributes[HostNameKey],
+	})
+
+	bdCache, err := cache.New(c.config, cache.Options{
+		Scheme:    c.Clientset.Scheme(),
+		Mapper:    c.Clientset.RESTMapper(),
+		Namespace: c.Namespace,
+		SelectorsByObject: cache.SelectorsByObject{
+			&apis.BlockDevice{}: {Label: nodeSelector},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create blockdevice cache: %v", err)
+	}
+
+	// the informer has to be registered before the cache is started,
+	// so that the cache waits for it to be synced
+	if _, err = bdCache.GetInformer(ctx, &apis.BlockDevice{}); err != nil {
+		return fmt.Errorf("unable to create blockdevice informer: %v", err)
+	}
+
+	go func() {
+		if err := bdCache.Start(ctx); err != nil {
+			klog.Errorf("blockdevice cache stopped: %v", err)
+		}
+	}()
+
+	if !bdCache.WaitForCacheSync(ctx) {
+		return fmt.Errorf("unable to sync blockdevice cache")
+	}
+	klog.Infof("blockdevice cache synced for node: %s", c.NodeAttributes[HostNameKey])
+	c.BDCache = bdCache
+	return nil
+}
+
+// bdReader returns the reader used for looking up the BlockDevices of this node.
+// The API server is queried directly, if the cache has not been started.
+func (c *Controller) bdReader() client.Reader {
+	if c.BDCache != nil {
+		return c.BDCache
+	}
+	return c.Clientset
+}
@@ -18,16 +18,21 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	util2 "github.com/openebs/node-disk-manager/pkg/controllers/util"
 	"github.com/openebs/node-disk-manager/pkg/util"
 )
 
@@ -40,11 +45,41 @@ func (c *Controller) CreateBlockDevice(blockDevice apis.BlockDevice) error {
 	blockDevice.SetNamespace(c.Namespace)
 
 	blockDeviceCopy := blockDevice.DeepCopy()
-	err := c.Clientset.Create(context.TODO(), blockDeviceCopy)
+	err := c.Clientset.Create(context.TODO(), blockDeviceCopy, util2.DaemonFieldManager)
 	if err == nil {
+		// the status is not persisted when the resource is created, and
+		// has to be set through the status subresource. The patch is retried,
+		// as the blockdevice would otherwise be left without a state until the
+		// next event for the device.
+		created := blockDeviceCopy.DeepCopy()
+		blockDeviceCopy.Status = blockDevice.Status
+		err = retry.OnError(retry.DefaultBackoff, func(err error) bool {
+			return !errors.IsNotFound(err)
+		}, func() error {
+			err := util2.PatchBlockDevice(context.TODO(), c.Clientset, created, blockDeviceCopy, util2.DaemonFieldManager)
+			if !errors.IsConflict(err) {
+				return err
+			}
+			// the blockdevice was modified after it was created, the status is
+			// merged into the latest copy of the blockdevice
+			latest, getErr := c.getLatestBlockDevice(blockDeviceCopy.Name, blockDeviceCopy.Namespace)
+			if getErr != nil {
+				return getErr
+			}
+			created = latest
+			blockDeviceCopy = mergeBlockDeviceData(*blockDevice.DeepCopy(), *latest.DeepCopy())
+			return err
+		})
+		if err != nil {
+			klog.Errorf("eventcode=%s msg=%s : %v rname=%v",
+				"ndm.blockdevice.create.failure", "Unable to set status of blockdevice object",
+				err, blockDeviceCopy.ObjectMeta.Name)
+			return err
+		}
 		klog.Infof("eventcode=%s msg=%s rname=%v",
 			"ndm.blockdevice.create.success", "Created blockdevice object in etcd",
 			blockDeviceCopy.ObjectMeta.Name)
+		c.recordHealthTransition("", blockDeviceCopy)
 		return err
 	}
 
@@ -61,67 +96,110 @@ func (c *Controller) CreateBlockDevice(blockDevice apis.BlockDevice) error {
 	 * cluster so blockdevice object need to be updated with new Node.
 	 */
 	err = c.UpdateBlockDevice(blockDevice, nil)
-	if err == nil {
-		return err
-	}
-
-	if !errors.IsConflict(err) {
+	if err != nil {
 		klog.Error("Updating of BlockDevice Object failed: ", err)
-		return err
-	}
-
-	/*
-	 * Update might failed due to to resource version mismatch which
-	 * can happen if some other entity updating same resource in parallel.
-	 */
-	err = c.UpdateBlockDevice(blockDevice, nil)
-	if err == nil {
-		return err
 	}
-	klog.Error("Update to blockdevice object failed: ", blockDevice.ObjectMeta.Name)
-	return nil
+	return err
 }
 
-// UpdateBlockDevice update the BlockDevice resource in etcd
+// UpdateBlockDevice update the BlockDevice resource in etcd. The changes are
+// sent as merge patches against the given old BlockDevice, the status being patched
+// through the status subresource. If the old BlockDevice is nil or is out of date,
+// the latest resource is fetched and the patch is retried.
 func (c *Controller) UpdateBlockDevice(blockDevice apis.BlockDevice, oldBlockDevice *apis.BlockDevice) error {
-	var err error
-
-	blockDeviceCopy := blockDevice.DeepCopy()
-	if oldBlockDevice == nil {
-		oldBlockDevice = &apis.BlockDevice{}
-		err = c.Clientset.Get(context.TODO(), client.ObjectKey{
-			Namespace: blockDeviceCopy.Namespace,
-			Name:      blockDeviceCopy.Name}, oldBlockDevice)
-		if err != nil {
-			klog.Errorf("eventcode=%s msg=%s : %v, err:%v rname=%v",
-				"ndm.blockdevice.update.failure",
-				"Failed to update block device : unable to get blockdevice object",
-				blockDeviceCopy.ObjectMeta.Name, err, blockDeviceCopy.ObjectMeta.Name)
-			return err
+	var blockDeviceCopy *apis.BlockDevice
+	var oldHealth apis.BlockDeviceHealth
+	patched := false
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if oldBlockDevice == nil {
+			latest, err := c.getLatestBlockDevice(blockDevice.Name, blockDevice.Namespace)
+			if err != nil {
+				klog.Errorf("eventcode=%s msg=%s : %v, err:%v rname=%v",
+					"ndm.blockdevice.update.failure",
+					"Failed to update block device : unable to get blockdevice object",
+					blockDevice.ObjectMeta.Name, err, blockDevice.ObjectMeta.Name)
+				return err
+			}
+			oldBlockDevice = latest
 		}
-	}
 
-	blockDeviceCopy = mergeBlockDeviceData(*blockDeviceCopy, *oldBlockDevice)
+		oldHealth = oldBlockDevice.Status.Health
+		blockDeviceCopy = mergeBlockDeviceData(*blockDevice.DeepCopy(), *oldBlockDevice.DeepCopy())
+		if isBlockDeviceUnchanged(blockDeviceCopy, oldBlockDevice) {
+			return nil
+		}
 
-	err = c.Clientset.Update(context.TODO(), blockDeviceCopy)
+		// the resource version of the old object is sent along with the patches,
+		// so that the changes made to the resource since then, like a claim by
+		// the operator, are not overwritten
+		err := util2.PatchBlockDevice(context.TODO(), c.Clientset, oldBlockDevice, blockDeviceCopy,
+			util2.DaemonFieldManager)
+		if errors.IsConflict(err) {
+			klog.V(4).Infof("blockdevice: %s was modified, retrying the update", blockDevice.Name)
+			oldBlockDevice = nil
+		}
+		patched = err == nil
+		return err
+	})
 	if err != nil {
 		klog.Errorf("eventcode=%s msg=%s : %v rname=%v",
 			"ndm.blockdevice.update.failure", "Unable to update blockdevice object",
-			err, blockDeviceCopy.ObjectMeta.Name)
+			err, blockDevice.ObjectMeta.Name)
 		return err
 	}
+	if !patched {
+		klog.V(4).Infof("no changes in blockdevice: %s. Skipping update", blockDevice.Name)
+		return nil
+	}
 	klog.Infof("eventcode=%s msg=%s rname=%v",
 		"ndm.blockdevice.update.success", "Updated blockdevice object",
 		blockDeviceCopy.ObjectMeta.Name)
+	c.recordHealthTransition(oldHealth, blockDeviceCopy)
 	return nil
 }
 
+// getLatestBlockDevice fetches the BlockDevice resource directly from the API
+// server, bypassing the cache, which may not have seen the latest changes
+func (c *Controller) getLatestBlockDevice(name, namespace string) (*apis.BlockDevice, error) {
+	blockDevice := &apis.BlockDevice{}
+	err := c.Clientset.Get(context.TODO(),
+		client.ObjectKey{Namespace: namespace, Name: name}, blockDevice)
+	if err != nil {
+		return nil, err
+	}
+	return blockDevice, nil
+}
+
+// isBlockDeviceUnchanged checks if the merged BlockDevice is same as the one
+// present in etcd, in which case the update can be skipped.
+func isBlockDeviceUnchanged(newBD, oldBD *apis.BlockDevice) bool {
+	return equality.Semantic.DeepEqual(newBD.ObjectMeta, oldBD.ObjectMeta) &&
+		equality.Semantic.DeepEqual(newBD.Spec, oldBD.Spec) &&
+		equality.Semantic.DeepEqual(newBD.Status, oldBD.Status)
+}
+
+// patchBlockDeviceState sets the state of the BlockDevice using a merge patch on
+// the status subresource. Only the state is sent in the patch, so a stale copy
+// of the BlockDevice does not overwrite any of the other fields.
+func (c *Controller) patchBlockDeviceState(blockDevice *apis.BlockDevice, state apis.BlockDeviceState) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"state": state,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Clientset.Status().Patch(context.TODO(), blockDevice, client.RawPatch(types.MergePatchType, data),
+		util2.DaemonFieldManager)
+}
+
 // DeactivateBlockDevice API is used to set blockdevice status to "inactive" state in etcd
 func (c *Controller) DeactivateBlockDevice(blockDevice apis.BlockDevice) {
 
 	blockDeviceCopy := blockDevice.DeepCopy()
-	blockDeviceCopy.Status.State = NDMInactive
-	err := c.Clientset.Update(context.TODO(), blockDeviceCopy)
+	err := c.patchBlockDeviceState(blockDeviceCopy, NDMInactive)
 	if err != nil {
 		klog.Errorf("eventcode=%s msg=%s : %v rname=%v ",
 			"ndm.blockdevice.deactivate.failure", "Unable to deactivate blockdevice",
@@ -133,11 +211,17 @@ func (c *Controller) DeactivateBlockDevice(blockDevice apis.BlockDevice) {
 		blockDeviceCopy.ObjectMeta.Name)
 }
 
-// GetBlockDevice get Disk resource from etcd
+// GetBlockDevice get Disk resource from etcd. The BlockDevice is looked up
+// from the cache first, and then from etcd, since the cache only contains
+// the BlockDevices of this node.
 func (c *Controller) GetBlockDevice(name string) (*apis.BlockDevice, error) {
 	dvr := &apis.BlockDevice{}
-	err := c.Clientset.Get(context.TODO(),
-		client.ObjectKey{Namespace: c.Namespace, Name: name}, dvr)
+	key := client.ObjectKey{Namespace: c.Namespace, Name: name}
+	err := c.bdReader().Get(context.TODO(), key, dvr)
+	// the device may have been moved from another node
+	if errors.IsNotFound(err) && c.BDCache != nil {
+		err = c.Clientset.Get(context.TODO(), key, dvr)
+	}
 
 	if err != nil {
 		klog.Error("Unable to get blockdevice object : ", err)
@@ -170,7 +254,8 @@ func (c *Controller) DeleteBlockDevice(name string) {
 // ListBlockDeviceResource queries the etcd for the devices
 // and returns list of blockdevice resources.
 // if listAll = true, all the BlockDevices in the cluster will be listed,
-// else only devices present in this node will be listed.
+// else only devices present in this node will be listed. The devices
+// present in this node are listed from the cache, if it is available.
 func (c *Controller) ListBlockDeviceResource(listAll bool) (*apis.BlockDeviceList, error) {
 
 	blockDeviceList := &apis.BlockDeviceList{
@@ -194,11 +279,13 @@ func (c *Controller) ListBlockDeviceResource(listAll bool) (*apis.BlockDeviceLis
 
 	opts = append(opts, client.MatchingLabelsSelector{Selector: sel})
 
+	var reader client.Reader = c.Clientset
 	if !listAll {
 		opts = append(opts, client.MatchingLabels{KubernetesHostNameLabel: c.NodeAttributes[HostNameKey]})
+		reader = c.bdReader()
 	}
 
-	err = c.Clientset.List(context.TODO(), blockDeviceList, opts...)
+	err = reader.List(context.TODO(), blockDeviceList, opts...)
 	if err != nil {
 		return blockDeviceList, err
 	}
@@ -272,8 +359,7 @@ func (c *Controller) MarkBlockDeviceStatusToUnknown() {
 	}
 	for _, item := range blockDeviceList.Items {
 		blockDeviceCopy := item.DeepCopy()
-		blockDeviceCopy.Status.State = NDMUnknown
-		err := c.Clientset.Update(context.TODO(), blockDeviceCopy)
+		err := c.patchBlockDeviceState(blockDeviceCopy, NDMUnknown)
 		if err == nil {
 			klog.Error("Status marked unknown for blockdevice object: ",
 				blockDeviceCopy.ObjectMeta.Name)
@@ -283,23 +369,69 @@ func (c *Controller) MarkBlockDeviceStatusToUnknown() {
 
 // mergeBlockDeviceData merges the data from BlockDevice resource available in etcd
 // with the system generated BlockDevice information
-// If the device is in use, then only the capacity, node attributes, path, devlinks
-// and state will be updated. This is because, these are the fields relevant even if
-// the device is in use.
+// If the device is in use, then only the capacity, node attributes, path, devlinks,
+// dependents, LVM details, multipath paths, state, health, usage and SMART data will be updated. This is because,
+// these are the fields relevant even if the device is in use.
 func mergeBlockDeviceData(newBD, oldBD apis.BlockDevice) *apis.BlockDevice {
+	oldNodeName := oldBD.Spec.NodeAttributes.NodeName
+	oldSystemUUID := oldBD.Spec.NodeAttributes.SystemUUID
 	oldBD.TypeMeta = newBD.TypeMeta
 	oldBD.ObjectMeta = mergeMetadata(newBD.ObjectMeta, oldBD.ObjectMeta)
-	// if the device is in use, only the below fields will be updated.
-	if oldBD.Status.ClaimState != apis.BlockDeviceUnclaimed {
+	// the quarantine label is removed once the device is no longer unhealthy
+	if _, ok := newBD.Labels[NDMQuarantinedKey]; !ok {
+		delete(oldBD.Labels, NDMQuarantinedKey)
+	}
+	// if the device is in use, only the below fields will be updated. A device
+	// without a claim state is one whose status could not be set on creation.
+	if oldBD.Status.ClaimState != apis.BlockDeviceUnclaimed && oldBD.Status.ClaimState != "" {
 		klog.V(4).Infof("device: %s is in use, updating only relevant fields", newBD.Spec.Path)
 		oldBD.Spec.NodeAttributes = newBD.Spec.NodeAttributes
 		oldBD.Spec.Capacity.Storage = newBD.Spec.Capacity.Storage
 		oldBD.Spec.Path = newBD.Spec.Path
 		oldBD.Spec.DevLinks = newBD.Spec.DevLinks
+		// the hierarchy changes when the consumer partitions the device or
+		// creates DM devices on it
+		oldBD.Spec.Dependents = newBD.Spec.Dependents
+		oldBD.Spec.Partition = newBD.Spec.Partition
+		oldBD.Spec.DeviceMapper = newBD.Spec.DeviceMapper
+		// the free space in the volume group changes as LVs are created on it
+		oldBD.Spec.LVM = newBD.Spec.LVM
+		// the state of the paths changes when a path fails
+		oldBD.Spec.Multipath = newBD.Spec.Multipath
+		// the session and portal change when a fabric device reconnects
+		oldBD.Spec.Transport = newBD.Spec.Transport
+		// the write cache and the scheduler can be changed at runtime, and
+		// are picked up on the next event for the device
+		oldBD.Spec.Queue = newBD.Spec.Queue
 		oldBD.Status.State = newBD.Status.State
+		oldBD.Status.Health = newBD.Status.Health
+		oldBD.Status.HealthReasons = newBD.Status.HealthReasons
+		oldBD.Status.Usage = newBD.Status.Usage
+		oldBD.Status.SMART = newBD.Status.SMART
 	} else {
+		// the claim reference, claim state, cleanup method and conditions are
+		// maintained by the operator, and should not be overwritten by the daemon
+		claimRef := oldBD.Spec.ClaimRef
 		oldBD.Spec = newBD.Spec
-		oldBD.Status = newBD.Status
+		oldBD.Spec.ClaimRef = claimRef
+		status := newBD.Status
+		status.Conditions = oldBD.Status.Conditions
+		status.CleanupMethod = oldBD.Status.CleanupMethod
+		if oldBD.Status.ClaimState != "" {
+			status.ClaimState = oldBD.Status.ClaimState
+		}
+		oldBD.Status = status
+	}
+
+	// the device was attached to the previous machine of a node which was
+	// recreated with the same name. The previous system UUID is recorded, so
+	// that the operator can report the move of a claimed device.
+	newSystemUUID := newBD.Spec.NodeAttributes.SystemUUID
+	if oldNodeName == newBD.Spec.NodeAttributes.NodeName &&
+		oldSystemUUID != "" && newSystemUUID != "" && oldSystemUUID != newSystemUUID {
+		klog.Infof("device: %s moved from the machine with system UUID %s to %s",
+			newBD.Name, oldSystemUUID, newSystemUUID)
+		oldBD.Annotations[OpenEBSPreviousSystemUUID] = oldSystemUUID
 	}
 	return &oldBD
 }
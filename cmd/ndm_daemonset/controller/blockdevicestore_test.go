@@ -17,10 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
 )
@@ -545,3 +549,154 @@ func compareBlockDeviceList(t *testing.T, bdList1, bdList2 apis.BlockDeviceList)
 		compareBlockDevice(t, bdList1.Items[i], bdList2.Items[i])
 	}
 }
+
+func TestUpdateDeviceConflict(t *testing.T) {
+	fakeNdmClient := CreateFakeClient(t)
+	fakeController := &Controller{
+		NodeAttributes: map[string]string{HostNameKey: fakeHostName},
+		Clientset:      fakeNdmClient,
+	}
+
+	dr := mockEmptyDeviceCr()
+	dr.Name = "conflict-blockdevice-uid"
+	dr.Spec.Capacity.Storage = 1024
+	assert.NoError(t, fakeController.CreateBlockDevice(dr))
+	staleDr, err := fakeController.GetBlockDevice(dr.Name)
+	assert.NoError(t, err)
+
+	// the blockdevice is claimed after the daemon has read it
+	claimedDr := staleDr.DeepCopy()
+	claimedDr.Status.ClaimState = apis.BlockDeviceClaimed
+	assert.NoError(t, fakeNdmClient.Update(context.TODO(), claimedDr))
+
+	// update using the stale copy of the blockdevice conflicts, and is
+	// retried on the latest version
+	newDr := mockEmptyDeviceCr()
+	newDr.Name = dr.Name
+	newDr.Spec.Capacity.Storage = 2048
+	newDr.Spec.Details.Model = "new model"
+	assert.NoError(t, fakeController.UpdateBlockDevice(newDr, staleDr))
+
+	// only the fields relevant for a claimed device are updated
+	got, err := fakeController.GetBlockDevice(dr.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, apis.BlockDeviceClaimed, got.Status.ClaimState)
+	assert.Equal(t, uint64(2048), got.Spec.Capacity.Storage)
+	assert.Empty(t, got.Spec.Details.Model)
+}
+
+// failingStatusClient drops the status of created blockdevices, like the API
+// server does for resources with a status subresource, and fails the given
+// number of patches of the status subresource
+type failingStatusClient struct {
+	client.Client
+	failures int
+}
+
+func (c *failingStatusClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if bd, ok := obj.(*apis.BlockDevice); ok {
+		bd.Status = apis.DeviceStatus{}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *failingStatusClient) Status() client.StatusWriter {
+	return &failingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type failingStatusWriter struct {
+	client.StatusWriter
+	c *failingStatusClient
+}
+
+func (w *failingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if w.c.failures > 0 {
+		w.c.failures--
+		return errors.NewInternalError(assert.AnError)
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func TestCreateDeviceStatusRetry(t *testing.T) {
+	fakeNdmClient := &failingStatusClient{Client: CreateFakeClient(t), failures: 2}
+	fakeController := &Controller{
+		NodeAttributes: map[string]string{HostNameKey: fakeHostName},
+		Clientset:      fakeNdmClient,
+	}
+
+	dr := mockEmptyDeviceCr()
+	dr.Name = "retry-blockdevice-uid"
+	dr.Status.State = NDMActive
+	dr.Status.ClaimState = apis.BlockDeviceUnclaimed
+
+	// the status is set even though the first patches of the status fail
+	assert.NoError(t, fakeController.CreateBlockDevice(dr))
+	assert.Equal(t, 0, fakeNdmClient.failures)
+	got, err := fakeController.GetBlockDevice(dr.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, apis.BlockDeviceState(NDMActive), got.Status.State)
+	assert.Equal(t, apis.BlockDeviceUnclaimed, got.Status.ClaimState)
+}
+
+func TestDeactivateDeviceStaleCopy(t *testing.T) {
+	fakeNdmClient := CreateFakeClient(t)
+	fakeController := &Controller{
+		NodeAttributes: map[string]string{HostNameKey: fakeHostName},
+		Clientset:      fakeNdmClient,
+	}
+
+	dr := mockEmptyDeviceCr()
+	dr.Name = "stale-blockdevice-uid"
+	assert.NoError(t, fakeController.CreateBlockDevice(dr))
+	staleDr, err := fakeController.GetBlockDevice(dr.Name)
+	assert.NoError(t, err)
+
+	claimedDr := staleDr.DeepCopy()
+	claimedDr.Status.ClaimState = apis.BlockDeviceClaimed
+	assert.NoError(t, fakeNdmClient.Update(context.TODO(), claimedDr))
+
+	// only the state is patched, the claim state is not overwritten
+	fakeController.DeactivateBlockDevice(*staleDr)
+	got, err := fakeController.GetBlockDevice(dr.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, apis.BlockDeviceState(NDMInactive), got.Status.State)
+	assert.Equal(t, apis.BlockDeviceClaimed, got.Status.ClaimState)
+}
+
+func TestBlockDeviceCacheLookup(t *testing.T) {
+	localDr := mockEmptyDeviceCr()
+	localDr.Name = "local-blockdevice-uid"
+	localDr.Labels[KubernetesHostNameLabel] = fakeHostName
+	localDr.Spec.Details.Model = "cached model"
+
+	movedDr := mockEmptyDeviceCr()
+	movedDr.Name = "moved-blockdevice-uid"
+	movedDr.Labels[KubernetesHostNameLabel] = "other-host-name"
+
+	// the cache only contains the blockdevices of this node
+	bdCache := fake.NewClientBuilder().WithObjects(localDr.DeepCopy()).Build()
+	localDr.Spec.Details.Model = "latest model"
+	fakeController := &Controller{
+		NodeAttributes: map[string]string{HostNameKey: fakeHostName},
+		Clientset:      fake.NewClientBuilder().WithObjects(localDr.DeepCopy(), movedDr.DeepCopy()).Build(),
+		BDCache:        bdCache,
+	}
+
+	got, err := fakeController.GetBlockDevice(localDr.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, "cached model", got.Spec.Details.Model)
+
+	// blockdevices which are not in the cache are fetched from etcd
+	got, err = fakeController.GetBlockDevice(movedDr.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, movedDr.Name, got.Name)
+
+	bdList, err := fakeController.ListBlockDeviceResource(false)
+	assert.NoError(t, err)
+	assert.Len(t, bdList.Items, 1)
+	assert.Equal(t, "cached model", bdList.Items[0].Spec.Details.Model)
+
+	bdList, err = fakeController.ListBlockDeviceResource(true)
+	assert.NoError(t, err)
+	assert.Len(t, bdList.Items, 2)
+}
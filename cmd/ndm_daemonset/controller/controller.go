@@ -27,6 +27,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -57,12 +58,54 @@ const (
 	NodeNameKey = "nodename"
 	// KubernetesHostNameLabel is the hostname label used by k8s
 	KubernetesHostNameLabel = kubernetesLabelPrefix + HostNameKey
+	// ZoneKey is the key for the zone of the node
+	ZoneKey = "zone"
+	// RegionKey is the key for the region of the node
+	RegionKey = "region"
+	// SystemUUIDKey is the key for the system UUID of the node
+	SystemUUIDKey = "system-uuid"
+	// BootIDKey is the key for the boot ID of the node
+	BootIDKey = "boot-id"
+	// KubernetesZoneLabel is the well known topology label for the zone of the node
+	KubernetesZoneLabel = "topology." + kubernetesLabelPrefix + ZoneKey
+	// KubernetesRegionLabel is the well known topology label for the region of the node
+	KubernetesRegionLabel = "topology." + kubernetesLabelPrefix + RegionKey
+	// legacyZoneLabel and legacyRegionLabel are the deprecated failure domain
+	// labels, which are still set on nodes in older clusters
+	legacyZoneLabel   = "failure-domain.beta." + kubernetesLabelPrefix + ZoneKey
+	legacyRegionLabel = "failure-domain.beta." + kubernetesLabelPrefix + RegionKey
 	// NDMVersion is the CR version.
 	NDMVersion = openEBSLabelPrefix + "v1alpha1"
 	// reconcileKey is the key used for enable/disable of reconciliation
 	reconcileKey = "reconcile"
 	// OpenEBSReconcile is used in annotation to check whether CR is to be reconciled or not
 	OpenEBSReconcile = openEBSLabelPrefix + reconcileKey
+	// dryRunKey is the key used for evaluating a claim without binding
+	dryRunKey = "dry-run"
+	// OpenEBSDryRun is used in annotation to check whether the claim is to be
+	// only evaluated, without claiming any blockdevice
+	OpenEBSDryRun = openEBSLabelPrefix + dryRunKey
+	// cleanupMethodKey is the key used for specifying the cleanup method of a blockdevice
+	cleanupMethodKey = "cleanup-method"
+	// OpenEBSCleanupMethod is used in annotation to set the method used for cleaning
+	// up the blockdevice when it is released
+	OpenEBSCleanupMethod = openEBSLabelPrefix + cleanupMethodKey
+	// retryCleanupKey is the key used for retrying a failed cleanup
+	retryCleanupKey = "retry-cleanup"
+	// OpenEBSRetryCleanup is used in annotation to retry the cleanup of a blockdevice
+	// in CleanupFailed state
+	OpenEBSRetryCleanup = openEBSLabelPrefix + retryCleanupKey
+	// reclaimPolicyKey is the key used for specifying the reclaim policy of a blockdevice
+	reclaimPolicyKey = "reclaim-policy"
+	// OpenEBSReclaimPolicy is used in annotation to override the reclaim policy of the
+	// claim, when the blockdevice is released
+	OpenEBSReclaimPolicy = openEBSLabelPrefix + reclaimPolicyKey
+	// previousSystemUUIDKey is the key used for recording the previous machine of a blockdevice
+	previousSystemUUIDKey = "previous-system-uuid"
+	// OpenEBSPreviousSystemUUID is used in annotation by the daemon to record the system UUID
+	// of the machine on which the blockdevice was found before its node was recreated. The
+	// operator reports the move of the blockdevice and removes the annotation.
+	OpenEBSPreviousSystemUUID = openEBSLabelPrefix + previousSystemUUIDKey
 	// NDMNotPartitioned is used to say blockdevice does not have any partition.
 	NDMNotPartitioned = "No"
 	// NDMPartitioned is used to say blockdevice has some partitions.
@@ -85,6 +128,9 @@ const (
 	NDMLabelPrefix = "ndm.io/"
 	// NDMZpoolName specifies the zpool name
 	NDMZpoolName = NDMLabelPrefix + "zpool-name"
+	// NDMTransportKey specifies the transport through which the blockdevice is
+	// attached, so that network attached devices can be selected or excluded
+	NDMTransportKey = NDMLabelPrefix + "transport"
 )
 
 const (
@@ -117,6 +163,9 @@ type Controller struct {
 	Namespace string
 	// Clientset is the client used to interface with API server
 	Clientset client.Client
+	// BDCache is the informer backed cache of the BlockDevices on this node.
+	// If set, the BlockDevices of this node are read from the cache.
+	BDCache   client.Reader
 	NDMConfig *NodeDiskManagerConfig // NDMConfig contains custom config for ndm
 	Mutex     *sync.Mutex            // Mutex is used to lock and unlock Controller
 	Filters   []*Filter              // Filters are the registered filters like os disk filter
@@ -126,6 +175,12 @@ type Controller struct {
 	NodeAttributes map[string]string
 	// BDHierarchy stores the hierarchy of devices on this node
 	BDHierarchy blockdevice.Hierarchy
+	// Recorder is used to generate events on the blockdevices
+	Recorder record.EventRecorder
+	// healthEvaluator is built once from the health config, and is reused for
+	// all the blockdevices. It is nil if health evaluation is disabled.
+	healthEvaluator     *HealthEvaluator
+	healthEvaluatorOnce sync.Once
 }
 
 // NewController returns a controller pointer for any error case it will return nil
@@ -153,6 +208,7 @@ func NewController() (*Controller, error) {
 	if err := apis.AddToScheme(mgr.GetScheme()); err != nil {
 		return controller, err
 	}
+	controller.Recorder = mgr.GetEventRecorderFor("node-disk-manager")
 
 	_, err = controller.newClientSet()
 	if err != nil {
@@ -226,6 +282,25 @@ func (c *Controller) setNodeLabels() error {
 		c.NodeAttributes[HostNameKey] = hostName
 	}
 
+	// the zone and region of the node are used for topology aware claiming
+	// of blockdevices. The deprecated failure domain labels are used only if
+	// the topology labels are not set on the node
+	if zone := getNodeLabel(node, KubernetesZoneLabel, legacyZoneLabel); zone != "" {
+		c.NodeAttributes[ZoneKey] = zone
+	}
+	if region := getNodeLabel(node, KubernetesRegionLabel, legacyRegionLabel); region != "" {
+		c.NodeAttributes[RegionKey] = region
+	}
+
+	// the system UUID is used by the operator to find out if the node was
+	// recreated on a different machine with the same name
+	if systemUUID := node.Status.NodeInfo.SystemUUID; systemUUID != "" {
+		c.NodeAttributes[SystemUUIDKey] = systemUUID
+	}
+	if bootID := node.Status.NodeInfo.BootID; bootID != "" {
+		c.NodeAttributes[BootIDKey] = bootID
+	}
+
 	var labelPattern []string
 
 	// Get the list of node label patterns to be added from the configmap
@@ -255,6 +330,17 @@ func (c *Controller) setNodeLabels() error {
 	return nil
 }
 
+// getNodeLabel returns the value of the first of the given labels that is
+// set on the node
+func getNodeLabel(node *v1.Node, keys ...string) string {
+	for _, key := range keys {
+		if value := node.Labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 // getNodeName gets the node name from env, else
 // returns an error
 func getNodeName() (string, error) {
@@ -298,6 +384,12 @@ func (c *Controller) Start() {
 	c.InitializeSparseFiles()
 	// set up signals so we handle the first shutdown signal gracefully
 	ctx := signals.SetupSignalHandler()
+	// the heartbeat is renewed only after the probes have started. By then,
+	// the initial scan has marked the devices no longer present on the node
+	// as Inactive, so the operator does not restore them. The add events of
+	// the devices that are present are processed asynchronously, and may
+	// still be updating the devices after the state has been restored.
+	go c.RenewNodeHeartbeat(ctx)
 	if err := c.run(2, ctx); err != nil {
 		klog.Fatalf("error running controller: %s", err.Error())
 	}
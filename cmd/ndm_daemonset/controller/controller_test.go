@@ -17,11 +17,18 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 /*
@@ -94,3 +101,114 @@ func TestBroadcast(t *testing.T) {
 		})
 	}
 }
+
+func TestSetNodeLabels(t *testing.T) {
+	tests := map[string]struct {
+		nodeLabels map[string]string
+		nodeInfo   v1.NodeSystemInfo
+		want       map[string]string
+	}{
+		"node without hostname and topology labels": {
+			nodeLabels: map[string]string{},
+			want: map[string]string{
+				NodeNameKey: "node1",
+				HostNameKey: "node1",
+			},
+		},
+		"node with topology labels": {
+			nodeLabels: map[string]string{
+				KubernetesHostNameLabel: "host1",
+				KubernetesZoneLabel:     "zone-a",
+				KubernetesRegionLabel:   "region-1",
+			},
+			want: map[string]string{
+				NodeNameKey: "node1",
+				HostNameKey: "host1",
+				ZoneKey:     "zone-a",
+				RegionKey:   "region-1",
+			},
+		},
+		"node with deprecated failure domain labels": {
+			nodeLabels: map[string]string{
+				legacyZoneLabel:   "zone-b",
+				legacyRegionLabel: "region-2",
+			},
+			want: map[string]string{
+				NodeNameKey: "node1",
+				HostNameKey: "node1",
+				ZoneKey:     "zone-b",
+				RegionKey:   "region-2",
+			},
+		},
+		"topology labels take precedence over deprecated labels": {
+			nodeLabels: map[string]string{
+				KubernetesZoneLabel: "zone-a",
+				legacyZoneLabel:     "zone-b",
+			},
+			want: map[string]string{
+				NodeNameKey: "node1",
+				HostNameKey: "node1",
+				ZoneKey:     "zone-a",
+			},
+		},
+		"node with system UUID and boot ID": {
+			nodeLabels: map[string]string{},
+			nodeInfo: v1.NodeSystemInfo{
+				SystemUUID: "system-uuid-1",
+				BootID:     "boot-id-1",
+			},
+			want: map[string]string{
+				NodeNameKey:   "node1",
+				HostNameKey:   "node1",
+				SystemUUIDKey: "system-uuid-1",
+				BootIDKey:     "boot-id-1",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			node := &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: test.nodeLabels,
+				},
+				Status: v1.NodeStatus{NodeInfo: test.nodeInfo},
+			}
+			c := &Controller{
+				Clientset:      fake.NewClientBuilder().WithObjects(node).Build(),
+				NodeAttributes: map[string]string{NodeNameKey: "node1"},
+			}
+			assert.NoError(t, c.setNodeLabels())
+			assert.Equal(t, test.want, c.NodeAttributes)
+		})
+	}
+}
+
+func TestRenewNodeLease(t *testing.T) {
+	c := &Controller{
+		Clientset: fake.NewClientBuilder().Build(),
+		Namespace: "openebs",
+		NodeAttributes: map[string]string{
+			NodeNameKey: "node1",
+			HostNameKey: "host1",
+		},
+	}
+	key := client.ObjectKey{Namespace: "openebs", Name: NodeLeaseName("node1")}
+
+	// the lease is created on the first renewal
+	assert.NoError(t, c.renewNodeLease(context.TODO()))
+	lease := &coordinationv1.Lease{}
+	assert.NoError(t, c.Clientset.Get(context.TODO(), key, lease))
+	assert.Equal(t, TrueString, lease.Labels[NDMNodeHeartbeatKey])
+	assert.Equal(t, "host1", lease.Labels[KubernetesHostNameLabel])
+	assert.Equal(t, "node1", *lease.Spec.HolderIdentity)
+	firstRenewal := lease.Spec.RenewTime.Time
+
+	// the renew time and the hostname are updated on the next renewals
+	c.NodeAttributes[HostNameKey] = "host2"
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, c.renewNodeLease(context.TODO()))
+	assert.NoError(t, c.Clientset.Get(context.TODO(), key, lease))
+	assert.Equal(t, "host2", lease.Labels[KubernetesHostNameLabel])
+	assert.True(t, lease.Spec.RenewTime.Time.After(firstRenewal))
+}
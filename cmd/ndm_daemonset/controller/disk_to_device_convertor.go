@@ -55,6 +55,8 @@ func (c *Controller) NewDeviceInfoFromBlockDevice(blockDevice *bd.BlockDevice) *
 	deviceDetails.HardwareSectorSize = blockDevice.DeviceAttributes.HardwareSectorSize
 	deviceDetails.DriveType = blockDevice.DeviceAttributes.DriveType
 	deviceDetails.DeviceType = blockDevice.DeviceAttributes.DeviceType
+	deviceDetails.ZonedInfo = blockDevice.DeviceAttributes.ZonedInfo
+	deviceDetails.QueueInfo = blockDevice.DeviceAttributes.QueueInfo
 
 	deviceDetails.Compliance = blockDevice.DeviceAttributes.Compliance
 	deviceDetails.FileSystemInfo.FileSystem = blockDevice.FSInfo.FileSystem
@@ -62,5 +64,17 @@ func (c *Controller) NewDeviceInfoFromBlockDevice(blockDevice *bd.BlockDevice) *
 	if len(blockDevice.FSInfo.MountPoint) != 0 {
 		deviceDetails.FileSystemInfo.MountPoint = blockDevice.FSInfo.MountPoint[0]
 	}
+
+	deviceDetails.DependentDevices = blockDevice.DependentDevices
+	deviceDetails.PartitionInfo = blockDevice.PartitionInfo
+	deviceDetails.DMInfo = blockDevice.DMInfo
+	deviceDetails.LVMInfo = blockDevice.LVMInfo
+	deviceDetails.MultipathInfo = blockDevice.MultipathInfo
+	deviceDetails.TransportInfo = blockDevice.TransportInfo
+	deviceDetails.DevUse = blockDevice.DevUse
+	deviceDetails.ZPoolName = blockDevice.Labels[NDMZpoolName]
+	deviceDetails.SMARTInfo = blockDevice.SMARTInfo
+
+	c.evaluateHealth(blockDevice, deviceDetails)
 	return deviceDetails
 }
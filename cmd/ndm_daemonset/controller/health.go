@@ -0,0 +1,272 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	bd "github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/nvme"
+	"github.com/openebs/node-disk-manager/pkg/util"
+)
+
+const (
+	// NDMQuarantinedKey is the label added on blockdevices which are quarantined
+	// because of their health. Quarantined blockdevices are not selected by
+	// auto-selection while claiming.
+	NDMQuarantinedKey = NDMLabelPrefix + "quarantined"
+)
+
+// keys of the health checks whose thresholds can be configured
+const (
+	healthCheckTemperature        = "temperature"
+	healthCheckEnduranceUsed      = "endurance-used"
+	healthCheckReallocatedSectors = "reallocated-sectors"
+	healthCheckPendingSectors     = "pending-sectors"
+	healthCheckGrownDefects       = "grown-defects"
+	healthCheckMediaErrors        = "media-errors"
+	healthCheckUncorrectedErrors  = "uncorrected-errors"
+	healthCheckFailedSelfTests    = "failed-self-tests"
+)
+
+// healthChecks is the order in which the threshold based health checks are performed
+var healthChecks = []string{
+	healthCheckTemperature,
+	healthCheckEnduranceUsed,
+	healthCheckReallocatedSectors,
+	healthCheckPendingSectors,
+	healthCheckGrownDefects,
+	healthCheckMediaErrors,
+	healthCheckUncorrectedErrors,
+	healthCheckFailedSelfTests,
+}
+
+// defaultHealthThresholds are the thresholds used if they are not overridden in
+// the health config. Temperatures are in celsius and endurance used is in percent.
+var defaultHealthThresholds = map[string]HealthThreshold{
+	healthCheckTemperature:        {Warning: 60, Failing: 70},
+	healthCheckEnduranceUsed:      {Warning: 80, Failing: 100},
+	healthCheckReallocatedSectors: {Warning: 1, Failing: 100},
+	healthCheckPendingSectors:     {Warning: 1, Failing: 10},
+	healthCheckGrownDefects:       {Warning: 1, Failing: 100},
+	healthCheckMediaErrors:        {Warning: 1},
+	healthCheckUncorrectedErrors:  {Warning: 1},
+	healthCheckFailedSelfTests:    {Warning: 1},
+}
+
+// defaultQuarantineHealth are the health states in which the blockdevice is
+// quarantined if not specified in the health config
+var defaultQuarantineHealth = []string{string(apis.BlockDeviceHealthFailing)}
+
+// event reasons used when the health of a blockdevice changes
+var healthEventReasons = map[apis.BlockDeviceHealth]string{
+	apis.BlockDeviceHealthy:       "BlockDeviceHealthy",
+	apis.BlockDeviceHealthWarning: "BlockDeviceHealthWarning",
+	apis.BlockDeviceHealthFailing: "BlockDeviceFailing",
+	apis.BlockDeviceHealthUnknown: "BlockDeviceHealthUnknown",
+}
+
+// healthSeverity is used to pick the worst health among the results of the checks
+var healthSeverity = map[apis.BlockDeviceHealth]int{
+	apis.BlockDeviceHealthy:       0,
+	apis.BlockDeviceHealthWarning: 1,
+	apis.BlockDeviceHealthFailing: 2,
+}
+
+// HealthEvaluator evaluates the health of blockdevices using the thresholds
+// from the health config
+type HealthEvaluator struct {
+	thresholds map[string]HealthThreshold
+	quarantine []string
+}
+
+// NewHealthEvaluator creates a HealthEvaluator from the health config. nil is
+// returned if health evaluation is disabled.
+func NewHealthEvaluator(config *HealthConfig) *HealthEvaluator {
+	he := &HealthEvaluator{
+		thresholds: make(map[string]HealthThreshold, len(defaultHealthThresholds)),
+		quarantine: defaultQuarantineHealth,
+	}
+	for key, threshold := range defaultHealthThresholds {
+		he.thresholds[key] = threshold
+	}
+	if config == nil {
+		return he
+	}
+	// health evaluation is disabled only if the state is explicitly set to false
+	if config.State != "" && util.CheckFalsy(config.State) {
+		return nil
+	}
+	if config.Quarantine != "" {
+		he.quarantine = strings.Split(config.Quarantine, ",")
+	}
+	for _, threshold := range config.Thresholds {
+		if _, ok := he.thresholds[threshold.Key]; !ok {
+			klog.Warningf("unknown health check %s in health config", threshold.Key)
+			continue
+		}
+		he.thresholds[threshold.Key] = threshold
+	}
+	return he
+}
+
+// Evaluate derives the health of the blockdevice from its SMART data. The checks
+// which caused the device to be not healthy are returned as the reasons.
+func (he *HealthEvaluator) Evaluate(blockDevice *bd.BlockDevice) (apis.BlockDeviceHealth, []string) {
+	smartInfo := blockDevice.SMARTInfo
+	if !hasHealthData(smartInfo) {
+		return apis.BlockDeviceHealthUnknown, nil
+	}
+
+	health := apis.BlockDeviceHealthy
+	var reasons []string
+	degrade := func(h apis.BlockDeviceHealth, reason string) {
+		if healthSeverity[h] > healthSeverity[health] {
+			health = h
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if smartInfo.OverallHealth == bd.SMARTHealthFailed {
+		degrade(apis.BlockDeviceHealthFailing, "device reports a predicted failure")
+	}
+	for _, attr := range smartInfo.Attributes {
+		if !attr.FailingNow {
+			continue
+		}
+		if attr.Prefailure {
+			degrade(apis.BlockDeviceHealthFailing,
+				fmt.Sprintf("pre-failure attribute %s(%d) is below its threshold", attr.Name, attr.ID))
+		} else {
+			degrade(apis.BlockDeviceHealthWarning,
+				fmt.Sprintf("attribute %s(%d) is below its threshold", attr.Name, attr.ID))
+		}
+	}
+	if smartInfo.CriticalWarning&nvme.CriticalWarningSpare != 0 {
+		degrade(apis.BlockDeviceHealthWarning, "available spare is below its threshold")
+	}
+	if smartInfo.CriticalWarning&nvme.CriticalWarningTemperature != 0 {
+		degrade(apis.BlockDeviceHealthWarning, "temperature is outside its thresholds")
+	}
+
+	for _, key := range healthChecks {
+		value, ok := healthCheckValue(key, smartInfo)
+		if !ok {
+			continue
+		}
+		threshold := he.thresholds[key]
+		if threshold.Failing > 0 && value >= threshold.Failing {
+			degrade(apis.BlockDeviceHealthFailing,
+				fmt.Sprintf("%s %g reached the failing threshold %g", key, value, threshold.Failing))
+		} else if threshold.Warning > 0 && value >= threshold.Warning {
+			degrade(apis.BlockDeviceHealthWarning,
+				fmt.Sprintf("%s %g reached the warning threshold %g", key, value, threshold.Warning))
+		}
+	}
+	return health, reasons
+}
+
+// IsQuarantined checks whether a blockdevice with the given health should be quarantined
+func (he *HealthEvaluator) IsQuarantined(health apis.BlockDeviceHealth) bool {
+	return util.Contains(he.quarantine, string(health))
+}
+
+// healthCheckValue returns the value of the SMART data used by the health check.
+// false is returned if the value is not reported by the device.
+func healthCheckValue(key string, smartInfo bd.SMARTStats) (float64, bool) {
+	switch key {
+	case healthCheckTemperature:
+		return float64(smartInfo.TemperatureInfo.CurrentTemperature),
+			smartInfo.TemperatureInfo.CurrentTemperatureDataValid
+	case healthCheckEnduranceUsed:
+		return smartInfo.PercentEnduranceUsed, true
+	case healthCheckReallocatedSectors:
+		return float64(smartInfo.ReallocatedSectors), true
+	case healthCheckPendingSectors:
+		return float64(smartInfo.PendingSectors), true
+	case healthCheckGrownDefects:
+		return float64(smartInfo.GrownDefects), true
+	case healthCheckMediaErrors:
+		return float64(smartInfo.MediaErrors), true
+	case healthCheckUncorrectedErrors:
+		return float64(smartInfo.UncorrectedReadErrors + smartInfo.UncorrectedWriteErrors +
+			smartInfo.UncorrectedVerifyErrors), true
+	case healthCheckFailedSelfTests:
+		return float64(smartInfo.FailedSelfTests), true
+	}
+	return 0, false
+}
+
+// hasHealthData checks whether any of the SMART data used for evaluating the
+// health has been reported by the device
+func hasHealthData(smartInfo bd.SMARTStats) bool {
+	return smartInfo.OverallHealth != "" ||
+		len(smartInfo.Attributes) != 0 ||
+		smartInfo.TemperatureInfo.CurrentTemperatureDataValid ||
+		smartInfo.PercentEnduranceUsed != 0 ||
+		smartInfo.PowerOnHours != 0
+}
+
+// evaluateHealth fills the health of the blockdevice in the device info, if
+// health evaluation is enabled
+func (c *Controller) evaluateHealth(blockDevice *bd.BlockDevice, deviceInfo *DeviceInfo) {
+	c.healthEvaluatorOnce.Do(c.setHealthEvaluator)
+	he := c.healthEvaluator
+	if he == nil {
+		return
+	}
+	deviceInfo.Health, deviceInfo.HealthReasons = he.Evaluate(blockDevice)
+	deviceInfo.Quarantined = he.IsQuarantined(deviceInfo.Health)
+	if deviceInfo.Health != apis.BlockDeviceHealthy && deviceInfo.Health != apis.BlockDeviceHealthUnknown {
+		klog.Infof("device: %s, health: %s, reasons: %s", blockDevice.DevPath,
+			deviceInfo.Health, strings.Join(deviceInfo.HealthReasons, ", "))
+	}
+}
+
+// recordHealthTransition generates an event on the blockdevice if its health
+// has changed. Newly discovered blockdevices generate an event only if they
+// are not healthy.
+func (c *Controller) recordHealthTransition(oldHealth apis.BlockDeviceHealth, blockDevice *apis.BlockDevice) {
+	newHealth := blockDevice.Status.Health
+	if c.Recorder == nil || newHealth == "" || newHealth == oldHealth {
+		return
+	}
+	if oldHealth == "" && (newHealth == apis.BlockDeviceHealthy || newHealth == apis.BlockDeviceHealthUnknown) {
+		return
+	}
+
+	eventType := corev1.EventTypeNormal
+	if newHealth == apis.BlockDeviceHealthWarning || newHealth == apis.BlockDeviceHealthFailing {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("Health changed to %s", newHealth)
+	if oldHealth != "" {
+		message = fmt.Sprintf("Health changed from %s to %s", oldHealth, newHealth)
+	}
+	if len(blockDevice.Status.HealthReasons) != 0 {
+		message += ": " + strings.Join(blockDevice.Status.HealthReasons, ", ")
+	}
+	if _, ok := blockDevice.Labels[NDMQuarantinedKey]; ok {
+		message += ". BD is quarantined"
+	}
+	c.Recorder.Event(blockDevice, eventType, healthEventReasons[newHealth], message)
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	bd "github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/nvme"
+)
+
+func TestHealthEvaluatorEvaluate(t *testing.T) {
+	tests := map[string]struct {
+		smartInfo   bd.SMARTStats
+		config      *HealthConfig
+		wantHealth  apis.BlockDeviceHealth
+		wantReasons []string
+	}{
+		"no SMART data": {
+			smartInfo:  bd.SMARTStats{},
+			wantHealth: apis.BlockDeviceHealthUnknown,
+		},
+		"healthy device": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth:   bd.SMARTHealthPassed,
+				TemperatureInfo: bd.TemperatureInformation{CurrentTemperatureDataValid: true, CurrentTemperature: 38},
+				PowerOnHours:    4321,
+			},
+			wantHealth: apis.BlockDeviceHealthy,
+		},
+		"predicted failure": {
+			smartInfo:   bd.SMARTStats{OverallHealth: bd.SMARTHealthFailed},
+			wantHealth:  apis.BlockDeviceHealthFailing,
+			wantReasons: []string{"device reports a predicted failure"},
+		},
+		"attributes below threshold": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth: bd.SMARTHealthPassed,
+				Attributes: []bd.SMARTAttribute{
+					{ID: 5, Name: "Reallocated_Sector_Ct", Prefailure: true, FailingNow: true},
+					{ID: 190, Name: "Airflow_Temperature_Cel", FailingNow: true},
+				},
+				ReallocatedSectors: 3712,
+			},
+			wantHealth: apis.BlockDeviceHealthFailing,
+			wantReasons: []string{
+				"pre-failure attribute Reallocated_Sector_Ct(5) is below its threshold",
+				"attribute Airflow_Temperature_Cel(190) is below its threshold",
+				"reallocated-sectors 3712 reached the failing threshold 100",
+			},
+		},
+		"nvme spare below threshold": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth:   bd.SMARTHealthPassed,
+				CriticalWarning: nvme.CriticalWarningSpare,
+			},
+			wantHealth:  apis.BlockDeviceHealthWarning,
+			wantReasons: []string{"available spare is below its threshold"},
+		},
+		"warning thresholds": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth:         bd.SMARTHealthPassed,
+				TemperatureInfo:       bd.TemperatureInformation{CurrentTemperatureDataValid: true, CurrentTemperature: 65},
+				PercentEnduranceUsed:  85,
+				GrownDefects:          12,
+				UncorrectedReadErrors: 2,
+			},
+			wantHealth: apis.BlockDeviceHealthWarning,
+			wantReasons: []string{
+				"temperature 65 reached the warning threshold 60",
+				"endurance-used 85 reached the warning threshold 80",
+				"grown-defects 12 reached the warning threshold 1",
+				"uncorrected-errors 2 reached the warning threshold 1",
+			},
+		},
+		"invalid temperature is ignored": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth:   bd.SMARTHealthPassed,
+				TemperatureInfo: bd.TemperatureInformation{CurrentTemperature: 95},
+			},
+			wantHealth: apis.BlockDeviceHealthy,
+		},
+		"thresholds overridden in config": {
+			smartInfo: bd.SMARTStats{
+				OverallHealth:   bd.SMARTHealthPassed,
+				TemperatureInfo: bd.TemperatureInformation{CurrentTemperatureDataValid: true, CurrentTemperature: 65},
+				PendingSectors:  2,
+			},
+			config: &HealthConfig{
+				Thresholds: []HealthThreshold{
+					{Key: healthCheckTemperature, Warning: 50, Failing: 60},
+					{Key: healthCheckPendingSectors},
+				},
+			},
+			wantHealth:  apis.BlockDeviceHealthFailing,
+			wantReasons: []string{"temperature 65 reached the failing threshold 60"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			he := NewHealthEvaluator(test.config)
+			require.NotNil(t, he)
+			blockDevice := &bd.BlockDevice{SMARTInfo: test.smartInfo}
+			gotHealth, gotReasons := he.Evaluate(blockDevice)
+			assert.Equal(t, test.wantHealth, gotHealth)
+			assert.Equal(t, test.wantReasons, gotReasons)
+		})
+	}
+}
+
+func TestNewHealthEvaluator(t *testing.T) {
+	tests := map[string]struct {
+		config         *HealthConfig
+		wantDisabled   bool
+		wantQuarantine map[apis.BlockDeviceHealth]bool
+	}{
+		"no health config": {
+			config: nil,
+			wantQuarantine: map[apis.BlockDeviceHealth]bool{
+				apis.BlockDeviceHealthWarning: false,
+				apis.BlockDeviceHealthFailing: true,
+			},
+		},
+		"health evaluation disabled": {
+			config:       &HealthConfig{State: "false"},
+			wantDisabled: true,
+		},
+		"quarantine on warning": {
+			config: &HealthConfig{State: "true", Quarantine: "Warning,Failing"},
+			wantQuarantine: map[apis.BlockDeviceHealth]bool{
+				apis.BlockDeviceHealthy:       false,
+				apis.BlockDeviceHealthWarning: true,
+				apis.BlockDeviceHealthFailing: true,
+			},
+		},
+		"quarantine disabled": {
+			config: &HealthConfig{Quarantine: "none"},
+			wantQuarantine: map[apis.BlockDeviceHealth]bool{
+				apis.BlockDeviceHealthFailing: false,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			he := NewHealthEvaluator(test.config)
+			if test.wantDisabled {
+				assert.Nil(t, he)
+				return
+			}
+			require.NotNil(t, he)
+			for health, want := range test.wantQuarantine {
+				assert.Equal(t, want, he.IsQuarantined(health), "quarantine for %s", health)
+			}
+		})
+	}
+}
+
+func TestMergeBlockDeviceDataHealth(t *testing.T) {
+	quarantinedBD := mockEmptyDeviceCr()
+	quarantinedBD.Labels[NDMQuarantinedKey] = TrueString
+	quarantinedBD.Status.ClaimState = apis.BlockDeviceClaimed
+	quarantinedBD.Status.Health = apis.BlockDeviceHealthFailing
+	quarantinedBD.Status.HealthReasons = []string{"device reports a predicted failure"}
+
+	healthyBD := mockEmptyDeviceCr()
+	healthyBD.Status.Health = apis.BlockDeviceHealthy
+
+	got := mergeBlockDeviceData(healthyBD, quarantinedBD)
+	_, ok := got.Labels[NDMQuarantinedKey]
+	assert.False(t, ok, "quarantine label should be removed")
+	assert.Equal(t, apis.BlockDeviceHealthy, got.Status.Health)
+	assert.Empty(t, got.Status.HealthReasons)
+	assert.Equal(t, apis.BlockDeviceClaimed, got.Status.ClaimState)
+}
+
+func TestRecordHealthTransition(t *testing.T) {
+	tests := map[string]struct {
+		oldHealth   apis.BlockDeviceHealth
+		newHealth   apis.BlockDeviceHealth
+		quarantined bool
+		wantEvent   string
+	}{
+		"new healthy device": {
+			oldHealth: "",
+			newHealth: apis.BlockDeviceHealthy,
+		},
+		"new failing device": {
+			oldHealth:   "",
+			newHealth:   apis.BlockDeviceHealthFailing,
+			quarantined: true,
+			wantEvent: "Warning BlockDeviceFailing Health changed to Failing: " +
+				"device reports a predicted failure. BD is quarantined",
+		},
+		"health unchanged": {
+			oldHealth: apis.BlockDeviceHealthWarning,
+			newHealth: apis.BlockDeviceHealthWarning,
+		},
+		"device recovered": {
+			oldHealth: apis.BlockDeviceHealthWarning,
+			newHealth: apis.BlockDeviceHealthy,
+			wantEvent: "Normal BlockDeviceHealthy Health changed from Warning to Healthy",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			c := &Controller{Recorder: recorder}
+
+			blockDevice := mockEmptyDeviceCr()
+			blockDevice.Status.Health = test.newHealth
+			if test.newHealth == apis.BlockDeviceHealthFailing {
+				blockDevice.Status.HealthReasons = []string{"device reports a predicted failure"}
+			}
+			if test.quarantined {
+				blockDevice.Labels[NDMQuarantinedKey] = TrueString
+			}
+			c.recordHealthTransition(test.oldHealth, &blockDevice)
+
+			select {
+			case event := <-recorder.Events:
+				assert.Equal(t, test.wantEvent, event)
+			default:
+				assert.Empty(t, test.wantEvent, "expected an event")
+			}
+		})
+	}
+}
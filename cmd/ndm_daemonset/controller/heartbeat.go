@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NDMNodeHeartbeatKey is the label set on the leases used as the heartbeat
+	// of the NDM daemons
+	NDMNodeHeartbeatKey = NDMLabelPrefix + "node-heartbeat"
+	// nodeLeasePrefix is the prefix of the name of the lease of a node
+	nodeLeasePrefix = "ndm-node-"
+	// NodeHeartbeatInterval is the interval at which the daemon renews the
+	// lease of its node
+	NodeHeartbeatInterval = 10 * time.Second
+	// NodeLeaseDuration is the duration for which the heartbeat of a node is
+	// valid after the lease is renewed
+	NodeLeaseDuration = 40 * time.Second
+)
+
+// NodeLeaseName returns the name of the lease renewed by the daemon on the given node
+func NodeLeaseName(nodeName string) string {
+	return nodeLeasePrefix + nodeName
+}
+
+// RenewNodeHeartbeat renews the lease of this node periodically, until the
+// context is cancelled. The operator uses the lease to detect that the daemon
+// on this node has stopped, and marks the BlockDevices of the node as Unknown.
+func (c *Controller) RenewNodeHeartbeat(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.renewNodeLease(ctx); err != nil {
+			klog.Errorf("unable to renew the heartbeat of node %s: %v", c.NodeAttributes[NodeNameKey], err)
+		}
+	}, NodeHeartbeatInterval)
+}
+
+// renewNodeLease updates the renew time of the lease of this node. The lease
+// is created if it does not exist.
+func (c *Controller) renewNodeLease(ctx context.Context) error {
+	nodeName := c.NodeAttributes[NodeNameKey]
+	now := metav1.NewMicroTime(time.Now())
+	lease := &coordinationv1.Lease{}
+	err := c.Clientset.Get(ctx, client.ObjectKey{Namespace: c.Namespace, Name: NodeLeaseName(nodeName)}, lease)
+	if errors.IsNotFound(err) {
+		lease = c.newNodeLease(now)
+		if err = c.Clientset.Create(ctx, lease); err == nil {
+			klog.Infof("created heartbeat lease %s for node %s", lease.Name, nodeName)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	// the hostname label is used by the operator to find the BlockDevices of
+	// the node, and may have changed since the lease was created
+	if lease.Labels == nil {
+		lease.Labels = make(map[string]string)
+	}
+	lease.Labels[NDMNodeHeartbeatKey] = TrueString
+	lease.Labels[KubernetesHostNameLabel] = c.NodeAttributes[HostNameKey]
+	lease.Spec = c.newNodeLease(now).Spec
+	return c.Clientset.Update(ctx, lease)
+}
+
+// newNodeLease returns the lease of this node, renewed at the given time
+func (c *Controller) newNodeLease(renewTime metav1.MicroTime) *coordinationv1.Lease {
+	nodeName := c.NodeAttributes[NodeNameKey]
+	holderIdentity := nodeName
+	leaseDurationSeconds := int32(NodeLeaseDuration / time.Second)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NodeLeaseName(nodeName),
+			Namespace: c.Namespace,
+			Labels: map[string]string{
+				NDMNodeHeartbeatKey:     TrueString,
+				KubernetesHostNameLabel: c.NodeAttributes[HostNameKey],
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holderIdentity,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			RenewTime:            &renewTime,
+		},
+	}
+}
@@ -38,6 +38,8 @@ type NodeDiskManagerConfig struct {
 	TagConfigs []TagConfig `json:"tagconfigs"`
 	// MetaConfig contains configs for device labels
 	MetaConfigs []MetaConfig `json:"metaconfigs"`
+	// HealthConfig contains the config for evaluating the health of blockdevices
+	HealthConfig *HealthConfig `json:"healthconfig,omitempty"`
 }
 
 // ProbeConfig contains configs of Probe
@@ -70,9 +72,31 @@ type MetaConfig struct {
 	Pattern string `json:"pattern"`
 }
 
+// HealthConfig contains the config for evaluating the health of blockdevices from
+// their SMART data. Health evaluation is enabled with the default thresholds if the
+// config is not present.
+type HealthConfig struct {
+	State string `json:"state"` // State is state of the health evaluation
+	// Quarantine contains , separated health states in which the blockdevice
+	// will be quarantined
+	Quarantine string `json:"quarantine"`
+	// Thresholds contains the thresholds that override the default thresholds
+	Thresholds []HealthThreshold `json:"thresholds"`
+}
+
+// HealthThreshold contains the thresholds of a health check. A threshold of 0
+// disables the check for that health state.
+type HealthThreshold struct {
+	Key     string  `json:"key"`     // Key is key for each health check
+	Warning float64 `json:"warning"` // Warning is the value from which the device is in Warning health
+	Failing float64 `json:"failing"` // Failing is the value from which the device is Failing
+}
+
 // SetNDMConfig sets config for probes and filters which user provides via configmap. If
 // no configmap present then ndm will load default config for each probes and filters.
 func (c *Controller) SetNDMConfig(opts NDMOptions) {
+	defer c.healthEvaluatorOnce.Do(c.setHealthEvaluator)
+
 	data, err := ioutil.ReadFile(opts.ConfigFilePath)
 	if err != nil {
 		c.NDMConfig = nil
@@ -94,3 +118,14 @@ func (c *Controller) SetNDMConfig(opts NDMOptions) {
 
 	c.NDMConfig = &ndmConfig
 }
+
+// setHealthEvaluator builds the health evaluator from the health config. It is
+// called only once, either when the config is loaded or, if no config is loaded,
+// when the health of the first blockdevice is evaluated.
+func (c *Controller) setHealthEvaluator() {
+	var healthConfig *HealthConfig
+	if c.NDMConfig != nil {
+		healthConfig = c.NDMConfig.HealthConfig
+	}
+	c.healthEvaluator = NewHealthEvaluator(healthConfig)
+}
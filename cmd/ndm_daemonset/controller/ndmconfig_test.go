@@ -21,6 +21,7 @@ import (
 	"os"
 	"testing"
 
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -119,6 +120,18 @@ func TestController_SetNDMConfig_yaml(t *testing.T) {
 	assert.Len(t, ctrl.NDMConfig.FilterConfigs, 1)
 	expectedFilterConfig := FilterConfig{Key: "os-disk-exclude-filter", Name: "os disk exclude filter", State: "true", Include: "", Exclude: "/,/etc/hosts,/boot"}
 	assert.Equal(t, expectedFilterConfig, ctrl.NDMConfig.FilterConfigs[0])
+
+	expectedHealthConfig := &HealthConfig{
+		State:      "true",
+		Quarantine: "Warning,Failing",
+		Thresholds: []HealthThreshold{{Key: "temperature", Warning: 55, Failing: 62.5}},
+	}
+	assert.Equal(t, expectedHealthConfig, ctrl.NDMConfig.HealthConfig)
+
+	// the health evaluator is built once from the health config
+	assert.NotNil(t, ctrl.healthEvaluator)
+	assert.Equal(t, 55.0, ctrl.healthEvaluator.thresholds["temperature"].Warning)
+	assert.True(t, ctrl.healthEvaluator.IsQuarantined(apis.BlockDeviceHealthWarning))
 }
 
 func writeTestYaml(t *testing.T, fpath string) {
@@ -133,6 +146,13 @@ filterconfigs:
     state: true
     include: ""
     exclude: /,/etc/hosts,/boot
+healthconfig:
+  state: true
+  quarantine: Warning,Failing
+  thresholds:
+    - key: temperature
+      warning: 55
+      failing: 62.5
 `
 
 	err := ioutil.WriteFile(fpath, []byte(data), 0644)
@@ -118,7 +118,9 @@ func (pf *pathFilter) Include(blockDevice *blockdevice.BlockDevice) bool {
 }
 
 // Exclude returns true if the disk path does not match any given
-// keywords
+// keywords. Multipath devices are matched only using the device mapper
+// path and the symlinks, so that they are not excluded along with the other
+// /dev/dm-X devices, since their paths are not used as blockdevices.
 func (pf *pathFilter) Exclude(blockDevice *blockdevice.BlockDevice) bool {
 	if len(pf.excludePaths) == 0 {
 		return true
@@ -130,7 +132,8 @@ func (pf *pathFilter) Exclude(blockDevice *blockdevice.BlockDevice) bool {
 			return false
 		}
 	}
-	if util.MatchIgnoredCase(pf.excludePaths, blockDevice.DevPath) {
+	if blockDevice.DeviceAttributes.DeviceType != blockdevice.BlockDeviceTypeMultiPath &&
+		util.MatchIgnoredCase(pf.excludePaths, blockDevice.DevPath) {
 		return false
 	}
 	for _, link := range blockDevice.DevLinks {
@@ -168,6 +168,36 @@ func TestPathFilterExclude(t *testing.T) {
 			},
 			expected: false,
 		},
+		"multipath device with /dev/dm- in exclude list": {
+			excludePath: "/dev/loop,/dev/dm-",
+			bd: blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{
+					DevPath: "/dev/dm-0",
+				},
+				DeviceAttributes: blockdevice.DeviceAttribute{
+					DeviceType: blockdevice.BlockDeviceTypeMultiPath,
+				},
+				DMInfo: blockdevice.DeviceMapperInformation{
+					DevMapperPath: "/dev/mapper/mpatha",
+				},
+			},
+			expected: true,
+		},
+		"multipath device with mapper path in exclude list": {
+			excludePath: "/dev/dm-,/dev/mapper/mpatha",
+			bd: blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{
+					DevPath: "/dev/dm-0",
+				},
+				DeviceAttributes: blockdevice.DeviceAttribute{
+					DeviceType: blockdevice.BlockDeviceTypeMultiPath,
+				},
+				DMInfo: blockdevice.DeviceMapperInformation{
+					DevMapperPath: "/dev/mapper/mpatha",
+				},
+			},
+			expected: false,
+		},
 		"dm device with no dm paths in exclude list": {
 			excludePath: "/dev/sdb",
 			bd: blockdevice.BlockDevice{
@@ -24,7 +24,9 @@ import (
 	"github.com/openebs/node-disk-manager/db/kubernetes"
 	"github.com/openebs/node-disk-manager/pkg/features"
 	"github.com/openebs/node-disk-manager/pkg/partition"
+	"github.com/openebs/node-disk-manager/pkg/util"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 )
@@ -35,6 +37,10 @@ const (
 	gptUUIDScheme                   = "gpt"
 	internalFSUUIDAnnotation        = "internal.openebs.io/fsuuid"
 	internalPartitionUUIDAnnotation = "internal.openebs.io/partition-uuid"
+
+	// multipathPathEventReason is the reason of the event generated when the
+	// BlockDevice of a path of a multipath device is deactivated
+	multipathPathEventReason = "MultipathPath"
 )
 
 // addBlockDeviceToHierarchyCache adds the given block device to the hierarchy of devices.
@@ -57,20 +63,77 @@ func (pe *ProbeEvent) addBlockDeviceToHierarchyCache(bd blockdevice.BlockDevice)
 
 	// in either case, whether it existed or not, we will update with the latest BD into the cache
 	pe.Controller.BDHierarchy[bd.DevPath] = bd
+	pe.linkLogicalVolume(bd)
 	return deviceAlreadyExistsInCache
 }
 
+// linkLogicalVolume adds the logical volume to its physical volumes in the
+// hierarchy cache. The details of the volume group are also updated on the
+// physical volumes, since the free space changes when an LV is created.
+func (pe *ProbeEvent) linkLogicalVolume(bd blockdevice.BlockDevice) {
+	if bd.LVMInfo.LVName == "" {
+		return
+	}
+	changedPVs := make([]string, 0, len(bd.LVMInfo.PhysicalVolumes))
+	for _, pvPath := range bd.LVMInfo.PhysicalVolumes {
+		pvBD, ok := pe.Controller.BDHierarchy[pvPath]
+		if !ok || pvBD.LVMInfo.VGUUID != bd.LVMInfo.VGUUID {
+			continue
+		}
+		isLinked := util.Contains(pvBD.LVMInfo.LogicalVolumes, bd.LVMInfo.LVName)
+		if isLinked && pvBD.LVMInfo.VGSize == bd.LVMInfo.VGSize && pvBD.LVMInfo.VGFree == bd.LVMInfo.VGFree {
+			continue
+		}
+		if !isLinked {
+			// a copy is made, since the slice is shared with the earlier
+			// copies of the BD
+			logicalVolumes := make([]string, 0, len(pvBD.LVMInfo.LogicalVolumes)+1)
+			logicalVolumes = append(logicalVolumes, pvBD.LVMInfo.LogicalVolumes...)
+			pvBD.LVMInfo.LogicalVolumes = append(logicalVolumes, bd.LVMInfo.LVName)
+		}
+		pvBD.LVMInfo.VGSize = bd.LVMInfo.VGSize
+		pvBD.LVMInfo.VGFree = bd.LVMInfo.VGFree
+		pe.Controller.BDHierarchy[pvPath] = pvBD
+		changedPVs = append(changedPVs, pvPath)
+		klog.V(4).Infof("linked LV: %s to PV: %s in cache", bd.DevPath, pvPath)
+	}
+	pe.updatePhysicalVolumes(changedPVs)
+}
+
+// updatePhysicalVolumes updates the BlockDevice resources of the physical volumes
+// whose LVM details were changed in the hierarchy cache. No event is generated
+// for a PV when an LV is created or removed on it, so the resources are updated
+// along with the cache.
+func (pe *ProbeEvent) updatePhysicalVolumes(pvPaths []string) {
+	for _, pvPath := range pvPaths {
+		pvBD := pe.Controller.BDHierarchy[pvPath]
+		// the resource of the PV is yet to be created, or the PV is not
+		// used as a blockdevice
+		if pvBD.UUID == "" || !pe.Controller.ApplyFilter(&pvBD) || pe.multipathDeviceOf(pvBD) != "" {
+			continue
+		}
+		apiBlockdevice, err := pe.Controller.NewDeviceInfoFromBlockDevice(&pvBD).ToDevice(pe.Controller)
+		if err != nil {
+			klog.Errorf("unable to create blockdevice resource for PV: %s, %v", pvPath, err)
+			continue
+		}
+		apiBlockdevice.SetNamespace(pe.Controller.Namespace)
+		if err := pe.Controller.UpdateBlockDevice(apiBlockdevice, nil); err != nil {
+			klog.Errorf("unable to update LVM details of PV: %s, %v", pvPath, err)
+		}
+	}
+}
+
 // addBlockDevice processed when an add event is received for a device
 func (pe *ProbeEvent) addBlockDevice(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) error {
 
 	// handle devices that are not managed by NDM
-	// eg:devices in use by mayastor, zfs PV and jiva
-	// TODO jiva handling is still to be added.
+	// eg:devices in use by mayastor, zfs PV, jiva and other storage systems
 	if ok, err := pe.handleUnmanagedDevices(bd, bdAPIList); err != nil {
 		klog.Errorf("error handling unmanaged device %s. error: %v", bd.DevPath, err)
 		return err
 	} else if !ok {
-		klog.V(4).Infof("processed device: %s being used by mayastor/zfs-localPV", bd.DevPath)
+		klog.V(4).Infof("processed device: %s being used by %s", bd.DevPath, bd.DevUse.UsedBy)
 		return nil
 	}
 
@@ -244,12 +307,11 @@ func (pe *ProbeEvent) addBlockDevice(bd blockdevice.BlockDevice, bdAPIList *apis
 		}
 
 		klog.V(4).Infof("creating resource for device: %s with uuid: %s", bd.DevPath, bd.UUID)
-		existingBlockDeviceResource := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, bd.UUID)
 		annotations := map[string]string{
 			internalUUIDSchemeAnnotation: gptUUIDScheme,
 		}
 
-		err = pe.createOrUpdateWithAnnotation(annotations, bd, existingBlockDeviceResource)
+		err = pe.createOrUpdateWithAnnotation(annotations, bd, bdAPI)
 		if err != nil {
 			klog.Errorf("creation of resource failed: %+v", err)
 			return err
@@ -259,6 +321,76 @@ func (pe *ProbeEvent) addBlockDevice(bd blockdevice.BlockDevice, bdAPIList *apis
 	return nil
 }
 
+// multipathDeviceOf returns the multipath device of which the device is a path.
+// The partitions of a path are also considered as paths. An empty string is
+// returned if the device is not a path of a multipath device.
+func (pe *ProbeEvent) multipathDeviceOf(bd blockdevice.BlockDevice) string {
+	if bd.MultipathInfo.MultipathDevice != "" {
+		return bd.MultipathInfo.MultipathDevice
+	}
+	if bd.DeviceAttributes.DeviceType == blockdevice.BlockDeviceTypePartition {
+		if parentBD, ok := pe.Controller.BDHierarchy[bd.DependentDevices.Parent]; ok {
+			return parentBD.MultipathInfo.MultipathDevice
+		}
+	}
+	return ""
+}
+
+// suppressMultipathPaths marks the paths of a multipath device in the hierarchy
+// cache, and suppresses them. This handles the paths which were added before the
+// multipath device was created.
+func (pe *ProbeEvent) suppressMultipathPaths(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) {
+	for _, path := range bd.MultipathInfo.Paths {
+		pathBD, ok := pe.Controller.BDHierarchy[path.DevPath]
+		// paths which are already marked have been suppressed when they were added
+		if !ok || pathBD.MultipathInfo.MultipathDevice == bd.DevPath {
+			continue
+		}
+		pathBD.MultipathInfo.MultipathDevice = bd.DevPath
+		pe.Controller.BDHierarchy[path.DevPath] = pathBD
+		pe.suppressMultipathPath(pathBD, bd.DevPath, bdAPIList)
+	}
+}
+
+// suppressMultipathPath skips creating a BlockDevice resource for a path of a
+// multipath device, since the multipath device is used as the BlockDevice. If a
+// BlockDevice resource was created for the path before the multipath device was
+// created, it is deactivated, unless it is claimed.
+func (pe *ProbeEvent) suppressMultipathPath(bd blockdevice.BlockDevice, multipathDevice string,
+	bdAPIList *apis.BlockDeviceList) {
+	klog.Infof("device: %s is a path of multipath device: %s, skip creating BlockDevice resource",
+		bd.DevPath, multipathDevice)
+
+	// the partitions of the path have the same partition UUID as the
+	// partitions of the multipath device, and cannot be used to look up
+	// the BlockDevice of the path
+	if bd.DeviceAttributes.DeviceType == blockdevice.BlockDeviceTypePartition {
+		return
+	}
+	uuid := bd.UUID
+	if features.FeatureGates.IsEnabled(features.GPTBasedUUID) {
+		var ok bool
+		if uuid, ok = generateUUID(bd); !ok {
+			return
+		}
+	}
+	existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid)
+	if existingBD == nil || existingBD.Status.State != apis.BlockDeviceActive {
+		return
+	}
+	if existingBD.Status.ClaimState != apis.BlockDeviceUnclaimed {
+		klog.Warningf("device: %s is a path of multipath device: %s, but its BlockDevice: %s is claimed",
+			bd.DevPath, multipathDevice, existingBD.Name)
+		return
+	}
+
+	pe.Controller.DeactivateBlockDevice(*existingBD)
+	if pe.Controller.Recorder != nil {
+		pe.Controller.Recorder.Eventf(existingBD, corev1.EventTypeNormal, multipathPathEventReason,
+			"BD deactivated, device %s is a path of multipath device %s", bd.DevPath, multipathDevice)
+	}
+}
+
 // createBlockDeviceResourceIfNoHolders creates/updates a blockdevice resource if it does not have any
 // holder devices
 func (pe *ProbeEvent) createBlockDeviceResourceIfNoHolders(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) error {
@@ -314,7 +446,6 @@ func (pe *ProbeEvent) upgradeBD(bd blockdevice.BlockDevice, bdAPIList *apis.Bloc
 
 // handleUnmanagedDevices handles add event for devices that are currently not managed by the NDM daemon
 // returns true, if further processing is required, else false
-// TODO include jiva storage engine also
 func (pe *ProbeEvent) handleUnmanagedDevices(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) (bool, error) {
 	// handle if the device is used by mayastor
 	if ok, err := pe.deviceInUseByMayastor(bd, bdAPIList); err != nil {
@@ -323,6 +454,13 @@ func (pe *ProbeEvent) handleUnmanagedDevices(bd blockdevice.BlockDevice, bdAPILi
 		return false, nil
 	}
 
+	// handle if the device is owned by any other storage system
+	if ok, err := pe.deviceInUseByForeignStorage(bd, bdAPIList); err != nil {
+		return ok, err
+	} else if !ok {
+		return false, nil
+	}
+
 	// handle if the device is used by zfs localPV
 	if ok, err := pe.deviceInUseByZFSLocalPV(bd, bdAPIList); err != nil {
 		return ok, err
@@ -348,6 +486,58 @@ func (pe *ProbeEvent) deviceInUseByMayastor(bd blockdevice.BlockDevice, bdAPILis
 	return false, nil
 }
 
+// foreignStorageEngines are the storage systems which take ownership of the device
+// without using blockdevice resources. LVM is not listed, since the physical volumes
+// are used by LocalPV-LVM. They are reported as blockdevices in use by LVM, tagged
+// by the used-by probe so that they are not claimed by untagged claims.
+var foreignStorageEngines = []blockdevice.StorageEngine{
+	blockdevice.Ceph,
+	blockdevice.MDRaid,
+	blockdevice.LUKS,
+	blockdevice.BCache,
+	blockdevice.VDO,
+	blockdevice.Longhorn,
+	blockdevice.Jiva,
+}
+
+// deviceInUseByForeignStorage checks if the device is owned by another storage system and returns true if
+// further processing of the event is required. A blockdevice resource is not created for such devices, and an
+// existing unclaimed resource is deactivated so that it cannot be claimed. If the resource is claimed, the
+// storage system was set up by the consumer of the blockdevice and the event is processed as usual.
+func (pe *ProbeEvent) deviceInUseByForeignStorage(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) (bool, error) {
+	if !bd.DevUse.InUse {
+		return true, nil
+	}
+
+	isForeign := false
+	for _, engine := range foreignStorageEngines {
+		if bd.DevUse.UsedBy == engine {
+			isForeign = true
+			break
+		}
+	}
+	if !isForeign {
+		return true, nil
+	}
+
+	uuid, ok := generateUUID(bd)
+	if ok {
+		existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid)
+		if existingBD != nil && existingBD.Status.ClaimState != apis.BlockDeviceUnclaimed {
+			klog.V(4).Infof("device: %s in use by %s is claimed as blockdevice: %s", bd.DevPath,
+				bd.DevUse.UsedBy, uuid)
+			return true, nil
+		}
+		if existingBD != nil && existingBD.Status.State == apis.BlockDeviceActive {
+			klog.Infof("deactivating blockdevice: %s, device: %s in use by %s", uuid, bd.DevPath, bd.DevUse.UsedBy)
+			pe.Controller.DeactivateBlockDevice(*existingBD)
+		}
+	}
+
+	klog.Infof("device: %s in use by %s(%s). ignoring the event", bd.DevPath, bd.DevUse.UsedBy, bd.DevUse.Owner)
+	return false, nil
+}
+
 // deviceInUseByZFSLocalPV check if the device is in use by zfs localPV and returns true if further processing of
 // event is required. If the device has ZFS pv on it, then a blockdevice resource will be created and zfs PV tag
 // will be added on to the resource
@@ -405,7 +595,7 @@ func (pe *ProbeEvent) deviceInUseByZFSLocalPV(bd blockdevice.BlockDevice, bdAPIL
 func (pe *ProbeEvent) upgradeDeviceInUseByCStor(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) (bool, error) {
 	uuid, ok := generateUUID(bd)
 	if ok {
-		existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid)
+		existingBD := pe.getExistingBlockDevice(bdAPIList, uuid)
 		if existingBD != nil {
 			if existingBD.Status.ClaimState != apis.BlockDeviceUnclaimed {
 				// device in use using gpt UUID
@@ -418,6 +608,14 @@ func (pe *ProbeEvent) upgradeDeviceInUseByCStor(bd blockdevice.BlockDevice, bdAP
 		}
 	}
 
+	// the legacy blockdevice is matched using annotations, which requires
+	// all the blockdevices in the cluster, as the device may have been moved
+	// from another node
+	bdAPIList, err := pe.Controller.ListBlockDeviceResource(true)
+	if err != nil {
+		return false, err
+	}
+
 	legacyUUID, isVirt := generateLegacyUUID(bd)
 	existingLegacyBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, legacyUUID)
 
@@ -461,7 +659,7 @@ func (pe *ProbeEvent) upgradeDeviceInUseByCStor(bd blockdevice.BlockDevice, bdAP
 func (pe *ProbeEvent) upgradeDeviceInUseByLocalPV(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) (bool, error) {
 	uuid, ok := generateUUID(bd)
 	if ok {
-		existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid)
+		existingBD := pe.getExistingBlockDevice(bdAPIList, uuid)
 		if existingBD != nil {
 			if existingBD.Status.ClaimState != apis.BlockDeviceUnclaimed {
 				// device in use using gpt UUID
@@ -474,6 +672,14 @@ func (pe *ProbeEvent) upgradeDeviceInUseByLocalPV(bd blockdevice.BlockDevice, bd
 		}
 	}
 
+	// the legacy blockdevice is matched using annotations, which requires
+	// all the blockdevices in the cluster, as the device may have been moved
+	// from another node
+	bdAPIList, err := pe.Controller.ListBlockDeviceResource(true)
+	if err != nil {
+		return false, err
+	}
+
 	legacyUUID, isVirt := generateLegacyUUID(bd)
 	existingLegacyBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, legacyUUID)
 
@@ -514,6 +720,20 @@ func (pe *ProbeEvent) upgradeDeviceInUseByLocalPV(bd blockdevice.BlockDevice, bd
 	}
 }
 
+// getExistingBlockDevice returns the blockdevice resource with the given uuid. The list
+// contains only the blockdevices on this node, so the resource is fetched from etcd if
+// it is not present in the list, since the device may have been moved from another node.
+func (pe *ProbeEvent) getExistingBlockDevice(bdAPIList *apis.BlockDeviceList, uuid string) *apis.BlockDevice {
+	if existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid); existingBD != nil {
+		return existingBD
+	}
+	existingBD, err := pe.Controller.GetBlockDevice(uuid)
+	if err != nil {
+		return nil
+	}
+	return existingBD
+}
+
 // isParentDeviceInUse checks if the parent device of a given device is in use.
 // The check is made only if the device is a partition
 func (pe *ProbeEvent) isParentDeviceInUse(bd blockdevice.BlockDevice) (bool, error) {
@@ -18,6 +18,7 @@ package probe
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
@@ -29,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -122,6 +124,90 @@ func TestAddBlockDeviceToHierarchyCache(t *testing.T) {
 	}
 }
 
+func TestLinkLogicalVolume(t *testing.T) {
+	pv := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{UUID: "blockdevice-pv", DevPath: "/dev/sdb"},
+		LVMInfo: blockdevice.LVMInformation{
+			VGName:         "vg_data",
+			VGUUID:         "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg",
+			VGSize:         8 << 30,
+			VGFree:         4 << 30,
+			PVUUID:         "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+			LogicalVolumes: []string{"lv_linear"},
+		},
+	}
+	lv := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: "/dev/dm-1"},
+		Capacity:   blockdevice.CapacityInformation{Storage: 1 << 30},
+		LVMInfo: blockdevice.LVMInformation{
+			VGName:          "vg_data",
+			VGUUID:          "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg",
+			VGSize:          8 << 30,
+			VGFree:          3 << 30,
+			LVName:          "lv_striped",
+			LVUUID:          "qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt",
+			PhysicalVolumes: []string{"/dev/sdb", "/dev/sdc"},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(apis.GroupVersion, &apis.BlockDevice{})
+	s.AddKnownTypes(apis.GroupVersion, &apis.BlockDeviceList{})
+	cl := fake.NewFakeClientWithScheme(s)
+	pvAPI := &apis.BlockDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: pv.UUID},
+		Spec: apis.DeviceSpec{
+			Path: pv.DevPath,
+			LVM: &apis.LVMInfo{
+				VolumeGroupUUID: pv.LVMInfo.VGUUID,
+				VolumeGroupSize: pv.LVMInfo.VGSize,
+				VolumeGroupFree: pv.LVMInfo.VGFree,
+				LogicalVolumes:  pv.LVMInfo.LogicalVolumes,
+			},
+		},
+		Status: apis.DeviceStatus{ClaimState: apis.BlockDeviceUnclaimed},
+	}
+	assert.NoError(t, cl.Create(context.TODO(), pvAPI))
+	getPVLVMInfo := func() *apis.LVMInfo {
+		gotPVAPI := &apis.BlockDevice{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: pv.UUID}, gotPVAPI))
+		return gotPVAPI.Spec.LVM
+	}
+
+	pe := &ProbeEvent{
+		Controller: &controller.Controller{
+			Clientset:   cl,
+			Mutex:       &sync.Mutex{},
+			BDHierarchy: blockdevice.Hierarchy{pv.DevPath: pv},
+		},
+	}
+
+	// the LV is added to the PV, along with the latest free space of the VG
+	pe.addBlockDeviceToHierarchyCache(lv)
+	gotPV := pe.Controller.BDHierarchy[pv.DevPath]
+	assert.Equal(t, []string{"lv_linear", "lv_striped"}, gotPV.LVMInfo.LogicalVolumes)
+	assert.Equal(t, uint64(3<<30), gotPV.LVMInfo.VGFree)
+	// the earlier copy of the PV is not modified
+	assert.Equal(t, []string{"lv_linear"}, pv.LVMInfo.LogicalVolumes)
+	// the resource of the PV is updated
+	assert.Equal(t, []string{"lv_linear", "lv_striped"}, getPVLVMInfo().LogicalVolumes)
+	assert.Equal(t, uint64(3<<30), getPVLVMInfo().VolumeGroupFree)
+
+	// adding the LV again does not duplicate it
+	pe.addBlockDeviceToHierarchyCache(lv)
+	assert.Equal(t, []string{"lv_linear", "lv_striped"},
+		pe.Controller.BDHierarchy[pv.DevPath].LVMInfo.LogicalVolumes)
+
+	// the LV is removed from the PV when it is removed from the cache. The
+	// metadata of the PV cannot be read, so the free space is left as is.
+	pe.removeBlockDeviceFromHierarchyCache(blockdevice.BlockDevice{Identifier: lv.Identifier})
+	gotPV = pe.Controller.BDHierarchy[pv.DevPath]
+	assert.Equal(t, []string{"lv_linear"}, gotPV.LVMInfo.LogicalVolumes)
+	assert.Equal(t, uint64(3<<30), gotPV.LVMInfo.VGFree)
+	assert.Equal(t, []string{"lv_linear"}, getPVLVMInfo().LogicalVolumes)
+	assert.Equal(t, uint64(3<<30), getPVLVMInfo().VolumeGroupFree)
+}
+
 func TestDeviceInUseByMayastor(t *testing.T) {
 	tests := map[string]struct {
 		bd        blockdevice.BlockDevice
@@ -172,6 +258,145 @@ func TestDeviceInUseByMayastor(t *testing.T) {
 	}
 }
 
+func TestDeviceInUseByForeignStorage(t *testing.T) {
+	mdBD := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{
+			DevPath: "/dev/sdb",
+		},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			DeviceType: blockdevice.BlockDeviceTypeDisk,
+			WWN:        "0x5000c500a1b2c3d4",
+			Serial:     "ZA1B2C3D",
+		},
+		DevUse: blockdevice.DeviceUsage{
+			InUse:  true,
+			UsedBy: blockdevice.MDRaid,
+			Owner:  "node1:data",
+		},
+	}
+	mdBDUUID, _ := generateUUID(mdBD)
+	lvmBD := mdBD
+	lvmBD.DevUse = blockdevice.DeviceUsage{
+		InUse:  true,
+		UsedBy: blockdevice.LVM,
+		Owner:  "vg_data",
+	}
+
+	tests := map[string]struct {
+		bd        blockdevice.BlockDevice
+		bdAPIList *apis.BlockDeviceList
+		want      bool
+		wantState apis.BlockDeviceState
+	}{
+		"device not in use": {
+			bd: blockdevice.BlockDevice{
+				DevUse: blockdevice.DeviceUsage{
+					InUse: false,
+				},
+			},
+			bdAPIList: &apis.BlockDeviceList{},
+			want:      true,
+		},
+		"device in use by localPV": {
+			bd: blockdevice.BlockDevice{
+				DevUse: blockdevice.DeviceUsage{
+					InUse:  true,
+					UsedBy: blockdevice.LocalPV,
+				},
+			},
+			bdAPIList: &apis.BlockDeviceList{},
+			want:      true,
+		},
+		"device in use by md raid, no existing resource": {
+			bd:        mdBD,
+			bdAPIList: &apis.BlockDeviceList{},
+			want:      false,
+		},
+		"device in use by md raid, existing unclaimed resource": {
+			bd: mdBD,
+			bdAPIList: &apis.BlockDeviceList{
+				Items: []apis.BlockDevice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: mdBDUUID,
+						},
+						Status: apis.DeviceStatus{
+							ClaimState: apis.BlockDeviceUnclaimed,
+							State:      apis.BlockDeviceActive,
+						},
+					},
+				},
+			},
+			want:      false,
+			wantState: apis.BlockDeviceInactive,
+		},
+		"device in use by md raid, existing claimed resource": {
+			bd: mdBD,
+			bdAPIList: &apis.BlockDeviceList{
+				Items: []apis.BlockDevice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: mdBDUUID,
+						},
+						Status: apis.DeviceStatus{
+							ClaimState: apis.BlockDeviceClaimed,
+							State:      apis.BlockDeviceActive,
+						},
+					},
+				},
+			},
+			want:      true,
+			wantState: apis.BlockDeviceActive,
+		},
+		"device in use by LVM": {
+			bd:        lvmBD,
+			bdAPIList: &apis.BlockDeviceList{},
+			want:      true,
+		},
+		"device in use by ceph, cannot be uniquely identified": {
+			bd: blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{
+					DevPath: "/dev/sdc",
+				},
+				DevUse: blockdevice.DeviceUsage{
+					InUse:  true,
+					UsedBy: blockdevice.Ceph,
+				},
+			},
+			bdAPIList: &apis.BlockDeviceList{},
+			want:      false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := scheme.Scheme
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDevice{})
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDeviceList{})
+			cl := fake.NewFakeClientWithScheme(s)
+			// resources are created in place, so that the list has the resource versions
+			for i := range tt.bdAPIList.Items {
+				cl.Create(context.TODO(), &tt.bdAPIList.Items[i])
+			}
+
+			pe := &ProbeEvent{
+				Controller: &controller.Controller{
+					Clientset: cl,
+				},
+			}
+			got, err := pe.deviceInUseByForeignStorage(tt.bd, tt.bdAPIList)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			if len(tt.wantState) != 0 {
+				gotBDAPI := &apis.BlockDevice{}
+				err := cl.Get(context.TODO(), client.ObjectKey{Name: mdBDUUID}, gotBDAPI)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantState, gotBDAPI.Status.State)
+			}
+		})
+	}
+}
+
 func TestDeviceInUseByZFSLocalPV(t *testing.T) {
 	fakePartTableID := "fake-part-table-uuid"
 	fakeBD := blockdevice.BlockDevice{
@@ -735,6 +960,19 @@ func TestHandleUnmanagedDevices(t *testing.T) {
 			want:                   true,
 			wantErr:                false,
 		},
+		"device in use by md raid": {
+			bd: blockdevice.BlockDevice{
+				DevUse: blockdevice.DeviceUsage{
+					InUse:  true,
+					UsedBy: blockdevice.MDRaid,
+				},
+			},
+			bdAPIList:              &apis.BlockDeviceList{},
+			bdCache:                nil,
+			createdOrUpdatedBDName: "",
+			want:                   false,
+			wantErr:                false,
+		},
 		"device in use, but not by mayastor or zfs localPV": {
 			bd: blockdevice.BlockDevice{
 				DevUse: blockdevice.DeviceUsage{
@@ -1064,6 +1302,129 @@ func TestCreateBlockDeviceResourceIfNoHolders(t *testing.T) {
 	}
 }
 
+func TestSuppressMultipathPath(t *testing.T) {
+	path := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: "/dev/sdb"},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			DeviceType: blockdevice.BlockDeviceTypeDisk,
+			WWN:        "0x600140500000000000000000000000a1",
+			Serial:     "360014050000000000000000000000a1",
+		},
+		MultipathInfo: blockdevice.MultipathInformation{MultipathDevice: "/dev/dm-0"},
+	}
+	pathUUID, _ := generateUUID(path)
+	// the partition of the path has the same partition UUID as the partition
+	// of the multipath device
+	partition := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: "/dev/sdb1"},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			DeviceType: blockdevice.BlockDeviceTypePartition,
+		},
+		PartitionInfo: blockdevice.PartitionInformation{
+			PartitionEntryUUID: "0d5c7d1a-7e5b-4e8a-9a6e-7a9b1c3d5e7f",
+		},
+		DependentDevices: blockdevice.DependentBlockDevices{Parent: "/dev/sdb"},
+	}
+	partitionUUID, _ := generateUUID(partition)
+
+	tests := map[string]struct {
+		bd         blockdevice.BlockDevice
+		bdName     string
+		claimState apis.DeviceClaimState
+		wantState  apis.BlockDeviceState
+		wantEvent  bool
+	}{
+		"unclaimed BD of the path is deactivated": {
+			bd:         path,
+			bdName:     pathUUID,
+			claimState: apis.BlockDeviceUnclaimed,
+			wantState:  apis.BlockDeviceInactive,
+			wantEvent:  true,
+		},
+		"claimed BD of the path is not deactivated": {
+			bd:         path,
+			bdName:     pathUUID,
+			claimState: apis.BlockDeviceClaimed,
+			wantState:  apis.BlockDeviceActive,
+		},
+		"BD of the partition of the multipath device is not deactivated": {
+			bd:         partition,
+			bdName:     partitionUUID,
+			claimState: apis.BlockDeviceUnclaimed,
+			wantState:  apis.BlockDeviceActive,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := scheme.Scheme
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDevice{})
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDeviceList{})
+			cl := fake.NewFakeClientWithScheme(s)
+			bdAPI := &apis.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.bdName},
+				Status: apis.DeviceStatus{
+					ClaimState: tt.claimState,
+					State:      apis.BlockDeviceActive,
+				},
+			}
+			cl.Create(context.TODO(), bdAPI)
+			bdAPIList := &apis.BlockDeviceList{}
+			if err := cl.List(context.TODO(), bdAPIList); err != nil {
+				t.Errorf("error updating the resource API List %v", err)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			pe := &ProbeEvent{
+				Controller: &controller.Controller{
+					Clientset:   cl,
+					Recorder:    recorder,
+					BDHierarchy: blockdevice.Hierarchy{path.DevPath: path},
+				},
+			}
+			multipathDevice := pe.multipathDeviceOf(tt.bd)
+			assert.Equal(t, "/dev/dm-0", multipathDevice)
+			pe.suppressMultipathPath(tt.bd, multipathDevice, bdAPIList)
+
+			gotBDAPI := &apis.BlockDevice{}
+			err := cl.Get(context.TODO(), client.ObjectKey{Name: tt.bdName}, gotBDAPI)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantState, gotBDAPI.Status.State)
+			assert.Equal(t, tt.wantEvent, len(recorder.Events) == 1)
+		})
+	}
+}
+
+func TestSuppressMultipathPaths(t *testing.T) {
+	sdb := blockdevice.BlockDevice{Identifier: blockdevice.Identifier{DevPath: "/dev/sdb"}}
+	sdc := blockdevice.BlockDevice{Identifier: blockdevice.Identifier{DevPath: "/dev/sdc"}}
+	mpath := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: "/dev/dm-0"},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			DeviceType: blockdevice.BlockDeviceTypeMultiPath,
+		},
+		MultipathInfo: blockdevice.MultipathInformation{
+			Name: "mpatha",
+			Paths: []blockdevice.MultipathPath{
+				{DevPath: "/dev/sdb", State: "running"},
+				{DevPath: "/dev/sdc", State: "offline"},
+			},
+		},
+	}
+	pe := &ProbeEvent{
+		Controller: &controller.Controller{
+			BDHierarchy: blockdevice.Hierarchy{sdb.DevPath: sdb},
+		},
+	}
+
+	// the paths added before the multipath device are marked in the cache,
+	// and paths which are not in the cache are not added
+	pe.suppressMultipathPaths(mpath, &apis.BlockDeviceList{})
+	assert.Equal(t, "/dev/dm-0", pe.Controller.BDHierarchy[sdb.DevPath].MultipathInfo.MultipathDevice)
+	_, ok := pe.Controller.BDHierarchy[sdc.DevPath]
+	assert.False(t, ok)
+	assert.Equal(t, "", pe.multipathDeviceOf(mpath))
+}
+
 func TestUpgradeDeviceInUseByCStor(t *testing.T) {
 
 	physicalBlockDevice := blockdevice.BlockDevice{
@@ -24,7 +24,7 @@ import (
 )
 
 const (
-	blkidProbePriority = 4
+	blkidProbePriority = 5
 )
 
 var (
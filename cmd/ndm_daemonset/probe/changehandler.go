@@ -18,6 +18,7 @@ package probe
 
 import (
 	"errors"
+	"reflect"
 
 	"github.com/openebs/node-disk-manager/blockdevice"
 	"github.com/openebs/node-disk-manager/pkg/util"
@@ -54,13 +55,19 @@ func (pe *ProbeEvent) changeBlockDevice(bd *blockdevice.BlockDevice, requestedPr
 	 * 1. Size
 	 * 2. Filesystem
 	 * 3. Mount-points
+	 * 4. Paths of a multipath device, which change when a path fails
+	 * 5. Queue settings, like the write cache and the scheduler, which can
+	 *    be changed at runtime. Changing them does not generate a uevent,
+	 *    so they are updated along with the other changes of the device.
 	 *
 	 * Check if any of these have actually changed. This prevents unnecessary
 	 * calls to the k8s api server.
 	 */
 	if bdCopy.Capacity.Storage == bd.Capacity.Storage &&
 		bdCopy.FSInfo.FileSystem == bd.FSInfo.FileSystem &&
-		haveEqualMountPoints {
+		haveEqualMountPoints &&
+		reflect.DeepEqual(bdCopy.MultipathInfo.Paths, bd.MultipathInfo.Paths) &&
+		bdCopy.DeviceAttributes.QueueInfo == bd.DeviceAttributes.QueueInfo {
 		klog.Infof("no changes in %s. Skipping update", bd.DevPath)
 		return nil
 	}
@@ -68,6 +75,10 @@ func (pe *ProbeEvent) changeBlockDevice(bd *blockdevice.BlockDevice, requestedPr
 	if !pe.Controller.ApplyFilter(bd) {
 		return nil
 	}
+	if multipathDevice := pe.multipathDeviceOf(*bd); multipathDevice != "" {
+		klog.Infof("device: %s is a path of multipath device: %s. Skipping update", bd.DevPath, multipathDevice)
+		return nil
+	}
 	apiBlockdevice, err := pe.Controller.NewDeviceInfoFromBlockDevice(bd).ToDevice(pe.Controller)
 	if err != nil {
 		klog.Error("Failed to create a block device resource CR, Error: ", err)
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeWriteCacheProbe fills the write cache of the device
+type fakeWriteCacheProbe struct {
+	writeCache string
+}
+
+func (p *fakeWriteCacheProbe) Start() {}
+
+func (p *fakeWriteCacheProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevice) {
+	blockDevice.DeviceAttributes.QueueInfo.WriteCache = p.writeCache
+}
+
+func TestChangeBlockDeviceQueueInfo(t *testing.T) {
+	tests := map[string]struct {
+		writeCache     string
+		wantWriteCache string
+	}{
+		"write cache not changed": {
+			writeCache:     blockdevice.WriteCacheWriteBack,
+			wantWriteCache: blockdevice.WriteCacheWriteBack,
+		},
+		"write cache disabled at runtime": {
+			writeCache:     blockdevice.WriteCacheWriteThrough,
+			wantWriteCache: blockdevice.WriteCacheWriteThrough,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{UUID: "blockdevice-1", DevPath: "/dev/sdb"},
+				DeviceAttributes: blockdevice.DeviceAttribute{
+					QueueInfo: blockdevice.QueueInformation{WriteCache: blockdevice.WriteCacheWriteBack},
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDevice{})
+			s.AddKnownTypes(apis.GroupVersion, &apis.BlockDeviceList{})
+			cl := fake.NewFakeClientWithScheme(s)
+			bdAPI := &apis.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{Name: bd.UUID},
+				Spec: apis.DeviceSpec{
+					Path:  bd.DevPath,
+					Queue: &apis.QueueInfo{WriteCache: blockdevice.WriteCacheWriteBack},
+				},
+				Status: apis.DeviceStatus{ClaimState: apis.BlockDeviceClaimed},
+			}
+			assert.NoError(t, cl.Create(context.TODO(), bdAPI))
+
+			ctrl := &controller.Controller{
+				Clientset:   cl,
+				Mutex:       &sync.Mutex{},
+				BDHierarchy: blockdevice.Hierarchy{bd.DevPath: bd},
+			}
+			ctrl.AddNewProbe(&controller.Probe{
+				Name:      "write cache probe",
+				State:     true,
+				Interface: &fakeWriteCacheProbe{writeCache: test.writeCache},
+			})
+			pe := &ProbeEvent{Controller: ctrl}
+
+			assert.NoError(t, pe.changeBlockDevice(&bd))
+
+			gotBDAPI := &apis.BlockDevice{}
+			assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: bd.UUID}, gotBDAPI))
+			assert.Equal(t, test.wantWriteCache, gotBDAPI.Spec.Queue.WriteCache)
+		})
+	}
+}
@@ -26,7 +26,7 @@ import (
 )
 
 const (
-	customTagProbePriority = 7
+	customTagProbePriority = 8
 
 	tagTypePath = "path"
 )
@@ -19,6 +19,7 @@ package probe
 import (
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
 	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/util"
 
 	"k8s.io/klog/v2"
 )
@@ -26,7 +27,7 @@ import (
 // removeBlockDeviceFromHierarchyCache removes a block device from the hierarchy.
 // returns true if the device existed in the cache, else returns false
 func (pe *ProbeEvent) removeBlockDeviceFromHierarchyCache(bd blockdevice.BlockDevice) bool {
-	_, ok := pe.Controller.BDHierarchy[bd.DevPath]
+	cacheBD, ok := pe.Controller.BDHierarchy[bd.DevPath]
 	if !ok {
 		klog.Infof("Disk %s not in hierarchy", bd.DevPath)
 		// not in hierarchy continue
@@ -34,21 +35,61 @@ func (pe *ProbeEvent) removeBlockDeviceFromHierarchyCache(bd blockdevice.BlockDe
 	}
 	// remove from the hierarchy
 	delete(pe.Controller.BDHierarchy, bd.DevPath)
+	pe.unlinkLogicalVolume(cacheBD)
 	return true
 }
 
+// unlinkLogicalVolume removes the logical volume from its physical volumes in
+// the hierarchy cache. The details of the volume group are read again from the
+// PVs, since the space allocated to the LV is freed only if the LV was removed,
+// and not if it was just deactivated.
+func (pe *ProbeEvent) unlinkLogicalVolume(bd blockdevice.BlockDevice) {
+	if bd.LVMInfo.LVName == "" {
+		return
+	}
+	changedPVs := make([]string, 0, len(bd.LVMInfo.PhysicalVolumes))
+	for _, pvPath := range bd.LVMInfo.PhysicalVolumes {
+		pvBD, ok := pe.Controller.BDHierarchy[pvPath]
+		if !ok || !util.Contains(pvBD.LVMInfo.LogicalVolumes, bd.LVMInfo.LVName) {
+			continue
+		}
+		// if the metadata cannot be read, only the LV is removed from the PV,
+		// and the free space is updated on the next event for the PV
+		if !readPhysicalVolumeDetails(&pvBD) {
+			pvBD.LVMInfo.LogicalVolumes = util.RemoveString(pvBD.LVMInfo.LogicalVolumes, bd.LVMInfo.LVName)
+		}
+		pe.Controller.BDHierarchy[pvPath] = pvBD
+		changedPVs = append(changedPVs, pvPath)
+		klog.V(4).Infof("unlinked LV: %s from PV: %s in cache", bd.DevPath, pvPath)
+	}
+	pe.updatePhysicalVolumes(changedPVs)
+}
+
 // deleteBlockDevice marks the block device resource as inactive
 // The following cases are handled
 //	1. Device using legacy UUID
 //	2. Device using GPT UUID
 //	3. Device using partition table UUID (zfs localPV)
 //  4. Device using the partition table / fs uuid annotation
+//
+// The paths of a multipath device are looked up only using the GPT UUID of the
+// disk, since the partition table, filesystem and partitions of a path are the
+// same as that of the multipath device, whose BlockDevice should be retained
+// when one of its paths fails.
 func (pe *ProbeEvent) deleteBlockDevice(bd blockdevice.BlockDevice, bdAPIList *apis.BlockDeviceList) error {
+	// the cached device is used, since the dependents are not available
+	// when the device is removed
+	multipathDevice := pe.multipathDeviceOf(pe.Controller.BDHierarchy[bd.DevPath])
 
 	if !pe.removeBlockDeviceFromHierarchyCache(bd) {
 		return nil
 	}
 
+	if multipathDevice != "" && bd.DeviceAttributes.DeviceType == blockdevice.BlockDeviceTypePartition {
+		klog.V(4).Infof("device: %s is a partition of a path of multipath device: %s", bd.DevPath, multipathDevice)
+		return nil
+	}
+
 	// try with gpt uuid
 	if uuid, ok := generateUUID(bd); ok {
 		existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, uuid)
@@ -60,6 +101,11 @@ func (pe *ProbeEvent) deleteBlockDevice(bd blockdevice.BlockDevice, bdAPIList *a
 		// uuid could be generated, but the disk may be using the legacy scheme
 	}
 
+	if multipathDevice != "" {
+		klog.Infof("path: %s of multipath device: %s removed", bd.DevPath, multipathDevice)
+		return nil
+	}
+
 	// try with partition table uuid - for zfs local pV
 	if partUUID, ok := generateUUIDFromPartitionTable(bd); ok {
 		existingBD := pe.Controller.GetExistingBlockDeviceResource(bdAPIList, partUUID)
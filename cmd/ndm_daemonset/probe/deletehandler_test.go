@@ -191,6 +191,22 @@ func TestDeleteBlockDevice(t *testing.T) {
 			FileSystemUUID: fakeFSUUID,
 		},
 	}
+	// a path of a multipath device, which has a zfs pool created on it
+	multipathPathUsedByZFSPV := blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{
+			DevPath: "/dev/sdc",
+		},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			WWN:    fakeWWN,
+			Serial: fakeSerial,
+		},
+		PartitionInfo: blockdevice.PartitionInformation{
+			PartitionTableUUID: fakePartTable,
+		},
+		MultipathInfo: blockdevice.MultipathInformation{
+			MultipathDevice: "/dev/dm-0",
+		},
+	}
 
 	fakePhysicalDiskGPTBasedUUID, _ := generateUUID(physicalDisk)
 	fakePhysicalDiskGPTBasedUUIDPart1, _ := generateUUID(physicalDiskPart1)
@@ -403,6 +419,60 @@ func TestDeleteBlockDevice(t *testing.T) {
 			deactivatedBDs: []string{fakelocalpvVirtualDiskLegacyUUID},
 			wantErr:        false,
 		},
+		"Type: disk, path of a multipath device used by zfs localPV": {
+			bd: multipathPathUsedByZFSPV,
+			bdAPIList: &apis.BlockDeviceList{
+				Items: []apis.BlockDevice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: fakezfspvPhysicalDiskUUID,
+						},
+						Spec: apis.DeviceSpec{
+							Path: "/dev/dm-0",
+						},
+						Status: apis.DeviceStatus{
+							ClaimState: apis.BlockDeviceUnclaimed,
+							State:      apis.BlockDeviceActive,
+						},
+					},
+				},
+			},
+			deactivatedBDs: []string{},
+			wantErr:        false,
+		},
+		"Type: disk, path of a multipath device with a BD created before the multipath device": {
+			bd: multipathPathUsedByZFSPV,
+			bdAPIList: &apis.BlockDeviceList{
+				Items: []apis.BlockDevice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: fakezfspvPhysicalDiskUUID,
+						},
+						Spec: apis.DeviceSpec{
+							Path: "/dev/dm-0",
+						},
+						Status: apis.DeviceStatus{
+							ClaimState: apis.BlockDeviceUnclaimed,
+							State:      apis.BlockDeviceActive,
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: fakePhysicalDiskGPTBasedUUID,
+						},
+						Spec: apis.DeviceSpec{
+							Path: "/dev/sdc",
+						},
+						Status: apis.DeviceStatus{
+							ClaimState: apis.BlockDeviceUnclaimed,
+							State:      apis.BlockDeviceActive,
+						},
+					},
+				},
+			},
+			deactivatedBDs: []string{fakePhysicalDiskGPTBasedUUID},
+			wantErr:        false,
+		},
 		"Type: disk, physical disk, used by zfs localPV": {
 			bd: physicalDiskUsedByZFSPV,
 			bdAPIList: &apis.BlockDeviceList{
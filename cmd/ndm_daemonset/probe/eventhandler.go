@@ -51,8 +51,9 @@ type ProbeEvent struct {
 
 // addBlockDeviceEvent fill block device details from different probes and push it to etcd
 func (pe *ProbeEvent) addBlockDeviceEvent(msg controller.EventMessage) {
-	// bdAPIList is the list of all the BlockDevice resources in the cluster
-	bdAPIList, err := pe.Controller.ListBlockDeviceResource(true)
+	// bdAPIList is the list of the BlockDevice resources on this node. Devices
+	// which were moved from another node are looked up individually.
+	bdAPIList, err := pe.Controller.ListBlockDeviceResource(false)
 	if err != nil {
 		klog.Error(err)
 		go Rescan(pe.Controller)
@@ -81,6 +82,16 @@ func (pe *ProbeEvent) addBlockDeviceEvent(msg controller.EventMessage) {
 		}
 		klog.Infof("Processed details for %s", device.DevPath)
 
+		// the paths of a multipath device are not used as blockdevices,
+		// only the multipath device is used
+		if multipathDevice := pe.multipathDeviceOf(*device); multipathDevice != "" {
+			pe.suppressMultipathPath(*device, multipathDevice, bdAPIList)
+			continue
+		}
+		if device.DeviceAttributes.DeviceType == blockdevice.BlockDeviceTypeMultiPath {
+			pe.suppressMultipathPaths(*device, bdAPIList)
+		}
+
 		if isGPTBasedUUIDEnabled {
 			if isParentOrSlaveDevice(*device, erroredDevices) {
 				klog.Warningf("device: %s skipped, because the parent / slave device has errored", device.DevPath)
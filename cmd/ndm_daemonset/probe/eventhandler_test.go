@@ -17,7 +17,10 @@ limitations under the License.
 package probe
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -27,6 +30,7 @@ import (
 	libudevwrapper "github.com/openebs/node-disk-manager/pkg/udev"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -186,6 +190,8 @@ func TestAddBlockDeviceEvent(t *testing.T) {
 	fakeDr.Spec.Details.Vendor = fakeVendor
 	fakeDr.Spec.Partitioned = controller.NDMNotPartitioned
 	fakeDr.Spec.Path = "/dev/sdX"
+	// no SMART data is available for the device
+	fakeDr.Status.Health = apis.BlockDeviceHealthUnknown
 
 	tests := map[string]struct {
 		actualDisk    apis.BlockDevice
@@ -257,6 +263,100 @@ func TestDeleteDiskEvent(t *testing.T) {
 	}
 }
 
+func TestLogicalVolumeEvents(t *testing.T) {
+	// the PV is an image with the LVM2 metadata of the volume group, which is
+	// replaced with the metadata written after the LV is removed
+	pvPath := filepath.Join(t.TempDir(), "pv0.img")
+	copyFixture := func(name string) {
+		data, err := os.ReadFile(filepath.Join(lvmFixtures, name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(pvPath, data, 0600))
+	}
+	copyFixture("vg_data-pv0.img")
+
+	fakeController := &controller.Controller{
+		Clientset:      CreateFakeClient(t),
+		Mutex:          &sync.Mutex{},
+		Filters:        make([]*controller.Filter, 0),
+		Probes:         make([]*controller.Probe, 0),
+		NodeAttributes: map[string]string{controller.HostNameKey: fakeHostName},
+		BDHierarchy:    make(blockdevice.Hierarchy),
+	}
+	fakeController.AddNewProbe(&controller.Probe{
+		Priority:  lvmProbePriority,
+		Name:      lvmProbeName,
+		State:     true,
+		Interface: &lvmProbe{Controller: fakeController},
+	})
+	probeEvent := &ProbeEvent{
+		Controller: fakeController,
+	}
+
+	pv := &blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: pvPath},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			WWN:        "fake-pv-WWN",
+			Serial:     "fake-pv-serial",
+			DeviceType: blockdevice.BlockDeviceTypeDisk,
+		},
+	}
+	lv := &blockdevice.BlockDevice{
+		Identifier: blockdevice.Identifier{DevPath: "/dev/dm-1"},
+		DeviceAttributes: blockdevice.DeviceAttribute{
+			DeviceType: blockdevice.BlockDeviceTypeLVM,
+		},
+		Capacity: blockdevice.CapacityInformation{Storage: 512 * 4 << 20},
+		DMInfo: blockdevice.DeviceMapperInformation{
+			DMUUID: "LVM-q9J0sM8xYdK2lA3bVnWc7eRt5uPz1oHgqYIRuRHoodYg9Jwkmyvvk0QNYK4YulHt",
+		},
+		DependentDevices: blockdevice.DependentBlockDevices{
+			Slaves: []string{pvPath},
+		},
+	}
+	pvUUID, ok := generateUUID(*pv)
+	require.True(t, ok)
+	getPVLVMInfo := func() *apis.LVMInfo {
+		pvAPI, err := fakeController.GetBlockDevice(pvUUID)
+		require.NoError(t, err)
+		return pvAPI.Spec.LVM
+	}
+
+	// the PV is created with a UUID, and the LV is linked to it
+	probeEvent.addBlockDeviceEvent(controller.EventMessage{
+		Action:  libudevwrapper.UDEV_ACTION_ADD,
+		Devices: []*blockdevice.BlockDevice{pv, lv},
+	})
+	assert.Equal(t, []string{"lv_linear", "lv_striped"}, getPVLVMInfo().LogicalVolumes)
+	assert.Equal(t, uint64(3322*4<<20), getPVLVMInfo().VolumeGroupFree)
+	lvUUID, ok := generateUUID(*lv)
+	require.True(t, ok)
+	lvAPI, err := fakeController.GetBlockDevice(lvUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "lv_striped", lvAPI.Spec.LVM.LogicalVolume)
+
+	removeLV := func() {
+		probeEvent.deleteBlockDeviceEvent(controller.EventMessage{
+			Action:  libudevwrapper.UDEV_ACTION_REMOVE,
+			Devices: []*blockdevice.BlockDevice{{Identifier: lv.Identifier}},
+		})
+	}
+
+	// the details of the volume group are read again from the PV when the LV
+	// is removed. The space of a deactivated LV is still allocated.
+	removeLV()
+	assert.Equal(t, []string{"lv_linear", "lv_striped"}, getPVLVMInfo().LogicalVolumes)
+	assert.Equal(t, uint64(3322*4<<20), getPVLVMInfo().VolumeGroupFree)
+
+	probeEvent.addBlockDeviceEvent(controller.EventMessage{
+		Action:  libudevwrapper.UDEV_ACTION_ADD,
+		Devices: []*blockdevice.BlockDevice{lv},
+	})
+	copyFixture("vg_data-pv0-lvremove.img")
+	removeLV()
+	assert.Equal(t, []string{"lv_linear"}, getPVLVMInfo().LogicalVolumes)
+	assert.Equal(t, uint64((3322+512)*4<<20), getPVLVMInfo().VolumeGroupFree)
+}
+
 // compareBlockDevice is the custom blockdevice comparison function. Only those values that need to be checked
 // for equality will be checked here. Resource version field will not be checked as it
 // will be updated on every write. Refer https://github.com/kubernetes-sigs/controller-runtime/pull/620
@@ -364,3 +464,120 @@ func TestIsParentOrSlaveDevice(t *testing.T) {
 		})
 	}
 }
+
+// countingClient counts the requests made to the API server
+type countingClient struct {
+	client.Client
+	requests int
+}
+
+func (c *countingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.requests++
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *countingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.requests++
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.requests++
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *countingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.requests++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *countingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.requests++
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *countingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+// countingStatusWriter counts the requests made to the status subresource
+// in the requests of the client
+type countingStatusWriter struct {
+	client.StatusWriter
+	c *countingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.c.requests++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.c.requests++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// BenchmarkAddBlockDeviceEvent measures the requests made to the API server when
+// the add events of all the devices on a node are processed again, eg: after a
+// partition table reread. The blockdevice cache is backed by the fake client
+// directly, as the informer serves the lookups from memory.
+func BenchmarkAddBlockDeviceEvent(b *testing.B) {
+	const deviceCount = 60
+
+	devices := make([]*blockdevice.BlockDevice, 0, deviceCount)
+	for i := 0; i < deviceCount; i++ {
+		devices = append(devices, &blockdevice.BlockDevice{
+			Identifier: blockdevice.Identifier{
+				DevPath: fmt.Sprintf("/dev/sd%d", i),
+			},
+			DeviceAttributes: blockdevice.DeviceAttribute{
+				DeviceType: blockdevice.BlockDeviceTypeDisk,
+				WWN:        fmt.Sprintf("%s-%d", fakeWWN, i),
+				Serial:     fmt.Sprintf("%s-%d", fakeSerial, i),
+			},
+		})
+	}
+	eventDetails := controller.EventMessage{
+		Action:  libudevwrapper.UDEV_ACTION_ADD,
+		Devices: devices,
+	}
+
+	tests := []struct {
+		name     string
+		useCache bool
+	}{
+		{name: "without cache", useCache: false},
+		{name: "with cache", useCache: true},
+	}
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			if err := apis.AddToScheme(scheme.Scheme); err != nil {
+				b.Fatal(err)
+			}
+			fakeNdmClient := ndmFakeClientset.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			apiClient := &countingClient{Client: fakeNdmClient}
+			fakeController := &controller.Controller{
+				Clientset:      apiClient,
+				Mutex:          &sync.Mutex{},
+				NodeAttributes: map[string]string{controller.HostNameKey: fakeHostName},
+				BDHierarchy:    make(blockdevice.Hierarchy),
+			}
+			if test.useCache {
+				fakeController.BDCache = fakeNdmClient
+			}
+			probeEvent := &ProbeEvent{
+				Controller: fakeController,
+			}
+
+			// create the blockdevices for all the devices
+			probeEvent.addBlockDeviceEvent(eventDetails)
+
+			apiClient.requests = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				probeEvent.addBlockDeviceEvent(eventDetails)
+			}
+			b.ReportMetric(float64(apiClient.requests)/float64(b.N), "requests/op")
+		})
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/lvm"
+	"github.com/openebs/node-disk-manager/pkg/sysfs"
+	"github.com/openebs/node-disk-manager/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// lvmProbe fills the details of the LVM volume group of physical volumes and
+// logical volumes, by reading the LVM2 metadata from the physical volumes
+type lvmProbe struct {
+	// Every new probe needs a controller object to register itself.
+	// Here Controller consists of Clientset, kubeClientset, probes, etc which is used to
+	// create, update, delete, deactivate the disk resources or list the probes already registered.
+	Controller *controller.Controller
+}
+
+const (
+	lvmConfigKey = "lvm-probe"
+	// lvmProbePriority is set so that the probe runs after the udev and sysfs
+	// probes, which fill the DM UUID and the slaves of the device
+	lvmProbePriority = 6
+	// maxLVMSlaveDepth is the depth up to which the slaves of an LV are looked
+	// up for its PVs. eg: a thin volume is on the thin pool, which is on the
+	// data and metadata volumes, which are on the PVs.
+	maxLVMSlaveDepth = 4
+)
+
+var (
+	lvmProbeName  = "lvm probe"
+	lvmProbeState = defaultEnabled
+)
+
+var lvmProbeRegister = func() {
+	// Get a controller object
+	ctrl := <-controller.ControllerBroadcastChannel
+	if ctrl == nil {
+		klog.Error("unable to configure", lvmProbeName)
+		return
+	}
+	if ctrl.NDMConfig != nil {
+		for _, probeConfig := range ctrl.NDMConfig.ProbeConfigs {
+			if probeConfig.Key == lvmConfigKey {
+				lvmProbeName = probeConfig.Name
+				lvmProbeState = util.CheckTruthy(probeConfig.State)
+				break
+			}
+		}
+	}
+	newRegisterProbe := &registerProbe{
+		priority:   lvmProbePriority,
+		name:       lvmProbeName,
+		state:      lvmProbeState,
+		pi:         &lvmProbe{Controller: ctrl},
+		controller: ctrl,
+	}
+	newRegisterProbe.register()
+}
+
+// Start is mainly used for one time activities such as monitoring.
+// It is a part of probe interface but here we does not require to perform
+// such activities, hence empty implementation
+func (lp *lvmProbe) Start() {}
+
+// FillBlockDeviceDetails fills the details of the volume group to which the
+// device belongs. The metadata of the volume group of a logical volume is read
+// from the physical volumes among its slaves.
+func (lp *lvmProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevice) {
+	if blockDevice.DevPath == "" {
+		klog.Errorf("device identifier found empty, lvm probe will not fetch information")
+		return
+	}
+
+	if vgUUID, lvUUID, ok := lvm.ParseDMUUID(blockDevice.DMInfo.DMUUID); ok {
+		pvs := make(map[string]physicalVolume)
+		findPhysicalVolumes(blockDevice.DependentDevices.Slaves, 0, pvs)
+		vg, pvPaths := volumeGroupOf(vgUUID, pvs)
+		if vg == nil {
+			klog.V(4).Infof("device: %s, LVM2 metadata of volume group %s not found on its slaves",
+				blockDevice.DevPath, vgUUID)
+			return
+		}
+		fillLogicalVolumeDetails(blockDevice, vg, lvUUID, pvPaths)
+		return
+	}
+
+	label, err := lvm.Probe(blockDevice.DevPath)
+	if err != nil {
+		klog.Errorf("error reading LVM2 label from device: %s, %v", blockDevice.DevPath, err)
+		return
+	}
+	if label == nil {
+		return
+	}
+	vg, err := label.VolumeGroup()
+	if err != nil {
+		klog.Errorf("error reading LVM2 metadata from device: %s, %v", blockDevice.DevPath, err)
+	}
+	fillPhysicalVolumeDetails(blockDevice, label.PVUUID, vg)
+}
+
+// readPhysicalVolumeDetails reads the details of the physical volume and its
+// volume group again from the LVM2 metadata on the device. false is returned,
+// and the details are left as is, if the metadata of the volume group could
+// not be read.
+func readPhysicalVolumeDetails(blockDevice *blockdevice.BlockDevice) bool {
+	label, err := lvm.Probe(blockDevice.DevPath)
+	if err != nil || label == nil {
+		klog.Errorf("unable to read LVM2 label from device: %s, %v", blockDevice.DevPath, err)
+		return false
+	}
+	vg, err := label.VolumeGroup()
+	if err != nil || vg == nil {
+		klog.Errorf("unable to read LVM2 metadata from device: %s, %v", blockDevice.DevPath, err)
+		return false
+	}
+	blockDevice.LVMInfo = blockdevice.LVMInformation{}
+	fillPhysicalVolumeDetails(blockDevice, label.PVUUID, vg)
+	return true
+}
+
+// physicalVolume is a PV found among the slaves of a logical volume
+type physicalVolume struct {
+	devPath string
+	label   *lvm.Label
+}
+
+// findPhysicalVolumes reads the LVM2 labels from the slaves. The slaves of the
+// devices without a label are looked up, since they can be other LVs.
+func findPhysicalVolumes(slaves []string, depth int, pvs map[string]physicalVolume) {
+	for _, slave := range slaves {
+		label, err := lvm.Probe(slave)
+		if err != nil {
+			klog.Errorf("error reading LVM2 label from device: %s, %v", slave, err)
+			continue
+		}
+		if label != nil {
+			pvs[label.PVUUID] = physicalVolume{devPath: slave, label: label}
+			continue
+		}
+		if depth+1 >= maxLVMSlaveDepth {
+			continue
+		}
+		sysfsDevice, err := sysfs.NewSysFsDeviceFromDevPath(slave)
+		if err != nil {
+			klog.Errorf("unable to get sysfs device for %s: %v", slave, err)
+			continue
+		}
+		dependents, err := sysfsDevice.GetDependents()
+		if err != nil {
+			klog.Errorf("unable to get slaves of %s: %v", slave, err)
+			continue
+		}
+		findPhysicalVolumes(dependents.Slaves, depth+1, pvs)
+	}
+}
+
+// volumeGroupOf parses the metadata of the volume group from the PVs, and
+// returns the volume group along with the paths of the PVs, keyed by their
+// UUID. The latest metadata among the PVs is used.
+func volumeGroupOf(vgUUID string, pvs map[string]physicalVolume) (*lvm.VolumeGroup, map[string]string) {
+	var latest *lvm.VolumeGroup
+	pvPaths := make(map[string]string)
+	for pvUUID, pv := range pvs {
+		vg, err := pv.label.VolumeGroup()
+		if err != nil {
+			klog.Errorf("error reading LVM2 metadata from device: %s, %v", pv.devPath, err)
+			continue
+		}
+		if vg == nil || vg.UUID != vgUUID {
+			continue
+		}
+		pvPaths[pvUUID] = pv.devPath
+		if latest == nil || vg.SeqNo > latest.SeqNo {
+			latest = vg
+		}
+	}
+	return latest, pvPaths
+}
+
+// fillVolumeGroupDetails fills the details of the volume group into the blockdevice
+func fillVolumeGroupDetails(blockDevice *blockdevice.BlockDevice, vg *lvm.VolumeGroup) {
+	blockDevice.LVMInfo.VGName = vg.Name
+	blockDevice.LVMInfo.VGUUID = vg.UUID
+	blockDevice.LVMInfo.VGSize = vg.Size()
+	blockDevice.LVMInfo.VGFree = vg.Free()
+	blockDevice.LVMInfo.ExtentSize = vg.ExtentSize
+	klog.V(4).Infof("device: %s, VG: %s, VGSize: %d, VGFree: %d filled by lvm probe",
+		blockDevice.DevPath, vg.Name, blockDevice.LVMInfo.VGSize, blockDevice.LVMInfo.VGFree)
+}
+
+// fillPhysicalVolumeDetails fills the details of the physical volume, and the
+// logical volumes allocated on it. vg is nil if the PV is not in a volume group.
+func fillPhysicalVolumeDetails(blockDevice *blockdevice.BlockDevice, pvUUID string, vg *lvm.VolumeGroup) {
+	blockDevice.LVMInfo.PVUUID = pvUUID
+	klog.V(4).Infof("device: %s, PVUUID: %s filled by lvm probe", blockDevice.DevPath, pvUUID)
+	if vg == nil {
+		return
+	}
+	fillVolumeGroupDetails(blockDevice, vg)
+
+	pv := vg.PhysicalVolumeByUUID(pvUUID)
+	if pv == nil {
+		klog.Errorf("device: %s, PV %s not found in volume group %s", blockDevice.DevPath, pvUUID, vg.Name)
+		return
+	}
+	for _, lv := range vg.LogicalVolumesOn(pv) {
+		blockDevice.LVMInfo.LogicalVolumes = append(blockDevice.LVMInfo.LogicalVolumes, lv.Name)
+	}
+	klog.V(4).Infof("device: %s, LogicalVolumes: %v filled by lvm probe",
+		blockDevice.DevPath, blockDevice.LVMInfo.LogicalVolumes)
+}
+
+// fillLogicalVolumeDetails fills the details of the logical volume, and the
+// paths of the physical volumes on which it is allocated. pvPaths are the paths
+// of the PVs keyed by their UUID.
+func fillLogicalVolumeDetails(blockDevice *blockdevice.BlockDevice, vg *lvm.VolumeGroup,
+	lvUUID string, pvPaths map[string]string) {
+	lv := vg.LogicalVolumeByUUID(lvUUID)
+	if lv == nil {
+		klog.Errorf("device: %s, LV %s not found in volume group %s", blockDevice.DevPath, lvUUID, vg.Name)
+		return
+	}
+	fillVolumeGroupDetails(blockDevice, vg)
+	blockDevice.LVMInfo.LVName = lv.Name
+	blockDevice.LVMInfo.LVUUID = lv.UUID
+	for _, pv := range vg.PhysicalVolumesOf(lv) {
+		if devPath, ok := pvPaths[pv.UUID]; ok {
+			blockDevice.LVMInfo.PhysicalVolumes = append(blockDevice.LVMInfo.PhysicalVolumes, devPath)
+		}
+	}
+	klog.V(4).Infof("device: %s, LV: %s, PhysicalVolumes: %v filled by lvm probe",
+		blockDevice.DevPath, lv.Name, blockDevice.LVMInfo.PhysicalVolumes)
+}
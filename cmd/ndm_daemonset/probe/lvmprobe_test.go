@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/lvm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lvmFixtures is the directory with the LVM2 metadata of a volume group. The
+// path is resolved when the package is initialized, since some of the tests
+// change the working directory.
+var lvmFixtures, _ = filepath.Abs(filepath.Join("..", "..", "..", "pkg", "lvm", "testdata"))
+
+const (
+	fakeVGUUID  = "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg"
+	fakePV0UUID = "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu"
+	fakePV1UUID = "Bx7Tq2-Lm4N-8pQr-Ws3E-Yu6I-Op9A-Df1GhJ"
+)
+
+func readVolumeGroupFixture(t *testing.T) *lvm.VolumeGroup {
+	text, err := os.ReadFile(filepath.Join(lvmFixtures, "vg_data.vg"))
+	require.NoError(t, err)
+	vg, err := lvm.ParseMetadata(text)
+	require.NoError(t, err)
+	return vg
+}
+
+func TestLVMProbeFillBlockDeviceDetails(t *testing.T) {
+	tests := map[string]struct {
+		image  string
+		dmUUID string
+		slaves []string
+		want   blockdevice.LVMInformation
+	}{
+		"pv in a volume group": {
+			image: "lvm2-pv.img",
+			want: blockdevice.LVMInformation{
+				VGName:     "vg_data",
+				VGUUID:     fakeVGUUID,
+				ExtentSize: 4 << 20,
+				PVUUID:     fakePV0UUID,
+			},
+		},
+		"pv without a volume group": {
+			image: "lvm2-orphan-pv.img",
+			want: blockdevice.LVMInformation{
+				PVUUID: fakePV0UUID,
+			},
+		},
+		"device without lvm label": {
+			image: "bluestore.img",
+		},
+		"lv whose volume group is not on the slaves": {
+			image:  "empty.img",
+			dmUUID: "LVM-q9J0sM8xYdK2lA3bVnWc7eRt5uPz1oHgOSlVs5gIXuqSKVPukc2aGPh0AeJw31TJ",
+			slaves: []string{filepath.Join(superblockFixtures, "lvm2-orphan-pv.img")},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{
+					DevPath: filepath.Join(superblockFixtures, test.image),
+				},
+				DMInfo: blockdevice.DeviceMapperInformation{
+					DMUUID: test.dmUUID,
+				},
+				DependentDevices: blockdevice.DependentBlockDevices{
+					Slaves: test.slaves,
+				},
+			}
+			lp := &lvmProbe{}
+			lp.FillBlockDeviceDetails(bd)
+			assert.Equal(t, test.want, bd.LVMInfo)
+		})
+	}
+}
+
+func TestFillPhysicalVolumeDetails(t *testing.T) {
+	vg := readVolumeGroupFixture(t)
+	tests := map[string]struct {
+		pvUUID string
+		vg     *lvm.VolumeGroup
+		want   blockdevice.LVMInformation
+	}{
+		"pv with logical volumes": {
+			pvUUID: fakePV1UUID,
+			vg:     vg,
+			want: blockdevice.LVMInformation{
+				VGName:         "vg_data",
+				VGUUID:         fakeVGUUID,
+				VGSize:         5118 * 4 << 20,
+				VGFree:         3322 * 4 << 20,
+				ExtentSize:     4 << 20,
+				PVUUID:         fakePV1UUID,
+				LogicalVolumes: []string{"lv_striped", "thinpool", "thinvol"},
+			},
+		},
+		"pv without a volume group": {
+			pvUUID: fakePV1UUID,
+			want: blockdevice.LVMInformation{
+				PVUUID: fakePV1UUID,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &blockdevice.BlockDevice{}
+			fillPhysicalVolumeDetails(bd, test.pvUUID, test.vg)
+			assert.Equal(t, test.want, bd.LVMInfo)
+		})
+	}
+}
+
+func TestFillLogicalVolumeDetails(t *testing.T) {
+	vg := readVolumeGroupFixture(t)
+	pvPaths := map[string]string{
+		fakePV0UUID: "/dev/sdb",
+		fakePV1UUID: "/dev/sdc",
+	}
+	tests := map[string]struct {
+		lvUUID              string
+		pvPaths             map[string]string
+		wantLVName          string
+		wantPhysicalVolumes []string
+	}{
+		"striped volume": {
+			lvUUID:              "qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt",
+			pvPaths:             pvPaths,
+			wantLVName:          "lv_striped",
+			wantPhysicalVolumes: []string{"/dev/sdb", "/dev/sdc"},
+		},
+		"thin volume": {
+			lvUUID:              "Gh7Jk2-Lq9W-eR4t-Yu8I-oP1a-Sd6F-gH3jKl",
+			pvPaths:             pvPaths,
+			wantLVName:          "thinvol",
+			wantPhysicalVolumes: []string{"/dev/sdc"},
+		},
+		"pv not found among the slaves": {
+			lvUUID:              "qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt",
+			pvPaths:             map[string]string{fakePV0UUID: "/dev/sdb"},
+			wantLVName:          "lv_striped",
+			wantPhysicalVolumes: []string{"/dev/sdb"},
+		},
+		"lv not in the volume group": {
+			lvUUID:  "Zz9Yy8-Xx7W-w6Vv-5Uu4-Tt3S-s2Rr-1Qq0Pp",
+			pvPaths: pvPaths,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &blockdevice.BlockDevice{}
+			fillLogicalVolumeDetails(bd, vg, test.lvUUID, test.pvPaths)
+			assert.Equal(t, test.wantLVName, bd.LVMInfo.LVName)
+			assert.Equal(t, test.wantPhysicalVolumes, bd.LVMInfo.PhysicalVolumes)
+			if test.wantLVName == "" {
+				assert.Empty(t, bd.LVMInfo.VGUUID)
+				return
+			}
+			assert.Equal(t, test.lvUUID, bd.LVMInfo.LVUUID)
+			assert.Equal(t, fakeVGUUID, bd.LVMInfo.VGUUID)
+			assert.Equal(t, uint64(3322*4<<20), bd.LVMInfo.VGFree)
+		})
+	}
+}
+
+func TestVolumeGroupOf(t *testing.T) {
+	// the slaves of devices without a label are not looked up at the
+	// maximum depth
+	pvs := make(map[string]physicalVolume)
+	findPhysicalVolumes([]string{
+		filepath.Join(superblockFixtures, "lvm2-pv.img"),
+		filepath.Join(superblockFixtures, "bluestore.img"),
+	}, maxLVMSlaveDepth, pvs)
+	require.Len(t, pvs, 1)
+
+	vg, pvPaths := volumeGroupOf(fakeVGUUID, pvs)
+	require.NotNil(t, vg)
+	assert.Equal(t, "vg_data", vg.Name)
+	assert.Equal(t, map[string]string{fakePV0UUID: filepath.Join(superblockFixtures, "lvm2-pv.img")}, pvPaths)
+
+	vg, pvPaths = volumeGroupOf("Zz9Yy8-Xx7W-w6Vv-5Uu4-Tt3S-s2Rr-1Qq0Pp", pvs)
+	assert.Nil(t, vg)
+	assert.Empty(t, pvPaths)
+}
@@ -42,7 +42,7 @@ type mountProbe struct {
 }
 
 const (
-	mountProbePriority = 4
+	mountProbePriority = 5
 	mountConfigKey     = "mount-probe"
 )
 
@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"math"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/nvme"
+	"github.com/openebs/node-disk-manager/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// nvmeProbe fills the details of NVMe namespaces by sending admin commands to the device
+type nvmeProbe struct {
+	// Every new probe needs a controller object to register itself.
+	// Here Controller consists of Clientset, kubeClientset, probes, etc which is used to
+	// create, update, delete, deactivate the disk resources or list the probes already registered.
+	Controller *controller.Controller
+}
+
+const (
+	nvmeConfigKey = "nvme-probe"
+	// nvmeProbePriority is set so that the probe runs after the smart probe, which
+	// overwrites the firmware revision irrespective of the bus type of the device
+	nvmeProbePriority = 5
+	// nvmeDataUnitSize is the size of the data units read and written reported
+	// by the controller in the SMART log page, in bytes
+	nvmeDataUnitSize = 512 * 1000
+)
+
+var (
+	nvmeProbeName  = "nvme probe"
+	nvmeProbeState = defaultEnabled
+)
+
+var nvmeProbeRegister = func() {
+	// Get a controller object
+	ctrl := <-controller.ControllerBroadcastChannel
+	if ctrl == nil {
+		klog.Error("unable to configure", nvmeProbeName)
+		return
+	}
+	if ctrl.NDMConfig != nil {
+		for _, probeConfig := range ctrl.NDMConfig.ProbeConfigs {
+			if probeConfig.Key == nvmeConfigKey {
+				nvmeProbeName = probeConfig.Name
+				nvmeProbeState = util.CheckTruthy(probeConfig.State)
+				break
+			}
+		}
+	}
+	newRegisterProbe := &registerProbe{
+		priority:   nvmeProbePriority,
+		name:       nvmeProbeName,
+		state:      nvmeProbeState,
+		pi:         &nvmeProbe{Controller: ctrl},
+		controller: ctrl,
+	}
+	newRegisterProbe.register()
+}
+
+// Start is mainly used for one time activities such as monitoring.
+// It is a part of probe interface but here we does not require to perform
+// such activities, hence empty implementation
+func (np *nvmeProbe) Start() {}
+
+// FillBlockDeviceDetails fills the details of the NVMe namespace and the SMART
+// details of its controller. Devices other than NVMe namespaces are skipped.
+func (np *nvmeProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevice) {
+	if !nvme.IsNVMeNamespace(blockDevice.DevPath) {
+		klog.V(4).Infof("device: %s is not an NVMe namespace, nvme probe will not fill details", blockDevice.DevPath)
+		return
+	}
+
+	identifier := &nvme.Identifier{DevPath: blockDevice.DevPath}
+	info, err := identifier.DeviceInfo()
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	fillNVMeDetails(blockDevice, info)
+}
+
+// fillNVMeDetails fills the details fetched from the NVMe namespace into the
+// blockdevice. Details already filled by other probes are not overwritten.
+func fillNVMeDetails(blockDevice *blockdevice.BlockDevice, info *nvme.DeviceInfo) {
+	if blockDevice.DeviceAttributes.Model == "" && info.Controller.Model != "" {
+		blockDevice.DeviceAttributes.Model = info.Controller.Model
+		klog.V(4).Infof("device: %s, Model: %s filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.DeviceAttributes.Model)
+	}
+
+	if blockDevice.DeviceAttributes.Serial == "" && info.Controller.Serial != "" {
+		blockDevice.DeviceAttributes.Serial = info.Controller.Serial
+		klog.V(4).Infof("device: %s, Serial: %s filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.DeviceAttributes.Serial)
+	}
+
+	if blockDevice.DeviceAttributes.FirmwareRevision == "" && info.Controller.FirmwareRevision != "" {
+		blockDevice.DeviceAttributes.FirmwareRevision = info.Controller.FirmwareRevision
+		klog.V(4).Infof("device: %s, FirmwareRevision: %s filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.DeviceAttributes.FirmwareRevision)
+	}
+
+	blockDevice.DeviceAttributes.NamespaceID = info.Namespace.ID
+	blockDevice.DeviceAttributes.EUI64 = info.Namespace.EUI64
+	blockDevice.DeviceAttributes.NGUID = info.Namespace.NGUID
+	klog.V(4).Infof("device: %s, NamespaceID: %d, EUI64: %s, NGUID: %s filled by nvme-probe",
+		blockDevice.DevPath, info.Namespace.ID, info.Namespace.EUI64, info.Namespace.NGUID)
+
+	if blockDevice.DeviceAttributes.LogicalBlockSize == 0 && info.Namespace.LogicalBlockSize != 0 {
+		blockDevice.DeviceAttributes.LogicalBlockSize = info.Namespace.LogicalBlockSize
+		klog.V(4).Infof("device: %s, LogicalBlockSize: %d filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.DeviceAttributes.LogicalBlockSize)
+	}
+
+	smartLog := info.SMARTLog
+
+	// the temperature is not reported by the controller if it is 0
+	if smartLog.Temperature != 0 {
+		blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+		blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature = smartLog.Temperature
+		klog.V(4).Infof("device: %s, CurrentTemperature: %d filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature)
+	}
+
+	if blockDevice.SMARTInfo.PercentEnduranceUsed == 0 {
+		blockDevice.SMARTInfo.PercentEnduranceUsed = float64(smartLog.PercentageUsed)
+		klog.V(4).Infof("device: %s, PercentEnduranceUsed: %f filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.PercentEnduranceUsed)
+	}
+
+	if blockDevice.SMARTInfo.TotalBytesRead == 0 {
+		blockDevice.SMARTInfo.TotalBytesRead = nvmeDataUnitsToBytes(smartLog.DataUnitsRead)
+	}
+
+	if blockDevice.SMARTInfo.TotalBytesWritten == 0 {
+		blockDevice.SMARTInfo.TotalBytesWritten = nvmeDataUnitsToBytes(smartLog.DataUnitsWritten)
+	}
+
+	// a degraded or read only device is reported as failed, while the spare and
+	// temperature warnings are left to the thresholds used for evaluating the health
+	blockDevice.SMARTInfo.CriticalWarning = smartLog.CriticalWarning
+	if blockDevice.SMARTInfo.OverallHealth == "" {
+		blockDevice.SMARTInfo.OverallHealth = blockdevice.SMARTHealthPassed
+		if smartLog.CriticalWarning&(nvme.CriticalWarningReliability|
+			nvme.CriticalWarningReadOnly|nvme.CriticalWarningVolatileBackup) != 0 {
+			blockDevice.SMARTInfo.OverallHealth = blockdevice.SMARTHealthFailed
+		}
+		klog.V(4).Infof("device: %s, OverallHealth: %s filled by nvme-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.OverallHealth)
+	}
+
+	blockDevice.SMARTInfo.MediaErrors = smartLog.MediaErrors
+	blockDevice.SMARTInfo.PowerOnHours = smartLog.PowerOnHours
+	blockDevice.SMARTInfo.UnsafeShutdowns = smartLog.UnsafeShutdowns
+	klog.V(4).Infof("device: %s, MediaErrors: %d, PowerOnHours: %d, UnsafeShutdowns: %d filled by nvme-probe",
+		blockDevice.DevPath, smartLog.MediaErrors, smartLog.PowerOnHours, smartLog.UnsafeShutdowns)
+}
+
+// nvmeDataUnitsToBytes converts the data units reported in the SMART log page
+// to bytes, capping the value at the max value of uint64 on overflow
+func nvmeDataUnitsToBytes(units uint64) uint64 {
+	if units > math.MaxUint64/nvmeDataUnitSize {
+		return math.MaxUint64
+	}
+	return units * nvmeDataUnitSize
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"testing"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/nvme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNVMeProbeSkipsOtherDevices(t *testing.T) {
+	np := &nvmeProbe{}
+	bd := &blockdevice.BlockDevice{}
+	bd.DevPath = "/dev/nvme0n1p1"
+	np.FillBlockDeviceDetails(bd)
+
+	expected := &blockdevice.BlockDevice{}
+	expected.DevPath = "/dev/nvme0n1p1"
+	assert.Equal(t, expected, bd)
+}
+
+func TestFillNVMeDetails(t *testing.T) {
+	info := &nvme.DeviceInfo{
+		Controller: nvme.ControllerInfo{
+			Serial:           "S4EVNF0M123456A",
+			Model:            "Samsung SSD 970 EVO Plus 500GB",
+			FirmwareRevision: "2B2QEXM7",
+		},
+		Namespace: nvme.NamespaceInfo{
+			ID:               1,
+			LogicalBlockSize: 512,
+			EUI64:            "0025385891b0a1b2",
+		},
+		SMARTLog: nvme.SMARTLog{
+			Temperature:      37,
+			PercentageUsed:   2,
+			DataUnitsRead:    10,
+			DataUnitsWritten: 20,
+			PowerOnHours:     4321,
+			UnsafeShutdowns:  37,
+			MediaErrors:      1,
+		},
+	}
+
+	tests := map[string]struct {
+		bd   *blockdevice.BlockDevice
+		want func(bd *blockdevice.BlockDevice)
+	}{
+		"empty blockdevice": {
+			bd: &blockdevice.BlockDevice{},
+			want: func(bd *blockdevice.BlockDevice) {
+				bd.DeviceAttributes.Model = "Samsung SSD 970 EVO Plus 500GB"
+				bd.DeviceAttributes.Serial = "S4EVNF0M123456A"
+				bd.DeviceAttributes.FirmwareRevision = "2B2QEXM7"
+				bd.DeviceAttributes.LogicalBlockSize = 512
+			},
+		},
+		"details filled by other probes are not overwritten": {
+			bd: func() *blockdevice.BlockDevice {
+				bd := &blockdevice.BlockDevice{}
+				bd.DeviceAttributes.Model = "Samsung_SSD_970_EVO_Plus_500GB"
+				bd.DeviceAttributes.Serial = "S4EVNF0M123456A_1"
+				bd.DeviceAttributes.LogicalBlockSize = 4096
+				return bd
+			}(),
+			want: func(bd *blockdevice.BlockDevice) {
+				bd.DeviceAttributes.Model = "Samsung_SSD_970_EVO_Plus_500GB"
+				bd.DeviceAttributes.Serial = "S4EVNF0M123456A_1"
+				bd.DeviceAttributes.FirmwareRevision = "2B2QEXM7"
+				bd.DeviceAttributes.LogicalBlockSize = 4096
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.bd.DevPath = "/dev/nvme0n1"
+			fillNVMeDetails(test.bd, info)
+
+			expected := &blockdevice.BlockDevice{}
+			expected.DevPath = "/dev/nvme0n1"
+			test.want(expected)
+			expected.DeviceAttributes.NamespaceID = 1
+			expected.DeviceAttributes.EUI64 = "0025385891b0a1b2"
+			expected.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+			expected.SMARTInfo.TemperatureInfo.CurrentTemperature = 37
+			expected.SMARTInfo.PercentEnduranceUsed = 2
+			expected.SMARTInfo.TotalBytesRead = 10 * 512 * 1000
+			expected.SMARTInfo.TotalBytesWritten = 20 * 512 * 1000
+			expected.SMARTInfo.PowerOnHours = 4321
+			expected.SMARTInfo.UnsafeShutdowns = 37
+			expected.SMARTInfo.MediaErrors = 1
+			expected.SMARTInfo.OverallHealth = blockdevice.SMARTHealthPassed
+			assert.Equal(t, expected, test.bd)
+		})
+	}
+}
@@ -30,10 +30,13 @@ const (
 var RegisteredProbes = []func(){
 	seachestProbeRegister,
 	smartProbeRegister,
+	nvmeProbeRegister,
 	mountProbeRegister,
 	udevProbeRegister,
 	sysfsProbeRegister,
 	usedbyProbeRegister,
+	lvmProbeRegister,
+	transportProbeRegister,
 	customTagProbeRegister,
 	blkidProbeRegister,
 }
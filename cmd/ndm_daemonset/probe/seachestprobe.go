@@ -35,7 +35,7 @@ type seachestProbe struct {
 
 const (
 	seachestConfigKey     = "seachest-probe"
-	seachestProbePriority = 6
+	seachestProbePriority = 7
 )
 
 var (
@@ -35,7 +35,20 @@ type smartProbe struct {
 
 const (
 	smartConfigKey     = "smart-probe"
-	smartProbePriority = 3
+	smartProbePriority = 4
+)
+
+// IDs of the ATA SMART attributes used to fill the SMART stats
+const (
+	smartReallocatedSectorCount = 5
+	smartPowerOnHours           = 9
+	smartWearLevelingCount      = 177
+	smartAirflowTemperature     = 190
+	smartTemperatureCelsius     = 194
+	smartCurrentPendingSector   = 197
+	smartUDMACRCErrorCount      = 199
+	smartSSDLifeLeft            = 231
+	smartMediaWearoutIndicator  = 233
 )
 
 var (
@@ -121,4 +134,152 @@ func (sp *smartProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevic
 		klog.V(4).Infof("device: %s, PhysicalBlockSize: %d filled by smart-probe",
 			blockDevice.DevPath, blockDevice.DeviceAttributes.PhysicalBlockSize)
 	}
+
+	ataSMARTInfo, ataErr := smartProbe.SmartIdentifier.ATASMARTInfo()
+	if ataErr != nil {
+		// SMART attributes are available only for ATA devices
+		klog.V(4).Infof("device: %s, unable to get ATA SMART attributes: %v", blockDevice.DevPath, ataErr)
+	} else {
+		fillATASMARTDetails(blockDevice, ataSMARTInfo)
+	}
+
+	// log pages are the only source of health information for SAS devices. Pages
+	// that could be read are used even if fetching some of the others failed.
+	logPages, logErr := smartProbe.SmartIdentifier.SCSILogPages()
+	if len(logErr) != 0 {
+		klog.V(4).Infof("device: %s, unable to get some of the SCSI log pages: %v", blockDevice.DevPath, logErr)
+	}
+	fillSCSILogPageDetails(blockDevice, logPages)
+}
+
+// fillSCSILogPageDetails fills the error counters and the health status of a
+// device from its SCSI log pages. Values already filled from the ATA SMART
+// attributes are not overwritten.
+func fillSCSILogPageDetails(blockDevice *blockdevice.BlockDevice, pages smart.SCSILogPages) {
+	if pages.GrownDefects != nil {
+		blockDevice.SMARTInfo.GrownDefects = *pages.GrownDefects
+	}
+	if pages.ReadErrors != nil {
+		blockDevice.SMARTInfo.UncorrectedReadErrors = pages.ReadErrors.TotalUncorrected
+	}
+	if pages.WriteErrors != nil {
+		blockDevice.SMARTInfo.UncorrectedWriteErrors = pages.WriteErrors.TotalUncorrected
+	}
+	if pages.VerifyErrors != nil {
+		blockDevice.SMARTInfo.UncorrectedVerifyErrors = pages.VerifyErrors.TotalUncorrected
+	}
+	if pages.NonMediumErrors != nil {
+		blockDevice.SMARTInfo.NonMediumErrors = pages.NonMediumErrors.Count
+	}
+	if pages.StartStopCycles != nil {
+		blockDevice.SMARTInfo.StartStopCycles = uint64(pages.StartStopCycles.AccumulatedCycles)
+	}
+	if pages.SelfTest != nil {
+		var failed uint64
+		for _, result := range pages.SelfTest.Results {
+			if result.Failed() {
+				failed++
+			}
+		}
+		blockDevice.SMARTInfo.FailedSelfTests = failed
+	}
+	klog.V(4).Infof("device: %s, GrownDefects: %d, UncorrectedReadErrors: %d, UncorrectedWriteErrors: %d, "+
+		"UncorrectedVerifyErrors: %d, NonMediumErrors: %d, StartStopCycles: %d, FailedSelfTests: %d filled by smart-probe",
+		blockDevice.DevPath, blockDevice.SMARTInfo.GrownDefects, blockDevice.SMARTInfo.UncorrectedReadErrors,
+		blockDevice.SMARTInfo.UncorrectedWriteErrors, blockDevice.SMARTInfo.UncorrectedVerifyErrors,
+		blockDevice.SMARTInfo.NonMediumErrors, blockDevice.SMARTInfo.StartStopCycles,
+		blockDevice.SMARTInfo.FailedSelfTests)
+
+	if pages.InformationalExceptions != nil && blockDevice.SMARTInfo.OverallHealth == "" {
+		blockDevice.SMARTInfo.OverallHealth = blockdevice.SMARTHealthPassed
+		if pages.InformationalExceptions.Failing() {
+			blockDevice.SMARTInfo.OverallHealth = blockdevice.SMARTHealthFailed
+		}
+		klog.V(4).Infof("device: %s, OverallHealth: %s filled by smart-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.OverallHealth)
+	}
+
+	if pages.Temperature != nil && pages.Temperature.CurrentValid &&
+		!blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid {
+		blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+		blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature = pages.Temperature.Current
+		klog.V(4).Infof("device: %s, CurrentTemperature: %d filled by smart-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature)
+	}
+
+	if pages.SolidStateMedia != nil && blockDevice.SMARTInfo.PercentEnduranceUsed == 0 {
+		blockDevice.SMARTInfo.PercentEnduranceUsed = float64(pages.SolidStateMedia.PercentageUsedEnduranceIndicator)
+		klog.V(4).Infof("device: %s, PercentEnduranceUsed: %f filled by smart-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.PercentEnduranceUsed)
+	}
+}
+
+// fillATASMARTDetails fills the SMART attribute table and the health status of
+// an ATA device, along with the values of the commonly used attributes
+func fillATASMARTDetails(blockDevice *blockdevice.BlockDevice, info smart.ATASMARTInfo) {
+	blockDevice.SMARTInfo.OverallHealth = string(info.Health)
+	klog.V(4).Infof("device: %s, OverallHealth: %s filled by smart-probe",
+		blockDevice.DevPath, blockDevice.SMARTInfo.OverallHealth)
+
+	attributes := make([]blockdevice.SMARTAttribute, 0, len(info.Attributes))
+	for _, attr := range info.Attributes {
+		attributes = append(attributes, blockdevice.SMARTAttribute{
+			ID:         attr.ID,
+			Name:       attr.Name,
+			Value:      attr.Value,
+			Worst:      attr.Worst,
+			Threshold:  attr.Threshold,
+			Raw:        attr.Raw,
+			Prefailure: attr.Prefailure,
+			FailingNow: attr.FailingNow,
+		})
+	}
+	blockDevice.SMARTInfo.Attributes = attributes
+
+	if attr, ok := info.Attribute(smartReallocatedSectorCount); ok {
+		blockDevice.SMARTInfo.ReallocatedSectors = attr.Raw
+	}
+	if attr, ok := info.Attribute(smartCurrentPendingSector); ok {
+		blockDevice.SMARTInfo.PendingSectors = attr.Raw
+	}
+	if attr, ok := info.Attribute(smartUDMACRCErrorCount); ok {
+		blockDevice.SMARTInfo.CRCErrors = attr.Raw
+	}
+	klog.V(4).Infof("device: %s, ReallocatedSectors: %d, PendingSectors: %d, CRCErrors: %d filled by smart-probe",
+		blockDevice.DevPath, blockDevice.SMARTInfo.ReallocatedSectors,
+		blockDevice.SMARTInfo.PendingSectors, blockDevice.SMARTInfo.CRCErrors)
+
+	// some vendors use the upper bytes of the raw value to report minutes and
+	// seconds, only the lower 4 bytes are the hours
+	if attr, ok := info.Attribute(smartPowerOnHours); ok && blockDevice.SMARTInfo.PowerOnHours == 0 {
+		blockDevice.SMARTInfo.PowerOnHours = attr.Raw & 0xffffffff
+		klog.V(4).Infof("device: %s, PowerOnHours: %d filled by smart-probe",
+			blockDevice.DevPath, blockDevice.SMARTInfo.PowerOnHours)
+	}
+
+	// the lowest byte of the raw value is the current temperature in celsius
+	if !blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid {
+		for _, id := range []uint8{smartTemperatureCelsius, smartAirflowTemperature} {
+			if attr, ok := info.Attribute(id); ok {
+				blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+				blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature = int16(attr.Raw & 0xff)
+				klog.V(4).Infof("device: %s, CurrentTemperature: %d filled by smart-probe",
+					blockDevice.DevPath, blockDevice.SMARTInfo.TemperatureInfo.CurrentTemperature)
+				break
+			}
+		}
+	}
+
+	// the normalized value of the wear leveling attributes is the percentage of
+	// the rated life remaining
+	if blockDevice.SMARTInfo.PercentEnduranceUsed == 0 {
+		for _, id := range []uint8{smartWearLevelingCount, smartMediaWearoutIndicator, smartSSDLifeLeft} {
+			if attr, ok := info.Attribute(id); ok && attr.Value <= 100 {
+				blockDevice.SMARTInfo.PercentEnduranceUsed = float64(100 - attr.Value)
+				klog.V(4).Infof("device: %s, PercentEnduranceUsed: %f filled by smart-probe",
+					blockDevice.DevPath, blockDevice.SMARTInfo.PercentEnduranceUsed)
+				break
+			}
+		}
+	}
 }
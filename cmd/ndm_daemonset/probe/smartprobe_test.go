@@ -103,3 +103,151 @@ func TestFillDiskDetailsBySmart(t *testing.T) {
 	expectedDiskInfo.DeviceAttributes.Compliance = mockOsDiskDetails.Compliance
 	assert.Equal(t, expectedDiskInfo, actualDiskInfo)
 }
+
+func TestFillATASMARTDetails(t *testing.T) {
+	info := smart.ATASMARTInfo{
+		Health: smart.ATASMARTHealthFailed,
+		Attributes: []smart.ATASMARTAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 3, Worst: 3, Threshold: 140, Raw: 3712,
+				Prefailure: true, FailingNow: true},
+			{ID: 9, Name: "Power_On_Hours", Value: 45, Worst: 45, Raw: 0x123400009c40},
+			{ID: 177, Name: "Wear_Leveling_Count", Value: 97, Worst: 97, Raw: 31, Prefailure: true},
+			{ID: 194, Name: "Temperature_Celsius", Value: 112, Worst: 95, Raw: 0x2d0014000026},
+			{ID: 197, Name: "Current_Pending_Sector", Value: 200, Worst: 200, Raw: 24},
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: 2},
+		},
+	}
+
+	tests := map[string]struct {
+		bd   *blockdevice.BlockDevice
+		want blockdevice.SMARTStats
+	}{
+		"empty blockdevice": {
+			bd: &blockdevice.BlockDevice{},
+			want: blockdevice.SMARTStats{
+				TemperatureInfo: blockdevice.TemperatureInformation{
+					CurrentTemperatureDataValid: true,
+					CurrentTemperature:          38,
+				},
+				PercentEnduranceUsed: 3,
+				PowerOnHours:         40000,
+			},
+		},
+		"stats filled by other probes are not overwritten": {
+			bd: func() *blockdevice.BlockDevice {
+				bd := &blockdevice.BlockDevice{}
+				bd.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+				bd.SMARTInfo.TemperatureInfo.CurrentTemperature = 40
+				bd.SMARTInfo.PercentEnduranceUsed = 5
+				return bd
+			}(),
+			want: blockdevice.SMARTStats{
+				TemperatureInfo: blockdevice.TemperatureInformation{
+					CurrentTemperatureDataValid: true,
+					CurrentTemperature:          40,
+				},
+				PercentEnduranceUsed: 5,
+				PowerOnHours:         40000,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fillATASMARTDetails(test.bd, info)
+
+			test.want.OverallHealth = "FAILED"
+			test.want.ReallocatedSectors = 3712
+			test.want.PendingSectors = 24
+			test.want.CRCErrors = 2
+			test.want.Attributes = []blockdevice.SMARTAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 3, Worst: 3, Threshold: 140, Raw: 3712,
+					Prefailure: true, FailingNow: true},
+				{ID: 9, Name: "Power_On_Hours", Value: 45, Worst: 45, Raw: 0x123400009c40},
+				{ID: 177, Name: "Wear_Leveling_Count", Value: 97, Worst: 97, Raw: 31, Prefailure: true},
+				{ID: 194, Name: "Temperature_Celsius", Value: 112, Worst: 95, Raw: 0x2d0014000026},
+				{ID: 197, Name: "Current_Pending_Sector", Value: 200, Worst: 200, Raw: 24},
+				{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: 2},
+			}
+			assert.Equal(t, test.want, test.bd.SMARTInfo)
+		})
+	}
+}
+
+func TestFillSCSILogPageDetails(t *testing.T) {
+	grownDefects := uint64(12)
+	pages := smart.SCSILogPages{
+		ReadErrors:      &smart.ErrorCounterLog{TotalCorrected: 100, TotalUncorrected: 2},
+		WriteErrors:     &smart.ErrorCounterLog{TotalUncorrected: 1},
+		NonMediumErrors: &smart.NonMediumErrorLog{Count: 17},
+		Temperature:     &smart.TemperatureLog{Current: 37, CurrentValid: true},
+		StartStopCycles: &smart.StartStopCycleLog{AccumulatedCycles: 75},
+		SelfTest: &smart.SelfTestLog{Results: []smart.SelfTestResult{
+			{Code: 2, Result: 7, Number: 3},
+			{Code: 1, Result: 0},
+		}},
+		SolidStateMedia:         &smart.SolidStateMediaLog{PercentageUsedEnduranceIndicator: 7},
+		InformationalExceptions: &smart.InformationalExceptionsLog{ASC: 0x5d, ASCQ: 0x10},
+		GrownDefects:            &grownDefects,
+	}
+
+	tests := map[string]struct {
+		bd    *blockdevice.BlockDevice
+		pages smart.SCSILogPages
+		want  blockdevice.SMARTStats
+	}{
+		"empty blockdevice": {
+			bd:    &blockdevice.BlockDevice{},
+			pages: pages,
+			want: blockdevice.SMARTStats{
+				TemperatureInfo: blockdevice.TemperatureInformation{
+					CurrentTemperatureDataValid: true,
+					CurrentTemperature:          37,
+				},
+				PercentEnduranceUsed:   7,
+				GrownDefects:           12,
+				UncorrectedReadErrors:  2,
+				UncorrectedWriteErrors: 1,
+				NonMediumErrors:        17,
+				StartStopCycles:        75,
+				FailedSelfTests:        1,
+				OverallHealth:          "FAILED",
+			},
+		},
+		"stats filled from ATA SMART are not overwritten": {
+			bd: func() *blockdevice.BlockDevice {
+				bd := &blockdevice.BlockDevice{}
+				bd.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid = true
+				bd.SMARTInfo.TemperatureInfo.CurrentTemperature = 40
+				bd.SMARTInfo.PercentEnduranceUsed = 5
+				bd.SMARTInfo.OverallHealth = "PASSED"
+				return bd
+			}(),
+			pages: pages,
+			want: blockdevice.SMARTStats{
+				TemperatureInfo: blockdevice.TemperatureInformation{
+					CurrentTemperatureDataValid: true,
+					CurrentTemperature:          40,
+				},
+				PercentEnduranceUsed:   5,
+				GrownDefects:           12,
+				UncorrectedReadErrors:  2,
+				UncorrectedWriteErrors: 1,
+				NonMediumErrors:        17,
+				StartStopCycles:        75,
+				FailedSelfTests:        1,
+				OverallHealth:          "PASSED",
+			},
+		},
+		"no log pages": {
+			bd:    &blockdevice.BlockDevice{},
+			pages: smart.SCSILogPages{},
+			want:  blockdevice.SMARTStats{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fillSCSILogPageDetails(test.bd, test.pages)
+			assert.Equal(t, test.want, test.bd.SMARTInfo)
+		})
+	}
+}
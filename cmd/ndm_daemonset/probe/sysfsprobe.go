@@ -24,6 +24,9 @@ https://www.kernel.org/doc/Documentation/block/queue-sysfs.txt
 package probe
 
 import (
+	"os"
+	"strings"
+
 	"github.com/openebs/node-disk-manager/blockdevice"
 	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
 	"github.com/openebs/node-disk-manager/pkg/sysfs"
@@ -68,7 +71,8 @@ var sysfsProbeRegister = func() {
 }
 
 // sysfsProbe fills the logical sector size,
-// physical sector size, drive type(ssd or hdd) of the disk
+// physical sector size, drive type(ssd or hdd), zoned model, IO capabilities
+// of the disk and the paths of multipath devices
 type sysfsProbe struct{}
 
 func newSysFSProbe() *sysfsProbe {
@@ -79,8 +83,9 @@ func newSysFSProbe() *sysfsProbe {
 func (cp *sysfsProbe) Start() {}
 
 // FillBlockDeviceDetails updates the logical sector size,
-// physical sector size, drive type(ssd or hdd) of the disk
-// if those are not populated.
+// physical sector size, drive type(ssd or hdd) and zoned model of the disk
+// if those are not populated. The multipath details and IO capabilities are
+// always updated, since the state of the paths and the settings can change.
 func (cp *sysfsProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevice) {
 
 	sysFsDevice, err := sysfs.NewSysFsDeviceFromDevPath(blockDevice.DevPath)
@@ -97,6 +102,8 @@ func (cp *sysfsProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevic
 	klog.V(4).Infof("blockdevice path: %s capacity :%d filled by sysfs probe.",
 		blockDevice.DevPath, blockDevice.Capacity.Storage)
 
+	fillMultipathDetails(blockDevice, sysFsDevice)
+
 	// If the blockdevice is a partition, we will use its parent disk to get block size, hw
 	// sector size and drive type.
 	// Get the parent disk sysfs device using the parent's dev path stored in the blokdevice
@@ -160,4 +167,184 @@ func (cp *sysfsProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevic
 		klog.V(4).Infof("blockdevice path: %s drive type :%s filled by sysfs probe.",
 			blockDevice.DevPath, blockDevice.DeviceAttributes.DriveType)
 	}
+
+	if blockDevice.DeviceAttributes.ZonedInfo.Model == "" {
+		fillZonedDetails(blockDevice, sysFsDevice)
+	}
+
+	fillQueueDetails(blockDevice, sysFsDevice)
+}
+
+// fillQueueDetails fills the IO capabilities and the queue settings of the device.
+// They are filled on every event for the device, since settings like the write
+// cache and the scheduler can be changed at runtime. No uevent is generated for
+// such a change, so it is picked up only on the next event for the device. Files
+// which are not reported by older kernels or by some devices are skipped.
+func fillQueueDetails(blockDevice *blockdevice.BlockDevice, sysFsDevice *sysfs.Device) {
+	queueInfo := blockdevice.QueueInformation{}
+	warn := func(attribute string, err error) {
+		if os.IsNotExist(err) {
+			klog.V(4).Infof("%s not reported for device: %s", attribute, blockDevice.DevPath)
+			return
+		}
+		klog.Warningf("unable to get %s for device: %s, err: %v", attribute, blockDevice.DevPath, err)
+	}
+
+	if discardGranularity, err := sysFsDevice.GetDiscardGranularity(); err != nil {
+		warn("discard granularity", err)
+	} else {
+		queueInfo.DiscardGranularity = uint64(discardGranularity)
+	}
+	if discardMaxBytes, err := sysFsDevice.GetDiscardMaxBytes(); err != nil {
+		warn("discard max bytes", err)
+	} else {
+		queueInfo.DiscardMaxBytes = uint64(discardMaxBytes)
+	}
+	if writeCache, err := sysFsDevice.GetWriteCache(); err != nil {
+		warn("write cache", err)
+	} else {
+		queueInfo.WriteCache = writeCache
+	}
+	if optimalIOSize, err := sysFsDevice.GetOptimalIOSize(); err != nil {
+		warn("optimal io size", err)
+	} else {
+		queueInfo.OptimalIOSize = uint32(optimalIOSize)
+	}
+	if minimumIOSize, err := sysFsDevice.GetMinimumIOSize(); err != nil {
+		warn("minimum io size", err)
+	} else {
+		queueInfo.MinimumIOSize = uint32(minimumIOSize)
+	}
+	if rotational, err := sysFsDevice.GetRotational(); err != nil {
+		warn("rotational", err)
+	} else {
+		queueInfo.Rotational = rotational
+	}
+	if scheduler, err := sysFsDevice.GetScheduler(); err != nil {
+		warn("scheduler", err)
+	} else {
+		queueInfo.Scheduler = scheduler
+	}
+	if nrRequests, err := sysFsDevice.GetNumberOfRequests(); err != nil {
+		warn("number of requests", err)
+	} else {
+		queueInfo.NumberOfRequests = uint32(nrRequests)
+	}
+	if dax, err := sysFsDevice.GetDAX(); err != nil {
+		warn("dax", err)
+	} else {
+		queueInfo.DAX = dax
+	}
+	if fua, err := sysFsDevice.GetFUA(); err != nil {
+		warn("fua", err)
+	} else {
+		queueInfo.FUA = fua
+	}
+	if writeZeroesMaxBytes, err := sysFsDevice.GetWriteZeroesMaxBytes(); err != nil {
+		warn("write zeroes max bytes", err)
+	} else {
+		queueInfo.WriteZeroesMaxBytes = uint64(writeZeroesMaxBytes)
+	}
+
+	blockDevice.DeviceAttributes.QueueInfo = queueInfo
+	klog.V(4).Infof("blockdevice path: %s queue info :%+v filled by sysfs probe.",
+		blockDevice.DevPath, blockDevice.DeviceAttributes.QueueInfo)
+}
+
+// fillZonedDetails fills the zoned model of the device, and the number of zones,
+// zone size and zone limits if the device is zoned. A device whose kernel does
+// not report the zoned model is considered to be a conventional device.
+func fillZonedDetails(blockDevice *blockdevice.BlockDevice, sysFsDevice *sysfs.Device) {
+	zonedModel, err := sysFsDevice.GetZonedModel()
+	if err != nil {
+		klog.Warningf("unable to get zoned model for device: %s, err: %v", blockDevice.DevPath, err)
+		zonedModel = blockdevice.ZonedModelNone
+	}
+	zonedInfo := blockdevice.ZonedInformation{Model: zonedModel}
+	if zonedModel != blockdevice.ZonedModelNone {
+		if nrZones, err := sysFsDevice.GetNumberOfZones(); err != nil {
+			klog.Warningf("unable to get number of zones for device: %s, err: %v", blockDevice.DevPath, err)
+		} else {
+			zonedInfo.NumberOfZones = uint32(nrZones)
+		}
+		if zoneSize, err := sysFsDevice.GetZoneSize(); err != nil {
+			klog.Warningf("unable to get zone size for device: %s, err: %v", blockDevice.DevPath, err)
+		} else {
+			zonedInfo.ZoneSize = uint64(zoneSize)
+		}
+		// the zone limits are not reported by older kernels
+		if maxOpenZones, err := sysFsDevice.GetMaxOpenZones(); err == nil {
+			zonedInfo.MaxOpenZones = uint32(maxOpenZones)
+		}
+		if maxActiveZones, err := sysFsDevice.GetMaxActiveZones(); err == nil {
+			zonedInfo.MaxActiveZones = uint32(maxActiveZones)
+		}
+	}
+	blockDevice.DeviceAttributes.ZonedInfo = zonedInfo
+	klog.V(4).Infof("blockdevice path: %s zoned info :%+v filled by sysfs probe.",
+		blockDevice.DevPath, blockDevice.DeviceAttributes.ZonedInfo)
+}
+
+// fillMultipathDetails fills the map name and the paths of a multipath device,
+// along with the state of each path. If the device is not a multipath device,
+// its holders are checked to find whether it is a path of a multipath device.
+func fillMultipathDetails(blockDevice *blockdevice.BlockDevice, sysFsDevice *sysfs.Device) {
+	blockDevice.MultipathInfo = blockdevice.MultipathInformation{}
+
+	if strings.HasPrefix(blockDevice.DMInfo.DMUUID, blockdevice.MultipathDMUUIDPrefix) {
+		name, err := sysFsDevice.GetDMName()
+		if err != nil {
+			klog.Warningf("unable to get multipath map name for device: %s, err: %v", blockDevice.DevPath, err)
+		}
+		blockDevice.MultipathInfo.Name = name
+		// the slaves are read again, since the dependents are not updated
+		// on change events, which are generated when a path is removed
+		dependents, err := sysFsDevice.GetDependents()
+		if err != nil {
+			klog.Warningf("unable to get paths of multipath device: %s, err: %v", blockDevice.DevPath, err)
+			dependents = blockDevice.DependentDevices
+		}
+		for _, slave := range dependents.Slaves {
+			blockDevice.MultipathInfo.Paths = append(blockDevice.MultipathInfo.Paths, blockdevice.MultipathPath{
+				DevPath: slave,
+				State:   getPathState(slave),
+			})
+		}
+		klog.V(4).Infof("blockdevice path: %s multipath paths :%+v filled by sysfs probe.",
+			blockDevice.DevPath, blockDevice.MultipathInfo.Paths)
+		return
+	}
+
+	for _, holder := range blockDevice.DependentDevices.Holders {
+		holderSysFsDevice, err := sysfs.NewSysFsDeviceFromDevPath(holder)
+		if err != nil {
+			klog.Warningf("unable to get sysfs device for holder: %s of device: %s, err: %v",
+				holder, blockDevice.DevPath, err)
+			continue
+		}
+		// holders which are not DM devices do not have a DM UUID
+		dmUUID, err := holderSysFsDevice.GetDMUUID()
+		if err != nil || !strings.HasPrefix(dmUUID, blockdevice.MultipathDMUUIDPrefix) {
+			continue
+		}
+		blockDevice.MultipathInfo.MultipathDevice = holder
+		klog.V(4).Infof("blockdevice path: %s multipath device :%s filled by sysfs probe.",
+			blockDevice.DevPath, holder)
+		return
+	}
+}
+
+// getPathState gets the state of the SCSI device of a path of a multipath device.
+// The state is unknown if the path is not a SCSI device.
+func getPathState(devPath string) string {
+	sysFsDevice, err := sysfs.NewSysFsDeviceFromDevPath(devPath)
+	if err != nil {
+		klog.Warningf("unable to get sysfs device for path: %s, err: %v", devPath, err)
+		return blockdevice.MultipathPathStateUnknown
+	}
+	state, err := sysFsDevice.GetSCSIDeviceState()
+	if err != nil || state == "" {
+		return blockdevice.MultipathPathStateUnknown
+	}
+	return state
 }
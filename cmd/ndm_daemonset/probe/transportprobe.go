@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/sysfs"
+	"github.com/openebs/node-disk-manager/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// transportProbe fills the bus or transport through which the device is
+// attached, and the details of the target for fabric devices, from sysfs
+type transportProbe struct{}
+
+const (
+	transportConfigKey = "transport-probe"
+	// transportProbePriority is set so that the probe runs after the sysfs
+	// probe, which fills the paths of multipath devices
+	transportProbePriority = 3
+)
+
+var (
+	transportProbeName  = "transport probe"
+	transportProbeState = defaultEnabled
+)
+
+var transportProbeRegister = func() {
+	// Get a controller object
+	ctrl := <-controller.ControllerBroadcastChannel
+	if ctrl == nil {
+		klog.Error("unable to configure", transportProbeName)
+		return
+	}
+	if ctrl.NDMConfig != nil {
+		for _, probeConfig := range ctrl.NDMConfig.ProbeConfigs {
+			if probeConfig.Key == transportConfigKey {
+				transportProbeName = probeConfig.Name
+				transportProbeState = util.CheckTruthy(probeConfig.State)
+				break
+			}
+		}
+	}
+	newRegisterProbe := &registerProbe{
+		priority:   transportProbePriority,
+		name:       transportProbeName,
+		state:      transportProbeState,
+		pi:         &transportProbe{},
+		controller: ctrl,
+	}
+	newRegisterProbe.register()
+}
+
+// Start is mainly used for one time activities such as monitoring.
+// It is a part of probe interface but here we does not require to perform
+// such activities, hence empty implementation
+func (tp *transportProbe) Start() {}
+
+// FillBlockDeviceDetails fills the transport of the device, and adds the
+// transport label to the device. A multipath device uses the transport and
+// the target of its paths, since the device itself is a virtual device.
+func (tp *transportProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevice) {
+	if blockDevice.DevPath == "" {
+		klog.Errorf("device identifier found empty, transport probe will not fetch information")
+		return
+	}
+
+	devPath := blockDevice.DevPath
+	isMultipath := blockDevice.DeviceAttributes.DeviceType == blockdevice.BlockDeviceTypeMultiPath
+	if isMultipath {
+		if len(blockDevice.MultipathInfo.Paths) == 0 {
+			klog.V(4).Infof("device: %s, no paths found for multipath device", blockDevice.DevPath)
+			return
+		}
+		devPath = blockDevice.MultipathInfo.Paths[0].DevPath
+	}
+
+	sysFsDevice, err := sysfs.NewSysFsDeviceFromDevPath(devPath)
+	if err != nil {
+		klog.Errorf("unable to get sysfs device for device: %s, err: %v", devPath, err)
+		return
+	}
+	transportInfo, err := sysFsDevice.GetTransport()
+	if err != nil {
+		klog.Warningf("unable to get transport details for device: %s, err: %v", devPath, err)
+	}
+	if transportInfo.Transport == "" {
+		return
+	}
+	if isMultipath {
+		// the portal, session and remote port are different for each path
+		transportInfo = blockdevice.TransportInformation{
+			Transport: transportInfo.Transport,
+			Target:    transportInfo.Target,
+		}
+	}
+
+	blockDevice.TransportInfo = transportInfo
+	blockDevice.Labels[controller.NDMTransportKey] = transportInfo.Transport
+	klog.V(4).Infof("blockdevice path: %s transport: %s target: %s filled by transport probe.",
+		blockDevice.DevPath, transportInfo.Transport, transportInfo.Target)
+}
@@ -25,8 +25,10 @@ import (
 
 	"github.com/openebs/node-disk-manager/blockdevice"
 	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/db/kubernetes"
 	"github.com/openebs/node-disk-manager/pkg/blkid"
 	"github.com/openebs/node-disk-manager/pkg/spdk"
+	"github.com/openebs/node-disk-manager/pkg/superblock"
 	libudevwrapper "github.com/openebs/node-disk-manager/pkg/udev"
 	"github.com/openebs/node-disk-manager/pkg/util"
 
@@ -40,16 +42,30 @@ type usedbyProbe struct {
 
 const (
 	usedbyProbeConfigKey = "used-by-probe"
-	usedbyProbePriority  = 5
+	usedbyProbePriority  = 6
 
 	k8sLocalVolumePath1 = "kubernetes.io/local-volume"
 	k8sLocalVolumePath2 = "kubernetes.io~local-volume"
 	zfsFileSystemLabel  = "zfs_member"
+
+	// cephOSDMountPath is the path at which ceph mounts the filestore OSDs
+	cephOSDMountPath = "/var/lib/ceph/osd/"
+	// cephVGPrefix is the prefix of the volume groups created by ceph-volume
+	cephVGPrefix = "ceph-"
+	// longhornDiskConfig is the file written by longhorn at the root of its disks
+	longhornDiskConfig = "longhorn-disk.cfg"
+	// jivaVolumeMeta is the file written by jiva in the replica directory
+	jivaVolumeMeta = "volume.meta"
 )
 
 var (
 	usedbyProbeName  = "used-by probe"
 	usedbyProbeState = defaultEnabled
+
+	// hostRootPath is the path at which the root filesystem of the host is
+	// accessible from the NDM container. The mountpoints of the device are
+	// looked up under this path.
+	hostRootPath = "/host/proc/1/root"
 )
 
 var usedbyProbeRegister = func() {
@@ -107,6 +123,14 @@ func (sp *usedbyProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevi
 		}
 	}
 
+	// checking for storage engines which keep their data on the mounted filesystem
+	if usedBy, ok := getStorageEngineFromMountPoints(blockDevice.FSInfo.MountPoint); ok {
+		blockDevice.DevUse.InUse = true
+		blockDevice.DevUse.UsedBy = usedBy
+		klog.V(4).Infof("device: %s Used by: %s filled by used-by probe", blockDevice.DevPath, blockDevice.DevUse.UsedBy)
+		return
+	}
+
 	// checking for cstor and zfs localPV
 	// we start with the assumption that device has a zfs file system
 	lookupZFS := true
@@ -166,7 +190,85 @@ func (sp *usedbyProbe) FillBlockDeviceDetails(blockDevice *blockdevice.BlockDevi
 		return
 	}
 
-	// TODO jiva disk detection
+	// checking for the superblocks written by other storage systems
+	sig, err := superblock.Probe(blockDevice.DevPath)
+	if err != nil {
+		klog.Errorf("error reading superblocks from device: %s, %v", blockDevice.DevPath, err)
+		return
+	}
+	if sig != nil {
+		blockDevice.DevUse.InUse = true
+		blockDevice.DevUse.UsedBy, blockDevice.DevUse.Owner = getStorageEngineFromSignature(sig)
+		klog.V(4).Infof("device: %s Used by: %s (%s) filled by used-by probe",
+			blockDevice.DevPath, blockDevice.DevUse.UsedBy, blockDevice.DevUse.Owner)
+		// LVM physical volumes are used by LocalPV-LVM, the blockdevice is tagged so
+		// that it can be claimed only by the claims which select the lvm tag
+		if blockDevice.DevUse.UsedBy == blockdevice.LVM {
+			blockDevice.Labels[kubernetes.BlockDeviceTagLabel] = string(blockdevice.LVM)
+		}
+	}
+}
+
+// getStorageEngineFromSignature returns the storage engine which owns the device
+// with the given signature, and the entity of the storage engine to which the
+// device belongs
+func getStorageEngineFromSignature(sig *superblock.Signature) (blockdevice.StorageEngine, string) {
+	switch sig.Type {
+	case superblock.CephBlueStore:
+		return blockdevice.Ceph, sig.UUID
+	case superblock.LVM2:
+		// ceph-volume creates the OSDs on logical volumes
+		if strings.HasPrefix(sig.Label, cephVGPrefix) {
+			return blockdevice.Ceph, sig.Label
+		}
+		return blockdevice.LVM, sig.Label
+	case superblock.MDRaid:
+		if sig.Label != "" {
+			return blockdevice.MDRaid, sig.Label
+		}
+		return blockdevice.MDRaid, sig.UUID
+	case superblock.LUKS:
+		return blockdevice.LUKS, sig.UUID
+	case superblock.BCache:
+		return blockdevice.BCache, sig.UUID
+	case superblock.VDO:
+		return blockdevice.VDO, sig.UUID
+	}
+	return blockdevice.StorageEngine(sig.Type), sig.UUID
+}
+
+// getStorageEngineFromMountPoints checks the mounted filesystems of the device
+// for the files and paths used by ceph filestore, longhorn and jiva
+func getStorageEngineFromMountPoints(mountPoints []string) (blockdevice.StorageEngine, bool) {
+	for _, mountPoint := range mountPoints {
+		if strings.HasPrefix(mountPoint, cephOSDMountPath) {
+			return blockdevice.Ceph, true
+		}
+		hostPath := filepath.Join(hostRootPath, mountPoint)
+		if fileExists(filepath.Join(hostPath, longhornDiskConfig)) {
+			return blockdevice.Longhorn, true
+		}
+		// jiva replicas are created either at the mountpoint or in a
+		// directory for each volume under it
+		if fileExists(filepath.Join(hostPath, jivaVolumeMeta)) {
+			return blockdevice.Jiva, true
+		}
+		matches, err := filepath.Glob(filepath.Join(hostPath, "*", jivaVolumeMeta))
+		if err != nil {
+			klog.Errorf("error looking up jiva replicas in %s: %v", mountPoint, err)
+			continue
+		}
+		if len(matches) != 0 {
+			return blockdevice.Jiva, true
+		}
+	}
+	return "", false
+}
+
+// fileExists checks whether a regular file exists at the given path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
 }
 
 // getBlockDeviceZFSPartition is used to get the zfs partition if it exist in a
@@ -17,9 +17,15 @@ limitations under the License.
 package probe
 
 import (
+	"os"
+	"path/filepath"
+	"testing"
+
 	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/db/kubernetes"
 	"github.com/stretchr/testify/assert"
-	"testing"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetBlockDeviceZFSPartition(t *testing.T) {
@@ -109,3 +115,133 @@ func TestGetBlockDeviceZFSPartition(t *testing.T) {
 		})
 	}
 }
+
+// superblockFixtures is the directory with the images containing the superblocks of
+// the storage systems. The path is resolved when the package is initialized, since
+// some of the tests change the working directory.
+var superblockFixtures, _ = filepath.Abs(filepath.Join("..", "..", "..", "pkg", "superblock", "testdata"))
+
+func TestUsedByProbeSignatures(t *testing.T) {
+	tests := map[string]struct {
+		image   string
+		want    blockdevice.DeviceUsage
+		wantTag string
+	}{
+		"lvm2 physical volume": {
+			image:   "lvm2-pv.img",
+			want:    blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.LVM, Owner: "vg_data"},
+			wantTag: string(blockdevice.LVM),
+		},
+		"ceph osd on lvm2": {
+			image: "lvm2-ceph-pv.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.Ceph,
+				Owner: "ceph-0e7b6c4a-3f2d-4b1e-8a9c-5d6f7e8a9b0c"},
+		},
+		"ceph bluestore osd": {
+			image: "bluestore.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.Ceph,
+				Owner: "6b1b4a87-0d6c-4f5e-9c1b-3e6f2a7d9c41"},
+		},
+		"named md raid member": {
+			image: "md-v1.2.img",
+			want:  blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.MDRaid, Owner: "node1:data"},
+		},
+		"md raid member without name": {
+			image: "md-v0.90.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.MDRaid,
+				Owner: "5a9c2b1e:7f3d40a8:b6c1e2d3:f4a5b6c7"},
+		},
+		"luks": {
+			image: "luks2.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.LUKS,
+				Owner: "d3c7e1a2-9b4f-4c6e-8a2d-1f0e3b5c7a9d"},
+		},
+		"bcache": {
+			image: "bcache.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.BCache,
+				Owner: "8f2e4c1a-6b3d-4e7f-9a0c-2d5b8e1f4a7c"},
+		},
+		"vdo": {
+			image: "vdo.img",
+			want: blockdevice.DeviceUsage{InUse: true, UsedBy: blockdevice.VDO,
+				Owner: "a4b6c8d0-e2f4-4a6b-8c0d-2e4f6a8b0c1d"},
+		},
+		"unused device": {
+			image: "empty.img",
+			want:  blockdevice.DeviceUsage{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &blockdevice.BlockDevice{
+				Identifier: blockdevice.Identifier{
+					DevPath: filepath.Join(superblockFixtures, tt.image),
+				},
+				DeviceAttributes: blockdevice.DeviceAttribute{
+					DeviceType: blockdevice.BlockDeviceTypeDisk,
+				},
+				Labels: make(map[string]string),
+			}
+			up := &usedbyProbe{Controller: &controller.Controller{}}
+			up.FillBlockDeviceDetails(bd)
+			assert.Equal(t, tt.want, bd.DevUse)
+			assert.Equal(t, tt.wantTag, bd.Labels[kubernetes.BlockDeviceTagLabel])
+		})
+	}
+}
+
+func TestGetStorageEngineFromMountPoints(t *testing.T) {
+	root := t.TempDir()
+	oldHostRootPath := hostRootPath
+	hostRootPath = root
+	defer func() { hostRootPath = oldHostRootPath }()
+
+	writeFile := func(path string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte("{}"), 0644))
+	}
+	writeFile("/mnt/longhorn/" + longhornDiskConfig)
+	writeFile("/mnt/jiva/" + jivaVolumeMeta)
+	writeFile("/var/openebs/pvc-3f2e/" + jivaVolumeMeta)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "/mnt/data/lost+found"), 0755))
+
+	tests := map[string]struct {
+		mountPoints []string
+		want        blockdevice.StorageEngine
+		wantOk      bool
+	}{
+		"not mounted": {
+			mountPoints: nil,
+		},
+		"mounted, not used by any storage engine": {
+			mountPoints: []string{"/mnt/data"},
+		},
+		"ceph filestore osd": {
+			mountPoints: []string{"/var/lib/ceph/osd/ceph-2"},
+			want:        blockdevice.Ceph,
+			wantOk:      true,
+		},
+		"longhorn disk": {
+			mountPoints: []string{"/mnt/data", "/mnt/longhorn"},
+			want:        blockdevice.Longhorn,
+			wantOk:      true,
+		},
+		"jiva replica at the mountpoint": {
+			mountPoints: []string{"/mnt/jiva"},
+			want:        blockdevice.Jiva,
+			wantOk:      true,
+		},
+		"jiva replicas in the storage pool": {
+			mountPoints: []string{"/var/openebs"},
+			want:        blockdevice.Jiva,
+			wantOk:      true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, gotOk := getStorageEngineFromMountPoints(tt.mountPoints)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, gotOk)
+		})
+	}
+}
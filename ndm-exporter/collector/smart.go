@@ -0,0 +1,275 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/db/kubernetes"
+	smartmetrics "github.com/openebs/node-disk-manager/pkg/metrics/smart"
+	"github.com/openebs/node-disk-manager/pkg/smart"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SmartCollectorNamespace is the namespace field in the prometheus metrics when
+	// the SCSI log pages are used to collect the metrics.
+	SmartCollectorNamespace = "smart"
+)
+
+// SmartCollector contains the metrics, concurrency handler and client to get the
+// health metrics from the SCSI log pages of the blockdevices
+type SmartCollector struct {
+	// Client is the k8s client which will be used to interface with etcd
+	Client kubernetes.Client
+
+	// concurrency handling
+	sync.Mutex
+	requestInProgress bool
+
+	// all metrics collected via the SCSI log pages
+	metrics *smartmetrics.Metrics
+}
+
+// SmartMetricData is the struct which holds the health data read from the SCSI
+// log pages corresponding to each blockdevice
+type SmartMetricData struct {
+	SmartIdentifier *smart.Identifier
+	LogPages        smart.SCSILogPages
+}
+
+// NewSmartMetricCollector creates a new instance of SmartCollector which
+// implements Collector interface
+func NewSmartMetricCollector(c kubernetes.Client) prometheus.Collector {
+	klog.V(2).Infof("Smart Metric Collector initialized")
+	sc := &SmartCollector{
+		Client:  c,
+		metrics: smartmetrics.NewMetrics(SmartCollectorNamespace),
+	}
+	sc.metrics.WithBlockDeviceCurrentTemperature().
+		WithBlockDeviceCurrentTemperatureValid().
+		WithBlockDevicePercentEnduranceUsed().
+		WithBlockDeviceGrownDefects().
+		WithBlockDeviceUncorrectedReadErrors().
+		WithBlockDeviceUncorrectedWriteErrors().
+		WithBlockDeviceUncorrectedVerifyErrors().
+		WithBlockDeviceNonMediumErrors().
+		WithBlockDeviceStartStopCycles().
+		WithBlockDeviceFailedSelfTests().
+		WithBlockDeviceHealthFailing().
+		WithRejectRequest().
+		WithErrorRequest()
+	return sc
+}
+
+// Describe is the implementation of Describe in prometheus.Collector
+func (sc *SmartCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, col := range sc.collectors() {
+		col.Describe(ch)
+	}
+}
+
+// Collect is the implementation of Collect in prometheus.Collector
+func (sc *SmartCollector) Collect(ch chan<- prometheus.Metric) {
+	klog.V(4).Info("Starting to collect smart metrics for a request")
+
+	sc.Lock()
+	if sc.requestInProgress {
+		klog.V(4).Info("Another request already in progress.")
+		sc.metrics.IncRejectRequestCounter()
+		sc.Unlock()
+		return
+	}
+
+	sc.requestInProgress = true
+	sc.Unlock()
+
+	// once a request is processed, set the progress flag to false
+	defer sc.setRequestProgressToFalse()
+
+	// set the client each time
+	if err := sc.Client.InitClient(); err != nil {
+		klog.Errorf("error setting client. %v", err)
+		sc.metrics.IncErrorRequestCounter()
+		sc.collectErrors(ch)
+		return
+	}
+
+	// get list of blockdevices from etcd
+	blockDevices, err := sc.Client.ListBlockDevice()
+	if err != nil {
+		klog.Errorf("Listing block devices failed %v", err)
+		sc.metrics.IncErrorRequestCounter()
+		sc.collectErrors(ch)
+		return
+	}
+
+	failing := getLogPageMetricData(blockDevices)
+
+	klog.V(4).Infof("metrics data obtained from the SCSI log pages")
+
+	sc.setMetricData(blockDevices, failing)
+
+	// collect each metric
+	for _, col := range sc.collectors() {
+		col.Collect(ch)
+	}
+}
+
+// collectors lists all the metrics exposed by the smart collector
+func (sc *SmartCollector) collectors() []prometheus.Collector {
+	return append(sc.metrics.Collectors(), sc.metrics.HealthCollectors()...)
+}
+
+// setRequestProgressToFalse is used to set the progress flag, when a request is
+// processed or errored
+func (sc *SmartCollector) setRequestProgressToFalse() {
+	sc.Lock()
+	sc.requestInProgress = false
+	sc.Unlock()
+}
+
+// collectErrors collects only the error metrics and set it on the channel
+func (sc *SmartCollector) collectErrors(ch chan<- prometheus.Metric) {
+	for _, col := range sc.metrics.ErrorCollectors() {
+		col.Collect(ch)
+	}
+}
+
+// getLogPageMetricData reads the SCSI log pages of each blockdevice and fills the
+// health details in the blockdevice struct. The returned map has an entry for
+// every blockdevice for which the health details were filled, with the value
+// telling whether the device reports that it is failing. Devices that do not
+// support log pages, like SATA or NVMe disks, are skipped, so the map may be
+// empty.
+func getLogPageMetricData(bds []blockdevice.BlockDevice) map[string]bool {
+	failing := make(map[string]bool)
+	for i, bd := range bds {
+		// do not report metrics for sparse devices
+		if bd.DeviceAttributes.DeviceType == blockdevice.SparseBlockDeviceType {
+			continue
+		}
+		sd := SmartMetricData{
+			SmartIdentifier: &smart.Identifier{
+				DevPath: bd.DevPath,
+			},
+		}
+		if err := sd.getLogPageData(); err != nil {
+			klog.V(4).Infof("skipping %s, SCSI log pages not available. %v", bd.DevPath, err)
+			continue
+		}
+		failing[bd.UUID] = sd.fillSMARTInfo(&bds[i].SMARTInfo)
+	}
+	return failing
+}
+
+// getLogPageData fetches the SCSI log pages of a blockdevice. It fails only if
+// none of the pages could be read.
+func (sd *SmartMetricData) getLogPageData() error {
+	logPages, errs := sd.SmartIdentifier.SCSILogPages()
+	if len(logPages.SupportedPages) == 0 {
+		return fmt.Errorf("error getting SCSI log pages for metrics. %v", errs)
+	}
+	if len(errs) != 0 {
+		klog.V(4).Infof("unable to get some of the SCSI log pages of %s. %v", sd.SmartIdentifier.DevPath, errs)
+	}
+	sd.LogPages = logPages
+	return nil
+}
+
+// fillSMARTInfo fills the SMART stats from the log pages and returns whether the
+// device reports that it is failing
+func (sd *SmartMetricData) fillSMARTInfo(info *blockdevice.SMARTStats) bool {
+	pages := sd.LogPages
+	if pages.Temperature != nil {
+		info.TemperatureInfo.CurrentTemperatureDataValid = pages.Temperature.CurrentValid
+		info.TemperatureInfo.CurrentTemperature = pages.Temperature.Current
+	}
+	if pages.SolidStateMedia != nil {
+		info.PercentEnduranceUsed = float64(pages.SolidStateMedia.PercentageUsedEnduranceIndicator)
+	}
+	if pages.GrownDefects != nil {
+		info.GrownDefects = *pages.GrownDefects
+	}
+	if pages.ReadErrors != nil {
+		info.UncorrectedReadErrors = pages.ReadErrors.TotalUncorrected
+	}
+	if pages.WriteErrors != nil {
+		info.UncorrectedWriteErrors = pages.WriteErrors.TotalUncorrected
+	}
+	if pages.VerifyErrors != nil {
+		info.UncorrectedVerifyErrors = pages.VerifyErrors.TotalUncorrected
+	}
+	if pages.NonMediumErrors != nil {
+		info.NonMediumErrors = pages.NonMediumErrors.Count
+	}
+	if pages.StartStopCycles != nil {
+		info.StartStopCycles = uint64(pages.StartStopCycles.AccumulatedCycles)
+	}
+	if pages.SelfTest != nil {
+		info.FailedSelfTests = 0
+		for _, result := range pages.SelfTest.Results {
+			if result.Failed() {
+				info.FailedSelfTests++
+			}
+		}
+	}
+
+	klog.V(4).Infof("Device is : %v", sd.SmartIdentifier.DevPath)
+	klog.V(4).Infof("Current temperature is %v", info.TemperatureInfo.CurrentTemperature)
+	klog.V(4).Infof("Grown defects are %v", info.GrownDefects)
+	klog.V(4).Infof("Uncorrected read/write/verify errors are %v/%v/%v", info.UncorrectedReadErrors,
+		info.UncorrectedWriteErrors, info.UncorrectedVerifyErrors)
+	klog.V(4).Infof("Failed self-tests are %v", info.FailedSelfTests)
+
+	return pages.InformationalExceptions != nil && pages.InformationalExceptions.Failing()
+}
+
+// setMetricData sets the SMART metric data collected from the log pages onto
+// the prometheus metrics
+func (sc *SmartCollector) setMetricData(blockdevices []blockdevice.BlockDevice, failing map[string]bool) {
+	// blockdevices which were removed, or whose log pages could not be read
+	// in this scrape, should not be reported with the values of an earlier scrape
+	sc.metrics.Reset()
+	for _, bd := range blockdevices {
+		isFailing, ok := failing[bd.UUID]
+		if !ok {
+			continue
+		}
+		// sets the label values
+		sc.metrics.WithBlockDeviceUUID(bd.UUID).
+			WithBlockDevicePath(bd.DevPath).
+			WithBlockDeviceHostName(bd.NodeAttributes[blockdevice.HostName]).
+			WithBlockDeviceNodeName(bd.NodeAttributes[blockdevice.NodeName])
+		// sets the metrics
+		sc.metrics.SetBlockDeviceCurrentTemperature(bd.SMARTInfo.TemperatureInfo.CurrentTemperature).
+			SetBlockDeviceCurrentTemperatureValid(bd.SMARTInfo.TemperatureInfo.CurrentTemperatureDataValid).
+			SetBlockDevicePercentEnduranceUsed(bd.SMARTInfo.PercentEnduranceUsed).
+			SetBlockDeviceGrownDefects(bd.SMARTInfo.GrownDefects).
+			SetBlockDeviceUncorrectedReadErrors(bd.SMARTInfo.UncorrectedReadErrors).
+			SetBlockDeviceUncorrectedWriteErrors(bd.SMARTInfo.UncorrectedWriteErrors).
+			SetBlockDeviceUncorrectedVerifyErrors(bd.SMARTInfo.UncorrectedVerifyErrors).
+			SetBlockDeviceNonMediumErrors(bd.SMARTInfo.NonMediumErrors).
+			SetBlockDeviceStartStopCycles(bd.SMARTInfo.StartStopCycles).
+			SetBlockDeviceFailedSelfTests(bd.SMARTInfo.FailedSelfTests).
+			SetBlockDeviceHealthFailing(isFailing)
+	}
+}
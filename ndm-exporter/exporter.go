@@ -122,5 +122,8 @@ func (e *Exporter) runNodeExporter() error {
 	seachestCollector := collector.NewSeachestMetricCollector(e.Client)
 	prometheus.MustRegister(seachestCollector)
 
+	smartCollector := collector.NewSmartMetricCollector(e.Client)
+	prometheus.MustRegister(smartCollector)
+
 	return nil
 }
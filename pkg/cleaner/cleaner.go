@@ -22,10 +22,16 @@ package cleaner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
 	"github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/pkg/controllers/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
 )
 
 // CleanupState represents the current state of the cleanup job
@@ -40,8 +46,19 @@ const (
 	CleanupStateRunning
 	// CleanupStateSucceeded represents that the cleanup job has been completed successfully
 	CleanupStateSucceeded
+	// CleanupStateFailed represents that the cleanup job has failed after all the retries
+	CleanupStateFailed
 )
 
+// ErrCleanupJobFailed is returned when the cleanup job of a BD has failed
+// after all the retries
+var ErrCleanupJobFailed = errors.New("cleanup job failed")
+
+// IsCleanupJobFailed checks whether the error is due to the failure of the cleanup job
+func IsCleanupJobFailed(err error) bool {
+	return errors.Is(err, ErrCleanupJobFailed)
+}
+
 // VolumeMode defines the volume mode of the BlockDevice. It can be either block mode or
 // filesystem mode
 type VolumeMode string
@@ -57,7 +74,8 @@ const (
 
 // Cleaner handles BD cleanup
 // For filesystem/mount based block devices, it deletes the contents of the directory
-// For raw block devices, a `wipefs` command will be issued.
+// For raw block devices, the data is removed using the cleanup method of the BD,
+// by default a `wipefs` command will be issued.
 type Cleaner struct {
 	Client        client.Client
 	Namespace     string
@@ -81,7 +99,10 @@ func NewCleaner(client client.Client, namespace string, cleanupTracker *CleanupS
 
 // Clean will launch a job to delete data on the BD depending on the
 // volume mode. Job will be launched only if another job is not running or a
-// job is in unknown state
+// job is in unknown state. The cleanup conditions on the BD are updated to
+// reflect the state of the cleanup, the caller is responsible for persisting them.
+// If the job has failed, it is removed and an ErrCleanupJobFailed error along with the
+// logs of the failed job is returned.
 func (c *Cleaner) Clean(blockDevice *v1alpha1.BlockDevice) (bool, error) {
 	bdName := blockDevice.Name
 	// check if a cleanup job for the bd already exists and return
@@ -93,7 +114,12 @@ func (c *Cleaner) Clean(blockDevice *v1alpha1.BlockDevice) (bool, error) {
 			if err := c.CleanupStatus.CancelJob(bdName); err != nil {
 				return false, err
 			}
+			setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupInProgress, metav1.ConditionFalse,
+				v1alpha1.BlockDeviceReasonCleanupCancelled, "Cleanup job cancelled since the blockdevice is not active")
+			return false, nil
 		}
+		setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupInProgress, metav1.ConditionTrue,
+			v1alpha1.BlockDeviceReasonCleanupJobRunning, "Cleanup job is running")
 		return false, nil
 	}
 	// Check if cleaning was just completed. if job was completed, it will be removed,
@@ -105,7 +131,13 @@ func (c *Cleaner) Clean(blockDevice *v1alpha1.BlockDevice) (bool, error) {
 
 	switch state {
 	case CleanupStateSucceeded:
+		setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupInProgress, metav1.ConditionFalse,
+			v1alpha1.BlockDeviceReasonCleanupCompleted, "Cleanup job completed")
+		setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupFailed, metav1.ConditionFalse,
+			v1alpha1.BlockDeviceReasonCleanupCompleted, "Cleanup job completed")
 		return true, nil
+	case CleanupStateFailed:
+		return false, c.handleFailedJob(blockDevice)
 	case CleanupStateNotFound:
 		// if the BD is not active, do not start the job
 		if blockDevice.Status.State != v1alpha1.BlockDeviceActive {
@@ -116,11 +148,54 @@ func (c *Cleaner) Clean(blockDevice *v1alpha1.BlockDevice) (bool, error) {
 	}
 
 	volMode := getVolumeMode(blockDevice.Spec)
+	method := GetCleanupMethod(blockDevice)
+	if err := validateCleanupMethod(blockDevice, volMode, method); err != nil {
+		setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupFailed, metav1.ConditionTrue,
+			v1alpha1.BlockDeviceReasonUnsupportedCleanupMethod, err.Error())
+		return false, err
+	}
 
 	// create a new job for the blockdevice
-	err = c.runJob(blockDevice, volMode)
+	err = c.runJob(blockDevice, volMode, method)
+	if err != nil {
+		setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupFailed, metav1.ConditionTrue,
+			v1alpha1.BlockDeviceReasonCleanupJobCreationFailed, fmt.Sprintf("Unable to create cleanup job: %v", err))
+		return false, err
+	}
+	message := fmt.Sprintf("Cleanup job is running using %s method", method)
+	setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupInProgress, metav1.ConditionTrue,
+		v1alpha1.BlockDeviceReasonCleanupJobRunning, message)
+	setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupFailed, metav1.ConditionFalse,
+		v1alpha1.BlockDeviceReasonCleanupJobRunning, message)
+
+	return false, nil
+}
 
-	return false, err
+// handleFailedJob records the logs of the failed cleanup job on the BD and removes
+// the job, so that a new job can be started if the cleanup is retried
+func (c *Cleaner) handleFailedJob(blockDevice *v1alpha1.BlockDevice) error {
+	bdName := blockDevice.Name
+	logs, err := c.CleanupStatus.GetLogs(bdName)
+	if err != nil {
+		klog.Errorf("unable to fetch logs of cleanup job for %s: %v", bdName, err)
+		logs = fmt.Sprintf("logs not available: %v", err)
+	}
+	if err := c.CleanupStatus.CancelJob(bdName); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Cleanup job failed, last %d lines of the log:\n%s", JobLogTailLines, logs)
+	setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupInProgress, metav1.ConditionFalse,
+		v1alpha1.BlockDeviceReasonCleanupJobFailed, "Cleanup job failed")
+	setCleanupCondition(blockDevice, v1alpha1.BlockDeviceConditionCleanupFailed, metav1.ConditionTrue,
+		v1alpha1.BlockDeviceReasonCleanupJobFailed, message)
+	return fmt.Errorf("%w for %s: %s", ErrCleanupJobFailed, bdName, message)
+}
+
+// setCleanupCondition sets the given cleanup condition on the BD
+func setCleanupCondition(bd *v1alpha1.BlockDevice, conditionType string,
+	status metav1.ConditionStatus, reason, message string) {
+	util.SetCondition(&bd.Status.Conditions, bd.Generation, conditionType, status, reason, message)
 }
 
 // InProgress returns whether a cleanup job is currently being done
@@ -140,8 +215,13 @@ func (c *CleanupStatusTracker) CancelJob(bdName string) error {
 	return c.JobController.CancelJob(bdName)
 }
 
+// GetLogs returns the logs of the failed cleanup job for the given BD
+func (c *CleanupStatusTracker) GetLogs(bdName string) (string, error) {
+	return c.JobController.GetJobLogs(bdName)
+}
+
 // runJob creates a new cleanup job in the namespace
-func (c *Cleaner) runJob(bd *v1alpha1.BlockDevice, volumeMode VolumeMode) error {
+func (c *Cleaner) runJob(bd *v1alpha1.BlockDevice, volumeMode VolumeMode, method v1alpha1.CleanupMethod) error {
 
 	// retrieve node Object to pass tolerations to the Job
 	nodeName := GetNodeName(bd)
@@ -151,7 +231,7 @@ func (c *Cleaner) runJob(bd *v1alpha1.BlockDevice, volumeMode VolumeMode) error
 	}
 	tolerations := getTolerationsForTaints(selectedNode.Spec.Taints...)
 
-	job, err := NewCleanupJob(bd, volumeMode, tolerations, c.Namespace)
+	job, err := NewCleanupJob(bd, volumeMode, method, tolerations, c.Namespace)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+)
+
+// fakeJobController is a JobController which reports a fixed state for the job
+type fakeJobController struct {
+	state     CleanupState
+	logs      string
+	logsErr   error
+	cancelled bool
+}
+
+func (f *fakeJobController) IsCleaningJobRunning(bdName string) bool {
+	return f.state == CleanupStateRunning
+}
+
+func (f *fakeJobController) CancelJob(bdName string) error {
+	f.cancelled = true
+	return nil
+}
+
+func (f *fakeJobController) RemoveJob(bdName string) (CleanupState, error) {
+	return f.state, nil
+}
+
+func (f *fakeJobController) GetJobLogs(bdName string) (string, error) {
+	return f.logs, f.logsErr
+}
+
+func TestCleanFailedJob(t *testing.T) {
+	tests := map[string]struct {
+		logs        string
+		logsErr     error
+		wantMessage string
+	}{
+		"logs are copied to the condition": {
+			logs:        "wipefs: error: /dev/sdb: probing initialization failed: Device or resource busy",
+			wantMessage: "Device or resource busy",
+		},
+		"logs not available": {
+			logsErr:     fmt.Errorf("pod not found"),
+			wantMessage: "logs not available: pod not found",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			jc := &fakeJobController{state: CleanupStateFailed, logs: test.logs, logsErr: test.logsErr}
+			c := NewCleaner(fakeclient.NewClientBuilder().Build(), fakeNamespace, &CleanupStatusTracker{JobController: jc})
+
+			bd := newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk)
+			bd.Status.State = v1alpha1.BlockDeviceActive
+			bd.Status.ClaimState = v1alpha1.BlockDeviceReleased
+
+			ok, err := c.Clean(bd)
+			assert.False(t, ok)
+			assert.True(t, IsCleanupJobFailed(err))
+			assert.Contains(t, err.Error(), test.wantMessage)
+			assert.True(t, jc.cancelled, "failed job should be removed")
+
+			condition := meta.FindStatusCondition(bd.Status.Conditions, v1alpha1.BlockDeviceConditionCleanupFailed)
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Equal(t, v1alpha1.BlockDeviceReasonCleanupJobFailed, condition.Reason)
+				assert.Contains(t, condition.Message, test.wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsCleanupJobFailed(t *testing.T) {
+	assert.False(t, IsCleanupJobFailed(nil))
+	assert.False(t, IsCleanupJobFailed(fmt.Errorf("node not found")))
+	assert.True(t, IsCleanupJobFailed(fmt.Errorf("%w for bd-1", ErrCleanupJobFailed)))
+}
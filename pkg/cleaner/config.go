@@ -18,24 +18,46 @@ package cleaner
 
 import (
 	"os"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/node-disk-manager/api/v1alpha1"
 )
 
 const (
 	// EnvCleanUpJobImage is the environment variable for getting the
 	// job container image
 	EnvCleanUpJobImage = "CLEANUP_JOB_IMAGE"
+	// EnvEraseJobImage is the environment variable for getting the job
+	// container image used by the ata-secure-erase and nvme-format cleanup
+	// methods, which need hdparm and nvme-cli in the image
+	EnvEraseJobImage = "CLEANUP_JOB_ERASE_IMAGE"
 	// ServiceAccountName is the service account in which the operator pod
 	// is running. The cleanup job, pod will be started with this service account
 	ServiceAccountName = "SERVICE_ACCOUNT"
+	// EnvCleanUpJobBackoffLimit is the environment variable for getting the
+	// number of times a failed cleanup job is retried
+	EnvCleanUpJobBackoffLimit = "CLEANUP_JOB_BACKOFF_LIMIT"
 )
 
 var (
 	// defaultCleanUpJobImage is the default job container image
-	defaultCleanUpJobImage = "quay.io/openebs/linux-utils:latest"
+	defaultCleanUpJobImage = "openebs/linux-utils:3.4.0"
+	// defaultCleanUpJobBackoffLimit is the default number of retries for a failed
+	// cleanup job
+	defaultCleanUpJobBackoffLimit int32 = 3
 )
 
-// getCleanUpImage gets the image to be used for the cleanup job
-func getCleanUpImage() string {
+// getCleanUpImage gets the image to be used for a cleanup job using the given
+// cleanup method. The erase image is used for the methods that erase the whole
+// drive, if it is set.
+func getCleanUpImage(method v1alpha1.CleanupMethod) string {
+	if method == v1alpha1.CleanupMethodATASecureErase || method == v1alpha1.CleanupMethodNVMeFormat {
+		if image := os.Getenv(EnvEraseJobImage); image != "" {
+			return image
+		}
+	}
 	image, ok := os.LookupEnv(EnvCleanUpJobImage)
 	if !ok {
 		return defaultCleanUpJobImage
@@ -43,6 +65,22 @@ func getCleanUpImage() string {
 	return image
 }
 
+// getCleanUpJobBackoffLimit gets the number of times the cleanup job is retried
+// before it is marked as failed
+func getCleanUpJobBackoffLimit() int32 {
+	limit, ok := os.LookupEnv(EnvCleanUpJobBackoffLimit)
+	if !ok {
+		return defaultCleanUpJobBackoffLimit
+	}
+	backoffLimit, err := strconv.ParseInt(limit, 10, 32)
+	if err != nil || backoffLimit < 0 {
+		klog.Warningf("invalid value %q for %s, using default %d", limit,
+			EnvCleanUpJobBackoffLimit, defaultCleanUpJobBackoffLimit)
+		return defaultCleanUpJobBackoffLimit
+	}
+	return int32(backoffLimit)
+}
+
 // getServiceAccount gets the service account in which the pod is running
 // TODO move env variable operations to a separate pkg
 func getServiceAccount() string {
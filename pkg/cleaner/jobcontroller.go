@@ -23,6 +23,7 @@ package cleaner
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/openebs/node-disk-manager/api/v1alpha1"
 	"github.com/openebs/node-disk-manager/blockdevice"
@@ -32,6 +33,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -42,6 +44,11 @@ const (
 	JobNamePrefix = "cleanup-"
 	// BDLabel is the label set on the job for identification of the BD
 	BDLabel = "blockdevice"
+	// JobLogTailLines is the number of lines from the end of the log of a
+	// failed cleanup pod, that are copied to the BD
+	JobLogTailLines int64 = 20
+	// jobNameLabel is the label set by kubernetes on the pods of a job
+	jobNameLabel = "job-name"
 )
 
 // JobController defines the interface for the job controller.
@@ -49,24 +56,31 @@ type JobController interface {
 	IsCleaningJobRunning(bdName string) bool
 	CancelJob(bdName string) error
 	RemoveJob(bdName string) (CleanupState, error)
+	GetJobLogs(bdName string) (string, error)
 }
 
 var _ JobController = &jobController{}
 
 type jobController struct {
 	client    client.Client
+	clientset kubernetes.Interface
 	namespace string
 }
 
 // NewCleanupJob creates a new cleanup job in the  namespace. It returns a Job object which can be used to
-// start the job
-func NewCleanupJob(bd *v1alpha1.BlockDevice, volMode VolumeMode, tolerations []v1.Toleration, namespace string) (*batchv1.Job, error) {
+// start the job. The data on the BD is removed using the given cleanup method.
+func NewCleanupJob(bd *v1alpha1.BlockDevice, volMode VolumeMode, method v1alpha1.CleanupMethod,
+	tolerations []v1.Toleration, namespace string) (*batchv1.Job, error) {
+	if err := validateCleanupMethod(bd, volMode, method); err != nil {
+		return nil, err
+	}
+
 	nodeName := bd.Labels[controller.KubernetesHostNameLabel]
 
 	priv := true
 	jobContainer := v1.Container{
 		Name:  JobContainerName,
-		Image: getCleanUpImage(),
+		Image: getCleanUpImage(method),
 		SecurityContext: &v1.SecurityContext{
 			Privileged: &priv,
 		},
@@ -77,50 +91,7 @@ func NewCleanupJob(bd *v1alpha1.BlockDevice, volMode VolumeMode, tolerations []v
 
 	if volMode == VolumeModeBlock {
 		jobContainer.Command = []string{"/bin/sh", "-c"}
-
-		// fdisk is used to get all the partitions of the device.
-		// Example
-		// $ fdisk -o Device -l /dev/sda
-		// 	Disk /dev/sda: 465.8 GiB, 500107862016 bytes, 976773168 sectors
-		// 	Units: sectors of 1 * 512 = 512 bytes
-		// 	Sector size (logical/physical): 512 bytes / 4096 bytes
-		// 	I/O size (minimum/optimal): 4096 bytes / 4096 bytes
-		// 	Disklabel type: dos
-		// 	Disk identifier: 0x065e2357
-		//
-		// 	Device
-		// 	/dev/sda1
-		// 	/dev/sda2
-		// 	/dev/sda5
-		// 	/dev/sda6
-		// 	/dev/sda7
-		//
-		// From the above output the partitions are filtered using grep,
-		//
-		// first all the partitions are cleared off any filesystem signatures, then the actual partition table
-		// header is removed. partprobe is called so as to re-read partition table, and update system with
-		// the changes.  Partprobe will be called only if the device is a block file; else if its sparse file, wipefs
-		// will be done.
-		// wipefs erases the filesystem signature from the block
-		// -a    wipe all magic strings
-		// -f    force erasure
-
-		args := fmt.Sprintf(""+
-			"(pvs -o pv_name,vg_name|grep %[1]s|awk '{print $2}'"+
-			"| xargs -I {} sh -c 'dmsetup info -c -o name --noheadings|grep ^{}- "+
-			"| xargs -t -I {} dmsetup remove {} ') && "+
-			"(fdisk -o Device -l %[1]s "+
-			"| grep \"^%[1]s\" "+
-			"| xargs -I '{}' wipefs -fa '{}') "+
-			"&& wipefs -fa %[1]s ",
-			bd.Spec.Path)
-
-		// partprobe need to be executed only if the device is of type disk.
-		if bd.Spec.Details.DeviceType == blockdevice.BlockDeviceTypeDisk {
-			args += fmt.Sprintf("&& partprobe %s ", bd.Spec.Path)
-		}
-
-		jobContainer.Args = []string{args}
+		jobContainer.Args = []string{getBlockCleanupCommand(bd, method)}
 
 		var volume v1.Volume
 		var volumeMount v1.VolumeMount
@@ -141,7 +112,7 @@ func NewCleanupJob(bd *v1alpha1.BlockDevice, volMode VolumeMode, tolerations []v
 
 	} else if volMode == VolumeModeFileSystem {
 		jobContainer.Command = []string{"/bin/sh", "-c"}
-		jobContainer.Args = []string{"find /tmp -mindepth 1 -maxdepth 1 -print0 | xargs -0 rm -rf"}
+		jobContainer.Args = []string{getFileSystemCleanupCommand("/tmp", method)}
 		volume, volumeMount := getVolumeMounts(bd.Spec.FileSystem.Mountpoint, "/tmp", mountName)
 
 		jobContainer.VolumeMounts = []v1.VolumeMount{volumeMount}
@@ -170,16 +141,22 @@ func NewCleanupJob(bd *v1alpha1.BlockDevice, volMode VolumeMode, tolerations []v
 	job := &batchv1.Job{}
 	job.ObjectMeta = podTemplate.ObjectMeta
 	job.Spec.Template.Spec = podTemplate.Spec
-	job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyOnFailure
+	// a new pod is created for every retry, so that the logs of the failed
+	// pods are available. The job controller retries the failed pods with an
+	// exponential backoff, till the backoff limit is reached.
+	job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyNever
+	backoffLimit := getCleanUpJobBackoffLimit()
+	job.Spec.BackoffLimit = &backoffLimit
 
 	return job, nil
 }
 
 // NewJobController returns a job controller struct which can be used to get the status
-// of the running job
-func NewJobController(client client.Client, namespace string) *jobController {
+// of the running job. The clientset is used for fetching the logs of the failed jobs.
+func NewJobController(client client.Client, clientset kubernetes.Interface, namespace string) *jobController {
 	return &jobController{
 		client:    client,
+		clientset: clientset,
 		namespace: namespace,
 	}
 }
@@ -202,7 +179,7 @@ func (c *jobController) IsCleaningJobRunning(bdName string) bool {
 		return true
 	}
 
-	return job.Status.Succeeded <= 0
+	return job.Status.Succeeded <= 0 && !isJobFailed(job)
 }
 
 func (c *jobController) RemoveJob(bdName string) (CleanupState, error) {
@@ -220,6 +197,10 @@ func (c *jobController) RemoveJob(bdName string) (CleanupState, error) {
 		}
 		return CleanupStateUnknown, err
 	}
+	// the failed job is not removed, so that the logs can be fetched
+	if isJobFailed(job) {
+		return CleanupStateFailed, nil
+	}
 	if job.Status.Succeeded == 0 {
 		return CleanupStateRunning, nil
 	}
@@ -247,6 +228,56 @@ func (c *jobController) CancelJob(bdName string) error {
 	return err
 }
 
+// GetJobLogs returns the last JobLogTailLines lines of the log of the most
+// recently failed pod of the cleanup job
+func (c *jobController) GetJobLogs(bdName string) (string, error) {
+	jobName := generateCleaningJobName(bdName)
+	if c.clientset == nil {
+		return "", fmt.Errorf("unable to fetch logs of job %s, clientset not available", jobName)
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: jobNameLabel + "=" + jobName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var failedPods []v1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodFailed {
+			failedPods = append(failedPods, pod)
+		}
+	}
+	if len(failedPods) == 0 {
+		return "", fmt.Errorf("no failed pods found for job %s", jobName)
+	}
+	sort.Slice(failedPods, func(i, j int) bool {
+		return failedPods[i].CreationTimestamp.Before(&failedPods[j].CreationTimestamp)
+	})
+
+	tailLines := JobLogTailLines
+	logs, err := c.clientset.CoreV1().Pods(c.namespace).GetLogs(failedPods[len(failedPods)-1].Name,
+		&v1.PodLogOptions{
+			Container: JobContainerName,
+			TailLines: &tailLines,
+		}).DoRaw(context.TODO())
+	if err != nil {
+		return "", err
+	}
+	return string(logs), nil
+}
+
+// isJobFailed checks if the job has failed after exhausting all the retries
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 func generateCleaningJobName(bdName string) string {
 	return JobNamePrefix + bdName
 }
@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	fakeBDName    = "blockdevice-123"
+	fakeNamespace = "openebs"
+)
+
+func TestRemoveJob(t *testing.T) {
+	tests := map[string]struct {
+		job         *batchv1.Job
+		wantState   CleanupState
+		wantRunning bool
+		wantDeleted bool
+	}{
+		"job not found": {
+			wantState: CleanupStateNotFound,
+		},
+		"job running": {
+			job:         newFakeJob(batchv1.JobStatus{Active: 1}),
+			wantState:   CleanupStateRunning,
+			wantRunning: true,
+		},
+		"job succeeded": {
+			job:         newFakeJob(batchv1.JobStatus{Succeeded: 1}),
+			wantState:   CleanupStateSucceeded,
+			wantDeleted: true,
+		},
+		"job failed after retries": {
+			job: newFakeJob(batchv1.JobStatus{
+				Failed: 4,
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+				},
+			}),
+			wantState: CleanupStateFailed,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme)
+			if test.job != nil {
+				builder = builder.WithObjects(test.job)
+			}
+			cl := builder.Build()
+			jc := NewJobController(cl, nil, fakeNamespace)
+
+			assert.Equal(t, test.wantRunning, jc.IsCleaningJobRunning(fakeBDName))
+
+			state, err := jc.RemoveJob(fakeBDName)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantState, state)
+
+			if test.job == nil {
+				return
+			}
+			err = cl.Get(context.TODO(), client.ObjectKeyFromObject(test.job), &batchv1.Job{})
+			assert.Equal(t, test.wantDeleted, err != nil)
+		})
+	}
+}
+
+func TestGetJobLogs(t *testing.T) {
+	jobName := generateCleaningJobName(fakeBDName)
+	now := time.Now()
+
+	tests := map[string]struct {
+		pods    []v1.Pod
+		wantErr bool
+	}{
+		"no pods": {
+			wantErr: true,
+		},
+		"no failed pods": {
+			pods:    []v1.Pod{newFakePod("pod-1", jobName, v1.PodRunning, now)},
+			wantErr: true,
+		},
+		"failed pods": {
+			pods: []v1.Pod{
+				newFakePod("pod-1", jobName, v1.PodFailed, now.Add(-time.Minute)),
+				newFakePod("pod-2", jobName, v1.PodFailed, now),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for i := range test.pods {
+				_, err := clientset.CoreV1().Pods(fakeNamespace).Create(context.TODO(), &test.pods[i], metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			jc := NewJobController(fakeclient.NewClientBuilder().Build(), clientset, fakeNamespace)
+
+			logs, err := jc.GetJobLogs(fakeBDName)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			// the fake clientset returns a fixed string as the pod log
+			assert.Equal(t, "fake logs", logs)
+		})
+	}
+}
+
+func TestGetJobLogsWithoutClientset(t *testing.T) {
+	jc := NewJobController(fakeclient.NewClientBuilder().Build(), nil, fakeNamespace)
+	_, err := jc.GetJobLogs(fakeBDName)
+	assert.Error(t, err)
+}
+
+func newFakeJob(status batchv1.JobStatus) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generateCleaningJobName(fakeBDName),
+			Namespace: fakeNamespace,
+			Labels:    map[string]string{BDLabel: fakeBDName},
+		},
+		Status: status,
+	}
+}
+
+func newFakePod(name, jobName string, phase v1.PodPhase, created time.Time) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         fakeNamespace,
+			Labels:            map[string]string{jobNameLabel: jobName},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/env"
+)
+
+// DefaultCleanupMethod is the method used when neither the BD, the claim
+// nor the operator specify a cleanup method
+const DefaultCleanupMethod = v1alpha1.CleanupMethodWipefs
+
+// supportedCleanupMethods are the cleanup methods supported for each volume mode
+var supportedCleanupMethods = map[VolumeMode][]v1alpha1.CleanupMethod{
+	VolumeModeBlock: {
+		v1alpha1.CleanupMethodWipefs,
+		v1alpha1.CleanupMethodDiscard,
+		v1alpha1.CleanupMethodZero,
+		v1alpha1.CleanupMethodATASecureErase,
+		v1alpha1.CleanupMethodNVMeFormat,
+	},
+	// a mounted filesystem can only be emptied and trimmed, the
+	// underlying device cannot be erased while it is in use
+	VolumeModeFileSystem: {
+		v1alpha1.CleanupMethodWipefs,
+		v1alpha1.CleanupMethodDiscard,
+	},
+}
+
+// GetCleanupMethod returns the method to be used for cleaning up the BD. The method
+// set on the BD using the cleanup-method annotation takes precedence over the method
+// requested by the claim that released the BD, which in turn takes precedence over
+// the cluster wide default.
+func GetCleanupMethod(bd *v1alpha1.BlockDevice) v1alpha1.CleanupMethod {
+	method := v1alpha1.CleanupMethod(strings.TrimSpace(bd.Annotations[controller.OpenEBSCleanupMethod]))
+	if method == "" {
+		method = bd.Status.CleanupMethod
+	}
+	if method == "" {
+		method = v1alpha1.CleanupMethod(env.GetDefaultCleanupMethod())
+	}
+	if method == "" {
+		method = DefaultCleanupMethod
+	}
+	return method
+}
+
+// validateCleanupMethod checks whether the cleanup method can be used to clean up
+// the BD in the given volume mode
+func validateCleanupMethod(bd *v1alpha1.BlockDevice, volMode VolumeMode, method v1alpha1.CleanupMethod) error {
+	if !isSupportedCleanupMethod(volMode, method) {
+		return fmt.Errorf("cleanup method %q is not supported in %s", method, volMode)
+	}
+
+	deviceType := bd.Spec.Details.DeviceType
+	switch method {
+	case v1alpha1.CleanupMethodATASecureErase, v1alpha1.CleanupMethodNVMeFormat:
+		// the whole drive or namespace is erased by these methods
+		if deviceType != blockdevice.BlockDeviceTypeDisk {
+			return fmt.Errorf("cleanup method %q cannot be used on a %s", method, deviceType)
+		}
+		if method == v1alpha1.CleanupMethodATASecureErase && !isATADevice(bd) {
+			return fmt.Errorf("cleanup method %q cannot be used on non ATA device %s", method, bd.Spec.Path)
+		}
+		if method == v1alpha1.CleanupMethodNVMeFormat && !isNVMeDevice(bd.Spec.Path) {
+			return fmt.Errorf("cleanup method %q cannot be used on non NVMe device %s", method, bd.Spec.Path)
+		}
+	}
+	return nil
+}
+
+// isSupportedCleanupMethod checks if the cleanup method is supported in the volume mode
+func isSupportedCleanupMethod(volMode VolumeMode, method v1alpha1.CleanupMethod) bool {
+	for _, m := range supportedCleanupMethods[volMode] {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// isATADevice checks if the BD is attached through a SATA controller. A BD
+// whose transport is not known is not considered to be an ATA device.
+func isATADevice(bd *v1alpha1.BlockDevice) bool {
+	return bd.Spec.Transport != nil && bd.Spec.Transport.Type == blockdevice.TransportSATA
+}
+
+// isNVMeDevice checks if the device path is that of an NVMe namespace
+func isNVMeDevice(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "nvme")
+}
+
+// getBlockCleanupCommand returns the shell command used to clean up a BD in block
+// volume mode using the given cleanup method.
+//
+// The device mapper devices of the LVM volume groups on the device are removed
+// first, so that the device is not in use while it is being cleaned up.
+// partprobe is called at the end so as to re-read partition table, and update
+// system with the changes. Partprobe will be called only if the device is a
+// disk.
+func getBlockCleanupCommand(bd *v1alpha1.BlockDevice, method v1alpha1.CleanupMethod) string {
+	path := bd.Spec.Path
+	isSparse := bd.Spec.Details.DeviceType == blockdevice.SparseBlockDeviceType
+
+	commands := []string{removeDMDevicesCommand(path)}
+	switch method {
+	case v1alpha1.CleanupMethodDiscard:
+		// discarded blocks need not read back as zeroes, the signatures are
+		// removed after the discard so that the device always appears empty
+		if isSparse {
+			commands = append(commands, punchHoleCommand(path))
+		} else {
+			commands = append(commands, fmt.Sprintf("blkdiscard %s", path))
+		}
+		commands = append(commands, wipefsCommand(path))
+	case v1alpha1.CleanupMethodZero:
+		// the holes punched in a sparse file read back as zeroes
+		if isSparse {
+			commands = append(commands, punchHoleCommand(path))
+		} else {
+			commands = append(commands, fmt.Sprintf("blkdiscard --zeroout %s", path))
+		}
+	case v1alpha1.CleanupMethodATASecureErase:
+		// a temporary password has to be set on the drive to enable the security
+		// feature set, the password is cleared by the erase. The erase fails if
+		// the drive is in frozen state.
+		commands = append(commands,
+			requireCommand("hdparm"),
+			fmt.Sprintf("hdparm --user-master u --security-set-pass NULL %s", path),
+			fmt.Sprintf("hdparm --user-master u --security-erase NULL %s", path))
+	case v1alpha1.CleanupMethodNVMeFormat:
+		// --ses=1 performs a user data erase of the namespace
+		commands = append(commands, requireCommand("nvme"),
+			fmt.Sprintf("nvme format %s --ses=1 --force", path))
+	default:
+		commands = append(commands, wipePartitionsCommand(path), wipefsCommand(path))
+	}
+
+	// partprobe need to be executed only if the device is of type disk.
+	if bd.Spec.Details.DeviceType == blockdevice.BlockDeviceTypeDisk {
+		commands = append(commands, fmt.Sprintf("partprobe %s", path))
+	}
+
+	return strings.Join(commands, " && ")
+}
+
+// getFileSystemCleanupCommand returns the shell command used to clean up a BD in
+// filesystem volume mode, which is mounted at mountPath in the cleanup job.
+func getFileSystemCleanupCommand(mountPath string, method v1alpha1.CleanupMethod) string {
+	command := fmt.Sprintf("find %s -mindepth 1 -maxdepth 1 -print0 | xargs -0 rm -rf", mountPath)
+	if method == v1alpha1.CleanupMethodDiscard {
+		// discard the blocks freed by removing the files
+		command += fmt.Sprintf(" && fstrim %s", mountPath)
+	}
+	return command
+}
+
+// removeDMDevicesCommand returns the command to remove the device mapper devices
+// of all the volume groups that have the device as a physical volume
+func removeDMDevicesCommand(path string) string {
+	return fmt.Sprintf(""+
+		"(pvs -o pv_name,vg_name|grep %[1]s|awk '{print $2}'"+
+		"| xargs -I {} sh -c 'dmsetup info -c -o name --noheadings|grep ^{}- "+
+		"| xargs -t -I {} dmsetup remove {} ')",
+		path)
+}
+
+// wipePartitionsCommand returns the command to clear all the partitions of the
+// device off any filesystem signatures.
+//
+// fdisk is used to get all the partitions of the device.
+// Example
+// $ fdisk -o Device -l /dev/sda
+//
+//	Disk /dev/sda: 465.8 GiB, 500107862016 bytes, 976773168 sectors
+//	Units: sectors of 1 * 512 = 512 bytes
+//	Sector size (logical/physical): 512 bytes / 4096 bytes
+//	I/O size (minimum/optimal): 4096 bytes / 4096 bytes
+//	Disklabel type: dos
+//	Disk identifier: 0x065e2357
+//
+//	Device
+//	/dev/sda1
+//	/dev/sda2
+//	/dev/sda5
+//	/dev/sda6
+//	/dev/sda7
+//
+// From the above output the partitions are filtered using grep.
+func wipePartitionsCommand(path string) string {
+	return fmt.Sprintf(""+
+		"(fdisk -o Device -l %[1]s "+
+		"| grep \"^%[1]s\" "+
+		"| xargs -I '{}' wipefs -fa '{}')",
+		path)
+}
+
+// wipefsCommand returns the command to erase the filesystem signature and the
+// partition table header from the device.
+// wipefs erases the filesystem signature from the block
+// -a    wipe all magic strings
+// -f    force erasure
+func wipefsCommand(path string) string {
+	return fmt.Sprintf("wipefs -fa %s", path)
+}
+
+// punchHoleCommand returns the command to deallocate all the blocks of a sparse
+// file, without changing the size of the file
+func punchHoleCommand(path string) string {
+	return fmt.Sprintf("fallocate --punch-hole --offset 0 --length $(stat -c %%s %[1]s) %[1]s", path)
+}
+
+// requireCommand returns the command that fails the cleanup job with a clear
+// message if the given tool is not available in the job image
+func requireCommand(name string) string {
+	return fmt.Sprintf("(command -v %[1]s >/dev/null || "+
+		"(echo \"%[1]s not found, set %[2]s to an image with %[1]s\" && exit 1))",
+		name, EnvEraseJobImage)
+}
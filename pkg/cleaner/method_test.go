@@ -0,0 +1,270 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/env"
+)
+
+func TestGetCleanupMethod(t *testing.T) {
+	tests := map[string]struct {
+		annotation    string
+		claimMethod   v1alpha1.CleanupMethod
+		defaultMethod string
+		want          v1alpha1.CleanupMethod
+	}{
+		"nothing specified": {
+			want: v1alpha1.CleanupMethodWipefs,
+		},
+		"cluster default": {
+			defaultMethod: "zero",
+			want:          v1alpha1.CleanupMethodZero,
+		},
+		"claim method overrides cluster default": {
+			claimMethod:   v1alpha1.CleanupMethodDiscard,
+			defaultMethod: "zero",
+			want:          v1alpha1.CleanupMethodDiscard,
+		},
+		"annotation overrides claim method": {
+			annotation:    "nvme-format",
+			claimMethod:   v1alpha1.CleanupMethodDiscard,
+			defaultMethod: "zero",
+			want:          v1alpha1.CleanupMethodNVMeFormat,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(env.DEFAULT_CLEANUP_METHOD_ENV, test.defaultMethod)
+			bd := &v1alpha1.BlockDevice{}
+			if test.annotation != "" {
+				bd.Annotations = map[string]string{controller.OpenEBSCleanupMethod: test.annotation}
+			}
+			bd.Status.CleanupMethod = test.claimMethod
+			assert.Equal(t, test.want, GetCleanupMethod(bd))
+		})
+	}
+}
+
+func TestValidateCleanupMethod(t *testing.T) {
+	tests := map[string]struct {
+		deviceType string
+		path       string
+		transport  string
+		volMode    VolumeMode
+		method     v1alpha1.CleanupMethod
+		wantErr    bool
+	}{
+		"wipefs on a partition": {
+			deviceType: blockdevice.BlockDeviceTypePartition,
+			path:       "/dev/sdb1",
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodWipefs,
+		},
+		"zero on a sparse file": {
+			deviceType: blockdevice.SparseBlockDeviceType,
+			path:       "/var/openebs/sparse/0-ndm-sparse.img",
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodZero,
+		},
+		"invalid method": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			volMode:    VolumeModeBlock,
+			method:     "shred",
+			wantErr:    true,
+		},
+		"discard in filesystem mode": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			volMode:    VolumeModeFileSystem,
+			method:     v1alpha1.CleanupMethodDiscard,
+		},
+		"zero in filesystem mode": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			volMode:    VolumeModeFileSystem,
+			method:     v1alpha1.CleanupMethodZero,
+			wantErr:    true,
+		},
+		"ata secure erase on a disk": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			transport:  blockdevice.TransportSATA,
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodATASecureErase,
+		},
+		"ata secure erase on a sas disk": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			transport:  blockdevice.TransportSAS,
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodATASecureErase,
+			wantErr:    true,
+		},
+		"ata secure erase on a disk with unknown transport": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodATASecureErase,
+			wantErr:    true,
+		},
+		"ata secure erase on a partition": {
+			deviceType: blockdevice.BlockDeviceTypePartition,
+			path:       "/dev/sdb1",
+			transport:  blockdevice.TransportSATA,
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodATASecureErase,
+			wantErr:    true,
+		},
+		"nvme format on an nvme disk": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/nvme0n1",
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodNVMeFormat,
+		},
+		"nvme format on a sata disk": {
+			deviceType: blockdevice.BlockDeviceTypeDisk,
+			path:       "/dev/sdb",
+			volMode:    VolumeModeBlock,
+			method:     v1alpha1.CleanupMethodNVMeFormat,
+			wantErr:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := newFakeBlockDevice(test.path, test.deviceType)
+			if test.transport != "" {
+				bd.Spec.Transport = &v1alpha1.TransportInfo{Type: test.transport}
+			}
+			err := validateCleanupMethod(bd, test.volMode, test.method)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewCleanupJob(t *testing.T) {
+	const fakeEraseImage = "openebs/erase-utils:1.0.0"
+	t.Setenv(EnvEraseJobImage, fakeEraseImage)
+
+	sataBD := newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk)
+	sataBD.Spec.Transport = &v1alpha1.TransportInfo{Type: blockdevice.TransportSATA}
+
+	tests := map[string]struct {
+		bd           *v1alpha1.BlockDevice
+		method       v1alpha1.CleanupMethod
+		wantCommands []string
+		wantImage    string
+		wantErr      bool
+	}{
+		"wipefs on a disk": {
+			bd:     newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk),
+			method: v1alpha1.CleanupMethodWipefs,
+			wantCommands: []string{
+				"dmsetup remove",
+				"xargs -I '{}' wipefs -fa '{}'",
+				"wipefs -fa /dev/sdb",
+				"partprobe /dev/sdb",
+			},
+		},
+		"discard on a disk": {
+			bd:           newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk),
+			method:       v1alpha1.CleanupMethodDiscard,
+			wantCommands: []string{"blkdiscard /dev/sdb", "wipefs -fa /dev/sdb", "partprobe /dev/sdb"},
+		},
+		"zero on a partition": {
+			bd:           newFakeBlockDevice("/dev/sdb1", blockdevice.BlockDeviceTypePartition),
+			method:       v1alpha1.CleanupMethodZero,
+			wantCommands: []string{"blkdiscard --zeroout /dev/sdb1"},
+		},
+		"zero on a sparse file": {
+			bd:           newFakeBlockDevice("/var/openebs/sparse/0-ndm-sparse.img", blockdevice.SparseBlockDeviceType),
+			method:       v1alpha1.CleanupMethodZero,
+			wantCommands: []string{"fallocate --punch-hole --offset 0 --length $(stat -c %s /var/openebs/sparse/0-ndm-sparse.img)"},
+		},
+		"ata secure erase on a disk": {
+			bd:     sataBD,
+			method: v1alpha1.CleanupMethodATASecureErase,
+			wantCommands: []string{
+				"command -v hdparm",
+				"hdparm --user-master u --security-set-pass NULL /dev/sdb",
+				"hdparm --user-master u --security-erase NULL /dev/sdb",
+			},
+			wantImage: fakeEraseImage,
+		},
+		"ata secure erase on a disk with unknown transport": {
+			bd:      newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk),
+			method:  v1alpha1.CleanupMethodATASecureErase,
+			wantErr: true,
+		},
+		"nvme format on a disk": {
+			bd:           newFakeBlockDevice("/dev/nvme0n1", blockdevice.BlockDeviceTypeDisk),
+			method:       v1alpha1.CleanupMethodNVMeFormat,
+			wantCommands: []string{"command -v nvme", "nvme format /dev/nvme0n1 --ses=1 --force"},
+			wantImage:    fakeEraseImage,
+		},
+		"nvme format on a sata disk": {
+			bd:      newFakeBlockDevice("/dev/sdb", blockdevice.BlockDeviceTypeDisk),
+			method:  v1alpha1.CleanupMethodNVMeFormat,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job, err := NewCleanupJob(test.bd, VolumeModeBlock, test.method, nil, "openebs")
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			wantImage := test.wantImage
+			if wantImage == "" {
+				wantImage = defaultCleanUpJobImage
+			}
+			assert.Equal(t, wantImage, job.Spec.Template.Spec.Containers[0].Image)
+			args := job.Spec.Template.Spec.Containers[0].Args
+			assert.Len(t, args, 1)
+			for _, command := range test.wantCommands {
+				assert.True(t, strings.Contains(args[0], command), "%q not found in %q", command, args[0])
+			}
+		})
+	}
+}
+
+func newFakeBlockDevice(path, deviceType string) *v1alpha1.BlockDevice {
+	return &v1alpha1.BlockDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "blockdevice-123"},
+		Spec: v1alpha1.DeviceSpec{
+			Path: path,
+			Details: v1alpha1.DeviceDetails{
+				DeviceType: deviceType,
+			},
+		},
+	}
+}
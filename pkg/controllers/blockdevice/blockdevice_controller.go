@@ -18,12 +18,18 @@ package blockdevice
 
 import (
 	"context"
+	"fmt"
+
 	util2 "github.com/openebs/node-disk-manager/pkg/controllers/util"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,6 +48,9 @@ type BlockDeviceReconciler struct {
 	Log      logr.Logger
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Clientset is used for the operations that are not supported by
+	// the controller-runtime client, like fetching the logs of a pod
+	Clientset kubernetes.Interface
 }
 
 //+kubebuilder:rbac:groups=openebs.io,resources=blockdevices,verbs=get;list;watch;create;update;patch;delete
@@ -72,13 +81,28 @@ func (r *BlockDeviceReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		return reconcile.Result{}, nil
 	}
 
+	// all the changes made during the reconciliation are patched against
+	// the BlockDevice as it was read
+	original := instance.DeepCopy()
+	setDeviceMissingCondition(instance)
+
 	switch instance.Status.ClaimState {
 	case apis.BlockDeviceReleased:
 		klog.V(2).Infof("%s is in Released state", instance.Name)
-		jobController := cleaner.NewJobController(r.Client, request.Namespace)
+		jobController := cleaner.NewJobController(r.Client, r.Clientset, request.Namespace)
 		cleanupTracker := &cleaner.CleanupStatusTracker{JobController: jobController}
 		bdCleaner := cleaner.NewCleaner(r.Client, request.Namespace, cleanupTracker)
 		ok, err := bdCleaner.Clean(instance)
+		if cleaner.IsCleanupJobFailed(err) {
+			klog.Errorf("Cleanup failed for %s: %v", instance.Name, err)
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "BlockDeviceCleanUpFailed", "CleanUp failed: %v", err)
+			// conditions are updated along with the claim state
+			if err := r.updateBDStatus(apis.BlockDeviceCleanupFailed, original, instance, nil); err != nil {
+				klog.Errorf("Failed to mark %s as %s: %v", instance.Name, apis.BlockDeviceCleanupFailed, err)
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{}, nil
+		}
 		if err != nil {
 			klog.Errorf("Error while cleaning %s: %v", instance.Name, err)
 			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "BlockDeviceCleanUp", "CleanUp unsuccessful, due to error: %v", err)
@@ -86,24 +110,65 @@ func (r *BlockDeviceReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		}
 		if ok {
 			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceReleased", "CleanUp Completed")
-			// remove the finalizer string from BlockDevice resource
-			instance.Finalizers = util.RemoveString(instance.Finalizers, util2.BlockDeviceFinalizer)
 			klog.Infof("Cleanup completed for %s", instance.Name)
-			err := r.updateBDStatus(apis.BlockDeviceUnclaimed, instance)
+			err := r.updateBDStatus(apis.BlockDeviceUnclaimed, original, instance, func(bd *apis.BlockDevice) {
+				// remove the finalizer string from BlockDevice resource
+				bd.Finalizers = util.RemoveString(bd.Finalizers, util2.BlockDeviceFinalizer)
+				bd.Status.CleanupMethod = ""
+			})
 			if err != nil {
 				klog.Errorf("Failed to mark %s as Unclaimed: %v", instance.Name, err)
+				return reconcile.Result{}, err
 			}
 			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceUnclaimed", "BD now marked as Unclaimed")
+			// conditions are updated along with the claim state
+			return reconcile.Result{}, nil
 		} else {
 			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceCleanUpInProgress", "CleanUp is in progress")
 		}
+	case apis.BlockDeviceCleanupFailed:
+		if !IsCleanupRetryRequested(instance) {
+			break
+		}
+		klog.Infof("Retrying cleanup of %s", instance.Name)
+		util2.SetCondition(&instance.Status.Conditions, instance.Generation, apis.BlockDeviceConditionCleanupFailed,
+			metav1.ConditionFalse, apis.BlockDeviceReasonCleanupRetried, "Cleanup retry requested")
+		// marking the BD as Released will start a new cleanup job
+		err := r.updateBDStatus(apis.BlockDeviceReleased, original, instance, func(bd *apis.BlockDevice) {
+			delete(bd.Annotations, ndm.OpenEBSRetryCleanup)
+		})
+		if err != nil {
+			klog.Errorf("Failed to mark %s as %s: %v", instance.Name, apis.BlockDeviceReleased, err)
+			return reconcile.Result{}, err
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceCleanUpRetry", "CleanUp retry requested, BD marked as Released")
+		return reconcile.Result{}, nil
+	case apis.BlockDeviceUnclaimed:
+		// a Retained BD which was cleared by the administrator still has the
+		// finalizer that was added when the BD was claimed
+		if util.Contains(instance.GetFinalizers(), util2.BlockDeviceFinalizer) {
+			err := r.updateBlockDevice(original, instance, func(bd *apis.BlockDevice) {
+				bd.Finalizers = util.RemoveString(bd.Finalizers, util2.BlockDeviceFinalizer)
+			})
+			if err != nil {
+				klog.Errorf("Error removing finalizer from %s: %v", instance.Name, err)
+				return reconcile.Result{}, err
+			}
+			klog.Infof("%s finalizer removed from unclaimed %s", util2.BlockDeviceFinalizer, instance.Name)
+			// conditions are updated along with the finalizer
+			return reconcile.Result{}, nil
+		}
 	case apis.BlockDeviceClaimed:
 		if !util.Contains(instance.GetFinalizers(), util2.BlockDeviceFinalizer) {
 			// finalizer is not present, may be a BlockDevice claimed from previous release
-			instance.Finalizers = append(instance.Finalizers, util2.BlockDeviceFinalizer)
-			err := r.Client.Update(context.TODO(), instance)
+			err := r.updateBlockDevice(original, instance, func(bd *apis.BlockDevice) {
+				if !util.Contains(bd.Finalizers, util2.BlockDeviceFinalizer) {
+					bd.Finalizers = append(bd.Finalizers, util2.BlockDeviceFinalizer)
+				}
+			})
 			if err != nil {
 				klog.Errorf("Error updating finalizer on %s: %v", instance.Name, err)
+				return reconcile.Result{}, err
 			}
 			klog.Infof("%s updated with %s finalizer", instance.Name, util2.BlockDeviceFinalizer)
 			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceClaimed", "BD Claimed, and finalizer added")
@@ -111,9 +176,37 @@ func (r *BlockDeviceReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		// if finalizer is already present. do nothing
 	}
 
+	// persist the conditions if they were modified during the reconciliation
+	if !equality.Semantic.DeepEqual(original.Status.Conditions, instance.Status.Conditions) {
+		if err := r.updateBlockDevice(original, instance, nil); err != nil {
+			klog.Errorf("Error updating conditions on %s: %v", instance.Name, err)
+			return reconcile.Result{}, err
+		}
+	}
+
 	return reconcile.Result{}, nil
 }
 
+// setDeviceMissingCondition sets the DeviceMissing condition on the BD depending
+// on whether the device is attached to the node or not
+func setDeviceMissingCondition(bd *apis.BlockDevice) {
+	nodeName := bd.Spec.NodeAttributes.NodeName
+	switch bd.Status.State {
+	case apis.BlockDeviceActive:
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionDeviceMissing,
+			metav1.ConditionFalse, apis.BlockDeviceReasonDeviceActive,
+			fmt.Sprintf("BlockDevice is attached to node %s", nodeName))
+	case apis.BlockDeviceInactive:
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionDeviceMissing,
+			metav1.ConditionTrue, apis.BlockDeviceReasonDeviceInactive,
+			fmt.Sprintf("BlockDevice is not attached to node %s", nodeName))
+	default:
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionDeviceMissing,
+			metav1.ConditionUnknown, apis.BlockDeviceReasonDeviceStateUnknown,
+			fmt.Sprintf("State of the BlockDevice on node %s cannot be determined", nodeName))
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BlockDeviceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -122,15 +215,71 @@ func (r *BlockDeviceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *BlockDeviceReconciler) updateBDStatus(state apis.DeviceClaimState, instance *apis.BlockDevice) error {
-	instance.Status.ClaimState = state
-	err := r.Client.Update(context.TODO(), instance)
+// updateBDStatus moves the BD to the given claim state, along with the changes
+// made by mutate
+func (r *BlockDeviceReconciler) updateBDStatus(state apis.DeviceClaimState, original, instance *apis.BlockDevice,
+	mutate func(bd *apis.BlockDevice)) error {
+	return r.updateBlockDevice(original, instance, func(bd *apis.BlockDevice) {
+		bd.Status.ClaimState = state
+		if mutate != nil {
+			mutate(bd)
+		}
+	})
+}
+
+// updateBlockDevice patches the changes made by mutate, along with the conditions
+// changed during the reconciliation, on the BD. On a conflict, the changes are
+// applied again on the latest BD, as long as its claim state, on which the
+// reconciliation was based, has not changed. original and instance are updated
+// with the patched BD.
+func (r *BlockDeviceReconciler) updateBlockDevice(original, instance *apis.BlockDevice,
+	mutate func(bd *apis.BlockDevice)) error {
+	claimState := original.Status.ClaimState
+	// only the conditions changed during the reconciliation are applied, so that
+	// the conditions set by other controllers in the meantime are retained
+	conditions := changedConditions(original.Status.Conditions, instance.Status.Conditions)
+	err := util2.UpdateBlockDevice(context.TODO(), r.Client, original, util2.OperatorFieldManager,
+		func(bd *apis.BlockDevice) error {
+			if bd.Status.ClaimState != claimState {
+				return fmt.Errorf("claim state of %s changed from %s to %s",
+					bd.Name, claimState, bd.Status.ClaimState)
+			}
+			for _, condition := range conditions {
+				meta.SetStatusCondition(&bd.Status.Conditions, condition)
+			}
+			// the device may have been attached or detached in the meantime
+			setDeviceMissingCondition(bd)
+			if mutate != nil {
+				mutate(bd)
+			}
+			return nil
+		})
 	if err != nil {
 		return err
 	}
+	original.DeepCopyInto(instance)
 	return nil
 }
 
+// changedConditions returns the conditions which are either not present in
+// observed, or are different from the ones in observed
+func changedConditions(observed, conditions []metav1.Condition) []metav1.Condition {
+	var changed []metav1.Condition
+	for _, condition := range conditions {
+		existing := meta.FindStatusCondition(observed, condition.Type)
+		if existing == nil || !equality.Semantic.DeepEqual(*existing, condition) {
+			changed = append(changed, condition)
+		}
+	}
+	return changed
+}
+
+// IsCleanupRetryRequested is used to check if the cleanup of a BlockDevice
+// in CleanupFailed state is to be retried
+func IsCleanupRetryRequested(bd *apis.BlockDevice) bool {
+	return util.CheckTruthy(bd.Annotations[ndm.OpenEBSRetryCleanup])
+}
+
 // IsReconcileDisabled is used to check if reconciliation is disabled for
 // BlockDevice
 func IsReconcileDisabled(bd *apis.BlockDevice) bool {
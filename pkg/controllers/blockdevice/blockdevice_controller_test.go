@@ -25,7 +25,9 @@ import (
 
 	openebsv1alpha1 "github.com/openebs/node-disk-manager/api/v1alpha1"
 	ndm "github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/openebs/node-disk-manager/pkg/controllers/util"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -96,6 +98,91 @@ func TestDeviceController(t *testing.T) {
 	} else {
 		t.Fatalf("BlockDevice Object state:%v did not match expected state:%v", deviceInstance.Status.State, ndm.NDMActive)
 	}
+
+	// DeviceMissing condition should be set to False for an Active device
+	condition := meta.FindStatusCondition(deviceInstance.Status.Conditions, openebsv1alpha1.BlockDeviceConditionDeviceMissing)
+	if condition == nil {
+		t.Fatalf("BlockDevice Object does not have condition:%v", openebsv1alpha1.BlockDeviceConditionDeviceMissing)
+	}
+	if condition.Status != metav1.ConditionFalse || condition.Reason != openebsv1alpha1.BlockDeviceReasonDeviceActive {
+		t.Fatalf("BlockDevice Object condition:%v/%v did not match expected condition:%v/%v", condition.Status,
+			condition.Reason, metav1.ConditionFalse, openebsv1alpha1.BlockDeviceReasonDeviceActive)
+	}
+}
+
+func TestCleanupRetry(t *testing.T) {
+	tests := map[string]struct {
+		annotations    map[string]string
+		wantClaimState openebsv1alpha1.DeviceClaimState
+	}{
+		"retry not requested": {
+			wantClaimState: openebsv1alpha1.BlockDeviceCleanupFailed,
+		},
+		"retry requested": {
+			annotations:    map[string]string{ndm.OpenEBSRetryCleanup: "true"},
+			wantClaimState: openebsv1alpha1.BlockDeviceReleased,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := GetFakeDeviceObject()
+			bd.Annotations = test.annotations
+			bd.Status.ClaimState = openebsv1alpha1.BlockDeviceCleanupFailed
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(bd).Build()
+			r := &BlockDeviceReconciler{Client: cl, Scheme: scheme.Scheme, Recorder: record.NewFakeRecorder(10)}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      deviceName,
+					Namespace: namespace,
+				},
+			}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			if err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			gotBD := &openebsv1alpha1.BlockDevice{}
+			if err := cl.Get(context.TODO(), req.NamespacedName, gotBD); err != nil {
+				t.Fatalf("get deviceInstance : (%v)", err)
+			}
+			if gotBD.Status.ClaimState != test.wantClaimState {
+				t.Fatalf("BlockDevice claim state:%v did not match expected state:%v",
+					gotBD.Status.ClaimState, test.wantClaimState)
+			}
+			if _, ok := gotBD.Annotations[ndm.OpenEBSRetryCleanup]; ok {
+				t.Fatalf("BlockDevice annotation:%v should be removed after the retry", ndm.OpenEBSRetryCleanup)
+			}
+		})
+	}
+}
+
+func TestClearedRetainedDevice(t *testing.T) {
+	// a retained BD which is cleared by the administrator becomes Unclaimed,
+	// but still has the finalizer
+	bd := GetFakeDeviceObject()
+	bd.Finalizers = []string{util.BlockDeviceFinalizer}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(bd).Build()
+	r := &BlockDeviceReconciler{Client: cl, Scheme: scheme.Scheme, Recorder: record.NewFakeRecorder(10)}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      deviceName,
+			Namespace: namespace,
+		},
+	}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	gotBD := &openebsv1alpha1.BlockDevice{}
+	if err := cl.Get(context.TODO(), req.NamespacedName, gotBD); err != nil {
+		t.Fatalf("get deviceInstance : (%v)", err)
+	}
+	if len(gotBD.Finalizers) != 0 {
+		t.Fatalf("BlockDevice finalizers:%v should be removed from an unclaimed BlockDevice", gotBD.Finalizers)
+	}
 }
 
 func GetFakeDeviceObject() *openebsv1alpha1.BlockDevice {
@@ -17,15 +17,16 @@ limitations under the License.
 package blockdeviceclaim
 
 import (
-
 	"context"
 	"fmt"
+	"strings"
 
 	util2 "github.com/openebs/node-disk-manager/pkg/controllers/util"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -135,6 +136,7 @@ func (r *BlockDeviceClaimReconciler) claimDeviceForBlockDeviceClaim(instance *ap
 			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "InvalidCapacity", "Invalid Capacity requested")
 			//Update deviceClaim CR with pending status
 			instance.Status.Phase = apis.BlockDeviceClaimStatusPending
+			setSelectionFailedConditions(instance, apis.BlockDeviceClaimReasonInvalidCapacity, "Invalid Capacity requested")
 			err1 := r.updateClaimStatus(instance.Status.Phase, instance)
 			if err1 != nil {
 				klog.Errorf("%s requested an invalid capacity: %v", instance.Name, err1)
@@ -154,25 +156,134 @@ func (r *BlockDeviceClaimReconciler) claimDeviceForBlockDeviceClaim(instance *ap
 		return err
 	}
 
-	selectedDevice, err := config.Filter(bdList)
+	// blockdevices claimed during an earlier, interrupted attempt to bind this
+	// claim are considered unclaimed while selecting, so that they can be
+	// selected again. The selection is made on a copy of the list, and nothing
+	// is written till the whole set of blockdevices has been selected.
+	candidates := bdList.DeepCopy()
+	var previouslyClaimed []apis.BlockDevice
+	for i := range bdList.Items {
+		if r.isDeviceRequestedByThisDeviceClaim(instance, bdList.Items[i]) {
+			previouslyClaimed = append(previouslyClaimed, bdList.Items[i])
+			candidates.Items[i].Spec.ClaimRef = nil
+			candidates.Items[i].Status.ClaimState = apis.BlockDeviceUnclaimed
+		}
+	}
+
+	selectedDevices, err := config.FilterDevices(candidates)
+
+	// in dry run mode, only the result of the selection is reported
+	if IsDryRun(instance) {
+		r.reportDryRun(instance, selectedDevices, err, config.Rejections.Summary(len(bdList.Items)))
+		return r.updateClaimStatus(instance.Status.Phase, instance)
+	}
+	meta.RemoveStatusCondition(&instance.Status.Conditions, apis.BlockDeviceClaimConditionDryRun)
+
 	if err != nil {
 		klog.Errorf("Error selecting device for %s: %v", instance.Name, err)
+		// the blockdevices of the earlier attempt are no longer held by the claim
+		if err := r.unclaimBlockDevices(previouslyClaimed, nil, instance); err != nil {
+			return err
+		}
 		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "SelectionFailed", err.Error())
 		instance.Status.Phase = apis.BlockDeviceClaimStatusPending
+		setSelectionFailedConditions(instance, blockdevice.GetSelectionFailureReason(err), err.Error())
 	} else {
-		instance.Spec.BlockDeviceName = selectedDevice.Name
-		instance.Status.Phase = apis.BlockDeviceClaimStatusDone
-		err = r.claimBlockDevice(selectedDevice, instance)
+		// the blockdevices are claimed using the objects that were listed, and
+		// not the copies used for the selection
+		listed := make(map[string]apis.BlockDevice, len(bdList.Items))
+		for _, bd := range bdList.Items {
+			listed[bd.Name] = bd
+		}
+		toClaim := make([]apis.BlockDevice, 0, len(selectedDevices))
+		for _, bd := range selectedDevices {
+			toClaim = append(toClaim, listed[bd.Name])
+		}
+		err = r.claimBlockDevices(toClaim, instance)
 		if err != nil {
 			return err
 		}
-		r.Recorder.Eventf(selectedDevice, corev1.EventTypeNormal, "BlockDeviceClaimed", "BlockDevice claimed by %v", instance.Name)
-		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceClaimed", "BlockDevice: %v claimed", instance.Spec.BlockDeviceName)
+		if err := r.unclaimBlockDevices(previouslyClaimed, toClaim, instance); err != nil {
+			return err
+		}
+		instance.Spec.BlockDeviceName = selectedDevices[0].Name
+		if len(selectedDevices) > 1 {
+			instance.Spec.BlockDeviceNames = getBlockDeviceNames(selectedDevices)
+		}
+		instance.Status.Phase = apis.BlockDeviceClaimStatusDone
+		setBoundConditions(instance)
+		for i := range toClaim {
+			r.Recorder.Eventf(&toClaim[i], corev1.EventTypeNormal, "BlockDeviceClaimed", "BlockDevice claimed by %v", instance.Name)
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceClaimed", "BlockDevice: %v claimed", toClaim[i].Name)
+		}
 	}
 
 	return r.updateClaimStatus(instance.Status.Phase, instance)
 }
 
+// getBlockDeviceNames returns the names of the given blockdevices
+func getBlockDeviceNames(bds []apis.BlockDevice) []string {
+	names := make([]string, 0, len(bds))
+	for _, bd := range bds {
+		names = append(names, bd.Name)
+	}
+	return names
+}
+
+// getClaimedBlockDeviceNames returns the names of all the blockdevices
+// bound to the claim
+func getClaimedBlockDeviceNames(instance *apis.BlockDeviceClaim) []string {
+	if len(instance.Spec.BlockDeviceNames) != 0 {
+		return instance.Spec.BlockDeviceNames
+	}
+	return []string{instance.Spec.BlockDeviceName}
+}
+
+// reportDryRun records the result of evaluating the claim in dry run mode as an
+// event and a condition on the claim. The selected blockdevice is not claimed.
+func (r *BlockDeviceClaimReconciler) reportDryRun(instance *apis.BlockDeviceClaim,
+	selectedDevices []apis.BlockDevice, selectionErr error, summary string) {
+	instance.Status.Phase = apis.BlockDeviceClaimStatusPending
+	if selectionErr != nil {
+		klog.Infof("Dry run for %s failed: %v", instance.Name, selectionErr)
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "DryRunSelectionFailed", selectionErr.Error())
+		util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+			apis.BlockDeviceClaimConditionDryRun, v1.ConditionFalse,
+			blockdevice.GetSelectionFailureReason(selectionErr), selectionErr.Error())
+		return
+	}
+	message := fmt.Sprintf("BlockDevice %s would be claimed (%s)",
+		strings.Join(getBlockDeviceNames(selectedDevices), ", "), summary)
+	klog.Infof("Dry run for %s: %s", instance.Name, message)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "DryRunSucceeded", message)
+	util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+		apis.BlockDeviceClaimConditionDryRun, v1.ConditionTrue,
+		apis.BlockDeviceClaimReasonDeviceAvailable, message)
+}
+
+// setSelectionFailedConditions sets the conditions on the claim when a
+// blockdevice could not be selected
+func setSelectionFailedConditions(instance *apis.BlockDeviceClaim, reason, message string) {
+	util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+		apis.BlockDeviceClaimConditionSelectionFailed, v1.ConditionTrue, reason, message)
+	util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+		apis.BlockDeviceClaimConditionBound, v1.ConditionFalse, apis.BlockDeviceClaimReasonPending,
+		"BlockDeviceClaim is not bound to any blockdevice")
+}
+
+// setBoundConditions sets the conditions on the claim when it is bound
+// to a blockdevice
+func setBoundConditions(instance *apis.BlockDeviceClaim) {
+	message := fmt.Sprintf("BlockDeviceClaim is bound to %s",
+		strings.Join(getClaimedBlockDeviceNames(instance), ", "))
+	util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+		apis.BlockDeviceClaimConditionSelectionFailed, v1.ConditionFalse,
+		apis.BlockDeviceClaimReasonBlockDeviceClaimed, message)
+	util2.SetCondition(&instance.Status.Conditions, instance.Generation,
+		apis.BlockDeviceClaimConditionBound, v1.ConditionTrue,
+		apis.BlockDeviceClaimReasonBlockDeviceClaimed, message)
+}
+
 // FinalizerHandling removes the finalizer from the claim resource
 func (r *BlockDeviceClaimReconciler) FinalizerHandling(instance *apis.BlockDeviceClaim) error {
 
@@ -191,11 +302,16 @@ func (r *BlockDeviceClaimReconciler) FinalizerHandling(instance *apis.BlockDevic
 				instance.Spec.BlockDeviceName, instance.Name, err)
 			return err
 		}
-		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceReleased", "BlockDevice: %v is released", instance.Spec.BlockDeviceName)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceReleased", "BlockDevice: %v is released",
+			strings.Join(getClaimedBlockDeviceNames(instance), ", "))
 
 		// Remove finalizer from list and update it.
-		instance.ObjectMeta.Finalizers = util.RemoveString(instance.ObjectMeta.Finalizers, util2.BlockDeviceClaimFinalizer)
-		if err := r.Client.Update(context.TODO(), instance); err != nil {
+		err := util2.UpdateBlockDeviceClaim(context.TODO(), r.Client, instance, util2.OperatorFieldManager,
+			func(bdc *apis.BlockDeviceClaim) error {
+				bdc.Finalizers = util.RemoveString(bdc.Finalizers, util2.BlockDeviceClaimFinalizer)
+				return nil
+			})
+		if err != nil {
 			klog.Errorf("Error removing finalizer from %s", instance.Name)
 			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UpdateOperationFailed", "Unable to remove Finalizer, due to error: %v", err.Error())
 			return err
@@ -205,20 +321,46 @@ func (r *BlockDeviceClaimReconciler) FinalizerHandling(instance *apis.BlockDevic
 	return nil
 }
 
+// updateClaimStatus patches the result of evaluating the claim, i.e. the names of
+// the claimed blockdevices, the status and the finalizer, on the latest version of
+// the claim. The result is discarded if the spec of the claim was changed since
+// it was evaluated.
 func (r *BlockDeviceClaimReconciler) updateClaimStatus(phase apis.DeviceClaimPhase,
 	instance *apis.BlockDeviceClaim) error {
 	switch phase {
 	case apis.BlockDeviceClaimStatusDone:
-		instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, util2.BlockDeviceClaimFinalizer)
-		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceClaimBound", "BlockDeviceClaim is bound to %v", instance.Spec.BlockDeviceName)
+		if !util.Contains(instance.Finalizers, util2.BlockDeviceClaimFinalizer) {
+			instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, util2.BlockDeviceClaimFinalizer)
+		}
+	}
 
+	latest := &apis.BlockDeviceClaim{}
+	err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(instance), latest)
+	if err == nil {
+		err = util2.UpdateBlockDeviceClaim(context.TODO(), r.Client, latest, util2.OperatorFieldManager,
+			func(bdc *apis.BlockDeviceClaim) error {
+				if bdc.Generation != instance.Generation {
+					return fmt.Errorf("spec of BDC %s was changed while it was being evaluated", bdc.Name)
+				}
+				if util.Contains(instance.Finalizers, util2.BlockDeviceClaimFinalizer) &&
+					!util.Contains(bdc.Finalizers, util2.BlockDeviceClaimFinalizer) {
+					bdc.Finalizers = append(bdc.Finalizers, util2.BlockDeviceClaimFinalizer)
+				}
+				bdc.Spec.BlockDeviceName = instance.Spec.BlockDeviceName
+				bdc.Spec.BlockDeviceNames = instance.Spec.BlockDeviceNames
+				bdc.Status = instance.Status
+				return nil
+			})
 	}
-	// Update BlockDeviceClaim CR
-	err := r.Client.Update(context.TODO(), instance)
 	if err != nil {
 		return fmt.Errorf("error updating status of BDC : %s, %v", instance.ObjectMeta.Name, err)
 	}
+	latest.DeepCopyInto(instance)
 
+	if phase == apis.BlockDeviceClaimStatusDone {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "BlockDeviceClaimBound", "BlockDeviceClaim is bound to %v",
+			strings.Join(getClaimedBlockDeviceNames(instance), ", "))
+	}
 	return nil
 }
 
@@ -259,50 +401,97 @@ func (r *BlockDeviceClaimReconciler) releaseClaimedBlockDevice(
 		return err
 	}
 
-	// Check if same deviceclaim holding the ObjRef
-	var claimedBd *apis.BlockDevice
+	// Check if same deviceclaim holding the ObjRef. A claim can hold more than
+	// one blockdevice, all of them are released together.
+	var claimedBds []*apis.BlockDevice
 	for i := range bdList.Items {
 		// Found a blockdevice ObjRef with BlockDeviceClaim, Clear
 		// ObjRef and mark blockdevice released in etcd
 		if r.isDeviceRequestedByThisDeviceClaim(instance, bdList.Items[i]) {
-			claimedBd = &bdList.Items[i]
-			break
+			claimedBds = append(claimedBds, &bdList.Items[i])
 		}
 	}
 	// This case occurs when a claimed BD is manually deleted by removing the finalizer.
-	// If this check is not performed, the NDM operator will continuously crash, because it
-	// will try to release a non existent BD.
-	if claimedBd == nil {
+	// There is nothing left to release, and the claim should not be blocked from being
+	// deleted because of it.
+	if len(claimedBds) == 0 {
 		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "BlockDeviceNotFound", "BlockDevice %s not found for releasing", instance.Spec.BlockDeviceName)
-		klog.Errorf("could not find blockdevice for claim: %s", instance.Name)
-		return fmt.Errorf("blockdevice: %s not found for releasing from bdc: %s", instance.Spec.BlockDeviceName, instance.Name)
+		klog.Warningf("could not find blockdevice for claim: %s, nothing to release", instance.Name)
+		return nil
 	}
 
-	dvr := claimedBd.DeepCopy()
-	dvr.Spec.ClaimRef = nil
-	dvr.Status.ClaimState = apis.BlockDeviceReleased
-
-	err = r.Client.Update(context.TODO(), dvr)
-	if err != nil {
-		klog.Errorf("Error updating ClaimRef of %s: %v", dvr.Name, err)
-		return err
+	for _, claimedBd := range claimedBds {
+		dvr := claimedBd.DeepCopy()
+		var reclaimPolicy apis.ReclaimPolicy
+		released := true
+		err = util2.UpdateBlockDevice(context.TODO(), r.Client, dvr, util2.OperatorFieldManager,
+			func(bd *apis.BlockDevice) error {
+				// the BD was released or claimed by another claim after it was
+				// listed, there is nothing left to release
+				if !r.isDeviceRequestedByThisDeviceClaim(instance, *bd) {
+					released = false
+					return nil
+				}
+				released = true
+				bd.Spec.ClaimRef = nil
+
+				reclaimPolicy = GetReclaimPolicy(bd, instance)
+				switch reclaimPolicy {
+				case apis.ReclaimPolicyRetain:
+					// the BD is not cleaned up, so that the data can be used later
+					bd.Status.ClaimState = apis.BlockDeviceRetained
+				case apis.ReclaimPolicyRecycle:
+					bd.Status.ClaimState = apis.BlockDeviceReleased
+					// the cleanup method requested by the claim is used when cleaning up the BD
+					bd.Status.CleanupMethod = instance.Spec.CleanupMethod
+				default:
+					bd.Status.ClaimState = apis.BlockDeviceReleased
+				}
+				return nil
+			})
+		if err != nil {
+			klog.Errorf("Error updating ClaimRef of %s: %v", dvr.Name, err)
+			return err
+		}
+		if !released {
+			klog.Infof("%s is no longer claimed by %s, treating it as released", dvr.Name, instance.Name)
+			continue
+		}
+		klog.Infof("%s released from %s with %s reclaim policy", dvr.Name, instance.Name, reclaimPolicy)
+		if reclaimPolicy == apis.ReclaimPolicyRetain {
+			r.Recorder.Eventf(dvr, corev1.EventTypeNormal, "BlockDeviceRetained", "Released from BDC: %v, data retained", instance.Name)
+		} else {
+			r.Recorder.Eventf(dvr, corev1.EventTypeNormal, "BlockDeviceCleanUpInProgress", "Released from BDC: %v", instance.Name)
+		}
 	}
-	r.Recorder.Eventf(dvr, corev1.EventTypeNormal, "BlockDeviceCleanUpInProgress", "Released from BDC: %v", instance.Name)
 
 	return nil
 }
 
-// claimBlockDevice is used to claim the passed on blockdevice
+// claimBlockDevice is used to claim the passed on blockdevice. A blockdevice which
+// is already claimed by the claim is left as it is.
 func (r *BlockDeviceClaimReconciler) claimBlockDevice(bd *apis.BlockDevice, instance *apis.BlockDeviceClaim) error {
 	claimRef, err := reference.GetReference(r.Scheme, instance)
 	if err != nil {
 		return fmt.Errorf("error getting claim reference for BDC:%s, %v", instance.ObjectMeta.Name, err)
 	}
-	// add finalizer to BlockDevice to prevent accidental deletion of BD
-	bd.Finalizers = append(bd.Finalizers, util2.BlockDeviceFinalizer)
-	bd.Spec.ClaimRef = claimRef
-	bd.Status.ClaimState = apis.BlockDeviceClaimed
-	err = r.Client.Update(context.TODO(), bd)
+	err = util2.UpdateBlockDevice(context.TODO(), r.Client, bd, util2.OperatorFieldManager,
+		func(bd *apis.BlockDevice) error {
+			if r.isDeviceRequestedByThisDeviceClaim(instance, *bd) {
+				return nil
+			}
+			// the BD may have been claimed by another claim since it was selected
+			if bd.Status.ClaimState != apis.BlockDeviceUnclaimed {
+				return fmt.Errorf("BD %s is %s", bd.Name, bd.Status.ClaimState)
+			}
+			// add finalizer to BlockDevice to prevent accidental deletion of BD
+			if !util.Contains(bd.Finalizers, util2.BlockDeviceFinalizer) {
+				bd.Finalizers = append(bd.Finalizers, util2.BlockDeviceFinalizer)
+			}
+			bd.Spec.ClaimRef = claimRef
+			bd.Status.ClaimState = apis.BlockDeviceClaimed
+			return nil
+		})
 	if err != nil {
 		return fmt.Errorf("error while updating BD:%s, %v", bd.ObjectMeta.Name, err)
 	}
@@ -310,6 +499,74 @@ func (r *BlockDeviceClaimReconciler) claimBlockDevice(bd *apis.BlockDevice, inst
 	return nil
 }
 
+// claimBlockDevices is used to claim all the passed on blockdevices. Either all
+// the blockdevices are claimed, or none. If claiming any of the blockdevices fails,
+// the blockdevices that were claimed in this attempt are reverted to Unclaimed.
+func (r *BlockDeviceClaimReconciler) claimBlockDevices(bds []apis.BlockDevice, instance *apis.BlockDeviceClaim) error {
+	wasClaimed := make([]bool, len(bds))
+	for i := range bds {
+		wasClaimed[i] = r.isDeviceRequestedByThisDeviceClaim(instance, bds[i])
+	}
+	for i := range bds {
+		err := r.claimBlockDevice(&bds[i], instance)
+		if err == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if wasClaimed[j] {
+				continue
+			}
+			if err1 := r.unclaimBlockDevice(&bds[j]); err1 != nil {
+				klog.Errorf("error reverting claim on %s for %s: %v", bds[j].Name, instance.Name, err1)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// unclaimBlockDevices reverts the blockdevices claimed by the claim, which are
+// not among the blockdevices to be kept, back to Unclaimed state
+func (r *BlockDeviceClaimReconciler) unclaimBlockDevices(bds, keep []apis.BlockDevice,
+	instance *apis.BlockDeviceClaim) error {
+	kept := make(map[string]bool, len(keep))
+	for _, bd := range keep {
+		kept[bd.Name] = true
+	}
+	for i := range bds {
+		if kept[bds[i].Name] {
+			continue
+		}
+		if err := r.unclaimBlockDevice(&bds[i]); err != nil {
+			klog.Errorf("error reverting claim on %s for %s: %v", bds[i].Name, instance.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// unclaimBlockDevice reverts a blockdevice that was claimed by claimBlockDevice
+// back to Unclaimed state
+func (r *BlockDeviceClaimReconciler) unclaimBlockDevice(bd *apis.BlockDevice) error {
+	claimRef := bd.Spec.ClaimRef
+	err := util2.UpdateBlockDevice(context.TODO(), r.Client, bd, util2.OperatorFieldManager,
+		func(bd *apis.BlockDevice) error {
+			if bd.Status.ClaimState != apis.BlockDeviceClaimed || bd.Spec.ClaimRef == nil ||
+				claimRef == nil || bd.Spec.ClaimRef.UID != claimRef.UID {
+				return fmt.Errorf("BD %s is no longer claimed by the same BDC", bd.Name)
+			}
+			bd.Finalizers = util.RemoveString(bd.Finalizers, util2.BlockDeviceFinalizer)
+			bd.Spec.ClaimRef = nil
+			bd.Status.ClaimState = apis.BlockDeviceUnclaimed
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("error while updating BD:%s, %v", bd.ObjectMeta.Name, err)
+	}
+	klog.Infof("%s reverted to %s", bd.Name, apis.BlockDeviceUnclaimed)
+	return nil
+}
+
 // GetBlockDevice get block device resource from etcd
 func (r *BlockDeviceClaimReconciler) GetBlockDevice(name string) (*apis.BlockDevice, error) {
 	bd := &apis.BlockDevice{}
@@ -360,6 +617,34 @@ func IsReconcileDisabled(bdc *apis.BlockDeviceClaim) bool {
 	return bdc.Annotations[ndm.OpenEBSReconcile] == "false"
 }
 
+// IsDryRun is used to check if the BlockDeviceClaim is to be only evaluated,
+// without claiming a BlockDevice
+func IsDryRun(bdc *apis.BlockDeviceClaim) bool {
+	return util.CheckTruthy(bdc.Annotations[ndm.OpenEBSDryRun])
+}
+
+// GetReclaimPolicy returns the reclaim policy to be used when the BD is released
+// by the claim. The reclaim policy set using the annotation on the BD takes precedence
+// over the policy in the claim. If neither is set, Recycle is used if the claim
+// requests a cleanup method, else Delete.
+func GetReclaimPolicy(bd *apis.BlockDevice, bdc *apis.BlockDeviceClaim) apis.ReclaimPolicy {
+	if policy, ok := bd.Annotations[ndm.OpenEBSReclaimPolicy]; ok {
+		switch reclaimPolicy := apis.ReclaimPolicy(policy); reclaimPolicy {
+		case apis.ReclaimPolicyDelete, apis.ReclaimPolicyRetain, apis.ReclaimPolicyRecycle:
+			return reclaimPolicy
+		default:
+			klog.Warningf("ignoring invalid reclaim policy %q on %s", policy, bd.Name)
+		}
+	}
+	if bdc.Spec.ReclaimPolicy != "" {
+		return bdc.Spec.ReclaimPolicy
+	}
+	if bdc.Spec.CleanupMethod != "" {
+		return apis.ReclaimPolicyRecycle
+	}
+	return apis.ReclaimPolicyDelete
+}
+
 // generateSelector creates the label selector for BlockDevices from
 // the BlockDeviceClaim spec
 func generateSelector(bdc apis.BlockDeviceClaim) *v1.LabelSelector {
@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -126,6 +127,10 @@ func TestBlockDeviceClaimController(t *testing.T) {
 		t.Log("reconcile did not requeue request as expected")
 	}
 	r.CheckBlockDeviceClaimStatus(t, req, openebsv1alpha1.BlockDeviceClaimStatusDone)
+	r.CheckBlockDeviceClaimCondition(t, req, openebsv1alpha1.BlockDeviceClaimConditionBound,
+		metav1.ConditionTrue, openebsv1alpha1.BlockDeviceClaimReasonBlockDeviceClaimed)
+	r.CheckBlockDeviceClaimCondition(t, req, openebsv1alpha1.BlockDeviceClaimConditionSelectionFailed,
+		metav1.ConditionFalse, openebsv1alpha1.BlockDeviceClaimReasonBlockDeviceClaimed)
 
 	r.DeviceRequestedHappyPathTest(t, req)
 	//TODO: Need to find a way to update deletion timestamp
@@ -258,6 +263,10 @@ func (r *BlockDeviceClaimReconciler) InvalidCapacityTest(t *testing.T,
 		t.Errorf("Get devRequestInst: (%v)", err)
 	}
 	r.CheckBlockDeviceClaimStatus(t, req, openebsv1alpha1.BlockDeviceClaimStatusPending)
+	r.CheckBlockDeviceClaimCondition(t, req, openebsv1alpha1.BlockDeviceClaimConditionSelectionFailed,
+		metav1.ConditionTrue, openebsv1alpha1.BlockDeviceClaimReasonInvalidCapacity)
+	r.CheckBlockDeviceClaimCondition(t, req, openebsv1alpha1.BlockDeviceClaimConditionBound,
+		metav1.ConditionFalse, openebsv1alpha1.BlockDeviceClaimReasonPending)
 }
 
 func TestBlockDeviceClaimsLabelSelector(t *testing.T) {
@@ -364,6 +373,416 @@ func TestBlockDeviceClaimsLabelSelector(t *testing.T) {
 	}
 }
 
+func TestBlockDeviceClaimDryRun(t *testing.T) {
+	tests := map[string]struct {
+		bdCapacity uint64
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		"device available for the claim": {
+			bdCapacity: capacity,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: openebsv1alpha1.BlockDeviceClaimReasonDeviceAvailable,
+		},
+		"no device available for the claim": {
+			bdCapacity: capacity / 2,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: openebsv1alpha1.BlockDeviceClaimReasonNoMatchingDevices,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, s := CreateFakeClient()
+			r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      blockDeviceClaimName,
+					Namespace: namespace,
+				},
+			}
+
+			bd := GetFakeDeviceObject(deviceName, test.bdCapacity)
+			bd.Labels[kubernetes.KubernetesHostNameLabel] = fakeHostName
+			if err := cl.Create(context.TODO(), bd); err != nil {
+				t.Fatal(err)
+			}
+			bdc := GetFakeBlockDeviceClaimObject()
+			bdc.Annotations = map[string]string{ndm.OpenEBSDryRun: "true"}
+			if err := cl.Create(context.TODO(), bdc); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+
+			r.CheckBlockDeviceClaimStatus(t, req, openebsv1alpha1.BlockDeviceClaimStatusPending)
+			r.CheckBlockDeviceClaimCondition(t, req, openebsv1alpha1.BlockDeviceClaimConditionDryRun,
+				test.wantStatus, test.wantReason)
+
+			// the blockdevice should not be claimed in dry run mode
+			gotBD := &openebsv1alpha1.BlockDevice{}
+			err = cl.Get(context.TODO(), types.NamespacedName{Name: deviceName, Namespace: namespace}, gotBD)
+			assert.NoError(t, err)
+			assert.Equal(t, openebsv1alpha1.BlockDeviceUnclaimed, gotBD.Status.ClaimState)
+			assert.Nil(t, gotBD.Spec.ClaimRef)
+		})
+	}
+}
+
+func TestBlockDeviceClaimWithCount(t *testing.T) {
+	tests := map[string]struct {
+		count       int32
+		wantPhase   openebsv1alpha1.DeviceClaimPhase
+		wantClaimed int
+	}{
+		"all requested devices are available": {
+			count:       2,
+			wantPhase:   openebsv1alpha1.BlockDeviceClaimStatusDone,
+			wantClaimed: 2,
+		},
+		"fewer devices than requested are available": {
+			count:       3,
+			wantPhase:   openebsv1alpha1.BlockDeviceClaimStatusPending,
+			wantClaimed: 0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, s := CreateFakeClient()
+			r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      blockDeviceClaimName,
+					Namespace: namespace,
+				},
+			}
+
+			for _, bdName := range []string{"bd-1", "bd-2"} {
+				bd := GetFakeDeviceObject(bdName, capacity)
+				bd.Labels[kubernetes.KubernetesHostNameLabel] = fakeHostName
+				if err := cl.Create(context.TODO(), bd); err != nil {
+					t.Fatal(err)
+				}
+			}
+			bdc := GetFakeBlockDeviceClaimObject()
+			bdc.Spec.Count = test.count
+			bdc.Spec.CleanupMethod = openebsv1alpha1.CleanupMethodZero
+			if err := cl.Create(context.TODO(), bdc); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+			r.CheckBlockDeviceClaimStatus(t, req, test.wantPhase)
+
+			bdList := &openebsv1alpha1.BlockDeviceList{}
+			assert.NoError(t, cl.List(context.TODO(), bdList))
+			claimed := 0
+			for _, bd := range bdList.Items {
+				if bd.Status.ClaimState == openebsv1alpha1.BlockDeviceClaimed {
+					claimed++
+				}
+			}
+			assert.Equal(t, test.wantClaimed, claimed)
+
+			if test.wantPhase != openebsv1alpha1.BlockDeviceClaimStatusDone {
+				return
+			}
+
+			// all the devices should be released together, and cleaned up
+			// using the method requested by the claim
+			gotBDC := &openebsv1alpha1.BlockDeviceClaim{}
+			assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, gotBDC))
+			assert.Equal(t, []string{"bd-1", "bd-2"}, gotBDC.Spec.BlockDeviceNames)
+			assert.NoError(t, r.releaseClaimedBlockDevice(gotBDC))
+
+			assert.NoError(t, cl.List(context.TODO(), bdList))
+			for _, bd := range bdList.Items {
+				assert.Equal(t, openebsv1alpha1.BlockDeviceReleased, bd.Status.ClaimState)
+				assert.Equal(t, openebsv1alpha1.CleanupMethodZero, bd.Status.CleanupMethod)
+			}
+		})
+	}
+}
+
+func TestClaimWithDevicesOfEarlierAttempt(t *testing.T) {
+	tests := map[string]struct {
+		count          int32
+		dryRun         bool
+		wantPhase      openebsv1alpha1.DeviceClaimPhase
+		wantClaimState map[string]openebsv1alpha1.DeviceClaimState
+	}{
+		"device of the earlier attempt is claimed again": {
+			count:     2,
+			wantPhase: openebsv1alpha1.BlockDeviceClaimStatusDone,
+			wantClaimState: map[string]openebsv1alpha1.DeviceClaimState{
+				"bd-1": openebsv1alpha1.BlockDeviceClaimed,
+				"bd-2": openebsv1alpha1.BlockDeviceClaimed,
+			},
+		},
+		"device of the earlier attempt is released if the selection fails": {
+			count:     3,
+			wantPhase: openebsv1alpha1.BlockDeviceClaimStatusPending,
+			wantClaimState: map[string]openebsv1alpha1.DeviceClaimState{
+				"bd-1": openebsv1alpha1.BlockDeviceUnclaimed,
+				"bd-2": openebsv1alpha1.BlockDeviceUnclaimed,
+			},
+		},
+		"devices are not modified in dry run mode": {
+			count:     2,
+			dryRun:    true,
+			wantPhase: openebsv1alpha1.BlockDeviceClaimStatusPending,
+			wantClaimState: map[string]openebsv1alpha1.DeviceClaimState{
+				"bd-1": openebsv1alpha1.BlockDeviceClaimed,
+				"bd-2": openebsv1alpha1.BlockDeviceUnclaimed,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, s := CreateFakeClient()
+			r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      blockDeviceClaimName,
+					Namespace: namespace,
+				},
+			}
+
+			// bd-1 was claimed by an earlier attempt, which was interrupted
+			// before the claim was bound
+			for _, bdName := range []string{"bd-1", "bd-2"} {
+				bd := GetFakeDeviceObject(bdName, capacity)
+				bd.Labels[kubernetes.KubernetesHostNameLabel] = fakeHostName
+				if bdName == "bd-1" {
+					bd.Spec.ClaimRef = &corev1.ObjectReference{
+						Kind: "BlockDeviceClaim",
+						Name: blockDeviceClaimName,
+						UID:  blockDeviceClaimUID,
+					}
+					bd.Status.ClaimState = openebsv1alpha1.BlockDeviceClaimed
+				}
+				if err := cl.Create(context.TODO(), bd); err != nil {
+					t.Fatal(err)
+				}
+			}
+			bdc := GetFakeBlockDeviceClaimObject()
+			bdc.Spec.Count = test.count
+			if test.dryRun {
+				bdc.Annotations = map[string]string{ndm.OpenEBSDryRun: "true"}
+			}
+			if err := cl.Create(context.TODO(), bdc); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+			r.CheckBlockDeviceClaimStatus(t, req, test.wantPhase)
+
+			for bdName, wantClaimState := range test.wantClaimState {
+				gotBD := &openebsv1alpha1.BlockDevice{}
+				assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: bdName}, gotBD))
+				assert.Equal(t, wantClaimState, gotBD.Status.ClaimState, bdName)
+			}
+		})
+	}
+}
+
+func TestClaimBlockDevicesRollback(t *testing.T) {
+	cl, s := CreateFakeClient()
+	r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	var bds []openebsv1alpha1.BlockDevice
+	for _, bdName := range []string{"bd-1", "bd-2"} {
+		bd := GetFakeDeviceObject(bdName, capacity)
+		if err := cl.Create(context.TODO(), bd); err != nil {
+			t.Fatal(err)
+		}
+		bds = append(bds, *bd)
+	}
+	// bd-2 is claimed by another claim after it was selected
+	otherBD := bds[1].DeepCopy()
+	otherBD.Spec.ClaimRef = &corev1.ObjectReference{Kind: "BlockDeviceClaim", Name: "other-bdc", UID: "other-bdc-uid"}
+	otherBD.Status.ClaimState = openebsv1alpha1.BlockDeviceClaimed
+	assert.NoError(t, cl.Update(context.TODO(), otherBD))
+
+	assert.Error(t, r.claimBlockDevices(bds, GetFakeBlockDeviceClaimObject()))
+
+	// the claim on bd-1 is reverted, and bd-2 is left to the other claim
+	gotBD := &openebsv1alpha1.BlockDevice{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "bd-1"}, gotBD))
+	assert.Equal(t, openebsv1alpha1.BlockDeviceUnclaimed, gotBD.Status.ClaimState)
+	assert.Nil(t, gotBD.Spec.ClaimRef)
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "bd-2"}, gotBD))
+	assert.Equal(t, "other-bdc", gotBD.Spec.ClaimRef.Name)
+}
+
+func TestReleaseWithReclaimPolicy(t *testing.T) {
+	tests := map[string]struct {
+		reclaimPolicy     openebsv1alpha1.ReclaimPolicy
+		cleanupMethod     openebsv1alpha1.CleanupMethod
+		bdAnnotations     map[string]string
+		wantClaimState    openebsv1alpha1.DeviceClaimState
+		wantCleanupMethod openebsv1alpha1.CleanupMethod
+	}{
+		"no reclaim policy": {
+			wantClaimState: openebsv1alpha1.BlockDeviceReleased,
+		},
+		"delete does not use the cleanup method of the claim": {
+			reclaimPolicy:  openebsv1alpha1.ReclaimPolicyDelete,
+			cleanupMethod:  openebsv1alpha1.CleanupMethodZero,
+			wantClaimState: openebsv1alpha1.BlockDeviceReleased,
+		},
+		"cleanup method without reclaim policy": {
+			cleanupMethod:     openebsv1alpha1.CleanupMethodZero,
+			wantClaimState:    openebsv1alpha1.BlockDeviceReleased,
+			wantCleanupMethod: openebsv1alpha1.CleanupMethodZero,
+		},
+		"recycle": {
+			reclaimPolicy:     openebsv1alpha1.ReclaimPolicyRecycle,
+			cleanupMethod:     openebsv1alpha1.CleanupMethodDiscard,
+			wantClaimState:    openebsv1alpha1.BlockDeviceReleased,
+			wantCleanupMethod: openebsv1alpha1.CleanupMethodDiscard,
+		},
+		"retain": {
+			reclaimPolicy:  openebsv1alpha1.ReclaimPolicyRetain,
+			wantClaimState: openebsv1alpha1.BlockDeviceRetained,
+		},
+		"retain overridden on the blockdevice": {
+			reclaimPolicy:  openebsv1alpha1.ReclaimPolicyRetain,
+			bdAnnotations:  map[string]string{ndm.OpenEBSReclaimPolicy: "Delete"},
+			wantClaimState: openebsv1alpha1.BlockDeviceReleased,
+		},
+		"delete overridden on the blockdevice": {
+			reclaimPolicy:  openebsv1alpha1.ReclaimPolicyDelete,
+			bdAnnotations:  map[string]string{ndm.OpenEBSReclaimPolicy: "Retain"},
+			wantClaimState: openebsv1alpha1.BlockDeviceRetained,
+		},
+		"invalid reclaim policy on the blockdevice": {
+			reclaimPolicy:  openebsv1alpha1.ReclaimPolicyRetain,
+			bdAnnotations:  map[string]string{ndm.OpenEBSReclaimPolicy: "Keep"},
+			wantClaimState: openebsv1alpha1.BlockDeviceRetained,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, s := CreateFakeClient()
+			r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      blockDeviceClaimName,
+					Namespace: namespace,
+				},
+			}
+
+			bd := GetFakeDeviceObject("bd-1", capacity)
+			bd.Labels[kubernetes.KubernetesHostNameLabel] = fakeHostName
+			bd.Annotations = test.bdAnnotations
+			if err := cl.Create(context.TODO(), bd); err != nil {
+				t.Fatal(err)
+			}
+			bdc := GetFakeBlockDeviceClaimObject()
+			bdc.Spec.ReclaimPolicy = test.reclaimPolicy
+			bdc.Spec.CleanupMethod = test.cleanupMethod
+			if err := cl.Create(context.TODO(), bdc); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+			r.CheckBlockDeviceClaimStatus(t, req, openebsv1alpha1.BlockDeviceClaimStatusDone)
+
+			gotBDC := &openebsv1alpha1.BlockDeviceClaim{}
+			assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, gotBDC))
+			assert.NoError(t, r.releaseClaimedBlockDevice(gotBDC))
+
+			gotBD := &openebsv1alpha1.BlockDevice{}
+			assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "bd-1", Namespace: namespace}, gotBD))
+			assert.Equal(t, test.wantClaimState, gotBD.Status.ClaimState)
+			assert.Equal(t, test.wantCleanupMethod, gotBD.Status.CleanupMethod)
+			assert.Nil(t, gotBD.Spec.ClaimRef)
+		})
+	}
+}
+
+func TestReleaseBlockDeviceNotClaimedByClaim(t *testing.T) {
+	cl, s := CreateFakeClient()
+	r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	// the blockdevice was released and claimed by another claim
+	bd := GetFakeDeviceObject("bd-1", capacity)
+	bd.Spec.ClaimRef = &corev1.ObjectReference{Kind: "BlockDeviceClaim", Name: "other-bdc", UID: "other-bdc-uid"}
+	bd.Status.ClaimState = openebsv1alpha1.BlockDeviceClaimed
+	if err := cl.Create(context.TODO(), bd); err != nil {
+		t.Fatal(err)
+	}
+	bdc := GetFakeBlockDeviceClaimObject()
+	bdc.Spec.BlockDeviceName = "bd-1"
+
+	assert.NoError(t, r.releaseClaimedBlockDevice(bdc))
+
+	gotBD := &openebsv1alpha1.BlockDevice{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "bd-1"}, gotBD))
+	assert.Equal(t, openebsv1alpha1.BlockDeviceClaimed, gotBD.Status.ClaimState)
+	assert.Equal(t, "other-bdc", gotBD.Spec.ClaimRef.Name)
+}
+
+func TestClaimBlockDeviceStaleCopy(t *testing.T) {
+	tests := map[string]struct {
+		// change made to the blockdevice after it was selected
+		modify         func(bd *openebsv1alpha1.BlockDevice)
+		wantErr        bool
+		wantClaimRef   string
+		wantLabelValue string
+	}{
+		"blockdevice updated by the daemon": {
+			modify: func(bd *openebsv1alpha1.BlockDevice) {
+				bd.Labels = map[string]string{"example.com/rescanned": "true"}
+			},
+			wantClaimRef:   blockDeviceClaimName,
+			wantLabelValue: "true",
+		},
+		"blockdevice claimed by another claim": {
+			modify: func(bd *openebsv1alpha1.BlockDevice) {
+				bd.Spec.ClaimRef = &corev1.ObjectReference{Kind: "BlockDeviceClaim", Name: "other-bdc", UID: "other-bdc-uid"}
+				bd.Status.ClaimState = openebsv1alpha1.BlockDeviceClaimed
+			},
+			wantErr:      true,
+			wantClaimRef: "other-bdc",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, s := CreateFakeClient()
+			r := &BlockDeviceClaimReconciler{Client: cl, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+			bd := GetFakeDeviceObject("bd-1", capacity)
+			if err := cl.Create(context.TODO(), bd); err != nil {
+				t.Fatal(err)
+			}
+			staleBD := &openebsv1alpha1.BlockDevice{}
+			assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), staleBD))
+
+			latestBD := staleBD.DeepCopy()
+			test.modify(latestBD)
+			assert.NoError(t, cl.Update(context.TODO(), latestBD))
+
+			err := r.claimBlockDevice(staleBD, GetFakeBlockDeviceClaimObject())
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotBD := &openebsv1alpha1.BlockDevice{}
+			assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), gotBD))
+			assert.Equal(t, openebsv1alpha1.BlockDeviceClaimed, gotBD.Status.ClaimState)
+			assert.Equal(t, test.wantClaimRef, gotBD.Spec.ClaimRef.Name)
+			assert.Equal(t, test.wantLabelValue, gotBD.Labels["example.com/rescanned"])
+		})
+	}
+}
+
 func (r *BlockDeviceClaimReconciler) CheckBlockDeviceClaimStatus(t *testing.T,
 	req reconcile.Request, phase openebsv1alpha1.DeviceClaimPhase) {
 
@@ -383,6 +802,23 @@ func (r *BlockDeviceClaimReconciler) CheckBlockDeviceClaimStatus(t *testing.T,
 	}
 }
 
+func (r *BlockDeviceClaimReconciler) CheckBlockDeviceClaimCondition(t *testing.T,
+	req reconcile.Request, conditionType string, status metav1.ConditionStatus, reason string) {
+
+	devRequestCR := &openebsv1alpha1.BlockDeviceClaim{}
+	err := r.Client.Get(context.TODO(), req.NamespacedName, devRequestCR)
+	if err != nil {
+		t.Errorf("get devRequestCR : (%v)", err)
+	}
+
+	condition := meta.FindStatusCondition(devRequestCR.Status.Conditions, conditionType)
+	if condition == nil {
+		t.Fatalf("BlockDeviceClaim Object does not have condition:%v", conditionType)
+	}
+	assert.Equal(t, status, condition.Status)
+	assert.Equal(t, reason, condition.Reason)
+}
+
 func GetFakeBlockDeviceClaimObject() *openebsv1alpha1.BlockDeviceClaim {
 	deviceRequestCR := &openebsv1alpha1.BlockDeviceClaim{}
 
@@ -0,0 +1,267 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	ndm "github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	util2 "github.com/openebs/node-disk-manager/pkg/controllers/util"
+)
+
+// NodeReconciler reconciles the BlockDevices of a node when the node is deleted,
+// or is recreated on a different machine with the same name.
+//
+// The BlockDevices of a deleted node are marked Unknown. When the node is
+// recreated, the system UUID of the node is compared with the one recorded in
+// the BlockDevices. A device which has not been found on the new machine is
+// stale, and remains Unknown till the daemon marks it Inactive. A device which
+// has been found on the new machine has moved, and is reported, since a claim
+// on it was made on the previous machine. The daemon records the system UUID of
+// the previous machine of a moved device in an annotation on the BlockDevice.
+type NodeReconciler struct {
+	Client   client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=openebs.io,resources=blockdevices,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=openebs.io,resources=blockdevices/status,verbs=get;update;patch
+
+// Reconcile updates the BlockDevices of the node based on whether the node
+// exists, and the machine on which it is running.
+func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	node := &corev1.Node{}
+	err := r.Client.Get(ctx, request.NamespacedName, node)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		node = nil
+	}
+
+	bdList := &apis.BlockDeviceList{}
+	if err = r.Client.List(ctx, bdList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for i := range bdList.Items {
+		bd := &bdList.Items[i]
+		if bd.Spec.NodeAttributes.NodeName != request.Name {
+			continue
+		}
+		var bdErr error
+		if node == nil {
+			bdErr = r.markNodeDeleted(ctx, bd, request.Name)
+		} else {
+			bdErr = r.checkNodeMachine(ctx, bd, node)
+		}
+		if bdErr != nil {
+			klog.Errorf("unable to update %s of node %s: %v", bd.Name, request.Name, bdErr)
+			err = bdErr
+		}
+	}
+	return ctrl.Result{}, err
+}
+
+// markNodeDeleted sets the NodeDeleted condition on the BD, and marks an
+// Active BD Unknown, since the daemon on the node is no longer running
+func (r *NodeReconciler) markNodeDeleted(ctx context.Context, bd *apis.BlockDevice, nodeName string) error {
+	if meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeDeleted) &&
+		bd.Status.State != apis.BlockDeviceActive {
+		return nil
+	}
+
+	message := fmt.Sprintf("node %s has been deleted", nodeName)
+	deleted, markedUnknown := false, false
+	err := util2.UpdateBlockDevice(ctx, r.Client, bd, util2.OperatorFieldManager, func(bd *apis.BlockDevice) error {
+		deleted = !meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeDeleted)
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeDeleted,
+			metav1.ConditionTrue, apis.BlockDeviceReasonNodeNotFound, message)
+		markedUnknown = bd.Status.State == apis.BlockDeviceActive
+		if markedUnknown {
+			bd.Status.State = apis.BlockDeviceUnknown
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if markedUnknown {
+		message = fmt.Sprintf("BD marked %s, %s", apis.BlockDeviceUnknown, message)
+	}
+	if deleted || markedUnknown {
+		klog.Infof("%s: %s", bd.Name, message)
+		r.Recorder.Event(bd, corev1.EventTypeWarning, "NodeDeleted", withClaim(bd, message))
+	}
+	return nil
+}
+
+// checkNodeMachine clears the NodeDeleted condition on the BD, and compares the
+// system UUID of the node with the one recorded in the BD, to find out whether
+// the BD is stale or has moved to the new machine of a recreated node
+func (r *NodeReconciler) checkNodeMachine(ctx context.Context, bd *apis.BlockDevice, node *corev1.Node) error {
+	systemUUID := node.Status.NodeInfo.SystemUUID
+	staleMessage := fmt.Sprintf("node %s was recreated on the machine with system UUID %s, and the device was not found on it",
+		node.Name, systemUUID)
+	found, stale, moved := false, false, false
+	err := util2.UpdateBlockDevice(ctx, r.Client, bd, util2.OperatorFieldManager, func(bd *apis.BlockDevice) error {
+		found, stale, moved = false, false, false
+		if meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeDeleted) {
+			util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeDeleted,
+				metav1.ConditionFalse, apis.BlockDeviceReasonNodeFound, fmt.Sprintf("node %s exists", node.Name))
+			found = true
+		}
+
+		isRecreated := meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeRecreated)
+		previousSystemUUID, isMoved := bd.Annotations[ndm.OpenEBSPreviousSystemUUID]
+		switch {
+		case isMoved:
+			// the daemon found the device on the new machine
+			util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeRecreated,
+				metav1.ConditionFalse, apis.BlockDeviceReasonDeviceMoved,
+				fmt.Sprintf("device moved from the machine with system UUID %s to %s",
+					previousSystemUUID, bd.Spec.NodeAttributes.SystemUUID))
+			delete(bd.Annotations, ndm.OpenEBSPreviousSystemUUID)
+			moved = true
+		case isStaleBlockDevice(bd, systemUUID):
+			stale = !isRecreated
+			util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeRecreated,
+				metav1.ConditionTrue, apis.BlockDeviceReasonDeviceStale, staleMessage)
+			if bd.Status.State == apis.BlockDeviceActive {
+				bd.Status.State = apis.BlockDeviceUnknown
+			}
+		case isRecreated:
+			// the node is back on the machine to which the device is attached
+			util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeRecreated,
+				metav1.ConditionFalse, apis.BlockDeviceReasonNodeFound,
+				fmt.Sprintf("node %s is on the machine with system UUID %s", node.Name, systemUUID))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if found {
+		klog.Infof("%s: node %s exists", bd.Name, node.Name)
+		r.Recorder.Eventf(bd, corev1.EventTypeNormal, "NodeFound", "node %s exists", node.Name)
+	}
+	if stale {
+		klog.Infof("%s: %s", bd.Name, staleMessage)
+		r.Recorder.Event(bd, corev1.EventTypeWarning, "StaleBlockDevice", withClaim(bd, staleMessage))
+	}
+	if moved {
+		message := fmt.Sprintf("device was found on node %s, which was recreated on a different machine", node.Name)
+		klog.Infof("%s: %s", bd.Name, message)
+		// a claim on the device was made on the previous machine, and should
+		// be verified by the user
+		eventType := corev1.EventTypeNormal
+		if bd.Status.ClaimState != apis.BlockDeviceUnclaimed {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(bd, eventType, "BlockDeviceMoved", withClaim(bd, message))
+	}
+	return nil
+}
+
+// isStaleBlockDevice checks if the BD was discovered on a different machine than
+// the one with the given system UUID. BDs which do not have the system UUID
+// recorded are not considered stale.
+func isStaleBlockDevice(bd *apis.BlockDevice, systemUUID string) bool {
+	return systemUUID != "" && bd.Spec.NodeAttributes.SystemUUID != "" &&
+		bd.Spec.NodeAttributes.SystemUUID != systemUUID
+}
+
+// withClaim adds the claim of the BD to the message, if the BD is claimed
+func withClaim(bd *apis.BlockDevice, message string) string {
+	if bd.Status.ClaimState == apis.BlockDeviceUnclaimed || bd.Spec.ClaimRef == nil {
+		return message
+	}
+	return fmt.Sprintf("%s, BD is still claimed by %s", message, bd.Spec.ClaimRef.Name)
+}
+
+// SetupWithManager sets up the controller with the Manager. Nodes are reconciled
+// when they are created or deleted, or their system UUID changes. The node of a
+// BD is reconciled when the daemon records that the BD has moved to a new machine.
+func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	nodeChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return oldNode.Status.NodeInfo.SystemUUID != newNode.Status.NodeInfo.SystemUUID
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+	isDeviceMoved := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		bd, ok := obj.(*apis.BlockDevice)
+		if !ok {
+			return false
+		}
+		_, ok = bd.Annotations[ndm.OpenEBSPreviousSystemUUID]
+		return ok
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Node{}, builder.WithPredicates(nodeChanged)).
+		Watches(&source.Kind{Type: &apis.BlockDevice{}},
+			handler.EnqueueRequestsFromMapFunc(blockDeviceToNode),
+			builder.WithPredicates(isDeviceMoved)).
+		Complete(r)
+}
+
+// blockDeviceToNode maps a BD to the request for its node
+func blockDeviceToNode(obj client.Object) []reconcile.Request {
+	bd, ok := obj.(*apis.BlockDevice)
+	if !ok || bd.Spec.NodeAttributes.NodeName == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: bd.Spec.NodeAttributes.NodeName}},
+	}
+}
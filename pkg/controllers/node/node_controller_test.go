@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	ndm "github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+)
+
+const (
+	fakeNamespace = "openebs"
+	fakeNodeName  = "fake-node"
+	fakeBDName    = "blockdevice-example"
+)
+
+func TestReconcile(t *testing.T) {
+	nodeDeleted := metav1.Condition{
+		Type:   apis.BlockDeviceConditionNodeDeleted,
+		Status: metav1.ConditionTrue,
+		Reason: apis.BlockDeviceReasonNodeNotFound,
+	}
+
+	tests := map[string]struct {
+		// system UUID of the node, the node does not exist if empty
+		nodeSystemUUID string
+		bdSystemUUID   string
+		state          apis.BlockDeviceState
+		annotations    map[string]string
+		conditions     []metav1.Condition
+		wantState      apis.BlockDeviceState
+		wantDeleted    metav1.ConditionStatus
+		wantRecreated  metav1.ConditionStatus
+		wantReason     string
+		wantEvent      string
+	}{
+		"node exists on the same machine": {
+			nodeSystemUUID: "uuid-1",
+			bdSystemUUID:   "uuid-1",
+			state:          apis.BlockDeviceActive,
+			wantState:      apis.BlockDeviceActive,
+		},
+		"node deleted": {
+			bdSystemUUID: "uuid-1",
+			state:        apis.BlockDeviceActive,
+			wantState:    apis.BlockDeviceUnknown,
+			wantDeleted:  metav1.ConditionTrue,
+			wantEvent:    "Warning NodeDeleted BD marked Unknown, node fake-node has been deleted, BD is still claimed by fake-bdc",
+		},
+		"node recreated on the same machine": {
+			nodeSystemUUID: "uuid-1",
+			bdSystemUUID:   "uuid-1",
+			state:          apis.BlockDeviceUnknown,
+			conditions:     []metav1.Condition{nodeDeleted},
+			wantState:      apis.BlockDeviceUnknown,
+			wantDeleted:    metav1.ConditionFalse,
+			wantEvent:      "Normal NodeFound node fake-node exists",
+		},
+		"node recreated on a different machine": {
+			nodeSystemUUID: "uuid-2",
+			bdSystemUUID:   "uuid-1",
+			state:          apis.BlockDeviceActive,
+			wantState:      apis.BlockDeviceUnknown,
+			wantRecreated:  metav1.ConditionTrue,
+			wantReason:     apis.BlockDeviceReasonDeviceStale,
+			wantEvent:      "Warning StaleBlockDevice node fake-node was recreated",
+		},
+		"device moved to the recreated node": {
+			nodeSystemUUID: "uuid-2",
+			bdSystemUUID:   "uuid-2",
+			state:          apis.BlockDeviceActive,
+			annotations:    map[string]string{ndm.OpenEBSPreviousSystemUUID: "uuid-1"},
+			wantState:      apis.BlockDeviceActive,
+			wantRecreated:  metav1.ConditionFalse,
+			wantReason:     apis.BlockDeviceReasonDeviceMoved,
+			wantEvent:      "Warning BlockDeviceMoved device was found on node fake-node",
+		},
+		"system UUID not recorded": {
+			nodeSystemUUID: "uuid-2",
+			state:          apis.BlockDeviceActive,
+			wantState:      apis.BlockDeviceActive,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &apis.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        fakeBDName,
+					Namespace:   fakeNamespace,
+					Annotations: test.annotations,
+				},
+				Spec: apis.DeviceSpec{
+					NodeAttributes: apis.NodeAttribute{
+						NodeName:   fakeNodeName,
+						SystemUUID: test.bdSystemUUID,
+					},
+					ClaimRef: &corev1.ObjectReference{Name: "fake-bdc"},
+				},
+				Status: apis.DeviceStatus{
+					ClaimState: apis.BlockDeviceClaimed,
+					State:      test.state,
+					Conditions: test.conditions,
+				},
+			}
+			// a device on another node is not modified
+			otherBD := bd.DeepCopy()
+			otherBD.Name = "blockdevice-other"
+			otherBD.Spec.NodeAttributes.NodeName = "other-node"
+			objs := []client.Object{bd, otherBD}
+			if test.nodeSystemUUID != "" {
+				objs = append(objs, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: fakeNodeName},
+					Status: corev1.NodeStatus{
+						NodeInfo: corev1.NodeSystemInfo{SystemUUID: test.nodeSystemUUID},
+					},
+				})
+			}
+			cl := newFakeClient(t, objs...)
+			recorder := record.NewFakeRecorder(10)
+			r := &NodeReconciler{Client: cl, Recorder: recorder}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: fakeNodeName}}
+			_, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+
+			gotBD := &apis.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), gotBD))
+			assert.Equal(t, test.wantState, gotBD.Status.State)
+			assertCondition(t, gotBD, apis.BlockDeviceConditionNodeDeleted, test.wantDeleted, "")
+			assertCondition(t, gotBD, apis.BlockDeviceConditionNodeRecreated, test.wantRecreated, test.wantReason)
+			// the move is reported only once
+			assert.NotContains(t, gotBD.Annotations, ndm.OpenEBSPreviousSystemUUID)
+
+			gotOtherBD := &apis.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(otherBD), gotOtherBD))
+			assert.Equal(t, otherBD.Status.State, gotOtherBD.Status.State)
+
+			if test.wantEvent == "" {
+				assert.Empty(t, recorder.Events)
+				return
+			}
+			require.Len(t, recorder.Events, 1)
+			gotEvent := <-recorder.Events
+			assert.True(t, strings.HasPrefix(gotEvent, test.wantEvent), gotEvent)
+		})
+	}
+}
+
+func assertCondition(t *testing.T, bd *apis.BlockDevice, conditionType string,
+	wantStatus metav1.ConditionStatus, wantReason string) {
+	condition := meta.FindStatusCondition(bd.Status.Conditions, conditionType)
+	if wantStatus == "" {
+		assert.Nil(t, condition, conditionType)
+		return
+	}
+	require.NotNil(t, condition, conditionType)
+	assert.Equal(t, wantStatus, condition.Status, conditionType)
+	if wantReason != "" {
+		assert.Equal(t, wantReason, condition.Reason, conditionType)
+	}
+}
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, apis.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeheartbeat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	ndm "github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	util2 "github.com/openebs/node-disk-manager/pkg/controllers/util"
+)
+
+const (
+	// EnvNodeHeartbeatGracePeriod is the environment variable for getting the
+	// duration after which the BlockDevices of a node whose heartbeat has not
+	// been renewed are marked Unknown
+	EnvNodeHeartbeatGracePeriod = "NODE_HEARTBEAT_GRACE_PERIOD"
+
+	// DefaultGracePeriod is the default grace period of the node heartbeat
+	DefaultGracePeriod = 2 * time.Minute
+)
+
+// NodeHeartbeatReconciler reconciles the heartbeat leases renewed by the NDM
+// daemons. The BlockDevices of a node whose heartbeat has expired cannot be
+// claimed, and are marked Unknown once the grace period has elapsed. The state
+// of the BlockDevices is restored when the heartbeat is renewed again.
+type NodeHeartbeatReconciler struct {
+	Client   client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// GracePeriod is the duration since the last renewal of the heartbeat,
+	// after which the BlockDevices of the node are marked Unknown
+	GracePeriod time.Duration
+	// Now returns the current time. time.Now is used if it is not set.
+	Now func() time.Time
+}
+
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch
+//+kubebuilder:rbac:groups=openebs.io,resources=blockdevices,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=openebs.io,resources=blockdevices/status,verbs=get;update;patch
+
+// Reconcile checks whether the heartbeat lease of a node has expired, and
+// updates the BlockDevices of the node accordingly. The lease is reconciled
+// again when it is due to expire, or the grace period is due to elapse.
+func (r *NodeHeartbeatReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	lease := &coordinationv1.Lease{}
+	err := r.Client.Get(ctx, request.NamespacedName, lease)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	hostName := lease.Labels[ndm.KubernetesHostNameLabel]
+	if hostName == "" || lease.Spec.RenewTime == nil {
+		return ctrl.Result{}, nil
+	}
+	renewTime := lease.Spec.RenewTime.Time
+	leaseDuration := ndm.NodeLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		leaseDuration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+
+	bdList := &apis.BlockDeviceList{}
+	err = r.Client.List(ctx, bdList, client.MatchingLabels{ndm.KubernetesHostNameLabel: hostName})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := r.now()
+	expiry := renewTime.Add(leaseDuration)
+	if now.Before(expiry) {
+		for i := range bdList.Items {
+			if err = r.restoreBlockDevice(ctx, &bdList.Items[i], hostName); err != nil {
+				klog.Errorf("unable to restore %s after heartbeat of %s was renewed: %v",
+					bdList.Items[i].Name, hostName, err)
+			}
+		}
+		return ctrl.Result{RequeueAfter: expiry.Sub(now)}, err
+	}
+
+	gracePeriodEnd := renewTime.Add(r.GracePeriod)
+	markUnknown := !now.Before(gracePeriodEnd)
+	message := fmt.Sprintf("heartbeat of node %s has not been renewed since %s",
+		hostName, renewTime.UTC().Format(time.RFC3339))
+	for i := range bdList.Items {
+		if err = r.expireBlockDevice(ctx, &bdList.Items[i], markUnknown, message); err != nil {
+			klog.Errorf("unable to update %s after heartbeat of %s expired: %v",
+				bdList.Items[i].Name, hostName, err)
+		}
+	}
+	if !markUnknown {
+		return ctrl.Result{RequeueAfter: gracePeriodEnd.Sub(now)}, err
+	}
+	return ctrl.Result{}, err
+}
+
+// expireBlockDevice sets the NodeHeartbeatLost condition on the BD, so that it
+// is no longer claimed. If markUnknown is set, an Active BD is marked Unknown.
+func (r *NodeHeartbeatReconciler) expireBlockDevice(ctx context.Context, bd *apis.BlockDevice,
+	markUnknown bool, message string) error {
+	if meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeHeartbeatLost) &&
+		!(markUnknown && bd.Status.State == apis.BlockDeviceActive) {
+		return nil
+	}
+
+	markedUnknown := false
+	err := util2.UpdateBlockDevice(ctx, r.Client, bd, util2.OperatorFieldManager, func(bd *apis.BlockDevice) error {
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeHeartbeatLost,
+			metav1.ConditionTrue, apis.BlockDeviceReasonHeartbeatExpired, message)
+		markedUnknown = markUnknown && bd.Status.State == apis.BlockDeviceActive
+		if markedUnknown {
+			bd.Status.State = apis.BlockDeviceUnknown
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if markedUnknown {
+		klog.Infof("%s marked %s: %s", bd.Name, apis.BlockDeviceUnknown, message)
+		r.Recorder.Eventf(bd, corev1.EventTypeWarning, "NodeHeartbeatLost", "BD marked %s, %s",
+			apis.BlockDeviceUnknown, message)
+	}
+	return nil
+}
+
+// restoreBlockDevice clears the NodeHeartbeatLost condition on the BD, and
+// marks the BD Active if it was marked Unknown because of the expired heartbeat
+func (r *NodeHeartbeatReconciler) restoreBlockDevice(ctx context.Context, bd *apis.BlockDevice, hostName string) error {
+	if !meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeHeartbeatLost) {
+		return nil
+	}
+
+	restored := false
+	err := util2.UpdateBlockDevice(ctx, r.Client, bd, util2.OperatorFieldManager, func(bd *apis.BlockDevice) error {
+		restored = false
+		if !meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeHeartbeatLost) {
+			return nil
+		}
+		util2.SetCondition(&bd.Status.Conditions, bd.Generation, apis.BlockDeviceConditionNodeHeartbeatLost,
+			metav1.ConditionFalse, apis.BlockDeviceReasonHeartbeatRenewed,
+			fmt.Sprintf("heartbeat of node %s is being renewed", hostName))
+		// a device which was detached in the meantime is marked Inactive by
+		// the daemon, and is not restored. Neither is a device which was not
+		// found on the new machine of a recreated node.
+		if bd.Status.State == apis.BlockDeviceUnknown &&
+			!meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeRecreated) {
+			bd.Status.State = apis.BlockDeviceActive
+			restored = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if restored {
+		klog.Infof("%s marked %s, heartbeat of node %s is being renewed", bd.Name, apis.BlockDeviceActive, hostName)
+		r.Recorder.Eventf(bd, corev1.EventTypeNormal, "NodeHeartbeatRenewed", "BD marked %s, heartbeat of node %s is being renewed",
+			apis.BlockDeviceActive, hostName)
+	}
+	return nil
+}
+
+func (r *NodeHeartbeatReconciler) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// SetupWithManager sets up the controller with the Manager. Only the leases
+// renewed by the NDM daemons are reconciled.
+func (r *NodeHeartbeatReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isNodeHeartbeat := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[ndm.NDMNodeHeartbeatKey] == ndm.TrueString
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&coordinationv1.Lease{}, builder.WithPredicates(isNodeHeartbeat)).
+		Complete(r)
+}
+
+// GetGracePeriod gets the grace period of the node heartbeat
+func GetGracePeriod() time.Duration {
+	value, ok := os.LookupEnv(EnvNodeHeartbeatGracePeriod)
+	if !ok {
+		return DefaultGracePeriod
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod < 0 {
+		klog.Warningf("invalid value %q for %s, using default %v", value,
+			EnvNodeHeartbeatGracePeriod, DefaultGracePeriod)
+		return DefaultGracePeriod
+	}
+	return gracePeriod
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeheartbeat
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	ndm "github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+)
+
+const (
+	fakeNamespace = "openebs"
+	fakeHostName  = "fake-hostname"
+	fakeBDName    = "blockdevice-example"
+)
+
+func TestReconcile(t *testing.T) {
+	renewTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	heartbeatLost := metav1.Condition{
+		Type:   apis.BlockDeviceConditionNodeHeartbeatLost,
+		Status: metav1.ConditionTrue,
+		Reason: apis.BlockDeviceReasonHeartbeatExpired,
+	}
+
+	tests := map[string]struct {
+		// time since the lease was renewed
+		elapsed        time.Duration
+		state          apis.BlockDeviceState
+		conditions     []metav1.Condition
+		wantState      apis.BlockDeviceState
+		wantHeartbeat  metav1.ConditionStatus
+		wantRequeue    time.Duration
+		wantEventCount int
+	}{
+		"heartbeat is being renewed": {
+			elapsed:     10 * time.Second,
+			state:       apis.BlockDeviceActive,
+			wantState:   apis.BlockDeviceActive,
+			wantRequeue: 30 * time.Second,
+		},
+		"heartbeat expired within the grace period": {
+			elapsed:       time.Minute,
+			state:         apis.BlockDeviceActive,
+			wantState:     apis.BlockDeviceActive,
+			wantHeartbeat: metav1.ConditionTrue,
+			wantRequeue:   time.Minute,
+		},
+		"heartbeat expired after the grace period": {
+			elapsed:        3 * time.Minute,
+			state:          apis.BlockDeviceActive,
+			wantState:      apis.BlockDeviceUnknown,
+			wantHeartbeat:  metav1.ConditionTrue,
+			wantEventCount: 1,
+		},
+		"inactive device is not marked unknown": {
+			elapsed:       3 * time.Minute,
+			state:         apis.BlockDeviceInactive,
+			wantState:     apis.BlockDeviceInactive,
+			wantHeartbeat: metav1.ConditionTrue,
+		},
+		"heartbeat renewed after the grace period": {
+			elapsed:        10 * time.Second,
+			state:          apis.BlockDeviceUnknown,
+			conditions:     []metav1.Condition{heartbeatLost},
+			wantState:      apis.BlockDeviceActive,
+			wantHeartbeat:  metav1.ConditionFalse,
+			wantRequeue:    30 * time.Second,
+			wantEventCount: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bd := &apis.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fakeBDName,
+					Namespace: fakeNamespace,
+					Labels:    map[string]string{ndm.KubernetesHostNameLabel: fakeHostName},
+				},
+				Status: apis.DeviceStatus{
+					ClaimState: apis.BlockDeviceUnclaimed,
+					State:      test.state,
+					Conditions: test.conditions,
+				},
+			}
+			cl := newFakeClient(t, newFakeLease(renewTime), bd)
+			recorder := record.NewFakeRecorder(10)
+			r := &NodeHeartbeatReconciler{
+				Client:      cl,
+				Recorder:    recorder,
+				GracePeriod: DefaultGracePeriod,
+				Now:         func() time.Time { return renewTime.Add(test.elapsed) },
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: fakeNamespace, Name: ndm.NodeLeaseName("fake-node"),
+			}}
+			res, err := r.Reconcile(context.TODO(), req)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRequeue, res.RequeueAfter)
+
+			gotBD := &apis.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), gotBD))
+			assert.Equal(t, test.wantState, gotBD.Status.State)
+			condition := meta.FindStatusCondition(gotBD.Status.Conditions, apis.BlockDeviceConditionNodeHeartbeatLost)
+			if test.wantHeartbeat == "" {
+				assert.Nil(t, condition)
+			} else {
+				require.NotNil(t, condition)
+				assert.Equal(t, test.wantHeartbeat, condition.Status)
+			}
+			assert.Equal(t, test.wantEventCount, len(recorder.Events))
+		})
+	}
+}
+
+func TestGetGracePeriod(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		set   bool
+		want  time.Duration
+	}{
+		"env not set": {
+			want: DefaultGracePeriod,
+		},
+		"valid duration": {
+			value: "5m",
+			set:   true,
+			want:  5 * time.Minute,
+		},
+		"invalid duration": {
+			value: "five",
+			set:   true,
+			want:  DefaultGracePeriod,
+		},
+		"negative duration": {
+			value: "-1m",
+			set:   true,
+			want:  DefaultGracePeriod,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.set {
+				t.Setenv(EnvNodeHeartbeatGracePeriod, test.value)
+			} else {
+				os.Unsetenv(EnvNodeHeartbeatGracePeriod)
+			}
+			assert.Equal(t, test.want, GetGracePeriod())
+		})
+	}
+}
+
+func newFakeLease(renewTime time.Time) *coordinationv1.Lease {
+	holderIdentity := "fake-node"
+	leaseDurationSeconds := int32(ndm.NodeLeaseDuration / time.Second)
+	microTime := metav1.NewMicroTime(renewTime)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ndm.NodeLeaseName(holderIdentity),
+			Namespace: fakeNamespace,
+			Labels: map[string]string{
+				ndm.NDMNodeHeartbeatKey:     ndm.TrueString,
+				ndm.KubernetesHostNameLabel: fakeHostName,
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holderIdentity,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			RenewTime:            &microTime,
+		},
+	}
+}
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, apis.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+const (
+	// DaemonFieldManager is the field manager sent with the requests of the NDM
+	// daemon, which identifies the daemon in the managed fields of the resource.
+	// The daemon sets the fields discovered from the device, like the path,
+	// capacity, details, state and health.
+	DaemonFieldManager client.FieldOwner = "node-disk-manager"
+
+	// OperatorFieldManager is the field manager sent with the requests of the
+	// NDM operator. The operator sets the claim reference, the claim state, the
+	// cleanup method, the conditions and the finalizers.
+	OperatorFieldManager client.FieldOwner = "node-disk-operator"
+)
+
+// PatchBlockDevice sends the changes made to the BlockDevice since original as
+// merge patches. The metadata and spec are patched on the BlockDevice, and the
+// status through the status subresource. The resource version of original is
+// sent along with the patches, so that the changes made by others in the meantime
+// are never overwritten and a conflict is returned instead. bd is updated with
+// the BlockDevice returned by the API server.
+func PatchBlockDevice(ctx context.Context, c client.Client, original, bd *apis.BlockDevice,
+	owner client.FieldOwner) error {
+	status := bd.Status
+	bd.Status = original.Status
+	if err := patch(ctx, c, original, bd, owner); err != nil {
+		bd.Status = status
+		return err
+	}
+	base := bd.DeepCopy()
+	bd.Status = status
+	return patchStatus(ctx, c, base, bd, owner)
+}
+
+// PatchBlockDeviceClaim sends the changes made to the BlockDeviceClaim since
+// original as merge patches, in the same way as PatchBlockDevice
+func PatchBlockDeviceClaim(ctx context.Context, c client.Client, original, bdc *apis.BlockDeviceClaim,
+	owner client.FieldOwner) error {
+	status := bdc.Status
+	bdc.Status = original.Status
+	if err := patch(ctx, c, original, bdc, owner); err != nil {
+		bdc.Status = status
+		return err
+	}
+	base := bdc.DeepCopy()
+	bdc.Status = status
+	return patchStatus(ctx, c, base, bdc, owner)
+}
+
+// UpdateBlockDevice applies mutate on bd and patches the changes. If the patch
+// fails due to a conflict, the latest BlockDevice is fetched and mutate is applied
+// on it again. mutate should return an error, if the change is no longer valid
+// on the latest BlockDevice, which stops the retries. On success, bd is updated
+// with the BlockDevice returned by the API server.
+func UpdateBlockDevice(ctx context.Context, c client.Client, bd *apis.BlockDevice,
+	owner client.FieldOwner, mutate func(bd *apis.BlockDevice) error) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		desired := bd.DeepCopy()
+		if err := mutate(desired); err != nil {
+			return err
+		}
+		err := PatchBlockDevice(ctx, c, bd, desired, owner)
+		if err == nil {
+			*bd = *desired
+			return nil
+		}
+		if errors.IsConflict(err) {
+			if getErr := c.Get(ctx, client.ObjectKeyFromObject(bd), bd); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+}
+
+// UpdateBlockDeviceClaim applies mutate on bdc and patches the changes, retrying
+// on conflicts in the same way as UpdateBlockDevice
+func UpdateBlockDeviceClaim(ctx context.Context, c client.Client, bdc *apis.BlockDeviceClaim,
+	owner client.FieldOwner, mutate func(bdc *apis.BlockDeviceClaim) error) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		desired := bdc.DeepCopy()
+		if err := mutate(desired); err != nil {
+			return err
+		}
+		err := PatchBlockDeviceClaim(ctx, c, bdc, desired, owner)
+		if err == nil {
+			*bdc = *desired
+			return nil
+		}
+		if errors.IsConflict(err) {
+			if getErr := c.Get(ctx, client.ObjectKeyFromObject(bdc), bdc); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+}
+
+// patch sends the changes made to obj since original as a merge patch, with
+// optimistic locking. Nothing is sent if obj is not changed.
+func patch(ctx context.Context, c client.Client, original, obj client.Object, owner client.FieldOwner) error {
+	if changed, err := isChanged(original, obj); err != nil || !changed {
+		return err
+	}
+	return c.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}), owner)
+}
+
+// patchStatus sends the changes made to the status of obj since original as a
+// merge patch on the status subresource, with optimistic locking. Nothing is sent
+// if the status is not changed.
+func patchStatus(ctx context.Context, c client.Client, original, obj client.Object, owner client.FieldOwner) error {
+	if changed, err := isChanged(original, obj); err != nil || !changed {
+		return err
+	}
+	return c.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}), owner)
+}
+
+// isChanged checks if the merge patch between original and obj is non empty
+func isChanged(original, obj client.Object) (bool, error) {
+	data, err := client.MergeFrom(original).Data(obj)
+	if err != nil {
+		return false, err
+	}
+	return string(data) != "{}", nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+func TestUpdateBlockDevice(t *testing.T) {
+	tests := map[string]struct {
+		// change made to the blockdevice by another client, after it was read
+		modify         func(bd *apis.BlockDevice)
+		mutate         func(bd *apis.BlockDevice) error
+		wantErr        bool
+		wantState      apis.BlockDeviceState
+		wantClaimState apis.DeviceClaimState
+	}{
+		"blockdevice not modified": {
+			mutate: func(bd *apis.BlockDevice) error {
+				bd.Status.ClaimState = apis.BlockDeviceClaimed
+				return nil
+			},
+			wantState:      apis.BlockDeviceActive,
+			wantClaimState: apis.BlockDeviceClaimed,
+		},
+		"conflict is retried on the latest blockdevice": {
+			modify: func(bd *apis.BlockDevice) {
+				bd.Status.State = apis.BlockDeviceInactive
+			},
+			mutate: func(bd *apis.BlockDevice) error {
+				bd.Status.ClaimState = apis.BlockDeviceClaimed
+				return nil
+			},
+			wantState:      apis.BlockDeviceInactive,
+			wantClaimState: apis.BlockDeviceClaimed,
+		},
+		"change not valid on the latest blockdevice": {
+			modify: func(bd *apis.BlockDevice) {
+				bd.Status.ClaimState = apis.BlockDeviceReleased
+			},
+			mutate: func(bd *apis.BlockDevice) error {
+				if bd.Status.ClaimState != apis.BlockDeviceUnclaimed {
+					return fmt.Errorf("blockdevice is %s", bd.Status.ClaimState)
+				}
+				bd.Status.ClaimState = apis.BlockDeviceClaimed
+				return nil
+			},
+			wantErr:        true,
+			wantState:      apis.BlockDeviceActive,
+			wantClaimState: apis.BlockDeviceReleased,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl := newFakeClient(t)
+			bd := &apis.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{Name: "blockdevice-1", Namespace: "openebs"},
+				Status: apis.DeviceStatus{
+					ClaimState: apis.BlockDeviceUnclaimed,
+					State:      apis.BlockDeviceActive,
+				},
+			}
+			require.NoError(t, cl.Create(context.TODO(), bd))
+
+			staleBD := &apis.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), staleBD))
+			if test.modify != nil {
+				latestBD := staleBD.DeepCopy()
+				test.modify(latestBD)
+				require.NoError(t, cl.Update(context.TODO(), latestBD))
+			}
+
+			err := UpdateBlockDevice(context.TODO(), cl, staleBD, OperatorFieldManager, test.mutate)
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.False(t, errors.IsConflict(err))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotBD := &apis.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), gotBD))
+			assert.Equal(t, test.wantState, gotBD.Status.State)
+			assert.Equal(t, test.wantClaimState, gotBD.Status.ClaimState)
+			if !test.wantErr {
+				assert.Equal(t, gotBD.ResourceVersion, staleBD.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestPatchBlockDeviceClaimUnchanged(t *testing.T) {
+	cl := newFakeClient(t)
+	bdc := &apis.BlockDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "blockdeviceclaim-1", Namespace: "openebs"},
+	}
+	require.NoError(t, cl.Create(context.TODO(), bdc))
+
+	// a stale copy without any changes does not conflict, since nothing is sent
+	staleBDC := bdc.DeepCopy()
+	require.NoError(t, cl.Update(context.TODO(), bdc))
+	desiredBDC := staleBDC.DeepCopy()
+	assert.NoError(t, PatchBlockDeviceClaim(context.TODO(), cl, staleBDC, desiredBDC, OperatorFieldManager))
+
+	desiredBDC.Status.Phase = apis.BlockDeviceClaimStatusDone
+	err := PatchBlockDeviceClaim(context.TODO(), cl, staleBDC, desiredBDC, OperatorFieldManager)
+	assert.True(t, errors.IsConflict(err))
+}
+
+func newFakeClient(t *testing.T) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, apis.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).Build()
+}
@@ -30,6 +30,17 @@ const (
 
 	// WATCH_NAMESPACE is the namespace to watch for resources
 	WATCH_NAMESPACE_ENV = "WATCH_NAMESPACE"
+
+	// DEFAULT_SELECTION_STRATEGY_ENV is the environment variable used to set the cluster
+	// wide default strategy for selecting a blockdevice for a claim
+	DEFAULT_SELECTION_STRATEGY_ENV = "DEFAULT_SELECTION_STRATEGY"
+
+	// DEFAULT_CLEANUP_METHOD_ENV is the environment variable used to set the cluster
+	// wide default method for cleaning up a released blockdevice
+	DEFAULT_CLEANUP_METHOD_ENV = "DEFAULT_CLEANUP_METHOD"
+
+	// SERVICE_ACCOUNT_ENV is the service account in which the operator is running
+	SERVICE_ACCOUNT_ENV = "SERVICE_ACCOUNT"
 )
 
 // GetOpenEBSImagePullSecrets is used to get the image pull secrets from the environment variable
@@ -59,3 +70,21 @@ func GetWatchNamespace() (string, error) {
 	}
 	return ns, nil
 }
+
+// GetDefaultSelectionStrategy gets the default blockdevice selection strategy. An empty
+// string is returned if the env is not set.
+func GetDefaultSelectionStrategy() string {
+	return strings.TrimSpace(os.Getenv(DEFAULT_SELECTION_STRATEGY_ENV))
+}
+
+// GetDefaultCleanupMethod gets the default blockdevice cleanup method. An empty
+// string is returned if the env is not set.
+func GetDefaultCleanupMethod() string {
+	return strings.TrimSpace(os.Getenv(DEFAULT_CLEANUP_METHOD_ENV))
+}
+
+// GetServiceAccount gets the service account in which the operator is running. An
+// empty string is returned if the env is not set.
+func GetServiceAccount() string {
+	return strings.TrimSpace(os.Getenv(SERVICE_ACCOUNT_ENV))
+}
@@ -64,3 +64,31 @@ func TestGetOpenEBSImagePullSecrets(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDefaultSelectionStrategy(t *testing.T) {
+	tests := map[string]struct {
+		envValue string
+		want     string
+	}{
+		"empty variable": {
+			envValue: "",
+			want:     "",
+		},
+		"strategy set": {
+			envValue: "WorstFit",
+			want:     "WorstFit",
+		},
+		"strategy with whitespaces": {
+			envValue: " BestFit ",
+			want:     "BestFit",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv(DEFAULT_SELECTION_STRATEGY_ENV, tt.envValue)
+			got := GetDefaultSelectionStrategy()
+			assert.Equal(t, tt.want, got)
+			os.Unsetenv(DEFAULT_SELECTION_STRATEGY_ENV)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package lvm reads the LVM2 metadata directly from the physical volumes, without
+depending on the lvm2 tools or a running lvmetad on the node.
+
+The label of the PV is read from the first 4 sectors of the device, and the
+volume group metadata is read from the text metadata area pointed to by the
+label. The text metadata is parsed into the volume group, with its physical
+volumes, logical volumes and their segments, from which the extents allocated
+to each logical volume and the free space in the volume group are calculated.
+
+Usage:
+
+	import "github.com/openebs/node-disk-manager/pkg/lvm"
+
+	label, err := lvm.Probe("/dev/sdb")
+	if err != nil {
+		klog.Error(err)
+	}
+	if label != nil {
+		vg, err := label.VolumeGroup()
+		if err != nil {
+			klog.Error(err)
+		}
+		if vg != nil {
+			fmt.Printf("VG: %s, Size: %d, Free: %d\n", vg.Name, vg.Size(), vg.Free())
+		}
+	}
+
+See https://github.com/lvmteam/lvm2/blob/main/lib/format_text/layout.h for the
+layout of the label and the metadata area.
+*/
+package lvm
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SectorSize is the unit in which the sizes and offsets are stored in the
+	// metadata
+	SectorSize = 512
+
+	// DMUUIDPrefix is the prefix of the DM UUID of the logical volumes
+	DMUUIDPrefix = "LVM-"
+
+	labelID        = "LABELONE"
+	labelType      = "LVM2 001"
+	labelScanCount = 4
+	mdaMagic       = " LVM2 x[5A%r0N*>"
+	mdaHeaderSize  = 512
+	maxMetadata    = 1 << 20
+
+	// rawLocnIgnored is set in the raw location of a metadata area in which
+	// the metadata is not maintained
+	rawLocnIgnored = 0x1
+)
+
+// Label is the label of an LVM2 physical volume
+type Label struct {
+	// PVUUID is the UUID of the physical volume
+	PVUUID string
+	// DeviceSize is the size of the physical volume in bytes
+	DeviceSize uint64
+	// VGName is the name of the volume group to which the physical volume
+	// belongs. It is empty if the physical volume is not in a volume group.
+	VGName string
+
+	// metadata is the text metadata of the volume group
+	metadata []byte
+}
+
+// Probe reads the LVM2 label and the volume group metadata from the device or
+// image file at the given path. nil is returned if the device is not a PV.
+func Probe(path string) (*Label, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get size of %s: %v", path, err)
+	}
+	return ProbeReader(f, size)
+}
+
+// ProbeReader reads the LVM2 label and the volume group metadata from the
+// reader of the given size. nil is returned if the label is not present.
+func ProbeReader(r io.ReaderAt, size int64) (*Label, error) {
+	// the label can be in any of the first 4 sectors
+	for sector := int64(0); sector < labelScanCount; sector++ {
+		buf, err := readAt(r, size, sector*SectorSize, SectorSize)
+		if err != nil || buf == nil {
+			return nil, err
+		}
+		if string(buf[0:8]) != labelID || string(buf[24:32]) != labelType {
+			continue
+		}
+		pvHeaderOffset := binary.LittleEndian.Uint32(buf[20:24])
+		if pvHeaderOffset < 32 || pvHeaderOffset > SectorSize-40 {
+			return nil, fmt.Errorf("invalid LVM2 PV header offset %d", pvHeaderOffset)
+		}
+		pvHeader := buf[pvHeaderOffset:]
+		label := &Label{
+			PVUUID:     FormatUUID(string(pvHeader[0:32])),
+			DeviceSize: binary.LittleEndian.Uint64(pvHeader[32:40]),
+		}
+		label.metadata, err = readMetadata(r, size, pvHeader[40:])
+		if err != nil {
+			return nil, err
+		}
+		label.VGName = vgName(label.metadata)
+		return label, nil
+	}
+	return nil, nil
+}
+
+// VolumeGroup parses the metadata of the volume group to which the physical
+// volume belongs. nil is returned if the physical volume is not in a volume
+// group.
+func (l *Label) VolumeGroup() (*VolumeGroup, error) {
+	if len(l.metadata) == 0 {
+		return nil, nil
+	}
+	return ParseMetadata(l.metadata)
+}
+
+// readMetadata reads the text metadata from the first metadata area of the PV
+// which has it. areas is the list of data areas followed by the list of
+// metadata areas, each list being terminated by an empty area. nil is returned
+// if the PV does not belong to a volume group.
+func readMetadata(r io.ReaderAt, size int64, areas []byte) ([]byte, error) {
+	var mdaOffsets []int64
+	inMetadataAreas := false
+	for i := 0; i+16 <= len(areas); i += 16 {
+		offset := binary.LittleEndian.Uint64(areas[i : i+8])
+		length := binary.LittleEndian.Uint64(areas[i+8 : i+16])
+		if offset == 0 && length == 0 {
+			if inMetadataAreas {
+				break
+			}
+			inMetadataAreas = true
+			continue
+		}
+		if inMetadataAreas {
+			mdaOffsets = append(mdaOffsets, int64(offset))
+		}
+	}
+
+	for _, mdaOffset := range mdaOffsets {
+		header, err := readAt(r, size, mdaOffset, mdaHeaderSize)
+		if err != nil || header == nil {
+			return nil, err
+		}
+		if string(header[4:20]) != mdaMagic {
+			continue
+		}
+		mdaSize := int64(binary.LittleEndian.Uint64(header[32:40]))
+		// the first raw location points to the active metadata
+		textOffset := int64(binary.LittleEndian.Uint64(header[40:48]))
+		textSize := int64(binary.LittleEndian.Uint64(header[48:56]))
+		flags := binary.LittleEndian.Uint32(header[60:64])
+		if textOffset == 0 || textSize == 0 || flags&rawLocnIgnored != 0 {
+			continue
+		}
+		if textSize > maxMetadata || textOffset < mdaHeaderSize || textOffset >= mdaSize {
+			return nil, fmt.Errorf("invalid metadata location %d of size %d in metadata area of size %d",
+				textOffset, textSize, mdaSize)
+		}
+		// the metadata area is a circular buffer after the header, the text
+		// which does not fit till the end of the area continues after the
+		// header
+		firstSize := textSize
+		if textOffset+textSize > mdaSize {
+			firstSize = mdaSize - textOffset
+		}
+		text, err := readAt(r, size, mdaOffset+textOffset, int(firstSize))
+		if err != nil || text == nil {
+			return nil, err
+		}
+		if firstSize < textSize {
+			wrapped, err := readAt(r, size, mdaOffset+mdaHeaderSize, int(textSize-firstSize))
+			if err != nil || wrapped == nil {
+				return nil, err
+			}
+			text = append(text, wrapped...)
+		}
+		// the text is terminated by a NUL
+		if i := bytes.IndexByte(text, 0); i >= 0 {
+			text = text[:i]
+		}
+		return text, nil
+	}
+	return nil, nil
+}
+
+// vgName returns the name of the volume group from the metadata, which starts
+// with "<vg name> {"
+func vgName(metadata []byte) string {
+	if i := bytes.IndexByte(metadata, '{'); i > 0 {
+		return strings.TrimSpace(string(metadata[:i]))
+	}
+	return ""
+}
+
+// readAt reads length bytes at the offset. nil is returned if the reader is
+// smaller than offset+length.
+func readAt(r io.ReaderAt, size, offset int64, length int) ([]byte, error) {
+	if offset < 0 || offset+int64(length) > size {
+		return nil, nil
+	}
+	buf := make([]byte, length)
+	n, err := r.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && n == length) {
+		return nil, fmt.Errorf("error reading %d bytes at offset %d: %v", length, offset, err)
+	}
+	return buf, nil
+}
+
+// FormatUUID formats the 32 character UUID of a PV, VG or LV in the form used
+// by the LVM2 tools
+func FormatUUID(s string) string {
+	if len(s) != 32 {
+		return s
+	}
+	return strings.Join([]string{s[0:6], s[6:10], s[10:14], s[14:18], s[18:22], s[22:26], s[26:32]}, "-")
+}
+
+// ParseDMUUID returns the UUIDs of the volume group and the logical volume from
+// the DM UUID of a logical volume, which is LVM-<vg uuid><lv uuid>. The DM UUID
+// of the internal devices of a logical volume, like the thin pool, has a suffix
+// eg: LVM-<vg uuid><lv uuid>-tpool. ok is false if the DM device is not an LV.
+func ParseDMUUID(dmUUID string) (vgUUID, lvUUID string, ok bool) {
+	if !strings.HasPrefix(dmUUID, DMUUIDPrefix) {
+		return "", "", false
+	}
+	ids := strings.TrimPrefix(dmUUID, DMUUIDPrefix)
+	if len(ids) < 64 || (len(ids) > 64 && ids[64] != '-') {
+		return "", "", false
+	}
+	return FormatUUID(ids[0:32]), FormatUUID(ids[32:64]), true
+}
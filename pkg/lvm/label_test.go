@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPVUUID     = "Kc3Ov1aT9rFwl2ZcQn8xWeYd0pHs4mJu"
+	testDeviceSize = 10 << 30
+	testMDAOffset  = 4096
+	testMDASize    = 8192
+	testImageSize  = testMDAOffset + testMDASize
+)
+
+// readTestData reads the metadata written by LVM2 in the metadata area of a PV
+func readTestData(t *testing.T, name string) []byte {
+	buf, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return buf
+}
+
+// newPVImage creates the image of a PV with the label in the second sector, and
+// the metadata at the given offset in the metadata area
+func newPVImage(metadata []byte, textOffset int, flags uint32) []byte {
+	buf := make([]byte, testImageSize)
+	label := buf[SectorSize:]
+	copy(label[0:8], labelID)
+	binary.LittleEndian.PutUint64(label[8:16], 1)
+	binary.LittleEndian.PutUint32(label[20:24], 32)
+	copy(label[24:32], labelType)
+
+	pvHeader := label[32:]
+	copy(pvHeader[0:32], testPVUUID)
+	binary.LittleEndian.PutUint64(pvHeader[32:40], testDeviceSize)
+	// one data area and one metadata area, each list terminated by an empty area
+	binary.LittleEndian.PutUint64(pvHeader[40:48], 1<<20)
+	binary.LittleEndian.PutUint64(pvHeader[72:80], testMDAOffset)
+	binary.LittleEndian.PutUint64(pvHeader[80:88], testMDASize)
+
+	mda := buf[testMDAOffset:]
+	copy(mda[4:20], mdaMagic)
+	binary.LittleEndian.PutUint32(mda[20:24], 1)
+	binary.LittleEndian.PutUint64(mda[24:32], testMDAOffset)
+	binary.LittleEndian.PutUint64(mda[32:40], testMDASize)
+	if metadata == nil {
+		return buf
+	}
+	text := append(append([]byte{}, metadata...), 0)
+	binary.LittleEndian.PutUint64(mda[40:48], uint64(textOffset))
+	binary.LittleEndian.PutUint64(mda[48:56], uint64(len(text)))
+	binary.LittleEndian.PutUint32(mda[60:64], flags)
+	// the text which does not fit till the end of the metadata area wraps
+	// around to the start of the circular buffer after the header
+	if textOffset < testMDASize {
+		n := copy(mda[textOffset:testMDASize], text)
+		copy(mda[mdaHeaderSize:], text[n:])
+	}
+	return buf
+}
+
+func TestProbeReader(t *testing.T) {
+	metadata := readTestData(t, "vg_data.vg")
+	tests := map[string]struct {
+		image      []byte
+		wantLabel  bool
+		wantVGName string
+		wantErr    bool
+	}{
+		"pv in a volume group": {
+			image:      newPVImage(metadata, mdaHeaderSize, 0),
+			wantLabel:  true,
+			wantVGName: "vg_data",
+		},
+		"metadata wrapped around the metadata area": {
+			image:      newPVImage(metadata, testMDASize-1000, 0),
+			wantLabel:  true,
+			wantVGName: "vg_data",
+		},
+		"pv without a volume group": {
+			image:     newPVImage(nil, 0, 0),
+			wantLabel: true,
+		},
+		"metadata area ignored": {
+			image:     newPVImage(metadata, mdaHeaderSize, rawLocnIgnored),
+			wantLabel: true,
+		},
+		"device without label": {
+			image: make([]byte, testImageSize),
+		},
+		"device smaller than the label": {
+			image: make([]byte, 100),
+		},
+		"metadata outside the metadata area": {
+			image:   newPVImage(metadata, testMDASize+mdaHeaderSize, 0),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ProbeReader(bytes.NewReader(test.image), int64(len(test.image)))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if !test.wantLabel {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu", got.PVUUID)
+			assert.Equal(t, uint64(testDeviceSize), got.DeviceSize)
+			assert.Equal(t, test.wantVGName, got.VGName)
+
+			vg, err := got.VolumeGroup()
+			require.NoError(t, err)
+			if test.wantVGName == "" {
+				assert.Nil(t, vg)
+				return
+			}
+			require.NotNil(t, vg)
+			assert.Equal(t, test.wantVGName, vg.Name)
+			assert.Len(t, vg.LogicalVolumes, 7)
+		})
+	}
+}
+
+func TestProbeReaderInvalidHeader(t *testing.T) {
+	image := newPVImage(nil, 0, 0)
+	// a label pointing to a PV header outside the sector is invalid
+	binary.LittleEndian.PutUint32(image[SectorSize+20:], 0x1ff)
+	_, err := ProbeReader(bytes.NewReader(image), int64(len(image)))
+	assert.Error(t, err)
+}
+
+func TestProbe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pv.img")
+	require.NoError(t, os.WriteFile(path, newPVImage(readTestData(t, "vg_data.vg"), mdaHeaderSize, 0), 0600))
+	got, err := Probe(path)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "vg_data", got.VGName)
+
+	_, err = Probe(filepath.Join(t.TempDir(), "missing.img"))
+	assert.Error(t, err)
+}
+
+func TestParseDMUUID(t *testing.T) {
+	tests := map[string]struct {
+		dmUUID     string
+		wantVGUUID string
+		wantLVUUID string
+		wantOK     bool
+	}{
+		"logical volume": {
+			dmUUID:     "LVM-q9J0sM8xYdK2lA3bVnWc7eRt5uPz1oHgOSlVs5gIXuqSKVPukc2aGPh0AeJw31TJ",
+			wantVGUUID: "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg",
+			wantLVUUID: "OSlVs5-gIXu-qSKV-Pukc-2aGP-h0Ae-Jw31TJ",
+			wantOK:     true,
+		},
+		"thin pool device of a logical volume": {
+			dmUUID:     "LVM-q9J0sM8xYdK2lA3bVnWc7eRt5uPz1oHgZt2Wq8Ne4RhT6yUj1KmP3sLx9CvB5nDa-tpool",
+			wantVGUUID: "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg",
+			wantLVUUID: "Zt2Wq8-Ne4R-hT6y-Uj1K-mP3s-Lx9C-vB5nDa",
+			wantOK:     true,
+		},
+		"crypt device": {
+			dmUUID: "CRYPT-LUKS2-4c25d69f9adc868f61e3d891cf3a5613-luks",
+		},
+		"truncated uuid": {
+			dmUUID: "LVM-q9J0sM8xYdK2lA3bVnWc7eRt5uPz1oHg",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			vgUUID, lvUUID, ok := ParseDMUUID(test.dmUUID)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.wantVGUUID, vgUUID)
+			assert.Equal(t, test.wantLVUUID, lvUUID)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// section is a section of the text metadata. eg:
+//
+//	pv0 {
+//		id = "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu"
+//		status = ["ALLOCATABLE"]
+//		pe_count = 2559
+//	}
+//
+// The values are either int64, float64, string or a list of them.
+type section struct {
+	name     string
+	values   map[string]interface{}
+	sections []*section
+}
+
+func newSection(name string) *section {
+	return &section{
+		name:   name,
+		values: make(map[string]interface{}),
+	}
+}
+
+// child returns the sub section with the given name, nil if it is not present
+func (s *section) child(name string) *section {
+	for _, child := range s.sections {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// str returns the string value of the key, empty if it is not a string
+func (s *section) str(key string) string {
+	value, _ := s.values[key].(string)
+	return value
+}
+
+// uint returns the integer value of the key, 0 if it is not a non negative
+// integer
+func (s *section) uint(key string) uint64 {
+	value, ok := s.values[key].(int64)
+	if !ok || value < 0 {
+		return 0
+	}
+	return uint64(value)
+}
+
+// list returns the list value of the key. A scalar value is returned as a
+// list with a single element.
+func (s *section) list(key string) []interface{} {
+	switch value := s.values[key].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return value
+	default:
+		return []interface{}{value}
+	}
+}
+
+// strings returns the string elements of the list value of the key
+func (s *section) strings(key string) []string {
+	var values []string
+	for _, value := range s.list(key) {
+		if str, ok := value.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenWord is a section name, key or number
+	tokenWord
+	tokenString
+	tokenEquals
+	tokenComma
+	tokenLeftBrace
+	tokenRightBrace
+	tokenLeftBracket
+	tokenRightBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// parser parses the text metadata written by LVM2. The metadata consists of
+// nested sections and key value pairs, with comments starting with #.
+type parser struct {
+	text []byte
+	pos  int
+	line int
+}
+
+// parseConfig parses the text metadata into the root section, which contains
+// the sections and values at the top level of the text
+func parseConfig(text []byte) (*section, error) {
+	p := &parser{text: text, line: 1}
+	root := newSection("")
+	if err := p.parseSection(root, true); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// parseSection parses the contents of the section till the closing brace, or
+// till the end of the text for the root section
+func (p *parser) parseSection(s *section, root bool) error {
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == tokenEOF && root:
+			return nil
+		case tok.kind == tokenEOF:
+			return p.errorf("section %s is not closed", s.name)
+		case tok.kind == tokenRightBrace && !root:
+			return nil
+		case tok.kind != tokenWord:
+			return p.errorf("unexpected %q, expected a section or key", tok.text)
+		}
+
+		name := tok.text
+		tok, err = p.next()
+		if err != nil {
+			return err
+		}
+		switch tok.kind {
+		case tokenLeftBrace:
+			child := newSection(name)
+			if err = p.parseSection(child, false); err != nil {
+				return err
+			}
+			s.sections = append(s.sections, child)
+		case tokenEquals:
+			value, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			s.values[name] = value
+		default:
+			return p.errorf("unexpected %q after %s", tok.text, name)
+		}
+	}
+}
+
+// parseValue parses a scalar value or a list of scalar values
+func (p *parser) parseValue() (interface{}, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind != tokenLeftBracket {
+		return p.scalar(tok)
+	}
+
+	list := make([]interface{}, 0)
+	for {
+		tok, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokenRightBracket {
+			return list, nil
+		}
+		if len(list) != 0 {
+			if tok.kind != tokenComma {
+				return nil, p.errorf("unexpected %q in list, expected ,", tok.text)
+			}
+			if tok, err = p.next(); err != nil {
+				return nil, err
+			}
+		}
+		value, err := p.scalar(tok)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+}
+
+// scalar returns the value of a string or number token
+func (p *parser) scalar(tok token) (interface{}, error) {
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenWord:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return f, nil
+		}
+		return nil, p.errorf("invalid number %q", tok.text)
+	}
+	return nil, p.errorf("unexpected %q, expected a value", tok.text)
+}
+
+// next returns the next token, skipping whitespaces and comments
+func (p *parser) next() (token, error) {
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '"':
+			return p.readString()
+		case isWordChar(c):
+			start := p.pos
+			for p.pos < len(p.text) && isWordChar(p.text[p.pos]) {
+				p.pos++
+			}
+			return token{kind: tokenWord, text: string(p.text[start:p.pos])}, nil
+		default:
+			p.pos++
+			tok := token{text: string(c)}
+			switch c {
+			case '=':
+				tok.kind = tokenEquals
+			case ',':
+				tok.kind = tokenComma
+			case '{':
+				tok.kind = tokenLeftBrace
+			case '}':
+				tok.kind = tokenRightBrace
+			case '[':
+				tok.kind = tokenLeftBracket
+			case ']':
+				tok.kind = tokenRightBracket
+			default:
+				return token{}, p.errorf("unexpected character %q", c)
+			}
+			return tok, nil
+		}
+	}
+	return token{kind: tokenEOF, text: "end of metadata"}, nil
+}
+
+// readString reads a double quoted string, in which " and \ are escaped with \
+func (p *parser) readString() (token, error) {
+	var value []byte
+	// skip the opening quote
+	p.pos++
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return token{kind: tokenString, text: string(value)}, nil
+		case '\\':
+			if p.pos < len(p.text) {
+				c = p.text[p.pos]
+				p.pos++
+			}
+		case '\n':
+			p.line++
+		}
+		value = append(value, c)
+	}
+	return token{}, p.errorf("string is not terminated")
+}
+
+// isWordChar checks if the character can be part of a name or a number. The
+// names of VGs and LVs can contain a-z, A-Z, 0-9, +, _, . and -
+func isWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '+' || c == '_' || c == '.' || c == '-'
+}
@@ -0,0 +1,349 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"fmt"
+)
+
+const (
+	// statusVisible is set in the status of the logical volumes which are
+	// shown to the user. The internal volumes like the data and metadata of a
+	// thin pool are not visible.
+	statusVisible = "VISIBLE"
+
+	segmentTypeStriped = "striped"
+)
+
+// subLVKeys are the keys in a segment which refer to other logical volumes
+// that the segment is built on. eg: the data and metadata volumes of a thin
+// pool, or the images of a raid volume.
+// The areas of mirror segments are parsed along with those of the striped
+// segments, since they can be either PVs or LVs.
+var subLVKeys = []string{
+	"raids", "mirror_log", "metadata", "pool", "thin_pool", "origin", "external_origin",
+	"cow_store", "cache_pool", "data", "vdo_pool", "writecache", "meta_dev",
+}
+
+// VolumeGroup is an LVM2 volume group parsed from the text metadata
+type VolumeGroup struct {
+	// Name of the volume group
+	Name string
+	// UUID of the volume group
+	UUID string
+	// SeqNo is the sequence number of the metadata, incremented on every
+	// change to the volume group
+	SeqNo uint64
+	// ExtentSize is the size of a physical extent in bytes
+	ExtentSize uint64
+	// PhysicalVolumes are the physical volumes in the volume group
+	PhysicalVolumes []PhysicalVolume
+	// LogicalVolumes are the logical volumes in the volume group, including
+	// the internal ones which are not visible
+	LogicalVolumes []LogicalVolume
+}
+
+// PhysicalVolume is a physical volume of a volume group
+type PhysicalVolume struct {
+	// Name of the PV in the metadata, eg: pv0
+	Name string
+	// UUID of the physical volume
+	UUID string
+	// Device is the path of the device last seen by LVM2 for the PV. This is
+	// only a hint and can be different on the node.
+	Device string
+	// DeviceSize is the size of the PV in bytes
+	DeviceSize uint64
+	// PEStart is the offset of the first physical extent in bytes
+	PEStart uint64
+	// PECount is the number of physical extents on the PV
+	PECount uint64
+}
+
+// LogicalVolume is a logical volume of a volume group
+type LogicalVolume struct {
+	// Name of the logical volume
+	Name string
+	// UUID of the logical volume
+	UUID string
+	// Status flags of the logical volume, eg: READ, WRITE, VISIBLE
+	Status []string
+	// Segments of the logical volume
+	Segments []Segment
+}
+
+// Segment is a range of logical extents of a logical volume, which is mapped
+// to areas on the physical volumes, or to other logical volumes
+type Segment struct {
+	// StartExtent is the first logical extent of the segment
+	StartExtent uint64
+	// ExtentCount is the number of logical extents in the segment
+	ExtentCount uint64
+	// Type of the segment, eg: striped, thin-pool, thin, raid1
+	Type string
+	// Areas are the physical extents allocated to the segment
+	Areas []Area
+	// LogicalVolumes are the names of the logical volumes on which the segment
+	// is built
+	LogicalVolumes []string
+}
+
+// Area is a range of physical extents on a physical volume
+type Area struct {
+	// PV is the name of the physical volume, eg: pv0
+	PV string
+	// StartExtent is the first physical extent of the area
+	StartExtent uint64
+	// ExtentCount is the number of physical extents in the area
+	ExtentCount uint64
+}
+
+// ParseMetadata parses the text metadata of a volume group
+func ParseMetadata(text []byte) (*VolumeGroup, error) {
+	root, err := parseConfig(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse LVM2 metadata: %v", err)
+	}
+	// the volume group is the only section at the top level, the description
+	// and creation details are the values at the top level
+	if len(root.sections) != 1 {
+		return nil, fmt.Errorf("expected one volume group in LVM2 metadata, found %d", len(root.sections))
+	}
+	vgSection := root.sections[0]
+	vg := &VolumeGroup{
+		Name:       vgSection.name,
+		UUID:       vgSection.str("id"),
+		SeqNo:      vgSection.uint("seqno"),
+		ExtentSize: vgSection.uint("extent_size") * SectorSize,
+	}
+	if vg.UUID == "" {
+		return nil, fmt.Errorf("volume group %s does not have an id", vg.Name)
+	}
+
+	pvNames := make(map[string]bool)
+	if pvs := vgSection.child("physical_volumes"); pvs != nil {
+		for _, pvSection := range pvs.sections {
+			vg.PhysicalVolumes = append(vg.PhysicalVolumes, PhysicalVolume{
+				Name:       pvSection.name,
+				UUID:       pvSection.str("id"),
+				Device:     pvSection.str("device"),
+				DeviceSize: pvSection.uint("dev_size") * SectorSize,
+				PEStart:    pvSection.uint("pe_start") * SectorSize,
+				PECount:    pvSection.uint("pe_count"),
+			})
+			pvNames[pvSection.name] = true
+		}
+	}
+
+	if lvs := vgSection.child("logical_volumes"); lvs != nil {
+		for _, lvSection := range lvs.sections {
+			lv := LogicalVolume{
+				Name:   lvSection.name,
+				UUID:   lvSection.str("id"),
+				Status: lvSection.strings("status"),
+			}
+			for _, segSection := range lvSection.sections {
+				lv.Segments = append(lv.Segments, parseSegment(segSection, pvNames))
+			}
+			vg.LogicalVolumes = append(vg.LogicalVolumes, lv)
+		}
+	}
+	return vg, nil
+}
+
+// parseSegment parses a segment of a logical volume. The areas of the segment
+// are lists of "<pv or lv name>", <start extent> pairs.
+func parseSegment(s *section, pvNames map[string]bool) Segment {
+	seg := Segment{
+		StartExtent: s.uint("start_extent"),
+		ExtentCount: s.uint("extent_count"),
+		Type:        s.str("type"),
+	}
+	for _, key := range []string{"stripes", "mirrors"} {
+		areas := s.list(key)
+		areaCount := uint64(len(areas) / 2)
+		if areaCount == 0 {
+			continue
+		}
+		// the extents of a striped segment are spread over the areas, while
+		// every area of a mirror has all the extents
+		areaLength := seg.ExtentCount
+		if seg.Type == segmentTypeStriped {
+			areaLength = seg.ExtentCount / areaCount
+		}
+		for i := 0; i+1 < len(areas); i += 2 {
+			name, ok := areas[i].(string)
+			if !ok {
+				continue
+			}
+			start, _ := areas[i+1].(int64)
+			if !pvNames[name] {
+				seg.LogicalVolumes = append(seg.LogicalVolumes, name)
+				continue
+			}
+			seg.Areas = append(seg.Areas, Area{
+				PV:          name,
+				StartExtent: uint64(start),
+				ExtentCount: areaLength,
+			})
+		}
+	}
+	for _, key := range subLVKeys {
+		seg.LogicalVolumes = append(seg.LogicalVolumes, s.strings(key)...)
+	}
+	return seg
+}
+
+// ExtentCount returns the number of physical extents in the volume group
+func (vg *VolumeGroup) ExtentCount() uint64 {
+	var count uint64
+	for _, pv := range vg.PhysicalVolumes {
+		count += pv.PECount
+	}
+	return count
+}
+
+// FreeExtentCount returns the number of physical extents in the volume group
+// which are not allocated to any logical volume
+func (vg *VolumeGroup) FreeExtentCount() uint64 {
+	var allocated uint64
+	for _, lv := range vg.LogicalVolumes {
+		for _, seg := range lv.Segments {
+			for _, area := range seg.Areas {
+				allocated += area.ExtentCount
+			}
+		}
+	}
+	count := vg.ExtentCount()
+	if allocated > count {
+		return 0
+	}
+	return count - allocated
+}
+
+// Size returns the size of the volume group in bytes
+func (vg *VolumeGroup) Size() uint64 {
+	return vg.ExtentCount() * vg.ExtentSize
+}
+
+// Free returns the space in the volume group which is not allocated to any
+// logical volume, in bytes
+func (vg *VolumeGroup) Free() uint64 {
+	return vg.FreeExtentCount() * vg.ExtentSize
+}
+
+// PhysicalVolumeByUUID returns the physical volume with the given UUID, nil if
+// it is not in the volume group
+func (vg *VolumeGroup) PhysicalVolumeByUUID(uuid string) *PhysicalVolume {
+	for i := range vg.PhysicalVolumes {
+		if vg.PhysicalVolumes[i].UUID == uuid {
+			return &vg.PhysicalVolumes[i]
+		}
+	}
+	return nil
+}
+
+// LogicalVolumeByUUID returns the logical volume with the given UUID, nil if
+// it is not in the volume group
+func (vg *VolumeGroup) LogicalVolumeByUUID(uuid string) *LogicalVolume {
+	for i := range vg.LogicalVolumes {
+		if vg.LogicalVolumes[i].UUID == uuid {
+			return &vg.LogicalVolumes[i]
+		}
+	}
+	return nil
+}
+
+// logicalVolume returns the logical volume with the given name
+func (vg *VolumeGroup) logicalVolume(name string) *LogicalVolume {
+	for i := range vg.LogicalVolumes {
+		if vg.LogicalVolumes[i].Name == name {
+			return &vg.LogicalVolumes[i]
+		}
+	}
+	return nil
+}
+
+// PhysicalVolumesOf returns the physical volumes on which the extents of the
+// logical volume are allocated, including those of the logical volumes on
+// which it is built. eg: a thin volume is on the PVs of the data and metadata
+// volumes of its thin pool.
+func (vg *VolumeGroup) PhysicalVolumesOf(lv *LogicalVolume) []PhysicalVolume {
+	pvNames := make(map[string]bool)
+	vg.collectPVs(lv, pvNames, make(map[string]bool))
+
+	var pvs []PhysicalVolume
+	for _, pv := range vg.PhysicalVolumes {
+		if pvNames[pv.Name] {
+			pvs = append(pvs, pv)
+		}
+	}
+	return pvs
+}
+
+func (vg *VolumeGroup) collectPVs(lv *LogicalVolume, pvNames, visited map[string]bool) {
+	if lv == nil || visited[lv.Name] {
+		return
+	}
+	visited[lv.Name] = true
+	for _, seg := range lv.Segments {
+		for _, area := range seg.Areas {
+			pvNames[area.PV] = true
+		}
+		for _, name := range seg.LogicalVolumes {
+			vg.collectPVs(vg.logicalVolume(name), pvNames, visited)
+		}
+	}
+}
+
+// LogicalVolumesOn returns the visible logical volumes which have extents
+// allocated on the physical volume
+func (vg *VolumeGroup) LogicalVolumesOn(pv *PhysicalVolume) []LogicalVolume {
+	var lvs []LogicalVolume
+	for i := range vg.LogicalVolumes {
+		lv := &vg.LogicalVolumes[i]
+		if !lv.Visible() {
+			continue
+		}
+		for _, lvPV := range vg.PhysicalVolumesOf(lv) {
+			if lvPV.Name == pv.Name {
+				lvs = append(lvs, *lv)
+				break
+			}
+		}
+	}
+	return lvs
+}
+
+// Visible checks if the logical volume is shown to the user
+func (lv *LogicalVolume) Visible() bool {
+	for _, status := range lv.Status {
+		if status == statusVisible {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtentCount returns the number of logical extents of the logical volume
+func (lv *LogicalVolume) ExtentCount() uint64 {
+	var count uint64
+	for _, seg := range lv.Segments {
+		count += seg.ExtentCount
+	}
+	return count
+}
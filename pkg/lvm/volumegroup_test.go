@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadata(t *testing.T) {
+	vg, err := ParseMetadata(readTestData(t, "vg_data.vg"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "vg_data", vg.Name)
+	assert.Equal(t, "q9J0sM-8xYd-K2lA-3bVn-Wc7e-Rt5u-Pz1oHg", vg.UUID)
+	assert.Equal(t, uint64(7), vg.SeqNo)
+	assert.Equal(t, uint64(4<<20), vg.ExtentSize)
+	assert.Equal(t, []PhysicalVolume{
+		{
+			Name:       "pv0",
+			UUID:       "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+			Device:     "/dev/sdb",
+			DeviceSize: 10 << 30,
+			PEStart:    1 << 20,
+			PECount:    2559,
+		},
+		{
+			Name:       "pv1",
+			UUID:       "Bx7Tq2-Lm4N-8pQr-Ws3E-Yu6I-Op9A-Df1GhJ",
+			Device:     "/dev/sdc",
+			DeviceSize: 10 << 30,
+			PEStart:    1 << 20,
+			PECount:    2559,
+		},
+	}, vg.PhysicalVolumes)
+
+	striped := vg.LogicalVolumeByUUID("qYIRuR-Hood-Yg9J-wkmy-vvk0-QNYK-4YulHt")
+	require.NotNil(t, striped)
+	assert.Equal(t, "lv_striped", striped.Name)
+	assert.True(t, striped.Visible())
+	assert.Equal(t, uint64(512), striped.ExtentCount())
+	assert.Equal(t, []Segment{{
+		ExtentCount: 512,
+		Type:        "striped",
+		Areas: []Area{
+			{PV: "pv0", StartExtent: 1024, ExtentCount: 256},
+			{PV: "pv1", StartExtent: 0, ExtentCount: 256},
+		},
+	}}, striped.Segments)
+
+	pool := vg.LogicalVolumeByUUID("Zt2Wq8-Ne4R-hT6y-Uj1K-mP3s-Lx9C-vB5nDa")
+	require.NotNil(t, pool)
+	assert.Equal(t, []string{"thinpool_tmeta", "thinpool_tdata"}, pool.Segments[0].LogicalVolumes)
+	assert.Empty(t, pool.Segments[0].Areas)
+
+	// 1024 + 512 + 2 + 2 + 256 extents are allocated out of 2 * 2559
+	assert.Equal(t, uint64(5118), vg.ExtentCount())
+	assert.Equal(t, uint64(3322), vg.FreeExtentCount())
+	assert.Equal(t, uint64(5118*4<<20), vg.Size())
+	assert.Equal(t, uint64(3322*4<<20), vg.Free())
+
+	assert.Nil(t, vg.LogicalVolumeByUUID("missing"))
+	assert.Nil(t, vg.PhysicalVolumeByUUID("missing"))
+}
+
+func TestPhysicalVolumesOf(t *testing.T) {
+	vg, err := ParseMetadata(readTestData(t, "vg_data.vg"))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		lvName string
+		want   []string
+	}{
+		"linear volume": {
+			lvName: "lv_linear",
+			want:   []string{"pv0"},
+		},
+		"striped volume": {
+			lvName: "lv_striped",
+			want:   []string{"pv0", "pv1"},
+		},
+		"thin pool on its data and metadata volumes": {
+			lvName: "thinpool",
+			want:   []string{"pv1"},
+		},
+		"thin volume in the thin pool": {
+			lvName: "thinvol",
+			want:   []string{"pv1"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lv := vg.logicalVolume(test.lvName)
+			require.NotNil(t, lv)
+			var got []string
+			for _, pv := range vg.PhysicalVolumesOf(lv) {
+				got = append(got, pv.Name)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestLogicalVolumesOn(t *testing.T) {
+	vg, err := ParseMetadata(readTestData(t, "vg_data.vg"))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		pvUUID string
+		want   []string
+	}{
+		"internal volumes are not listed": {
+			pvUUID: "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+			want:   []string{"lv_linear", "lv_striped"},
+		},
+		"volumes built on other volumes are listed": {
+			pvUUID: "Bx7Tq2-Lm4N-8pQr-Ws3E-Yu6I-Op9A-Df1GhJ",
+			want:   []string{"lv_striped", "thinpool", "thinvol"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pv := vg.PhysicalVolumeByUUID(test.pvUUID)
+			require.NotNil(t, pv)
+			var got []string
+			for _, lv := range vg.LogicalVolumesOn(pv) {
+				got = append(got, lv.Name)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseMetadataErrors(t *testing.T) {
+	tests := map[string]string{
+		"no volume group":         `contents = "Text Format Volume Group"`,
+		"volume group without id": "vg_data {\nseqno = 1\n}\n",
+		"section not closed":      "vg_data {\nid = \"q9J0sM\"\n",
+		"string not terminated":   "vg_data {\nid = \"q9J0sM\n}\n",
+		"invalid number":          "vg_data {\nid = \"q9J0sM\"\nseqno = one\n}\n",
+		"list without separator":  "vg_data {\nid = \"q9J0sM\"\nstatus = [\"READ\" \"WRITE\"]\n}\n",
+		"unexpected character":    "vg_data {\nid = \"q9J0sM\"\n;\n}\n",
+		"missing value":           "vg_data {\nid = \"q9J0sM\"\nseqno\n}\n",
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMetadata([]byte(text))
+			assert.Error(t, err)
+		})
+	}
+}
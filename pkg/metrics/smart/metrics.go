@@ -58,6 +58,30 @@ type MetricsData struct {
 	// blockDevicePercentEnduranceUsed  is percentage of endurance used by a block device
 	blockDevicePercentEnduranceUsed *prometheus.GaugeVec
 
+	// blockDeviceGrownDefects is the number of entries in the grown defect list of the block device
+	blockDeviceGrownDefects *prometheus.GaugeVec
+
+	// blockDeviceUncorrectedReadErrors is the number of uncorrected read errors of the block device
+	blockDeviceUncorrectedReadErrors *prometheus.GaugeVec
+
+	// blockDeviceUncorrectedWriteErrors is the number of uncorrected write errors of the block device
+	blockDeviceUncorrectedWriteErrors *prometheus.GaugeVec
+
+	// blockDeviceUncorrectedVerifyErrors is the number of uncorrected verify errors of the block device
+	blockDeviceUncorrectedVerifyErrors *prometheus.GaugeVec
+
+	// blockDeviceNonMediumErrors is the number of non medium errors of the block device
+	blockDeviceNonMediumErrors *prometheus.GaugeVec
+
+	// blockDeviceStartStopCycles is the number of start-stop cycles of the block device
+	blockDeviceStartStopCycles *prometheus.GaugeVec
+
+	// blockDeviceFailedSelfTests is the number of failed self-tests in the self-test log of the block device
+	blockDeviceFailedSelfTests *prometheus.GaugeVec
+
+	// blockDeviceHealthFailing tells whether the block device reports that it is failing
+	blockDeviceHealthFailing *prometheus.GaugeVec
+
 	// errors and rejected requests
 	rejectRequestCount prometheus.Counter
 	errorRequestCount  prometheus.Counter
@@ -86,9 +110,10 @@ func NewMetrics(collector string) *Metrics {
 	}
 }
 
-// Collectors lists out all the collectors for which the metrics is exposed
+// Collectors lists out all the collectors for which the metrics is exposed.
+// The metrics that were not declared are skipped.
 func (m *Metrics) Collectors() []prometheus.Collector {
-	return []prometheus.Collector{
+	return append(declaredCollectors(
 		m.blockDeviceCurrentTemperatureValid,
 		m.blockDeviceHighestTemperatureValid,
 		m.blockDeviceLowestTemperatureValid,
@@ -99,9 +124,58 @@ func (m *Metrics) Collectors() []prometheus.Collector {
 		m.blockDeviceTotalWrittenBytes,
 		m.blockDeviceUtilizationRate,
 		m.blockDevicePercentEnduranceUsed,
-		m.rejectRequestCount,
-		m.errorRequestCount,
+	), m.rejectRequestCount, m.errorRequestCount)
+}
+
+// HealthCollectors lists out the collectors for the metrics related to the
+// health of the block device. The metrics that were not declared are skipped.
+func (m *Metrics) HealthCollectors() []prometheus.Collector {
+	return declaredCollectors(
+		m.blockDeviceGrownDefects,
+		m.blockDeviceUncorrectedReadErrors,
+		m.blockDeviceUncorrectedWriteErrors,
+		m.blockDeviceUncorrectedVerifyErrors,
+		m.blockDeviceNonMediumErrors,
+		m.blockDeviceStartStopCycles,
+		m.blockDeviceFailedSelfTests,
+		m.blockDeviceHealthFailing,
+	)
+}
+
+// Reset deletes the values of all the block device metrics, so that the
+// metrics of the block devices which are no longer reported are not exposed
+func (m *Metrics) Reset() {
+	for _, col := range append(m.Collectors(), m.HealthCollectors()...) {
+		switch vec := col.(type) {
+		case *prometheus.GaugeVec:
+			vec.Reset()
+		case *prometheus.CounterVec:
+			vec.Reset()
+		}
+	}
+	if m.blockDeviceCapacity != nil {
+		m.blockDeviceCapacity.Reset()
+	}
+}
+
+// declaredCollectors returns the metric vectors which were declared using the
+// With* methods
+func declaredCollectors(collectors ...prometheus.Collector) []prometheus.Collector {
+	declared := make([]prometheus.Collector, 0, len(collectors))
+	for _, col := range collectors {
+		switch vec := col.(type) {
+		case *prometheus.GaugeVec:
+			if vec == nil {
+				continue
+			}
+		case *prometheus.CounterVec:
+			if vec == nil {
+				continue
+			}
+		}
+		declared = append(declared, col)
 	}
+	return declared
 }
 
 var labels []string = []string{"blockdevicename", "path", "hostname", "nodename"}
@@ -273,6 +347,110 @@ func (m *Metrics) WithBlockDevicePercentEnduranceUsed() *Metrics {
 	return m
 }
 
+// WithBlockDeviceGrownDefects declares the number of grown defects of a block device
+func (m *Metrics) WithBlockDeviceGrownDefects() *Metrics {
+	m.blockDeviceGrownDefects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_grown_defects",
+			Help:      `Number of entries in the grown defect list of the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceUncorrectedReadErrors declares the number of uncorrected read errors of a block device
+func (m *Metrics) WithBlockDeviceUncorrectedReadErrors() *Metrics {
+	m.blockDeviceUncorrectedReadErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_uncorrected_read_errors",
+			Help:      `Number of read errors that could not be corrected by the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceUncorrectedWriteErrors declares the number of uncorrected write errors of a block device
+func (m *Metrics) WithBlockDeviceUncorrectedWriteErrors() *Metrics {
+	m.blockDeviceUncorrectedWriteErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_uncorrected_write_errors",
+			Help:      `Number of write errors that could not be corrected by the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceUncorrectedVerifyErrors declares the number of uncorrected verify errors of a block device
+func (m *Metrics) WithBlockDeviceUncorrectedVerifyErrors() *Metrics {
+	m.blockDeviceUncorrectedVerifyErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_uncorrected_verify_errors",
+			Help:      `Number of verify errors that could not be corrected by the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceNonMediumErrors declares the number of non medium errors of a block device
+func (m *Metrics) WithBlockDeviceNonMediumErrors() *Metrics {
+	m.blockDeviceNonMediumErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_non_medium_errors",
+			Help:      `Number of recoverable errors other than read, write or verify errors`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceStartStopCycles declares the number of start-stop cycles of a block device
+func (m *Metrics) WithBlockDeviceStartStopCycles() *Metrics {
+	m.blockDeviceStartStopCycles = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_start_stop_cycles",
+			Help:      `Number of start-stop cycles over the lifetime of the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceFailedSelfTests declares the number of failed self-tests of a block device
+func (m *Metrics) WithBlockDeviceFailedSelfTests() *Metrics {
+	m.blockDeviceFailedSelfTests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_failed_self_tests",
+			Help:      `Number of failed self-tests in the self-test log of the block device`,
+		},
+		labels,
+	)
+	return m
+}
+
+// WithBlockDeviceHealthFailing declares whether the block device reports that it is failing
+func (m *Metrics) WithBlockDeviceHealthFailing() *Metrics {
+	m.blockDeviceHealthFailing = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: m.CollectorType,
+			Name:      "block_device_health_failing",
+			Help:      `1 if the block device reports that it is failing, 0 otherwise`,
+		},
+		labels,
+	)
+	return m
+}
+
 // WithRejectRequest declares the reject request count metric
 func (m *Metrics) WithRejectRequest() *Metrics {
 	m.rejectRequestCount = prometheus.NewCounter(
@@ -452,3 +630,95 @@ func (m *Metrics) SetBlockDevicePercentEnduranceUsed(size float64) *Metrics {
 		Set(float64(size))
 	return m
 }
+
+// SetBlockDeviceGrownDefects sets the number of grown defects of a block device to the metric
+func (m *Metrics) SetBlockDeviceGrownDefects(count uint64) *Metrics {
+	m.blockDeviceGrownDefects.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceUncorrectedReadErrors sets the number of uncorrected read errors of a block device to the metric
+func (m *Metrics) SetBlockDeviceUncorrectedReadErrors(count uint64) *Metrics {
+	m.blockDeviceUncorrectedReadErrors.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceUncorrectedWriteErrors sets the number of uncorrected write errors of a block device to the metric
+func (m *Metrics) SetBlockDeviceUncorrectedWriteErrors(count uint64) *Metrics {
+	m.blockDeviceUncorrectedWriteErrors.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceUncorrectedVerifyErrors sets the number of uncorrected verify errors of a block device to the metric
+func (m *Metrics) SetBlockDeviceUncorrectedVerifyErrors(count uint64) *Metrics {
+	m.blockDeviceUncorrectedVerifyErrors.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceNonMediumErrors sets the number of non medium errors of a block device to the metric
+func (m *Metrics) SetBlockDeviceNonMediumErrors(count uint64) *Metrics {
+	m.blockDeviceNonMediumErrors.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceStartStopCycles sets the number of start-stop cycles of a block device to the metric
+func (m *Metrics) SetBlockDeviceStartStopCycles(count uint64) *Metrics {
+	m.blockDeviceStartStopCycles.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceFailedSelfTests sets the number of failed self-tests of a block device to the metric
+func (m *Metrics) SetBlockDeviceFailedSelfTests(count uint64) *Metrics {
+	m.blockDeviceFailedSelfTests.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(float64(count))
+	return m
+}
+
+// SetBlockDeviceHealthFailing sets whether the block device reports that it is failing to the metric
+func (m *Metrics) SetBlockDeviceHealthFailing(failing bool) *Metrics {
+	var value float64
+	if failing {
+		value = 1
+	}
+	m.blockDeviceHealthFailing.WithLabelValues(m.UUID,
+		m.Path,
+		m.HostName,
+		m.NodeName,
+	).
+		Set(value)
+	return m
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package nvme provides functionality for fetching the details of NVMe namespaces
+and their controllers by sending admin commands to the device using the
+NVME_IOCTL_ADMIN_CMD ioctl, without depending on any external binaries.
+
+The following admin commands are used:
+  - Identify Controller, for the model, serial number and firmware revision
+  - Identify Namespace, for the EUI64 and NGUID of the namespace
+  - Get Log Page (SMART / Health Information), for the temperature, percentage
+    used, media errors, power on hours, unsafe shutdowns etc.
+
+Usage:
+
+	import "github.com/openebs/node-disk-manager/pkg/nvme"
+
+	id := &nvme.Identifier{DevPath: "/dev/nvme0n1"}
+	info, err := id.DeviceInfo()
+	if err != nil {
+		klog.Error(err)
+	}
+	fmt.Printf("Model: %s, Temperature: %d\n", info.Controller.Model, info.SMARTLog.Temperature)
+
+The binary needs to have CAP_SYS_ADMIN capability for sending the admin commands.
+See NVM Express Base Specification Revision 1.4, https://nvmexpress.org/specifications/
+for the layout of the data structures returned by the commands.
+*/
+package nvme
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nvme
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"unsafe"
+
+	"github.com/openebs/node-disk-manager/pkg/smart"
+	"golang.org/x/sys/unix"
+)
+
+// namespaceDevRegex matches the devpath of an NVMe namespace. Partitions on the
+// namespace are not matched, since admin commands cannot be sent through them.
+var namespaceDevRegex = regexp.MustCompile(`^/dev/nvme\d+n\d+$`)
+
+// IsNVMeNamespace checks if the devpath is that of an NVMe namespace
+func IsNVMeNamespace(devPath string) bool {
+	return namespaceDevRegex.MatchString(devPath)
+}
+
+// DeviceInfo sends the identify controller, identify namespace and get log page
+// commands to the NVMe namespace and returns the parsed details
+func (id *Identifier) DeviceInfo() (*DeviceInfo, error) {
+	if !IsNVMeNamespace(id.DevPath) {
+		return nil, fmt.Errorf("%s is not an NVMe namespace", id.DevPath)
+	}
+	if err := smart.CheckBinaryPerm(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(id.DevPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fd := f.Fd()
+
+	info := &DeviceInfo{}
+
+	buf, err := identifyController(fd)
+	if err != nil {
+		return nil, fmt.Errorf("identify controller failed for %s: %v", id.DevPath, err)
+	}
+	if info.Controller, err = ParseIdentifyController(buf); err != nil {
+		return nil, err
+	}
+
+	nsid, err := namespaceID(fd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get namespace id of %s: %v", id.DevPath, err)
+	}
+	buf, err = identifyNamespace(fd, nsid)
+	if err != nil {
+		return nil, fmt.Errorf("identify namespace failed for %s: %v", id.DevPath, err)
+	}
+	if info.Namespace, err = ParseIdentifyNamespace(buf); err != nil {
+		return nil, err
+	}
+	info.Namespace.ID = nsid
+
+	buf, err = smartLogPage(fd)
+	if err != nil {
+		return nil, fmt.Errorf("get smart log page failed for %s: %v", id.DevPath, err)
+	}
+	if info.SMARTLog, err = ParseSMARTLog(buf); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// namespaceID returns the ID of the namespace opened using the fd
+func namespaceID(fd uintptr) (uint32, error) {
+	nsid, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, NVMeIoctlID, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return uint32(nsid), nil
+}
+
+// identifyController sends the identify command with CNS 01h
+func identifyController(fd uintptr) ([]byte, error) {
+	buf := make([]byte, identifyDataLen)
+	cmd := adminCmd{
+		opcode: adminIdentify,
+		cdw10:  identifyCNSController,
+	}
+	return buf, submitAdminCmd(fd, &cmd, buf)
+}
+
+// identifyNamespace sends the identify command with CNS 00h for the given namespace
+func identifyNamespace(fd uintptr, nsid uint32) ([]byte, error) {
+	buf := make([]byte, identifyDataLen)
+	cmd := adminCmd{
+		opcode: adminIdentify,
+		nsid:   nsid,
+		cdw10:  identifyCNSNamespace,
+	}
+	return buf, submitAdminCmd(fd, &cmd, buf)
+}
+
+// smartLogPage sends the get log page command for the SMART / Health Information
+// log of the controller
+func smartLogPage(fd uintptr) ([]byte, error) {
+	buf := make([]byte, smartLogLen)
+	// number of dwords to be returned, 0's based value
+	numd := uint32(smartLogLen/4 - 1)
+	cmd := adminCmd{
+		opcode: adminGetLogPage,
+		nsid:   nsidGlobal,
+		cdw10:  (numd&0xFFFF)<<16 | logPageSMART,
+		cdw11:  numd >> 16,
+	}
+	return buf, submitAdminCmd(fd, &cmd, buf)
+}
+
+// submitAdminCmd sends the admin command to the device, with buf as the data
+// buffer to which the controller returns the data
+func submitAdminCmd(fd uintptr, cmd *adminCmd, buf []byte) error {
+	cmd.addr = uint64(uintptr(unsafe.Pointer(&buf[0])))
+	cmd.dataLen = uint32(len(buf))
+	cmd.timeoutMs = defaultTimeout
+	status, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, NVMeIoctlAdminCmd, uintptr(unsafe.Pointer(cmd)))
+	runtime.KeepAlive(buf)
+	if errno != 0 {
+		return errno
+	}
+	// a positive return value is the NVMe status of the failed command
+	if status != 0 {
+		return fmt.Errorf("admin command %#x failed with status %#x", cmd.opcode, status)
+	}
+	return nil
+}
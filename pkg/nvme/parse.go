@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nvme
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// kelvinOffset is used to convert the temperatures reported in kelvin to celsius
+const kelvinOffset = 273
+
+// ParseIdentifyController parses the 4096 byte Identify Controller data structure.
+// Figure 247 of NVM Express Base Specification Revision 1.4
+func ParseIdentifyController(buf []byte) (ControllerInfo, error) {
+	if len(buf) < identifyDataLen {
+		return ControllerInfo{}, fmt.Errorf("identify controller data is %d bytes, expected %d", len(buf), identifyDataLen)
+	}
+	return ControllerInfo{
+		VendorID:           binary.LittleEndian.Uint16(buf[0:2]),
+		Serial:             parseString(buf[4:24]),
+		Model:              parseString(buf[24:64]),
+		FirmwareRevision:   parseString(buf[64:72]),
+		NumberOfNamespaces: binary.LittleEndian.Uint32(buf[516:520]),
+	}, nil
+}
+
+// ParseIdentifyNamespace parses the 4096 byte Identify Namespace data structure.
+// Figure 245 of NVM Express Base Specification Revision 1.4
+func ParseIdentifyNamespace(buf []byte) (NamespaceInfo, error) {
+	if len(buf) < identifyDataLen {
+		return NamespaceInfo{}, fmt.Errorf("identify namespace data is %d bytes, expected %d", len(buf), identifyDataLen)
+	}
+	info := NamespaceInfo{
+		Size:  binary.LittleEndian.Uint64(buf[0:8]),
+		NGUID: parseUniqueID(buf[104:120]),
+		EUI64: parseUniqueID(buf[120:128]),
+	}
+	// the lower 4 bits of FLBAS is the index of the LBA format in use. Each LBA
+	// format is 4 bytes starting at byte 128, with LBADS (the LBA data size as a
+	// power of 2) at byte 2
+	lbaFormat := buf[26] & 0x0F
+	lbads := buf[128+4*int(lbaFormat)+2]
+	if lbads >= 9 && lbads < 32 {
+		info.LogicalBlockSize = 1 << lbads
+	}
+	return info, nil
+}
+
+// ParseSMARTLog parses the 512 byte SMART / Health Information log page.
+// Figure 194 of NVM Express Base Specification Revision 1.4
+func ParseSMARTLog(buf []byte) (SMARTLog, error) {
+	if len(buf) < smartLogLen {
+		return SMARTLog{}, fmt.Errorf("smart log page is %d bytes, expected %d", len(buf), smartLogLen)
+	}
+	log := SMARTLog{
+		CriticalWarning:         buf[0],
+		AvailableSpare:          buf[3],
+		AvailableSpareThreshold: buf[4],
+		PercentageUsed:          buf[5],
+		DataUnitsRead:           parseUint128(buf[32:48]),
+		DataUnitsWritten:        parseUint128(buf[48:64]),
+		PowerCycles:             parseUint128(buf[112:128]),
+		PowerOnHours:            parseUint128(buf[128:144]),
+		UnsafeShutdowns:         parseUint128(buf[144:160]),
+		MediaErrors:             parseUint128(buf[160:176]),
+		ErrorLogEntries:         parseUint128(buf[176:192]),
+	}
+	// a composite temperature of 0 means that the temperature is not reported
+	if kelvin := binary.LittleEndian.Uint16(buf[1:3]); kelvin != 0 {
+		log.Temperature = int16(int(kelvin) - kelvinOffset)
+	}
+	return log, nil
+}
+
+// parseString returns the ASCII string padded with spaces in the data structure
+func parseString(buf []byte) string {
+	return strings.TrimSpace(strings.TrimRight(string(buf), "\x00"))
+}
+
+// parseUniqueID returns the hex representation of the identifier. An identifier
+// with all bytes as zero is not supported by the controller, and empty string
+// is returned.
+func parseUniqueID(buf []byte) string {
+	for _, b := range buf {
+		if b != 0 {
+			return hex.EncodeToString(buf)
+		}
+	}
+	return ""
+}
+
+// parseUint128 returns the 128 bit little endian counter as an uint64. Counters
+// that overflow 64 bits are capped at the max value of uint64
+func parseUint128(buf []byte) uint64 {
+	if binary.LittleEndian.Uint64(buf[8:16]) != 0 {
+		return math.MaxUint64
+	}
+	return binary.LittleEndian.Uint64(buf[0:8])
+}
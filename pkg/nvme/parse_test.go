@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nvme
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTestData reads the buffer captured from an NVMe device
+func readTestData(t *testing.T, name string) []byte {
+	buf, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return buf
+}
+
+func TestParseIdentifyController(t *testing.T) {
+	tests := map[string]struct {
+		buf     []byte
+		want    ControllerInfo
+		wantErr bool
+	}{
+		"captured identify controller data": {
+			buf: readTestData(t, "identify-controller.bin"),
+			want: ControllerInfo{
+				VendorID:           0x144d,
+				Serial:             "S4EVNF0M123456A",
+				Model:              "Samsung SSD 970 EVO Plus 500GB",
+				FirmwareRevision:   "2B2QEXM7",
+				NumberOfNamespaces: 1,
+			},
+		},
+		"short buffer": {
+			buf:     make([]byte, 512),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseIdentifyController(test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseIdentifyNamespace(t *testing.T) {
+	captured := readTestData(t, "identify-namespace.bin")
+
+	// namespace with NGUID reported, formatted with the 4K LBA format at index 1
+	withNGUID := make([]byte, len(captured))
+	copy(withNGUID, captured)
+	copy(withNGUID[104:120], []byte{
+		0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x5c, 0xd2, 0xe4, 0x3f, 0x91, 0x4f, 0x51, 0x00,
+	})
+	withNGUID[26] = 0x01
+	withNGUID[134] = 12
+
+	tests := map[string]struct {
+		buf     []byte
+		want    NamespaceInfo
+		wantErr bool
+	}{
+		"captured identify namespace data": {
+			buf: captured,
+			want: NamespaceInfo{
+				Size:             976773168,
+				LogicalBlockSize: 512,
+				EUI64:            "0025385891b0a1b2",
+			},
+		},
+		"namespace with NGUID": {
+			buf: withNGUID,
+			want: NamespaceInfo{
+				Size:             976773168,
+				LogicalBlockSize: 4096,
+				NGUID:            "01000000010000005cd2e43f914f5100",
+				EUI64:            "0025385891b0a1b2",
+			},
+		},
+		"short buffer": {
+			buf:     make([]byte, 64),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseIdentifyNamespace(test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseSMARTLog(t *testing.T) {
+	captured := readTestData(t, "smart-log.bin")
+
+	// counters that overflow 64 bits and temperature not reported
+	overflow := make([]byte, len(captured))
+	copy(overflow, captured)
+	overflow[1], overflow[2] = 0, 0
+	overflow[128+8] = 0x01
+
+	capturedLog := SMARTLog{
+		Temperature:             37,
+		AvailableSpare:          100,
+		AvailableSpareThreshold: 10,
+		PercentageUsed:          2,
+		DataUnitsRead:           12345678,
+		DataUnitsWritten:        23456789,
+		PowerCycles:             512,
+		PowerOnHours:            4321,
+		UnsafeShutdowns:         37,
+		ErrorLogEntries:         12,
+	}
+	overflowLog := capturedLog
+	overflowLog.Temperature = 0
+	overflowLog.PowerOnHours = math.MaxUint64
+
+	tests := map[string]struct {
+		buf     []byte
+		want    SMARTLog
+		wantErr bool
+	}{
+		"captured smart log page": {
+			buf:  captured,
+			want: capturedLog,
+		},
+		"overflowing counters": {
+			buf:  overflow,
+			want: overflowLog,
+		},
+		"short buffer": {
+			buf:     make([]byte, 64),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseSMARTLog(test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestIsNVMeNamespace(t *testing.T) {
+	tests := map[string]bool{
+		"/dev/nvme0n1":   true,
+		"/dev/nvme10n12": true,
+		"/dev/nvme0n1p1": false,
+		"/dev/nvme0":     false,
+		"/dev/sda":       false,
+	}
+	for devPath, want := range tests {
+		t.Run(devPath, func(t *testing.T) {
+			assert.Equal(t, want, IsNVMeNamespace(devPath))
+		})
+	}
+}
+
+func TestAdminCmdSize(t *testing.T) {
+	// size of struct nvme_admin_cmd is encoded in the ioctl request code
+	assert.Equal(t, uintptr(NVMeIoctlAdminCmd>>16&0x3FFF), unsafe.Sizeof(adminCmd{}))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nvme
+
+// ioctl request codes defined in linux/nvme_ioctl.h
+const (
+	// NVMeIoctlID is _IO('N', 0x40), returns the namespace ID of the block device
+	NVMeIoctlID = 0x4E40
+	// NVMeIoctlAdminCmd is _IOWR('N', 0x41, struct nvme_admin_cmd)
+	NVMeIoctlAdminCmd = 0xC0484E41
+)
+
+// admin command opcodes
+const (
+	adminGetLogPage = 0x02
+	adminIdentify   = 0x06
+)
+
+// controller or namespace structure (CNS) values of the identify command
+const (
+	identifyCNSNamespace  = 0x00
+	identifyCNSController = 0x01
+)
+
+const (
+	// logPageSMART is the log page identifier of the SMART / Health Information log
+	logPageSMART = 0x02
+	// nsidGlobal is used to get the log page for the controller as a whole
+	nsidGlobal = 0xFFFFFFFF
+	// defaultTimeout of the admin commands in millisecs
+	defaultTimeout = 20000
+)
+
+// size of the data structures returned by the admin commands
+const (
+	identifyDataLen = 4096
+	smartLogLen     = 512
+)
+
+// adminCmd is the struct nvme_admin_cmd (or struct nvme_passthru_cmd) passed
+// to the NVME_IOCTL_ADMIN_CMD ioctl. The layout should exactly match the struct
+// defined in linux/nvme_ioctl.h
+type adminCmd struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+// Identifier is used to identify the NVMe namespace by its devpath
+type Identifier struct {
+	// DevPath is the path of the namespace block device, eg: /dev/nvme0n1
+	DevPath string
+}
+
+// ControllerInfo contains the details from the Identify Controller data structure
+type ControllerInfo struct {
+	// VendorID is the PCI vendor ID of the controller
+	VendorID uint16
+	// Serial is the serial number of the controller
+	Serial string
+	// Model is the model number of the controller
+	Model string
+	// FirmwareRevision is the currently active firmware revision
+	FirmwareRevision string
+	// NumberOfNamespaces is the maximum number of namespaces supported by the controller
+	NumberOfNamespaces uint32
+}
+
+// NamespaceInfo contains the details from the Identify Namespace data structure
+type NamespaceInfo struct {
+	// ID is the namespace ID
+	ID uint32
+	// Size is the total size of the namespace in logical blocks
+	Size uint64
+	// LogicalBlockSize is the size of the logical block of the formatted LBA
+	// format in bytes
+	LogicalBlockSize uint32
+	// NGUID is the namespace globally unique identifier as a hex string,
+	// empty if not reported by the controller
+	NGUID string
+	// EUI64 is the IEEE extended unique identifier as a hex string, empty if
+	// not reported by the controller
+	EUI64 string
+}
+
+// Bits of the critical warning field in the SMART / Health Information log page
+const (
+	// CriticalWarningSpare is set if the available spare capacity is below the threshold
+	CriticalWarningSpare = 1 << 0
+	// CriticalWarningTemperature is set if the temperature is outside the thresholds
+	CriticalWarningTemperature = 1 << 1
+	// CriticalWarningReliability is set if the reliability of the device has
+	// degraded due to media or internal errors
+	CriticalWarningReliability = 1 << 2
+	// CriticalWarningReadOnly is set if the media has been placed in read only mode
+	CriticalWarningReadOnly = 1 << 3
+	// CriticalWarningVolatileBackup is set if the volatile memory backup device has failed
+	CriticalWarningVolatileBackup = 1 << 4
+)
+
+// SMARTLog contains the details from the SMART / Health Information log page
+type SMARTLog struct {
+	// CriticalWarning is the bit field of critical warnings for the state of the controller
+	CriticalWarning uint8
+	// Temperature is the composite temperature of the controller in celsius
+	Temperature int16
+	// AvailableSpare is the normalized percentage of the remaining spare capacity
+	AvailableSpare uint8
+	// AvailableSpareThreshold is the threshold of available spare below which
+	// an asynchronous event is raised
+	AvailableSpareThreshold uint8
+	// PercentageUsed is the vendor specific estimate of the life of the device
+	// used in percent. The value can exceed 100.
+	PercentageUsed uint8
+	// DataUnitsRead is the number of 512 byte data units read, reported in
+	// thousands
+	DataUnitsRead uint64
+	// DataUnitsWritten is the number of 512 byte data units written, reported
+	// in thousands
+	DataUnitsWritten uint64
+	// PowerCycles is the number of power cycles
+	PowerCycles uint64
+	// PowerOnHours is the number of hours the controller has been powered on
+	PowerOnHours uint64
+	// UnsafeShutdowns is the number of shutdowns without a shutdown notification
+	UnsafeShutdowns uint64
+	// MediaErrors is the number of unrecovered data integrity errors
+	MediaErrors uint64
+	// ErrorLogEntries is the number of error information log entries
+	ErrorLogEntries uint64
+}
+
+// DeviceInfo contains all the details fetched from an NVMe namespace
+type DeviceInfo struct {
+	Controller ControllerInfo
+	Namespace  NamespaceInfo
+	SMARTLog   SMARTLog
+}
@@ -18,28 +18,60 @@ package blockdevice
 
 import (
 	"github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/pkg/env"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Config stores the configuration for selecting a block device from a
-// block device claim. It contains the claim spec, selection type and
-// client to interface with etcd
+// block device claim. It contains the claim spec, selection type,
+// selection strategy and client to interface with etcd
 type Config struct {
 	Client          client.Client
 	ClaimSpec       *v1alpha1.DeviceClaimSpec
 	ManualSelection bool
+	// Strategy is used to pick a device when multiple devices
+	// match the claim
+	Strategy v1alpha1.SelectionStrategy
+	// Count is the number of devices to be selected
+	Count int
+	// Placement specifies how the devices are placed across nodes or
+	// zones, when more than one device is selected
+	Placement v1alpha1.DevicePlacement
+	// Rejections contains the filter that rejected each block device
+	// during the last selection
+	Rejections Rejections
 }
 
+// DefaultSelectionStrategy is the strategy used when neither the claim
+// nor the operator specifies a selection strategy
+const DefaultSelectionStrategy = v1alpha1.SelectionStrategyBestFit
+
 // NewConfig creates a new Config struct for the block device claim
 func NewConfig(claimSpec *v1alpha1.DeviceClaimSpec, client client.Client) *Config {
 	isManualSelection := false
 	if claimSpec.BlockDeviceName != "" {
 		isManualSelection = true
 	}
+	// strategy in the claim takes precedence over the cluster wide default
+	strategy := claimSpec.SelectionStrategy
+	if strategy == "" {
+		strategy = v1alpha1.SelectionStrategy(env.GetDefaultSelectionStrategy())
+	}
+	if strategy == "" {
+		strategy = DefaultSelectionStrategy
+	}
+	// count is not applicable in manual selection
+	count := int(claimSpec.Count)
+	if count < 1 || isManualSelection {
+		count = 1
+	}
 	c := &Config{
 		Client:          client,
 		ClaimSpec:       claimSpec,
 		ManualSelection: isManualSelection,
+		Strategy:        strategy,
+		Count:           count,
+		Placement:       claimSpec.Placement,
 	}
 	return c
 }
@@ -24,6 +24,8 @@ import (
 	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
 	"github.com/openebs/node-disk-manager/db/kubernetes"
 	"github.com/openebs/node-disk-manager/pkg/select/verify"
+	"github.com/openebs/node-disk-manager/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -47,11 +49,30 @@ const (
 	FilterOutSparseBlockDevices = "filterSparseBlockDevice"
 	// FilterNodeName is used to filter based on nodename
 	FilterNodeName = "filterNodeName"
+	// FilterZone is used to filter based on the zone of the node
+	FilterZone = "filterZone"
+	// FilterRegion is used to filter based on the region of the node
+	FilterRegion = "filterRegion"
 	// FilterBlockDeviceTag is used to filter out blockdevices having
 	// openebs.io/blockdevice-tag label
 	FilterBlockDeviceTag = "filterBlockDeviceTag"
 	// FilterOutLegacyAnnotation is used to filter out devices with legacy annotation
 	FilterOutLegacyAnnotation = "filterOutLegacyAnnotation"
+	// FilterHealthy is used to filter out devices which are quarantined because
+	// of their health
+	FilterHealthy = "filterHealthy"
+	// FilterNodeHeartbeat is used to filter out devices on nodes whose NDM
+	// daemon has stopped renewing its heartbeat
+	FilterNodeHeartbeat = "filterNodeHeartbeat"
+	// FilterNodeRecreated is used to filter out devices on nodes which were
+	// recreated on a different machine
+	FilterNodeRecreated = "filterNodeRecreated"
+	// FilterZonedModel is used to filter based on the zoned model of the device.
+	// Host-managed zoned devices are filtered out unless requested by the claim
+	FilterZonedModel = "filterZonedModel"
+	// FilterCapabilities is used to filter based on the IO capabilities
+	// required by the claim, like discard support
+	FilterCapabilities = "filterCapabilities"
 )
 
 const (
@@ -71,15 +92,28 @@ var filterFuncMap = map[string]filterFunc{
 	FilterResourceStorage:       filterResourceStorage,
 	FilterOutSparseBlockDevices: filterOutSparseBlockDevice,
 	FilterNodeName:              filterNodeName,
+	FilterZone:                  filterZone,
+	FilterRegion:                filterRegion,
 	FilterBlockDeviceTag:        filterBlockDeviceTag,
 	FilterOutLegacyAnnotation:   filterOutLegacyAnnotation,
+	FilterHealthy:               filterHealthy,
+	FilterNodeHeartbeat:         filterNodeHeartbeat,
+	FilterNodeRecreated:         filterNodeRecreated,
+	FilterZonedModel:            filterZonedModel,
+	FilterCapabilities:          filterCapabilities,
 }
 
 // ApplyFilters apply the filter specified in the filterkeys on the given BD List,
+// The first filter that rejected each BD is recorded in the rejections of the config.
 func (c *Config) ApplyFilters(bdList *apis.BlockDeviceList, filterKeys ...string) *apis.BlockDeviceList {
+	if c.Rejections == nil {
+		c.Rejections = make(Rejections)
+	}
 	filteredList := bdList
 	for _, key := range filterKeys {
-		filteredList = filterFuncMap[key](filteredList, c.ClaimSpec)
+		originalList := filteredList
+		filteredList = filterFuncMap[key](originalList, c.ClaimSpec)
+		c.Rejections.record(originalList, filteredList, key)
 	}
 	return filteredList
 }
@@ -101,7 +135,8 @@ func filterActive(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec)
 	return filteredBDList
 }
 
-// filterUnclaimed returns only unclaimed devices
+// filterUnclaimed returns only unclaimed devices. Devices which are Released,
+// Retained or whose cleanup has failed are also excluded.
 func filterUnclaimed(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
 	filteredBDList := &apis.BlockDeviceList{
 		TypeMeta: metav1.TypeMeta{
@@ -216,7 +251,6 @@ func filterResourceStorage(originalBD *apis.BlockDeviceList, spec *apis.DeviceCl
 	for _, bd := range originalBD.Items {
 		if bd.Spec.Capacity.Storage >= uint64(capacity) {
 			filteredBDList.Items = append(filteredBDList.Items, bd)
-			break
 		}
 	}
 	return filteredBDList
@@ -261,6 +295,52 @@ func filterNodeName(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec
 	return filteredBDList
 }
 
+// filterZone returns only BDs on nodes in the zone requested by the claim
+func filterZone(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+
+	// if zone is not given in BDC, this filter will not work
+	if len(spec.BlockDeviceNodeAttributes.Zone) == 0 {
+		return originalBD
+	}
+
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		if bd.Spec.NodeAttributes.Zone == spec.BlockDeviceNodeAttributes.Zone {
+			filteredBDList.Items = append(filteredBDList.Items, bd)
+		}
+	}
+	return filteredBDList
+}
+
+// filterRegion returns only BDs on nodes in the region requested by the claim
+func filterRegion(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+
+	// if region is not given in BDC, this filter will not work
+	if len(spec.BlockDeviceNodeAttributes.Region) == 0 {
+		return originalBD
+	}
+
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		if bd.Spec.NodeAttributes.Region == spec.BlockDeviceNodeAttributes.Region {
+			filteredBDList.Items = append(filteredBDList.Items, bd)
+		}
+	}
+	return filteredBDList
+}
+
 // filterBlockDeviceTag is used to filter out BlockDevices which do not have the
 // block-device-tag label. This filter works on a block device list which has
 // already been filtered by the given selector.
@@ -348,6 +428,133 @@ func filterOutLegacyAnnotation(originalBD *apis.BlockDeviceList, spec *apis.Devi
 	return filteredBDList
 }
 
+// filterHealthy removes all blockdevices which are quarantined by NDM because
+// of their health
+func filterHealthy(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		if util.CheckTruthy(bd.Labels[controller.NDMQuarantinedKey]) {
+			continue
+		}
+		filteredBDList.Items = append(filteredBDList.Items, bd)
+	}
+	return filteredBDList
+}
+
+// filterNodeHeartbeat removes all blockdevices on nodes whose heartbeat has
+// expired. The state of such a device may not have been updated yet, since
+// the device is marked Unknown only after the grace period.
+func filterNodeHeartbeat(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		if meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeHeartbeatLost) {
+			continue
+		}
+		filteredBDList.Items = append(filteredBDList.Items, bd)
+	}
+	return filteredBDList
+}
+
+// filterNodeRecreated removes all blockdevices which were attached to the
+// previous machine of a recreated node, till the operator has found out whether
+// the device is stale or has moved to the new machine
+func filterNodeRecreated(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		if meta.IsStatusConditionTrue(bd.Status.Conditions, apis.BlockDeviceConditionNodeRecreated) {
+			continue
+		}
+		filteredBDList.Items = append(filteredBDList.Items, bd)
+	}
+	return filteredBDList
+}
+
+// filterZonedModel returns only BDs which match the zoned model requested by the
+// claim. If the claim does not request a zoned model, host-managed devices are
+// removed, since they cannot be used by consumers which are not aware of zones.
+func filterZonedModel(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		zonedModel := apis.ZonedModelNone
+		if bd.Spec.Zoned != nil {
+			zonedModel = apis.DeviceZonedModel(bd.Spec.Zoned.Model)
+		}
+		if spec.Details.ZonedModel == "" {
+			if zonedModel == apis.ZonedModelHostManaged {
+				continue
+			}
+		} else if zonedModel != spec.Details.ZonedModel {
+			continue
+		}
+		filteredBDList.Items = append(filteredBDList.Items, bd)
+	}
+	return filteredBDList
+}
+
+// filterCapabilities returns only BDs which match the IO capabilities required by
+// the claim. BDs whose capabilities are not known are removed if any capability
+// is required.
+func filterCapabilities(originalBD *apis.BlockDeviceList, spec *apis.DeviceClaimSpec) *apis.BlockDeviceList {
+	required := spec.Details.Capabilities
+
+	// if no capability is required, this filter will not be effective
+	if required == (apis.DeviceCapabilityRequirements{}) {
+		return originalBD
+	}
+
+	filteredBDList := &apis.BlockDeviceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BlockDevice",
+			APIVersion: "openebs.io/v1alpha1",
+		},
+	}
+
+	for _, bd := range originalBD.Items {
+		queue := bd.Spec.Queue
+		if queue == nil {
+			continue
+		}
+		if matchesCapability(required.Discard, queue.DiscardMaxBytes > 0) &&
+			matchesCapability(required.VolatileWriteCache, queue.WriteCache == blockdevice.WriteCacheWriteBack) &&
+			matchesCapability(required.FUA, queue.FUA) &&
+			matchesCapability(required.DAX, queue.DAX) &&
+			matchesCapability(required.WriteZeroes, queue.WriteZeroesMaxBytes > 0) {
+			filteredBDList.Items = append(filteredBDList.Items, bd)
+		}
+	}
+	return filteredBDList
+}
+
+// matchesCapability checks if the capability of the BD is as required. A
+// capability which is not required always matches.
+func matchesCapability(required *bool, supported bool) bool {
+	return required == nil || *required == supported
+}
+
 // isBDTagDoesNotExistSelectorRequired is used to check whether a selector
 // was present on the BDC. It is used to decide whether a `does not exist` selector
 // for the block-device-tag label should be applied or not.
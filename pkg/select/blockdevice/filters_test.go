@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
 	"github.com/openebs/node-disk-manager/db/kubernetes"
 
 	"github.com/stretchr/testify/assert"
@@ -242,6 +243,101 @@ func TestFilterBlockDeviceTag(t *testing.T) {
 	}
 }
 
+func TestFilterHealthy(t *testing.T) {
+	bdLabelList := []BDLabel{
+		map[string]string{
+			kubernetes.KubernetesHostNameLabel: "host1",
+		},
+		map[string]string{
+			kubernetes.KubernetesHostNameLabel: "host2",
+			controller.NDMQuarantinedKey:       controller.TrueString,
+		},
+		map[string]string{
+			kubernetes.KubernetesHostNameLabel: "host3",
+			controller.NDMQuarantinedKey:       controller.FalseString,
+		},
+	}
+
+	got := filterHealthy(createFakeBlockDeviceList(bdLabelList, len(bdLabelList)), &apis.DeviceClaimSpec{})
+	assert.Equal(t, []string{"bd0", "bd2"}, bdNames(got))
+}
+
+// bdNames returns the names of the blockdevices in the list
+func bdNames(list *apis.BlockDeviceList) []string {
+	names := make([]string, 0, len(list.Items))
+	for _, bd := range list.Items {
+		names = append(names, bd.Name)
+	}
+	return names
+}
+
+func TestFilterTopology(t *testing.T) {
+	bdList := createFakeBlockDeviceList(make(BDLabelList, 4), 4)
+	topology := []apis.NodeAttribute{
+		{NodeName: "node1", Zone: "zone-a", Region: "region-1"},
+		{NodeName: "node2", Zone: "zone-b", Region: "region-1"},
+		{NodeName: "node3", Zone: "zone-c", Region: "region-2"},
+		{NodeName: "node4"},
+	}
+	for i := range bdList.Items {
+		bdList.Items[i].Spec.NodeAttributes = topology[i]
+	}
+
+	tests := map[string]struct {
+		nodeAttributes apis.BlockDeviceNodeAttributes
+		wantDevices    []string
+	}{
+		"no topology constraint": {
+			wantDevices: []string{"bd0", "bd1", "bd2", "bd3"},
+		},
+		"zone": {
+			nodeAttributes: apis.BlockDeviceNodeAttributes{Zone: "zone-b"},
+			wantDevices:    []string{"bd1"},
+		},
+		"region": {
+			nodeAttributes: apis.BlockDeviceNodeAttributes{Region: "region-1"},
+			wantDevices:    []string{"bd0", "bd1"},
+		},
+		"zone in another region": {
+			nodeAttributes: apis.BlockDeviceNodeAttributes{Zone: "zone-c", Region: "region-1"},
+			wantDevices:    []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &apis.DeviceClaimSpec{BlockDeviceNodeAttributes: test.nodeAttributes}
+			got := filterZone(filterRegion(bdList, spec), spec)
+			assert.Equal(t, test.wantDevices, bdNames(got))
+		})
+	}
+}
+
+func TestFilterNodeHeartbeat(t *testing.T) {
+	bdList := createFakeBlockDeviceList(make(BDLabelList, 3), 3)
+	bdList.Items[1].Status.Conditions = []v1.Condition{
+		{Type: apis.BlockDeviceConditionNodeHeartbeatLost, Status: v1.ConditionTrue},
+	}
+	bdList.Items[2].Status.Conditions = []v1.Condition{
+		{Type: apis.BlockDeviceConditionNodeHeartbeatLost, Status: v1.ConditionFalse},
+	}
+
+	got := filterNodeHeartbeat(bdList, &apis.DeviceClaimSpec{})
+	assert.Equal(t, []string{"bd0", "bd2"}, bdNames(got))
+}
+
+func TestFilterNodeRecreated(t *testing.T) {
+	bdList := createFakeBlockDeviceList(make(BDLabelList, 3), 3)
+	bdList.Items[1].Status.Conditions = []v1.Condition{
+		{Type: apis.BlockDeviceConditionNodeRecreated, Status: v1.ConditionTrue},
+	}
+	bdList.Items[2].Status.Conditions = []v1.Condition{
+		{Type: apis.BlockDeviceConditionNodeRecreated, Status: v1.ConditionFalse},
+	}
+
+	got := filterNodeRecreated(bdList, &apis.DeviceClaimSpec{})
+	assert.Equal(t, []string{"bd0", "bd2"}, bdNames(got))
+}
+
 func createFakeBlockDeviceList(labelList BDLabelList, noOfBDs int) *apis.BlockDeviceList {
 	bdListAPI := &apis.BlockDeviceList{
 		TypeMeta: v1.TypeMeta{
@@ -268,3 +364,87 @@ func createFakeBlockDevice(name string, label map[string]string) apis.BlockDevic
 	bdAPI.Labels = label
 	return bdAPI
 }
+
+func TestFilterZonedModel(t *testing.T) {
+	bdList := createFakeBlockDeviceList(make(BDLabelList, 3), 3)
+	bdList.Items[1].Spec.Zoned = &apis.ZonedInfo{Model: string(apis.ZonedModelHostAware)}
+	bdList.Items[2].Spec.Zoned = &apis.ZonedInfo{Model: string(apis.ZonedModelHostManaged)}
+
+	tests := map[string]struct {
+		zonedModel  apis.DeviceZonedModel
+		wantDevices []string
+	}{
+		"no zoned model requested": {
+			wantDevices: []string{"bd0", "bd1"},
+		},
+		"conventional device requested": {
+			zonedModel:  apis.ZonedModelNone,
+			wantDevices: []string{"bd0"},
+		},
+		"host-aware device requested": {
+			zonedModel:  apis.ZonedModelHostAware,
+			wantDevices: []string{"bd1"},
+		},
+		"host-managed device requested": {
+			zonedModel:  apis.ZonedModelHostManaged,
+			wantDevices: []string{"bd2"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &apis.DeviceClaimSpec{Details: apis.DeviceClaimDetails{ZonedModel: test.zonedModel}}
+			got := filterZonedModel(bdList, spec)
+			assert.Equal(t, test.wantDevices, bdNames(got))
+		})
+	}
+}
+
+func TestFilterCapabilities(t *testing.T) {
+	bdList := createFakeBlockDeviceList(make(BDLabelList, 3), 3)
+	bdList.Items[0].Spec.Queue = &apis.QueueInfo{
+		DiscardMaxBytes: 2199023255040,
+		WriteCache:      "write back",
+		FUA:             true,
+	}
+	bdList.Items[1].Spec.Queue = &apis.QueueInfo{
+		WriteCache: "write through",
+	}
+	// capabilities of bd2 are not known
+
+	enabled, disabled := true, false
+	tests := map[string]struct {
+		capabilities apis.DeviceCapabilityRequirements
+		wantDevices  []string
+	}{
+		"no capability required": {
+			wantDevices: []string{"bd0", "bd1", "bd2"},
+		},
+		"discard required": {
+			capabilities: apis.DeviceCapabilityRequirements{Discard: &enabled},
+			wantDevices:  []string{"bd0"},
+		},
+		"volatile write cache disabled": {
+			capabilities: apis.DeviceCapabilityRequirements{VolatileWriteCache: &disabled},
+			wantDevices:  []string{"bd1"},
+		},
+		"discard and FUA required with volatile write cache": {
+			capabilities: apis.DeviceCapabilityRequirements{
+				Discard:            &enabled,
+				FUA:                &enabled,
+				VolatileWriteCache: &enabled,
+			},
+			wantDevices: []string{"bd0"},
+		},
+		"DAX required": {
+			capabilities: apis.DeviceCapabilityRequirements{DAX: &enabled},
+			wantDevices:  []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &apis.DeviceClaimSpec{Details: apis.DeviceClaimDetails{Capabilities: test.capabilities}}
+			got := filterCapabilities(bdList, spec)
+			assert.Equal(t, test.wantDevices, bdNames(got))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+// FilterDevices selects the number of block devices requested by the claim
+// from a list of block devices. Either all the requested devices are
+// selected or an error is returned.
+func (c *Config) FilterDevices(bdList *apis.BlockDeviceList) ([]apis.BlockDevice, error) {
+	if c.Count <= 1 {
+		selectedDevice, err := c.Filter(bdList)
+		if err != nil {
+			return nil, err
+		}
+		return []apis.BlockDevice{*selectedDevice}, nil
+	}
+
+	if len(bdList.Items) == 0 {
+		return nil, c.noDevicesFoundError()
+	}
+
+	// rejections are recorded afresh for each selection
+	c.Rejections = make(Rejections)
+
+	candidateDevices, err := c.getCandidateDevices(bdList)
+	if err != nil {
+		return nil, err
+	}
+	sortedDevices, err := c.getSortedDevices(candidateDevices, bdList)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedDevices []apis.BlockDevice
+	switch c.Placement {
+	case apis.PlacementSameNode:
+		selectedDevices = selectFromSameNode(sortedDevices.Items, c.Count)
+	case apis.PlacementSpreadAcrossNodes:
+		selectedDevices = selectAcrossNodes(sortedDevices.Items, c.Count)
+	case apis.PlacementSpreadAcrossZones:
+		selectedDevices = selectAcrossZones(sortedDevices.Items, c.Count)
+	default:
+		if len(sortedDevices.Items) >= c.Count {
+			selectedDevices = sortedDevices.Items[:c.Count]
+		}
+	}
+
+	if len(selectedDevices) < c.Count {
+		return nil, newSelectionError(apis.BlockDeviceClaimReasonInsufficientDevices,
+			"could not find %d devices matching the criteria, %d devices are available (%s)",
+			c.Count, len(sortedDevices.Items), c.Rejections.Summary(len(bdList.Items)))
+	}
+	return selectedDevices, nil
+}
+
+// selectFromSameNode selects count devices which are present on the same
+// node. The devices are expected to be in the order of preference, and the
+// node on which the required number of devices are available first is used.
+func selectFromSameNode(bds []apis.BlockDevice, count int) []apis.BlockDevice {
+	devicesOnNode := make(map[string][]apis.BlockDevice)
+	for _, bd := range bds {
+		nodeName := bd.Spec.NodeAttributes.NodeName
+		devicesOnNode[nodeName] = append(devicesOnNode[nodeName], bd)
+		if len(devicesOnNode[nodeName]) == count {
+			return devicesOnNode[nodeName]
+		}
+	}
+	return nil
+}
+
+// selectAcrossNodes selects count devices such that each device is present
+// on a different node. The devices are expected to be in the order of
+// preference, and the most preferred device on each node is used.
+func selectAcrossNodes(bds []apis.BlockDevice, count int) []apis.BlockDevice {
+	selectedNodes := make(map[string]bool)
+	selectedDevices := make([]apis.BlockDevice, 0, count)
+	for _, bd := range bds {
+		nodeName := bd.Spec.NodeAttributes.NodeName
+		if selectedNodes[nodeName] {
+			continue
+		}
+		selectedNodes[nodeName] = true
+		selectedDevices = append(selectedDevices, bd)
+		if len(selectedDevices) == count {
+			break
+		}
+	}
+	return selectedDevices
+}
+
+// selectAcrossZones selects count devices such that each device is present
+// on a node in a different zone. The devices are expected to be in the order
+// of preference, and the most preferred device in each zone is used. Devices
+// on nodes without a zone are not selected, as their failure domain is not known.
+func selectAcrossZones(bds []apis.BlockDevice, count int) []apis.BlockDevice {
+	selectedZones := make(map[string]bool)
+	selectedDevices := make([]apis.BlockDevice, 0, count)
+	for _, bd := range bds {
+		zone := bd.Spec.NodeAttributes.Zone
+		if zone == "" || selectedZones[zone] {
+			continue
+		}
+		selectedZones[zone] = true
+		selectedDevices = append(selectedDevices, bd)
+		if len(selectedDevices) == count {
+			break
+		}
+	}
+	return selectedDevices
+}
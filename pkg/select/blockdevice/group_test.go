@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	"testing"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFilterDevices(t *testing.T) {
+	bdList := &apis.BlockDeviceList{
+		Items: []apis.BlockDevice{
+			createFakeBlockDeviceForScoring("bd1", "node1", blockdevice.DriveTypeHDD, 10*GiB),
+			createFakeBlockDeviceForScoring("bd2", "node1", blockdevice.DriveTypeHDD, 40*GiB),
+			createFakeBlockDeviceForScoring("bd3", "node2", blockdevice.DriveTypeHDD, 20*GiB),
+			createFakeBlockDeviceForScoring("bd4", "node2", blockdevice.DriveTypeHDD, 30*GiB),
+			createFakeBlockDeviceForScoring("bd5", "node2", blockdevice.DriveTypeHDD, 50*GiB),
+			createFakeBlockDeviceForScoring("bd6", "node3", blockdevice.DriveTypeHDD, 5*GiB),
+		},
+	}
+	// node3 does not have a zone
+	zones := map[string]string{"node1": "zone-a", "node2": "zone-b"}
+	for i := range bdList.Items {
+		bdList.Items[i].Spec.NodeAttributes.Zone = zones[bdList.Items[i].Spec.NodeAttributes.NodeName]
+	}
+
+	tests := map[string]struct {
+		count       int32
+		placement   apis.DevicePlacement
+		zone        string
+		wantDevices []string
+		wantReason  string
+	}{
+		"single device": {
+			count:       1,
+			wantDevices: []string{"bd1"},
+		},
+		"multiple devices from any node": {
+			count:       3,
+			wantDevices: []string{"bd1", "bd3", "bd4"},
+		},
+		"multiple devices from the same node": {
+			count:       3,
+			placement:   apis.PlacementSameNode,
+			wantDevices: []string{"bd3", "bd4", "bd5"},
+		},
+		"multiple devices spread across nodes": {
+			count:       2,
+			placement:   apis.PlacementSpreadAcrossNodes,
+			wantDevices: []string{"bd1", "bd3"},
+		},
+		"not enough devices on the same node": {
+			count:      4,
+			placement:  apis.PlacementSameNode,
+			wantReason: apis.BlockDeviceClaimReasonInsufficientDevices,
+		},
+		"not enough nodes to spread the devices": {
+			count:      3,
+			placement:  apis.PlacementSpreadAcrossNodes,
+			wantReason: apis.BlockDeviceClaimReasonInsufficientDevices,
+		},
+		"multiple devices spread across zones": {
+			count:       2,
+			placement:   apis.PlacementSpreadAcrossZones,
+			wantDevices: []string{"bd1", "bd3"},
+		},
+		"multiple devices from the requested zone": {
+			count:       2,
+			zone:        "zone-b",
+			wantDevices: []string{"bd3", "bd4"},
+		},
+		"no devices in the requested zone": {
+			count:      2,
+			zone:       "zone-c",
+			wantReason: apis.BlockDeviceClaimReasonTopologyMismatch,
+		},
+		"not enough zones to spread the devices": {
+			count:      3,
+			placement:  apis.PlacementSpreadAcrossZones,
+			wantReason: apis.BlockDeviceClaimReasonInsufficientDevices,
+		},
+		"not enough devices": {
+			count:      6,
+			wantReason: apis.BlockDeviceClaimReasonInsufficientDevices,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &apis.DeviceClaimSpec{
+				Resources: apis.DeviceClaimResources{
+					Requests: v1.ResourceList{
+						apis.ResourceStorage: resource.MustParse("10Gi"),
+					},
+				},
+				Count:     test.count,
+				Placement: test.placement,
+				BlockDeviceNodeAttributes: apis.BlockDeviceNodeAttributes{
+					Zone: test.zone,
+				},
+			}
+			c := NewConfig(spec, nil)
+			got, err := c.FilterDevices(bdList.DeepCopy())
+			if test.wantReason != "" {
+				assert.Error(t, err)
+				assert.Equal(t, test.wantReason, GetSelectionFailureReason(err))
+				return
+			}
+			assert.NoError(t, err)
+			gotDevices := make([]string, 0)
+			for _, bd := range got {
+				gotDevices = append(gotDevices, bd.Name)
+			}
+			assert.Equal(t, test.wantDevices, gotDevices)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+// rejectionDescriptionMap contains a short description for each filter, which
+// is used to explain why a block device was rejected by that filter
+var rejectionDescriptionMap = map[string]string{
+	FilterActive:                "inactive",
+	FilterUnclaimed:             "claimed",
+	FilterDeviceType:            "wrong device type",
+	FilterVolumeMode:            "wrong volume mode",
+	FilterBlockDeviceName:       "name mismatch",
+	FilterResourceStorage:       "too small",
+	FilterOutSparseBlockDevices: "sparse",
+	FilterNodeName:              "wrong node",
+	FilterZone:                  "wrong zone",
+	FilterRegion:                "wrong region",
+	FilterBlockDeviceTag:        "tagged",
+	FilterOutLegacyAnnotation:   "legacy uuid scheme",
+	FilterHealthy:               "quarantined",
+	FilterNodeHeartbeat:         "node heartbeat lost",
+	FilterNodeRecreated:         "node recreated",
+	FilterZonedModel:            "wrong zoned model",
+	FilterCapabilities:          "missing capabilities",
+}
+
+// Rejections records, for each block device, the first filter that
+// rejected it. The key is the name of the block device and the value
+// is the filter key.
+type Rejections map[string]string
+
+// record marks all the devices in original that are not present in filtered
+// as rejected by the filter. If the device was already rejected by an earlier
+// filter, it is not updated.
+func (r Rejections) record(original, filtered *apis.BlockDeviceList, filterKey string) {
+	if len(original.Items) == len(filtered.Items) {
+		return
+	}
+	selected := make(map[string]struct{}, len(filtered.Items))
+	for _, bd := range filtered.Items {
+		selected[bd.Name] = struct{}{}
+	}
+	for _, bd := range original.Items {
+		if _, ok := selected[bd.Name]; ok {
+			continue
+		}
+		if _, ok := r[bd.Name]; !ok {
+			r[bd.Name] = filterKey
+		}
+	}
+}
+
+// RejectedBy returns the filter which rejected the given block device
+func (r Rejections) RejectedBy(bdName string) (string, bool) {
+	filterKey, ok := r[bdName]
+	return filterKey, ok
+}
+
+// Summary aggregates the rejections into a human readable summary, eg:
+// "12 devices: 5 claimed, 4 wrong node, 3 too small". total is the number
+// of block devices that were evaluated.
+func (r Rejections) Summary(total int) string {
+	counts := make(map[string]int)
+	for _, filterKey := range r {
+		counts[describeFilter(filterKey)]++
+	}
+
+	descriptions := make([]string, 0, len(counts))
+	for description := range counts {
+		descriptions = append(descriptions, description)
+	}
+	// most common rejection reasons are listed first
+	sort.Slice(descriptions, func(i, j int) bool {
+		if counts[descriptions[i]] != counts[descriptions[j]] {
+			return counts[descriptions[i]] > counts[descriptions[j]]
+		}
+		return descriptions[i] < descriptions[j]
+	})
+
+	parts := make([]string, 0, len(descriptions))
+	for _, description := range descriptions {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[description], description))
+	}
+
+	summary := fmt.Sprintf("%d devices", total)
+	if len(parts) != 0 {
+		summary += ": " + strings.Join(parts, ", ")
+	}
+	return summary
+}
+
+// describeFilter returns the description of the filter, if no description
+// is available, the filter key itself is used
+func describeFilter(filterKey string) string {
+	if description, ok := rejectionDescriptionMap[filterKey]; ok {
+		return description
+	}
+	return filterKey
+}
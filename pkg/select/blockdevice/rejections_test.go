@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	"testing"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestRejectionsSummary(t *testing.T) {
+	tests := map[string]struct {
+		rejections Rejections
+		total      int
+		want       string
+	}{
+		"no rejections": {
+			rejections: Rejections{},
+			total:      2,
+			want:       "2 devices",
+		},
+		"rejections are ordered by count": {
+			rejections: Rejections{
+				"bd1": FilterNodeName,
+				"bd2": FilterUnclaimed,
+				"bd3": FilterUnclaimed,
+				"bd4": FilterResourceStorage,
+				"bd5": FilterNodeName,
+				"bd6": FilterUnclaimed,
+			},
+			total: 7,
+			want:  "7 devices: 3 claimed, 2 wrong node, 1 too small",
+		},
+		"equal counts are ordered by description": {
+			rejections: Rejections{
+				"bd1": FilterResourceStorage,
+				"bd2": FilterActive,
+			},
+			total: 2,
+			want:  "2 devices: 1 inactive, 1 too small",
+		},
+		"filter without description": {
+			rejections: Rejections{
+				"bd1": "customFilter",
+			},
+			total: 1,
+			want:  "1 devices: 1 customFilter",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.rejections.Summary(test.total))
+		})
+	}
+}
+
+func TestFilterRecordsRejections(t *testing.T) {
+	spec := &apis.DeviceClaimSpec{
+		Resources: apis.DeviceClaimResources{
+			Requests: v1.ResourceList{
+				apis.ResourceStorage: resource.MustParse("10Gi"),
+			},
+		},
+		BlockDeviceNodeAttributes: apis.BlockDeviceNodeAttributes{
+			NodeName: "node1",
+		},
+	}
+
+	claimed := createFakeBlockDeviceForScoring("bd1", "node1", blockdevice.DriveTypeHDD, 20*GiB)
+	claimed.Status.ClaimState = apis.BlockDeviceClaimed
+	inactiveAndClaimed := createFakeBlockDeviceForScoring("bd2", "node1", blockdevice.DriveTypeHDD, 20*GiB)
+	inactiveAndClaimed.Status.State = controller.NDMInactive
+	inactiveAndClaimed.Status.ClaimState = apis.BlockDeviceClaimed
+	wrongNode := createFakeBlockDeviceForScoring("bd3", "node2", blockdevice.DriveTypeHDD, 20*GiB)
+	tooSmall := createFakeBlockDeviceForScoring("bd4", "node1", blockdevice.DriveTypeHDD, 5*GiB)
+	quarantined := createFakeBlockDeviceForScoring("bd5", "node1", blockdevice.DriveTypeHDD, 20*GiB)
+	quarantined.Labels = map[string]string{controller.NDMQuarantinedKey: controller.TrueString}
+
+	tests := map[string]struct {
+		bdList         []apis.BlockDevice
+		wantRejections Rejections
+		wantErr        string
+	}{
+		"no devices pass the filters": {
+			bdList: []apis.BlockDevice{claimed, inactiveAndClaimed, wrongNode, tooSmall, quarantined},
+			wantRejections: Rejections{
+				"bd1": FilterUnclaimed,
+				"bd2": FilterActive,
+				"bd3": FilterNodeName,
+				"bd4": FilterResourceStorage,
+				"bd5": FilterHealthy,
+			},
+			wantErr: "could not find a device with matching resource requirements " +
+				"(5 devices: 1 claimed, 1 inactive, 1 quarantined, 1 too small, 1 wrong node)",
+		},
+		"all devices are eliminated by the candidate filters": {
+			bdList: []apis.BlockDevice{claimed, wrongNode},
+			wantRejections: Rejections{
+				"bd1": FilterUnclaimed,
+				"bd3": FilterNodeName,
+			},
+			wantErr: "no devices found matching the criteria (2 devices: 1 claimed, 1 wrong node)",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Config{
+				ClaimSpec: spec,
+				Strategy:  DefaultSelectionStrategy,
+			}
+			_, err := c.Filter(&apis.BlockDeviceList{Items: test.bdList})
+			assert.EqualError(t, err, test.wantErr)
+			assert.Equal(t, test.wantRejections, c.Rejections)
+		})
+	}
+}
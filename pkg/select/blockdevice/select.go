@@ -17,21 +17,60 @@ limitations under the License.
 package blockdevice
 
 import (
+	"errors"
 	"fmt"
+
 	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
 )
 
+// SelectionError is the error returned when a block device could not be
+// selected for a claim. It contains the reason for the failure which can be
+// used in the claim status.
+type SelectionError struct {
+	// Reason is the machine readable reason for the failure
+	Reason string
+	// Message is the human readable description of the failure
+	Message string
+}
+
+// Error implements the error interface
+func (e *SelectionError) Error() string {
+	return e.Message
+}
+
+// newSelectionError creates a new SelectionError with the given reason
+func newSelectionError(reason, format string, a ...interface{}) *SelectionError {
+	return &SelectionError{
+		Reason:  reason,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// GetSelectionFailureReason returns the reason for the selection failure from
+// the error returned by Filter. If the error is not a SelectionError,
+// NoMatchingDevices will be returned as the reason.
+func GetSelectionFailureReason(err error) string {
+	var selectionErr *SelectionError
+	if errors.As(err, &selectionErr) {
+		return selectionErr.Reason
+	}
+	return apis.BlockDeviceClaimReasonNoMatchingDevices
+}
+
 // Filter selects a single block device from a list of block devices
 func (c *Config) Filter(bdList *apis.BlockDeviceList) (*apis.BlockDevice, error) {
 	if len(bdList.Items) == 0 {
-		return nil, fmt.Errorf("no blockdevices found")
+		return nil, c.noDevicesFoundError()
 	}
 
+	// rejections are recorded afresh for each selection
+	c.Rejections = make(Rejections)
+
 	candidateDevices, err := c.getCandidateDevices(bdList)
 	if err != nil {
 		return nil, err
 	}
-	selectedDevice, err := c.getSelectedDevice(candidateDevices)
+	selectedDevice, err := c.getSelectedDevice(candidateDevices, bdList)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +83,12 @@ func (c *Config) getCandidateDevices(bdList *apis.BlockDeviceList) (*apis.BlockD
 
 	// filterKeys to be used for filtering, by default active and unclaimed filter is present
 	filterKeys := []string{FilterActive,
+		// devices on nodes which are not reachable cannot be claimed, even if
+		// they have not been marked Unknown yet
+		FilterNodeHeartbeat,
+		// devices of a recreated node cannot be claimed till it is known
+		// whether they have moved to the new machine
+		FilterNodeRecreated,
 		FilterUnclaimed,
 		// do not consider any devices with legacy annotation for claiming
 		FilterOutLegacyAnnotation,
@@ -62,37 +107,101 @@ func (c *Config) getCandidateDevices(bdList *apis.BlockDeviceList) (*apis.BlockD
 			// Sparse BDs can be claimed only by manual selection. Therefore, all
 			// sparse BDs will be filtered out in auto mode
 			FilterOutSparseBlockDevices,
+			// devices quarantined because of their health can be claimed only
+			// by manual selection
+			FilterHealthy,
 			FilterDeviceType,
+			// host-managed zoned devices can be claimed only if requested
+			FilterZonedModel,
+			FilterCapabilities,
 			FilterVolumeMode,
 			FilterNodeName,
+			FilterRegion,
+			FilterZone,
 		)
 	}
 
-	candidateBD := c.ApplyFilters(bdList, filterKeys...)
-
-	if len(candidateBD.Items) == 0 {
-		return nil, fmt.Errorf("no devices found matching the criteria")
+	// filters are applied one at a time, so that the filter which eliminated
+	// all the devices can be used to determine the reason for the failure
+	candidateBD := bdList
+	for _, key := range filterKeys {
+		candidateBD = c.ApplyFilters(candidateBD, key)
+		if len(candidateBD.Items) == 0 {
+			reason := apis.BlockDeviceClaimReasonNoMatchingDevices
+			switch key {
+			case FilterNodeName:
+				reason = apis.BlockDeviceClaimReasonNodeMismatch
+			case FilterNodeHeartbeat:
+				reason = apis.BlockDeviceClaimReasonNodeHeartbeatLost
+			case FilterNodeRecreated:
+				reason = apis.BlockDeviceClaimReasonNodeRecreated
+			case FilterRegion, FilterZone:
+				reason = apis.BlockDeviceClaimReasonTopologyMismatch
+			}
+			return nil, newSelectionError(reason, "no devices found matching the criteria (%s)",
+				c.Rejections.Summary(len(bdList.Items)))
+		}
 	}
 
 	return candidateBD, nil
 }
 
 // getSelectedDevice selects a single a block device based on the resource requirements
-// requested by the claim
-func (c *Config) getSelectedDevice(bdList *apis.BlockDeviceList) (*apis.BlockDevice, error) {
+// requested by the claim. If multiple devices satisfy the requirements, the device is
+// picked using the selection strategy. allDevices is the complete list of devices
+// from which the candidates were obtained, and is used to build the scoring context.
+func (c *Config) getSelectedDevice(bdList, allDevices *apis.BlockDeviceList) (*apis.BlockDevice, error) {
 	if c.ManualSelection {
 		return &bdList.Items[0], nil
 	}
 
+	selectedDevices, err := c.getSortedDevices(bdList, allDevices)
+	if err != nil {
+		return nil, err
+	}
+
+	// will use the most preferred block device
+	return &selectedDevices.Items[0], nil
+}
+
+// getSortedDevices returns the block devices that satisfy the resource requirements
+// requested by the claim, in the order of preference of the selection strategy.
+func (c *Config) getSortedDevices(bdList, allDevices *apis.BlockDeviceList) (*apis.BlockDeviceList, error) {
 	// filterKeys for filtering based on resource requirements
 	filterKeys := []string{FilterResourceStorage}
 
 	selectedDevices := c.ApplyFilters(bdList, filterKeys...)
 
 	if len(selectedDevices.Items) == 0 {
-		return nil, fmt.Errorf("could not find a device with matching resource requirements")
+		return nil, newSelectionError(apis.BlockDeviceClaimReasonNoMatchingDevices,
+			"could not find a device with matching resource requirements (%s)",
+			c.Rejections.Summary(len(allDevices.Items)))
 	}
 
-	// will use the first available block device
-	return &selectedDevices.Items[0], nil
+	scorers, ok := getSelectionStrategy(c.Strategy)
+	if !ok {
+		return nil, newSelectionError(apis.BlockDeviceClaimReasonUnsupportedSelectionStrategy,
+			"unsupported selection strategy: %s", c.Strategy)
+	}
+
+	sortByScore(selectedDevices, scorers, newScoringContext(c.ClaimSpec, allDevices))
+	return selectedDevices, nil
+}
+
+// noDevicesFoundError returns the error to be used when there are no block
+// devices from which a selection can be made
+func (c *Config) noDevicesFoundError() *SelectionError {
+	if hasNodeConstraint(c.ClaimSpec) {
+		return newSelectionError(apis.BlockDeviceClaimReasonNodeMismatch,
+			"no blockdevices found on the requested node")
+	}
+	return newSelectionError(apis.BlockDeviceClaimReasonNoMatchingDevices,
+		"no blockdevices found")
+}
+
+// hasNodeConstraint checks whether the claim requests a device from a specific node
+func hasNodeConstraint(spec *apis.DeviceClaimSpec) bool {
+	return spec.HostName != "" ||
+		spec.BlockDeviceNodeAttributes.HostName != "" ||
+		spec.BlockDeviceNodeAttributes.NodeName != ""
 }
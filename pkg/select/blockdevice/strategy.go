@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	"sort"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/pkg/select/verify"
+)
+
+// Scorer is used to rank the block devices that match a claim. The
+// block device with the highest score is selected for claiming.
+type Scorer interface {
+	// Score returns the score of the block device for the claim
+	Score(bd *apis.BlockDevice, sc *ScoringContext) int64
+}
+
+// ScorerFunc is an adapter to allow the use of ordinary functions
+// as a Scorer
+type ScorerFunc func(bd *apis.BlockDevice, sc *ScoringContext) int64
+
+// Score calls f(bd, sc)
+func (f ScorerFunc) Score(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+	return f(bd, sc)
+}
+
+// ScoringContext contains the details of the claim and the block devices
+// in the cluster, which can be used by scorers to rank the devices
+type ScoringContext struct {
+	// ClaimSpec is the spec of the claim for which devices are being scored
+	ClaimSpec *apis.DeviceClaimSpec
+
+	// RequestedCapacity is the storage capacity in bytes requested by the claim
+	RequestedCapacity uint64
+
+	// ClaimedDevicesPerNode is the number of claimed block devices on each node
+	ClaimedDevicesPerNode map[string]int
+}
+
+// strategyMap maps each selection strategy to its scorers. The scorers are
+// evaluated in order, each subsequent scorer is used only to break the ties
+// of the previous ones.
+var strategyMap = map[apis.SelectionStrategy][]Scorer{
+	apis.SelectionStrategyBestFit:      {ScorerFunc(bestFitScore)},
+	apis.SelectionStrategyWorstFit:     {ScorerFunc(worstFitScore)},
+	apis.SelectionStrategySpreadByNode: {ScorerFunc(spreadByNodeScore), ScorerFunc(bestFitScore)},
+	apis.SelectionStrategyPreferSSD:    {ScorerFunc(preferSSDScore), ScorerFunc(bestFitScore)},
+}
+
+// RegisterSelectionStrategy registers the scorers for a selection strategy. If the
+// strategy is already registered, its scorers will be replaced. The scorers are
+// evaluated in order, each subsequent scorer is used only to break the ties of the
+// previous ones. If all the scores are equal, devices are ordered by name.
+//
+// NOTE: This should be called before the operator starts reconciling claims.
+func RegisterSelectionStrategy(strategy apis.SelectionStrategy, scorers ...Scorer) {
+	strategyMap[strategy] = scorers
+}
+
+// getSelectionStrategy returns the scorers for the given strategy
+func getSelectionStrategy(strategy apis.SelectionStrategy) ([]Scorer, bool) {
+	scorers, ok := strategyMap[strategy]
+	return scorers, ok
+}
+
+// newScoringContext creates the scoring context for the claim from the list of
+// all block devices that were considered for the claim
+func newScoringContext(spec *apis.DeviceClaimSpec, bdList *apis.BlockDeviceList) *ScoringContext {
+	sc := &ScoringContext{
+		ClaimSpec:             spec,
+		ClaimedDevicesPerNode: make(map[string]int),
+	}
+
+	if capacity, err := verify.GetRequestedCapacity(spec.Resources.Requests); err == nil {
+		sc.RequestedCapacity = uint64(capacity)
+	}
+
+	for _, bd := range bdList.Items {
+		if bd.Status.ClaimState == apis.BlockDeviceClaimed {
+			sc.ClaimedDevicesPerNode[bd.Spec.NodeAttributes.NodeName]++
+		}
+	}
+	return sc
+}
+
+// sortByScore sorts the block devices in the list in descending order of their
+// scores. The ordering is deterministic, devices with equal scores are ordered
+// by their names.
+func sortByScore(bdList *apis.BlockDeviceList, scorers []Scorer, sc *ScoringContext) {
+	scores := make(map[string][]int64, len(bdList.Items))
+	for i := range bdList.Items {
+		bd := &bdList.Items[i]
+		s := make([]int64, len(scorers))
+		for j, scorer := range scorers {
+			s[j] = scorer.Score(bd, sc)
+		}
+		scores[bd.Name] = s
+	}
+
+	sort.SliceStable(bdList.Items, func(i, j int) bool {
+		si, sj := scores[bdList.Items[i].Name], scores[bdList.Items[j].Name]
+		for k := range si {
+			if si[k] != sj[k] {
+				return si[k] > sj[k]
+			}
+		}
+		return bdList.Items[i].Name < bdList.Items[j].Name
+	})
+}
+
+// bestFitScore prefers the smallest block device, so that the least
+// amount of capacity is left unused
+func bestFitScore(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+	return -int64(bd.Spec.Capacity.Storage)
+}
+
+// worstFitScore prefers the largest block device
+func worstFitScore(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+	return int64(bd.Spec.Capacity.Storage)
+}
+
+// spreadByNodeScore prefers block devices on nodes that have the least
+// number of claimed block devices
+func spreadByNodeScore(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+	return -int64(sc.ClaimedDevicesPerNode[bd.Spec.NodeAttributes.NodeName])
+}
+
+// preferSSDScore prefers block devices backed by an SSD
+func preferSSDScore(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+	if bd.Spec.Details.DriveType == blockdevice.DriveTypeSSD {
+		return 1
+	}
+	return 0
+}
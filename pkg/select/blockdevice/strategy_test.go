@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockdevice
+
+import (
+	"testing"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/blockdevice"
+	"github.com/openebs/node-disk-manager/cmd/ndm_daemonset/controller"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	GiB uint64 = 1 << 30
+)
+
+func TestSortByScore(t *testing.T) {
+	spec := &apis.DeviceClaimSpec{
+		Resources: apis.DeviceClaimResources{
+			Requests: v1.ResourceList{
+				apis.ResourceStorage: resource.MustParse("10Gi"),
+			},
+		},
+	}
+
+	// devices bd1-bd4 are the candidates, bd5 and bd6 are claimed devices
+	// which are used only for scoring context
+	candidates := []apis.BlockDevice{
+		createFakeBlockDeviceForScoring("bd1", "node1", blockdevice.DriveTypeHDD, 4096*GiB),
+		createFakeBlockDeviceForScoring("bd2", "node1", blockdevice.DriveTypeHDD, 20*GiB),
+		createFakeBlockDeviceForScoring("bd3", "node2", blockdevice.DriveTypeSSD, 100*GiB),
+		createFakeBlockDeviceForScoring("bd4", "node3", blockdevice.DriveTypeHDD, 20*GiB),
+	}
+	claimed := []apis.BlockDevice{
+		createFakeBlockDeviceForScoring("bd5", "node1", blockdevice.DriveTypeHDD, 20*GiB),
+		createFakeBlockDeviceForScoring("bd6", "node3", blockdevice.DriveTypeHDD, 20*GiB),
+	}
+	for i := range claimed {
+		claimed[i].Status.ClaimState = apis.BlockDeviceClaimed
+	}
+
+	tests := map[string]struct {
+		strategy  apis.SelectionStrategy
+		wantOrder []string
+	}{
+		"best fit selects the smallest device, ties broken by name": {
+			strategy:  apis.SelectionStrategyBestFit,
+			wantOrder: []string{"bd2", "bd4", "bd3", "bd1"},
+		},
+		"worst fit selects the largest device": {
+			strategy:  apis.SelectionStrategyWorstFit,
+			wantOrder: []string{"bd1", "bd3", "bd2", "bd4"},
+		},
+		"spread by node selects from the node with least claimed devices": {
+			strategy:  apis.SelectionStrategySpreadByNode,
+			wantOrder: []string{"bd3", "bd2", "bd4", "bd1"},
+		},
+		"prefer SSD selects SSD and then falls back to best fit": {
+			strategy:  apis.SelectionStrategyPreferSSD,
+			wantOrder: []string{"bd3", "bd2", "bd4", "bd1"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			allDevices := &apis.BlockDeviceList{}
+			allDevices.Items = append(allDevices.Items, candidates...)
+			allDevices.Items = append(allDevices.Items, claimed...)
+
+			// reverse the order of candidates, so that the input order
+			// does not influence the result
+			bdList := &apis.BlockDeviceList{}
+			for i := len(candidates) - 1; i >= 0; i-- {
+				bdList.Items = append(bdList.Items, candidates[i])
+			}
+
+			scorers, ok := getSelectionStrategy(test.strategy)
+			assert.True(t, ok)
+			sortByScore(bdList, scorers, newScoringContext(spec, allDevices))
+
+			gotOrder := make([]string, 0)
+			for _, bd := range bdList.Items {
+				gotOrder = append(gotOrder, bd.Name)
+			}
+			assert.Equal(t, test.wantOrder, gotOrder)
+		})
+	}
+}
+
+func TestGetSelectedDevice(t *testing.T) {
+	spec := &apis.DeviceClaimSpec{
+		Resources: apis.DeviceClaimResources{
+			Requests: v1.ResourceList{
+				apis.ResourceStorage: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	bdList := &apis.BlockDeviceList{
+		Items: []apis.BlockDevice{
+			createFakeBlockDeviceForScoring("bd1", "node1", blockdevice.DriveTypeHDD, 4096*GiB),
+			createFakeBlockDeviceForScoring("bd2", "node1", blockdevice.DriveTypeHDD, 5*GiB),
+			createFakeBlockDeviceForScoring("bd3", "node1", blockdevice.DriveTypeHDD, 20*GiB),
+		},
+	}
+
+	RegisterSelectionStrategy("PreferNode1", ScorerFunc(func(bd *apis.BlockDevice, sc *ScoringContext) int64 {
+		if bd.Spec.NodeAttributes.NodeName == "node1" {
+			return 1
+		}
+		return 0
+	}))
+	defer delete(strategyMap, "PreferNode1")
+
+	tests := map[string]struct {
+		strategy   apis.SelectionStrategy
+		wantDevice string
+		wantErr    bool
+	}{
+		"best fit does not select device smaller than request": {
+			strategy:   apis.SelectionStrategyBestFit,
+			wantDevice: "bd3",
+		},
+		"worst fit": {
+			strategy:   apis.SelectionStrategyWorstFit,
+			wantDevice: "bd1",
+		},
+		"custom registered strategy": {
+			strategy:   "PreferNode1",
+			wantDevice: "bd1",
+		},
+		"unsupported strategy": {
+			strategy: "Random",
+			wantErr:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Config{
+				ClaimSpec: spec,
+				Strategy:  test.strategy,
+			}
+			got, err := c.getSelectedDevice(bdList.DeepCopy(), bdList)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantDevice, got.Name)
+		})
+	}
+}
+
+func createFakeBlockDeviceForScoring(name, nodeName, driveType string, capacity uint64) apis.BlockDevice {
+	bd := createFakeBlockDevice(name, nil)
+	bd.Spec.NodeAttributes.NodeName = nodeName
+	bd.Spec.Details.DriveType = driveType
+	bd.Spec.Capacity.Storage = capacity
+	bd.Status.State = controller.NDMActive
+	bd.Status.ClaimState = apis.BlockDeviceUnclaimed
+	return bd
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smart
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"k8s.io/klog/v2"
+)
+
+// ATA SMART command and the feature register values of its subcommands.
+// See Section 7.48 of T13/2161-D Revision 5 (ACS-3)
+const (
+	AtaSmart            = 0xb0
+	SmartReadData       = 0xd0
+	SmartReadThresholds = 0xd1
+	SmartReturnStatus   = 0xda
+)
+
+const (
+	// LBA mid and LBA high values to be sent with the SMART command, the same
+	// values are returned by SMART RETURN STATUS if the device is healthy
+	smartLBAMid  = 0x4f
+	smartLBAHigh = 0xc2
+	// LBA mid and LBA high values returned by SMART RETURN STATUS if the device
+	// has detected a threshold exceeded condition
+	smartFailingLBAMid  = 0xf4
+	smartFailingLBAHigh = 0x2c
+
+	// layout of the SMART data and thresholds sectors
+	smartSectorLen       = 512
+	smartAttributeOffset = 2
+	smartAttributeCount  = 30
+	smartAttributeLen    = 12
+	smartAttrPrefailure  = 0x01
+
+	// descriptor format sense data and the ATA status return descriptor
+	senseDescriptorFormat = 0x72
+	ataStatusDescriptor   = 0x09
+	ataStatusDescLen      = 14
+)
+
+// ATASMARTHealth is the overall health self-assessment of the ATA device as
+// reported by the SMART RETURN STATUS command
+type ATASMARTHealth string
+
+const (
+	// ATASMARTHealthPassed means the device has not detected a threshold exceeded condition
+	ATASMARTHealthPassed ATASMARTHealth = "PASSED"
+	// ATASMARTHealthFailed means the device has detected a threshold exceeded condition
+	ATASMARTHealthFailed ATASMARTHealth = "FAILED"
+)
+
+// ataSMARTAttributeNames are the names of the commonly used SMART attributes. The
+// attributes are vendor specific, the names used here are same as that of smartctl
+var ataSMARTAttributeNames = map[uint8]string{
+	1:   "Raw_Read_Error_Rate",
+	3:   "Spin_Up_Time",
+	4:   "Start_Stop_Count",
+	5:   "Reallocated_Sector_Ct",
+	7:   "Seek_Error_Rate",
+	9:   "Power_On_Hours",
+	10:  "Spin_Retry_Count",
+	12:  "Power_Cycle_Count",
+	177: "Wear_Leveling_Count",
+	179: "Used_Rsvd_Blk_Cnt_Tot",
+	181: "Program_Fail_Cnt_Total",
+	182: "Erase_Fail_Count_Total",
+	183: "Runtime_Bad_Block",
+	184: "End-to-End_Error",
+	187: "Reported_Uncorrect",
+	188: "Command_Timeout",
+	190: "Airflow_Temperature_Cel",
+	192: "Power-Off_Retract_Count",
+	193: "Load_Cycle_Count",
+	194: "Temperature_Celsius",
+	196: "Reallocated_Event_Count",
+	197: "Current_Pending_Sector",
+	198: "Offline_Uncorrectable",
+	199: "UDMA_CRC_Error_Count",
+	231: "SSD_Life_Left",
+	233: "Media_Wearout_Indicator",
+	241: "Total_LBAs_Written",
+	242: "Total_LBAs_Read",
+}
+
+// ATASMARTAttribute is an entry of the SMART attribute table of an ATA device
+type ATASMARTAttribute struct {
+	ID        uint8
+	Name      string
+	Flags     uint16
+	Value     uint8
+	Worst     uint8
+	Threshold uint8
+	// Raw is the 48 bit vendor specific raw value of the attribute
+	Raw uint64
+	// Prefailure is set if the attribute exceeding its threshold indicates an
+	// imminent failure, else the attribute is an indicator of usage or age
+	Prefailure bool
+	// FailingNow is set if the normalized value is less than or equal to the threshold
+	FailingNow bool
+}
+
+// ATASMARTInfo contains the SMART attributes and the health status of an ATA device
+type ATASMARTInfo struct {
+	Attributes []ATASMARTAttribute
+	Health     ATASMARTHealth
+}
+
+// Attribute returns the attribute with the given ID, if present
+func (s ATASMARTInfo) Attribute(id uint8) (ATASMARTAttribute, bool) {
+	for _, attr := range s.Attributes {
+		if attr.ID == id {
+			return attr, true
+		}
+	}
+	return ATASMARTAttribute{}, false
+}
+
+// ATASMARTInfo returns the SMART attribute table along with the thresholds and the
+// overall health status of an ATA device, using the SCSI ATA pass-through command
+func (I *Identifier) ATASMARTInfo() (ATASMARTInfo, error) {
+	info := ATASMARTInfo{}
+	if err := isConditionSatisfied(I.DevPath); err != nil {
+		return info, err
+	}
+	d, err := detectSCSIType(I.DevPath)
+	if err != nil {
+		return info, fmt.Errorf("error in detecting type of SCSI device, Error: %+v", err)
+	}
+	defer d.Close()
+
+	sata, ok := d.(*SATA)
+	if !ok {
+		return info, fmt.Errorf("device %q is not an ATA device", I.DevPath)
+	}
+	return sata.ataSMARTInfo()
+}
+
+// ataSMARTInfo sends the SMART READ DATA, READ THRESHOLDS and RETURN STATUS commands
+// to the device and parses the responses
+func (d *SATA) ataSMARTInfo() (ATASMARTInfo, error) {
+	info := ATASMARTInfo{}
+
+	data, err := d.ataSMARTRead(SmartReadData)
+	if err != nil {
+		return info, fmt.Errorf("error in sending SMART READ DATA, Error: %+v", err)
+	}
+	thresholds, err := d.ataSMARTRead(SmartReadThresholds)
+	if err != nil {
+		return info, fmt.Errorf("error in sending SMART READ THRESHOLDS, Error: %+v", err)
+	}
+	if info.Attributes, err = ParseATASMARTAttributes(data, thresholds); err != nil {
+		return info, err
+	}
+
+	sense, err := d.ataSMARTReturnStatus()
+	if err != nil {
+		return info, fmt.Errorf("error in sending SMART RETURN STATUS, Error: %+v", err)
+	}
+	if info.Health, err = ParseATASMARTReturnStatus(sense); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// ataSMARTRead sends a SMART subcommand that returns a 512 byte sector of data
+// using the SCSI_ATA_PASSTHRU_16 command
+func (d *SATA) ataSMARTRead(feature uint8) ([]byte, error) {
+	respBuf := make([]byte, smartSectorLen)
+
+	cdb16 := CDB16{SCSIATAPassThru}
+	cdb16[1] = 0x08         // ATA protocol (4 << 1, PIO data-in)
+	cdb16[2] = 0x0e         // BYT_BLOK = 1, T_LENGTH = 2, T_DIR = 1
+	cdb16[4] = feature      // features (7:0), the SMART subcommand
+	cdb16[6] = 0x01         // sector count (7:0)
+	cdb16[10] = smartLBAMid // LBA mid (7:0)
+	cdb16[12] = smartLBAHigh
+	cdb16[14] = AtaSmart
+
+	if err := d.sendSCSICDB(cdb16[:], &respBuf); err != nil {
+		return nil, err
+	}
+	return respBuf, nil
+}
+
+// ataSMARTReturnStatus sends the SMART RETURN STATUS command and returns the sense
+// data. The result of the command is returned by the device in the LBA mid and LBA
+// high registers, which are available only in the ATA status return descriptor of
+// the sense data.
+func (d *SATA) ataSMARTReturnStatus() ([]byte, error) {
+	senseBuf := make([]byte, 32)
+
+	cdb16 := CDB16{SCSIATAPassThru}
+	cdb16[1] = 0x06 // ATA protocol (3 << 1, non-data)
+	cdb16[2] = 0x20 // CK_COND = 1, return the ATA registers in the sense data
+	cdb16[4] = SmartReturnStatus
+	cdb16[10] = smartLBAMid
+	cdb16[12] = smartLBAHigh
+	cdb16[14] = AtaSmart
+
+	header := sgIOHeader{
+		interfaceID:    'S',
+		dxferDirection: SGDxferNone,
+		cmdLen:         uint8(len(cdb16)),
+		mxSBLen:        uint8(len(senseBuf)),
+		cmdp:           uintptr(unsafe.Pointer(&cdb16[0])),
+		sbp:            uintptr(unsafe.Pointer(&senseBuf[0])), // nosec
+		timeout:        DefaultTimeout,
+	}
+	// the command is expected to terminate with CHECK CONDITION status since
+	// CK_COND is set, so the status is not checked here
+	if err := Ioctl(uintptr(d.fd), SGIO, uintptr(unsafe.Pointer(&header))); err != nil {
+		return nil, err
+	}
+	if header.SBLenwr == 0 {
+		return nil, sgIOErr{
+			scsiStatus:   header.status,
+			hostStatus:   header.hostStatus,
+			driverStatus: header.driverStatus,
+		}
+	}
+	return senseBuf[:header.SBLenwr], nil
+}
+
+// ParseATASMARTAttributes parses the 512 byte SMART READ DATA and SMART READ
+// THRESHOLDS responses into the attribute table. The data structures are vendor
+// specific, the layout used by all the vendors as followed by smartctl is used.
+func ParseATASMARTAttributes(data, thresholds []byte) ([]ATASMARTAttribute, error) {
+	if err := validateSMARTSector(data); err != nil {
+		return nil, fmt.Errorf("invalid SMART data, Error: %+v", err)
+	}
+	// some drives do not set the checksum of the thresholds sector, the
+	// attributes are still reported with the thresholds read from it
+	if err := validateSMARTSector(thresholds); errors.Is(err, errSMARTChecksum) {
+		klog.Warningf("invalid SMART thresholds, Error: %+v", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid SMART thresholds, Error: %+v", err)
+	}
+
+	// thresholds are stored in entries of the same size as the attributes, with
+	// the attribute ID at byte 0 and the threshold at byte 1
+	thresholdByID := make(map[uint8]uint8)
+	for i := 0; i < smartAttributeCount; i++ {
+		entry := thresholds[smartAttributeOffset+i*smartAttributeLen:]
+		if entry[0] != 0 {
+			thresholdByID[entry[0]] = entry[1]
+		}
+	}
+
+	attributes := make([]ATASMARTAttribute, 0)
+	for i := 0; i < smartAttributeCount; i++ {
+		entry := data[smartAttributeOffset+i*smartAttributeLen:]
+		// entries with ID 0 are unused
+		if entry[0] == 0 {
+			continue
+		}
+		attr := ATASMARTAttribute{
+			ID:        entry[0],
+			Name:      ataSMARTAttributeNames[entry[0]],
+			Flags:     binary.LittleEndian.Uint16(entry[1:3]),
+			Value:     entry[3],
+			Worst:     entry[4],
+			Threshold: thresholdByID[entry[0]],
+		}
+		if attr.Name == "" {
+			attr.Name = "Unknown_Attribute"
+		}
+		// raw value is 6 bytes little endian
+		for j := 10; j >= 5; j-- {
+			attr.Raw = attr.Raw<<8 | uint64(entry[j])
+		}
+		attr.Prefailure = attr.Flags&smartAttrPrefailure != 0
+		// a threshold of 0 means that the attribute can never fail
+		attr.FailingNow = attr.Threshold != 0 && attr.Value <= attr.Threshold
+		attributes = append(attributes, attr)
+	}
+	return attributes, nil
+}
+
+// ParseATASMARTReturnStatus parses the sense data returned by the SMART RETURN
+// STATUS command and returns the health status of the device.
+// See Section 12.2.2.7 of T10/BSR INCITS 491 Revision 10 (SAT-3) for the ATA
+// status return sense data descriptor
+func ParseATASMARTReturnStatus(sense []byte) (ATASMARTHealth, error) {
+	if len(sense) < 8 || sense[0]&0x7f != senseDescriptorFormat {
+		return "", fmt.Errorf("sense data is not in descriptor format")
+	}
+	// descriptors start from byte 8, additional sense length at byte 7 is the
+	// length of all the descriptors
+	end := 8 + int(sense[7])
+	if end > len(sense) {
+		end = len(sense)
+	}
+	for i := 8; i+1 < end; i += int(sense[i+1]) + 2 {
+		if sense[i] != ataStatusDescriptor {
+			continue
+		}
+		if i+ataStatusDescLen > end {
+			return "", fmt.Errorf("ATA status return descriptor is truncated")
+		}
+		lbaMid, lbaHigh := sense[i+9], sense[i+11]
+		switch {
+		case lbaMid == smartLBAMid && lbaHigh == smartLBAHigh:
+			return ATASMARTHealthPassed, nil
+		case lbaMid == smartFailingLBAMid && lbaHigh == smartFailingLBAHigh:
+			return ATASMARTHealthFailed, nil
+		default:
+			return "", fmt.Errorf("unknown SMART status, LBA mid: %#02x, LBA high: %#02x", lbaMid, lbaHigh)
+		}
+	}
+	return "", fmt.Errorf("ATA status return descriptor not found in sense data")
+}
+
+// errSMARTChecksum is returned when the checksum of a SMART data sector does
+// not match
+var errSMARTChecksum = errors.New("checksum mismatch")
+
+// validateSMARTSector checks the size and the checksum of a SMART data sector.
+// The sum of all the bytes of the sector including the checksum at byte 511
+// should be 0 modulo 256
+func validateSMARTSector(buf []byte) error {
+	if len(buf) != smartSectorLen {
+		return fmt.Errorf("sector is %d bytes, expected %d", len(buf), smartSectorLen)
+	}
+	var sum uint8
+	for _, b := range buf {
+		sum += b
+	}
+	if sum != 0 {
+		return errSMARTChecksum
+	}
+	return nil
+}
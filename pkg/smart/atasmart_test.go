@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readSectorDump reads a SMART sector recorded from an ATA device
+func readSectorDump(t *testing.T, name string) []byte {
+	buf, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return buf
+}
+
+func TestParseATASMARTAttributes(t *testing.T) {
+	ssdData := readSectorDump(t, "smart-ssd-data.bin")
+	ssdThresholds := readSectorDump(t, "smart-ssd-thresholds.bin")
+	hddData := readSectorDump(t, "smart-hdd-failing-data.bin")
+	hddThresholds := readSectorDump(t, "smart-hdd-failing-thresholds.bin")
+
+	corrupted := make([]byte, len(ssdData))
+	copy(corrupted, ssdData)
+	corrupted[100]++
+
+	// thresholds sector without the checksum set
+	noChecksumThresholds := make([]byte, len(ssdThresholds))
+	copy(noChecksumThresholds, ssdThresholds)
+	noChecksumThresholds[smartSectorLen-1]++
+
+	tests := map[string]struct {
+		data       []byte
+		thresholds []byte
+		wantCount  int
+		want       map[uint8]ATASMARTAttribute
+		wantErr    bool
+	}{
+		"ssd attributes": {
+			data:       ssdData,
+			thresholds: ssdThresholds,
+			wantCount:  14,
+			want: map[uint8]ATASMARTAttribute{
+				5: {ID: 5, Name: "Reallocated_Sector_Ct", Flags: 0x0033, Value: 100, Worst: 100,
+					Threshold: 10, Raw: 0, Prefailure: true},
+				9: {ID: 9, Name: "Power_On_Hours", Flags: 0x0032, Value: 98, Worst: 98, Raw: 8760},
+				177: {ID: 177, Name: "Wear_Leveling_Count", Flags: 0x0013, Value: 97, Worst: 97,
+					Raw: 31, Prefailure: true},
+				199: {ID: 199, Name: "UDMA_CRC_Error_Count", Flags: 0x003e, Value: 100, Worst: 100, Raw: 2},
+				235: {ID: 235, Name: "Unknown_Attribute", Flags: 0x0012, Value: 99, Worst: 99, Raw: 140},
+				241: {ID: 241, Name: "Total_LBAs_Written", Flags: 0x0032, Value: 99, Worst: 99, Raw: 24578129875},
+			},
+		},
+		"hdd with failing attribute": {
+			data:       hddData,
+			thresholds: hddThresholds,
+			wantCount:  8,
+			want: map[uint8]ATASMARTAttribute{
+				5: {ID: 5, Name: "Reallocated_Sector_Ct", Flags: 0x0033, Value: 3, Worst: 3,
+					Threshold: 140, Raw: 3712, Prefailure: true, FailingNow: true},
+				9:   {ID: 9, Name: "Power_On_Hours", Flags: 0x0032, Value: 45, Worst: 45, Raw: 0x123400009c40},
+				194: {ID: 194, Name: "Temperature_Celsius", Flags: 0x0022, Value: 112, Worst: 95, Raw: 0x2d0014000026},
+				197: {ID: 197, Name: "Current_Pending_Sector", Flags: 0x0032, Value: 200, Worst: 200, Raw: 24},
+			},
+		},
+		"checksum mismatch": {
+			data:       corrupted,
+			thresholds: ssdThresholds,
+			wantErr:    true,
+		},
+		"thresholds checksum mismatch": {
+			data:       ssdData,
+			thresholds: noChecksumThresholds,
+			wantCount:  14,
+			want: map[uint8]ATASMARTAttribute{
+				5: {ID: 5, Name: "Reallocated_Sector_Ct", Flags: 0x0033, Value: 100, Worst: 100,
+					Threshold: 10, Raw: 0, Prefailure: true},
+			},
+		},
+		"short sector": {
+			data:       ssdData,
+			thresholds: ssdThresholds[:256],
+			wantErr:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseATASMARTAttributes(test.data, test.thresholds)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, got, test.wantCount)
+			info := ATASMARTInfo{Attributes: got}
+			for id, want := range test.want {
+				attr, ok := info.Attribute(id)
+				assert.True(t, ok, "attribute %d not found", id)
+				assert.Equal(t, want, attr)
+			}
+		})
+	}
+}
+
+func TestParseATASMARTReturnStatus(t *testing.T) {
+	// sense data with the ATA status return descriptor, as returned by the SMART
+	// RETURN STATUS command with CK_COND set
+	newSense := func(lbaMid, lbaHigh byte) []byte {
+		return []byte{
+			0x72, 0x01, 0x00, 0x1d, 0x00, 0x00, 0x00, 0x0e,
+			0x09, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, lbaMid, 0x00, lbaHigh, 0x00, 0x50,
+		}
+	}
+
+	tests := map[string]struct {
+		sense   []byte
+		want    ATASMARTHealth
+		wantErr bool
+	}{
+		"healthy device": {
+			sense: newSense(0x4f, 0xc2),
+			want:  ATASMARTHealthPassed,
+		},
+		"threshold exceeded": {
+			sense: newSense(0xf4, 0x2c),
+			want:  ATASMARTHealthFailed,
+		},
+		"unknown registers": {
+			sense:   newSense(0x00, 0x00),
+			wantErr: true,
+		},
+		"fixed format sense data": {
+			sense:   []byte{0x70, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00},
+			wantErr: true,
+		},
+		"truncated descriptor": {
+			sense:   newSense(0x4f, 0xc2)[:16],
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseATASMARTReturnStatus(test.sense)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
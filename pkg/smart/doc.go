@@ -18,8 +18,11 @@ Raw_Read_Error_Rate, Temperature_Celsius, Spin_Up_Time, etc by parsing various d
 pages such as inquiry page, ata command set page ,etc using various SCSI commands such
 as scsi inquiry, read device capacity, mode sense, etc.
 
-NOTE : For now, the implementation is only for getting the basic details (not smart details)
-of SCSI disks such as vendor,serial, model, firmware revision, logical sector size,etc.
+NOTE : For SCSI disks, the implementation is only for getting the basic details such as
+vendor, serial, model, firmware revision, logical sector size, etc. For ATA disks, the SMART
+attribute table and the overall health status are also available using ATASMARTInfo(). The
+error counters, temperature, self-test results and informational exceptions of SCSI disks are
+available from the log pages using SCSILogPages().
 
 Usage:
 
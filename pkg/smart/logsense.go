@@ -0,0 +1,541 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smart
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// SCSI commands used for getting the health of a SCSI device
+const (
+	SCSILogSense       = 0x4d // log sense command
+	SCSIReadDefectData = 0x37 // read defect data (10) command
+)
+
+// Log page codes defined in Section 7.3 of T10/BSR INCITS 513 Revision 37a (SPC-4)
+// and Section 6.4 of T10/BSR INCITS 506 Revision 18 (SBC-4)
+const (
+	SupportedLogPages              = 0x00
+	WriteErrorCounterLogPage       = 0x02
+	ReadErrorCounterLogPage        = 0x03
+	VerifyErrorCounterLogPage      = 0x05
+	NonMediumErrorLogPage          = 0x06
+	TemperatureLogPage             = 0x0d
+	StartStopCycleLogPage          = 0x0e
+	SelfTestResultsLogPage         = 0x10
+	SolidStateMediaLogPage         = 0x11
+	InformationalExceptionsLogPage = 0x2f
+)
+
+// Constants being used as keys for sending map of errors while fetching log pages
+const (
+	LogSenseErr       = "LogSenseError"
+	ReadDefectDataErr = "ReadDefectDataError"
+)
+
+const (
+	// logPageHeaderLen is the length of the header of a log page
+	logPageHeaderLen = 4
+	// logParamHeaderLen is the length of the header of a log parameter
+	logParamHeaderLen = 4
+	// logSenseCumulativeValues is the page control field for the cumulative values
+	logSenseCumulativeValues = 0x01
+	// temperatureNotAvailable is reported in the temperature log page if the
+	// temperature is not available
+	temperatureNotAvailable = 0xff
+	// selfTestParamLen is the length of each self test result log parameter
+	selfTestParamLen = 0x10
+	// glistFormatBytesFromIndex is the defect list format used to get the grown
+	// defect list, each defect descriptor being 8 bytes
+	glistFormatBytesFromIndex = 0x04
+	glistRequest              = 0x08
+	defectDescriptorLen       = 8
+)
+
+// ErrorCounterLog is the write, read or verify error counter log page
+type ErrorCounterLog struct {
+	// CorrectedWithoutDelay is the number of errors corrected without substantial delay
+	CorrectedWithoutDelay uint64
+	// CorrectedWithDelay is the number of errors corrected with possible delays
+	CorrectedWithDelay uint64
+	// TotalRetries is the total number of rewrites or rereads
+	TotalRetries uint64
+	// TotalCorrected is the total number of errors corrected
+	TotalCorrected uint64
+	// CorrectionAlgorithmInvocations is the number of times the error correction
+	// algorithm has been invoked
+	CorrectionAlgorithmInvocations uint64
+	// TotalBytesProcessed is the total number of bytes written, read or verified
+	TotalBytesProcessed uint64
+	// TotalUncorrected is the total number of uncorrected errors
+	TotalUncorrected uint64
+}
+
+// NonMediumErrorLog is the non medium error log page
+type NonMediumErrorLog struct {
+	// Count is the number of recoverable errors other than the write, read or
+	// verify errors
+	Count uint64
+}
+
+// TemperatureLog is the temperature log page
+type TemperatureLog struct {
+	// Current is the current temperature in celsius
+	Current int16
+	// CurrentValid is false if the device could not report the current temperature
+	CurrentValid bool
+	// Reference is the maximum temperature in celsius at which the device is
+	// capable of operating continuously
+	Reference int16
+	// ReferenceValid is false if the reference temperature is not reported
+	ReferenceValid bool
+}
+
+// StartStopCycleLog is the start-stop cycle counter log page
+type StartStopCycleLog struct {
+	// ManufactureDate is the year and week of manufacture in YYYY/WW format
+	ManufactureDate string
+	// SpecifiedCycles is the number of start-stop cycles the device can
+	// handle over its lifetime
+	SpecifiedCycles uint32
+	// AccumulatedCycles is the number of start-stop cycles over the lifetime
+	AccumulatedCycles uint32
+	// SpecifiedLoadUnloadCycles is the number of load-unload cycles the device
+	// can handle over its lifetime
+	SpecifiedLoadUnloadCycles uint32
+	// AccumulatedLoadUnloadCycles is the number of load-unload cycles over the lifetime
+	AccumulatedLoadUnloadCycles uint32
+}
+
+// SelfTestResult is a result in the self-test results log page
+type SelfTestResult struct {
+	// Code is the self-test code with which the self-test was started
+	Code uint8
+	// Result is the self-test result value. 0 means completed without error,
+	// values 3 to 7 means the self-test failed.
+	Result uint8
+	// Number is the segment number in which the self-test failed
+	Number uint8
+	// PowerOnHours is the accumulated power on hours when the self-test completed
+	PowerOnHours uint16
+	// FirstFailureLBA is the LBA of the first failure
+	FirstFailureLBA uint64
+	SenseKey        uint8
+	ASC             uint8
+	ASCQ            uint8
+}
+
+// Failed returns true if the self-test completed with a failure
+func (r SelfTestResult) Failed() bool {
+	return r.Result >= 3 && r.Result <= 7
+}
+
+// SelfTestLog is the self-test results log page, with the most recent result first
+type SelfTestLog struct {
+	Results []SelfTestResult
+}
+
+// SolidStateMediaLog is the solid state media log page
+type SolidStateMediaLog struct {
+	// PercentageUsedEnduranceIndicator is the estimate of the device life that
+	// has been used in percent. The value can exceed 100.
+	PercentageUsedEnduranceIndicator uint8
+}
+
+// InformationalExceptionsLog is the informational exceptions log page
+type InformationalExceptionsLog struct {
+	// ASC and ASCQ are the additional sense code and qualifier of the most recent
+	// informational exception. An ASC of 0 means that there are no exceptions.
+	ASC  uint8
+	ASCQ uint8
+	// MostRecentTemperature is the most recent temperature in celsius
+	MostRecentTemperature int16
+}
+
+// Failing returns true if the device has reported a failure prediction
+func (l InformationalExceptionsLog) Failing() bool {
+	return l.ASC != 0
+}
+
+// SCSILogPages contains the decoded log pages of a SCSI device. A page is nil
+// if the device does not support it.
+type SCSILogPages struct {
+	SupportedPages          []uint8
+	WriteErrors             *ErrorCounterLog
+	ReadErrors              *ErrorCounterLog
+	VerifyErrors            *ErrorCounterLog
+	NonMediumErrors         *NonMediumErrorLog
+	Temperature             *TemperatureLog
+	StartStopCycles         *StartStopCycleLog
+	SelfTest                *SelfTestLog
+	SolidStateMedia         *SolidStateMediaLog
+	InformationalExceptions *InformationalExceptionsLog
+	// GrownDefects is the number of defects in the grown defect list, nil if
+	// the device does not report it
+	GrownDefects *uint64
+}
+
+// SCSILogPages returns all the supported log pages of a SCSI device, decoded into
+// the typed structs, along with the errors if any (in form of map)
+func (I *Identifier) SCSILogPages() (SCSILogPages, map[string]error) {
+	logPages := SCSILogPages{}
+	collector := NewErrorCollector()
+
+	if err := isConditionSatisfied(I.DevPath); err != nil {
+		collector.Collect(errorCheckConditions, err)
+		return logPages, collector.Error()
+	}
+	d, err := detectSCSIType(I.DevPath)
+	if collector.Collect(DetectSCSITypeErr, err) {
+		return logPages, collector.Error()
+	}
+	defer d.Close()
+
+	// the log pages are fetched using the embedded SCSI device for ATA devices,
+	// since the SCSI ATA translation layer emulates some of the pages
+	var scsiDev *SCSIDev
+	switch dev := d.(type) {
+	case *SATA:
+		scsiDev = &dev.SCSIDev
+	case *SCSIDev:
+		scsiDev = dev
+	default:
+		collector.Collect(DetectSCSITypeErr, fmt.Errorf("unknown SCSI device type %T", d))
+		return logPages, collector.Error()
+	}
+	logPages, err = scsiDev.getLogPages()
+	collector.Collect(LogSenseErr, err)
+
+	count, err := scsiDev.grownDefectCount()
+	if !collector.Collect(ReadDefectDataErr, err) {
+		logPages.GrownDefects = &count
+	}
+	return logPages, collector.Error()
+}
+
+// getLogPages fetches and decodes all the supported log pages
+func (d *SCSIDev) getLogPages() (SCSILogPages, error) {
+	logPages := SCSILogPages{}
+
+	buf, err := d.logSense(SupportedLogPages, 0)
+	if err != nil {
+		return logPages, err
+	}
+	if logPages.SupportedPages, err = ParseSupportedLogPages(buf); err != nil {
+		return logPages, err
+	}
+
+	var errs []string
+	for _, page := range logPages.SupportedPages {
+		if page == SupportedLogPages {
+			continue
+		}
+		buf, err := d.logSense(page, 0)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("page %#02x: %v", page, err))
+			continue
+		}
+		if err := logPages.decode(page, buf); err != nil {
+			errs = append(errs, fmt.Sprintf("page %#02x: %v", page, err))
+		}
+	}
+	if len(errs) != 0 {
+		return logPages, fmt.Errorf("error in fetching log pages, %s", strings.Join(errs, ", "))
+	}
+	return logPages, nil
+}
+
+// decode decodes the log page and sets it in the log pages. Pages that are not
+// known are ignored.
+func (p *SCSILogPages) decode(page uint8, buf []byte) error {
+	var err error
+	switch page {
+	case WriteErrorCounterLogPage:
+		p.WriteErrors, err = ParseErrorCounterLog(buf)
+	case ReadErrorCounterLogPage:
+		p.ReadErrors, err = ParseErrorCounterLog(buf)
+	case VerifyErrorCounterLogPage:
+		p.VerifyErrors, err = ParseErrorCounterLog(buf)
+	case NonMediumErrorLogPage:
+		p.NonMediumErrors, err = ParseNonMediumErrorLog(buf)
+	case TemperatureLogPage:
+		p.Temperature, err = ParseTemperatureLog(buf)
+	case StartStopCycleLogPage:
+		p.StartStopCycles, err = ParseStartStopCycleLog(buf)
+	case SelfTestResultsLogPage:
+		p.SelfTest, err = ParseSelfTestLog(buf)
+	case SolidStateMediaLogPage:
+		p.SolidStateMedia, err = ParseSolidStateMediaLog(buf)
+	case InformationalExceptionsLogPage:
+		p.InformationalExceptions, err = ParseInformationalExceptionsLog(buf)
+	}
+	return err
+}
+
+// logSense sends a SCSI LOG SENSE command to get the cumulative values of the log
+// page. The page header is read first to get the length of the page.
+func (d *SCSIDev) logSense(page, subPage uint8) ([]byte, error) {
+	header := make([]byte, logPageHeaderLen)
+	if err := d.sendLogSense(page, subPage, &header); err != nil {
+		return nil, err
+	}
+	pageLen := int(binary.BigEndian.Uint16(header[2:4]))
+	if pageLen == 0 {
+		return header, nil
+	}
+
+	respBuf := make([]byte, logPageHeaderLen+pageLen)
+	if err := d.sendLogSense(page, subPage, &respBuf); err != nil {
+		return nil, err
+	}
+	return respBuf, nil
+}
+
+// sendLogSense sends the LOG SENSE command using cdb10 with the length of the
+// response buffer as the allocation length
+func (d *SCSIDev) sendLogSense(page, subPage uint8, respBuf *[]byte) error {
+	cdb := CDB10{SCSILogSense}
+	cdb[2] = logSenseCumulativeValues<<6 | page&0x3f
+	cdb[3] = subPage
+	binary.BigEndian.PutUint16(cdb[7:], uint16(len(*respBuf)))
+	return d.sendSCSICDB(cdb[:], respBuf)
+}
+
+// grownDefectCount sends the READ DEFECT DATA (10) command to get the number of
+// defects in the grown defect list. Only the header of the list is requested
+// since the length of the list is all that is required.
+func (d *SCSIDev) grownDefectCount() (uint64, error) {
+	respBuf := make([]byte, 4)
+	cdb := CDB10{SCSIReadDefectData}
+	cdb[2] = glistRequest | glistFormatBytesFromIndex
+	binary.BigEndian.PutUint16(cdb[7:], uint16(len(respBuf)))
+	if err := d.sendSCSICDB(cdb[:], &respBuf); err != nil {
+		return 0, err
+	}
+	return ParseGrownDefectCount(respBuf)
+}
+
+// ParseGrownDefectCount parses the header of the READ DEFECT DATA (10) response
+// and returns the number of defects in the grown defect list
+func ParseGrownDefectCount(buf []byte) (uint64, error) {
+	if len(buf) < 4 {
+		return 0, fmt.Errorf("defect data header is %d bytes, expected 4", len(buf))
+	}
+	if buf[1]&glistRequest == 0 {
+		return 0, fmt.Errorf("grown defect list is not available")
+	}
+	if buf[1]&0x07 != glistFormatBytesFromIndex {
+		return 0, fmt.Errorf("defect list returned in unexpected format %#02x", buf[1]&0x07)
+	}
+	return uint64(binary.BigEndian.Uint16(buf[2:4]) / defectDescriptorLen), nil
+}
+
+// parseLogParameters validates the log page header and returns the parameters of
+// the log page, indexed by the parameter code
+func parseLogParameters(buf []byte, page uint8) (map[uint16][]byte, error) {
+	if len(buf) < logPageHeaderLen {
+		return nil, fmt.Errorf("log page is %d bytes, expected at least %d", len(buf), logPageHeaderLen)
+	}
+	if buf[0]&0x3f != page {
+		return nil, fmt.Errorf("expected log page %#02x, got %#02x", page, buf[0]&0x3f)
+	}
+	end := logPageHeaderLen + int(binary.BigEndian.Uint16(buf[2:4]))
+	if end > len(buf) {
+		return nil, fmt.Errorf("log page %#02x is truncated", page)
+	}
+
+	params := make(map[uint16][]byte)
+	for i := logPageHeaderLen; i+logParamHeaderLen <= end; {
+		code := binary.BigEndian.Uint16(buf[i : i+2])
+		paramLen := int(buf[i+3])
+		start := i + logParamHeaderLen
+		if start+paramLen > end {
+			return nil, fmt.Errorf("log parameter %#04x of page %#02x is truncated", code, page)
+		}
+		params[code] = buf[start : start+paramLen]
+		i = start + paramLen
+	}
+	return params, nil
+}
+
+// parseCounter returns the value of a counter log parameter of any length upto 8 bytes
+func parseCounter(value []byte) uint64 {
+	var counter uint64
+	for _, b := range value {
+		counter = counter<<8 | uint64(b)
+	}
+	return counter
+}
+
+// ParseSupportedLogPages parses the supported log pages page
+func ParseSupportedLogPages(buf []byte) ([]uint8, error) {
+	if len(buf) < logPageHeaderLen {
+		return nil, fmt.Errorf("log page is %d bytes, expected at least %d", len(buf), logPageHeaderLen)
+	}
+	if buf[0]&0x3f != SupportedLogPages {
+		return nil, fmt.Errorf("expected log page %#02x, got %#02x", SupportedLogPages, buf[0]&0x3f)
+	}
+	end := logPageHeaderLen + int(binary.BigEndian.Uint16(buf[2:4]))
+	if end > len(buf) {
+		return nil, fmt.Errorf("log page %#02x is truncated", SupportedLogPages)
+	}
+	pages := make([]uint8, 0, end-logPageHeaderLen)
+	for _, page := range buf[logPageHeaderLen:end] {
+		pages = append(pages, page&0x3f)
+	}
+	return pages, nil
+}
+
+// ParseErrorCounterLog parses the write (0x02), read (0x03) or verify (0x05) error
+// counter log page
+func ParseErrorCounterLog(buf []byte) (*ErrorCounterLog, error) {
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("empty log page")
+	}
+	page := buf[0] & 0x3f
+	if page != WriteErrorCounterLogPage && page != ReadErrorCounterLogPage && page != VerifyErrorCounterLogPage {
+		return nil, fmt.Errorf("log page %#02x is not an error counter log page", page)
+	}
+	params, err := parseLogParameters(buf, page)
+	if err != nil {
+		return nil, err
+	}
+	return &ErrorCounterLog{
+		CorrectedWithoutDelay:          parseCounter(params[0x0000]),
+		CorrectedWithDelay:             parseCounter(params[0x0001]),
+		TotalRetries:                   parseCounter(params[0x0002]),
+		TotalCorrected:                 parseCounter(params[0x0003]),
+		CorrectionAlgorithmInvocations: parseCounter(params[0x0004]),
+		TotalBytesProcessed:            parseCounter(params[0x0005]),
+		TotalUncorrected:               parseCounter(params[0x0006]),
+	}, nil
+}
+
+// ParseNonMediumErrorLog parses the non medium error log page
+func ParseNonMediumErrorLog(buf []byte) (*NonMediumErrorLog, error) {
+	params, err := parseLogParameters(buf, NonMediumErrorLogPage)
+	if err != nil {
+		return nil, err
+	}
+	return &NonMediumErrorLog{Count: parseCounter(params[0x0000])}, nil
+}
+
+// ParseTemperatureLog parses the temperature log page
+func ParseTemperatureLog(buf []byte) (*TemperatureLog, error) {
+	params, err := parseLogParameters(buf, TemperatureLogPage)
+	if err != nil {
+		return nil, err
+	}
+	log := &TemperatureLog{}
+	// the temperature is in the second byte of the parameter
+	if value := params[0x0000]; len(value) >= 2 && value[1] != temperatureNotAvailable {
+		log.Current = int16(value[1])
+		log.CurrentValid = true
+	}
+	if value := params[0x0001]; len(value) >= 2 && value[1] != temperatureNotAvailable {
+		log.Reference = int16(value[1])
+		log.ReferenceValid = true
+	}
+	return log, nil
+}
+
+// ParseStartStopCycleLog parses the start-stop cycle counter log page
+func ParseStartStopCycleLog(buf []byte) (*StartStopCycleLog, error) {
+	params, err := parseLogParameters(buf, StartStopCycleLogPage)
+	if err != nil {
+		return nil, err
+	}
+	log := &StartStopCycleLog{
+		SpecifiedCycles:             uint32(parseCounter(params[0x0003])),
+		AccumulatedCycles:           uint32(parseCounter(params[0x0004])),
+		SpecifiedLoadUnloadCycles:   uint32(parseCounter(params[0x0005])),
+		AccumulatedLoadUnloadCycles: uint32(parseCounter(params[0x0006])),
+	}
+	// date of manufacture is 4 ASCII characters of the year followed by 2 of the week
+	if value := params[0x0001]; len(value) == 6 {
+		year, week := strings.TrimSpace(string(value[0:4])), strings.TrimSpace(string(value[4:6]))
+		if year != "" && week != "" {
+			log.ManufactureDate = year + "/" + week
+		}
+	}
+	return log, nil
+}
+
+// ParseSelfTestLog parses the self-test results log page. Unused result
+// parameters are skipped.
+func ParseSelfTestLog(buf []byte) (*SelfTestLog, error) {
+	params, err := parseLogParameters(buf, SelfTestResultsLogPage)
+	if err != nil {
+		return nil, err
+	}
+	log := &SelfTestLog{Results: make([]SelfTestResult, 0)}
+	// parameters 0x0001 to 0x0014 are the 20 most recent results
+	for code := uint16(0x0001); code <= 0x0014; code++ {
+		value, ok := params[code]
+		if !ok || len(value) < selfTestParamLen {
+			continue
+		}
+		result := SelfTestResult{
+			Code:            value[0] >> 5,
+			Result:          value[0] & 0x0f,
+			Number:          value[1],
+			PowerOnHours:    binary.BigEndian.Uint16(value[2:4]),
+			FirstFailureLBA: binary.BigEndian.Uint64(value[4:12]),
+			SenseKey:        value[12] & 0x0f,
+			ASC:             value[13],
+			ASCQ:            value[14],
+		}
+		if result.Code == 0 && result.Result == 0 && result.PowerOnHours == 0 {
+			continue
+		}
+		log.Results = append(log.Results, result)
+	}
+	return log, nil
+}
+
+// ParseSolidStateMediaLog parses the solid state media log page
+func ParseSolidStateMediaLog(buf []byte) (*SolidStateMediaLog, error) {
+	params, err := parseLogParameters(buf, SolidStateMediaLogPage)
+	if err != nil {
+		return nil, err
+	}
+	log := &SolidStateMediaLog{}
+	// the percentage used endurance indicator is the last byte of the 4 byte parameter
+	if value := params[0x0001]; len(value) >= 4 {
+		log.PercentageUsedEnduranceIndicator = value[3]
+	}
+	return log, nil
+}
+
+// ParseInformationalExceptionsLog parses the informational exceptions log page
+func ParseInformationalExceptionsLog(buf []byte) (*InformationalExceptionsLog, error) {
+	params, err := parseLogParameters(buf, InformationalExceptionsLogPage)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := params[0x0000]
+	if !ok || len(value) < 3 {
+		return nil, fmt.Errorf("informational exceptions general parameter not found")
+	}
+	return &InformationalExceptionsLog{
+		ASC:                   value[0],
+		ASCQ:                  value[1],
+		MostRecentTemperature: int16(value[2]),
+	}, nil
+}
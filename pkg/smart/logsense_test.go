@@ -0,0 +1,241 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// log pages recorded from a SAS HDD
+var (
+	supportedLogPagesResp = []byte{
+		0x00, 0x00, 0x00, 0x0c,
+		0x00, 0x02, 0x03, 0x05, 0x06, 0x0d, 0x0e, 0x0f, 0x10, 0x15, 0x18, 0x2f,
+	}
+	readErrorCounterResp = []byte{
+		0x03, 0x00, 0x00, 0x54,
+		0x00, 0x00, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x34, 0x8e, 0x1d, 0x6b,
+		0x00, 0x01, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x02, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x03, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x34, 0x8e, 0x1d, 0x6b,
+		0x00, 0x04, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x05, 0x02, 0x08, 0x00, 0x00, 0x3f, 0x2c, 0x8b, 0x4a, 0x60, 0x00,
+		0x00, 0x06, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+	}
+	nonMediumErrorResp = []byte{
+		0x06, 0x00, 0x00, 0x0c,
+		0x00, 0x00, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x11,
+	}
+	temperatureResp = []byte{
+		0x0d, 0x00, 0x00, 0x0c,
+		0x00, 0x00, 0x03, 0x02, 0x00, 0x25,
+		0x00, 0x01, 0x03, 0x02, 0x00, 0x44,
+	}
+	startStopCycleResp = []byte{
+		0x0e, 0x00, 0x00, 0x34,
+		0x00, 0x01, 0x17, 0x06, 0x32, 0x30, 0x31, 0x39, 0x32, 0x33,
+		0x00, 0x02, 0x17, 0x06, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+		0x00, 0x03, 0x17, 0x04, 0x00, 0x00, 0xc3, 0x50,
+		0x00, 0x04, 0x17, 0x04, 0x00, 0x00, 0x00, 0x4b,
+		0x00, 0x05, 0x17, 0x04, 0x00, 0x09, 0x27, 0xc0,
+		0x00, 0x06, 0x17, 0x04, 0x00, 0x00, 0x02, 0x3a,
+	}
+	selfTestResp = []byte{
+		0x10, 0x00, 0x00, 0x3c,
+		// most recent, background long self-test failed in segment 3
+		0x00, 0x01, 0x03, 0x10, 0x47, 0x03, 0x4e, 0x20,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0xd6, 0x87, 0x03, 0x11, 0x00, 0x00,
+		// background short self-test completed without error
+		0x00, 0x02, 0x03, 0x10, 0x20, 0x00, 0x4d, 0xf0,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
+		// unused
+		0x00, 0x03, 0x03, 0x10, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	solidStateMediaResp = []byte{
+		0x11, 0x00, 0x00, 0x08,
+		0x00, 0x01, 0x03, 0x04, 0x00, 0x00, 0x00, 0x07,
+	}
+	informationalExceptionsResp = []byte{
+		0x2f, 0x00, 0x00, 0x08,
+		0x00, 0x00, 0x03, 0x04, 0x5d, 0x10, 0x26, 0x00,
+	}
+)
+
+func TestParseSupportedLogPages(t *testing.T) {
+	tests := map[string]struct {
+		buf     []byte
+		want    []uint8
+		wantErr bool
+	}{
+		"supported pages": {
+			buf:  supportedLogPagesResp,
+			want: []uint8{0x00, 0x02, 0x03, 0x05, 0x06, 0x0d, 0x0e, 0x0f, 0x10, 0x15, 0x18, 0x2f},
+		},
+		"wrong page": {
+			buf:     temperatureResp,
+			wantErr: true,
+		},
+		"truncated page": {
+			buf:     supportedLogPagesResp[:10],
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseSupportedLogPages(test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseLogPages(t *testing.T) {
+	tests := map[string]struct {
+		page    uint8
+		buf     []byte
+		want    SCSILogPages
+		wantErr bool
+	}{
+		"read error counter": {
+			page: ReadErrorCounterLogPage,
+			buf:  readErrorCounterResp,
+			want: SCSILogPages{ReadErrors: &ErrorCounterLog{
+				CorrectedWithoutDelay: 881728875,
+				TotalCorrected:        881728875,
+				TotalBytesProcessed:   69460548018176,
+				TotalUncorrected:      2,
+			}},
+		},
+		"non medium errors": {
+			page: NonMediumErrorLogPage,
+			buf:  nonMediumErrorResp,
+			want: SCSILogPages{NonMediumErrors: &NonMediumErrorLog{Count: 17}},
+		},
+		"temperature": {
+			page: TemperatureLogPage,
+			buf:  temperatureResp,
+			want: SCSILogPages{Temperature: &TemperatureLog{
+				Current:        37,
+				CurrentValid:   true,
+				Reference:      68,
+				ReferenceValid: true,
+			}},
+		},
+		"start stop cycles": {
+			page: StartStopCycleLogPage,
+			buf:  startStopCycleResp,
+			want: SCSILogPages{StartStopCycles: &StartStopCycleLog{
+				ManufactureDate:             "2019/23",
+				SpecifiedCycles:             50000,
+				AccumulatedCycles:           75,
+				SpecifiedLoadUnloadCycles:   600000,
+				AccumulatedLoadUnloadCycles: 570,
+			}},
+		},
+		"self test results": {
+			page: SelfTestResultsLogPage,
+			buf:  selfTestResp,
+			want: SCSILogPages{SelfTest: &SelfTestLog{Results: []SelfTestResult{
+				{Code: 2, Result: 7, Number: 3, PowerOnHours: 20000, FirstFailureLBA: 1234567,
+					SenseKey: 3, ASC: 0x11},
+				{Code: 1, Result: 0, PowerOnHours: 19952, FirstFailureLBA: 0xffffffffffffffff},
+			}}},
+		},
+		"solid state media": {
+			page: SolidStateMediaLogPage,
+			buf:  solidStateMediaResp,
+			want: SCSILogPages{SolidStateMedia: &SolidStateMediaLog{PercentageUsedEnduranceIndicator: 7}},
+		},
+		"informational exceptions": {
+			page: InformationalExceptionsLogPage,
+			buf:  informationalExceptionsResp,
+			want: SCSILogPages{InformationalExceptions: &InformationalExceptionsLog{
+				ASC:                   0x5d,
+				ASCQ:                  0x10,
+				MostRecentTemperature: 38,
+			}},
+		},
+		"page code mismatch": {
+			page:    TemperatureLogPage,
+			buf:     nonMediumErrorResp,
+			wantErr: true,
+		},
+		"truncated parameter": {
+			page:    ReadErrorCounterLogPage,
+			buf:     append([]byte{0x03, 0x00, 0x00, 0x08}, readErrorCounterResp[4:12]...),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := SCSILogPages{}
+			err := got.decode(test.page, test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSelfTestResultFailed(t *testing.T) {
+	log, err := ParseSelfTestLog(selfTestResp)
+	assert.NoError(t, err)
+	assert.True(t, log.Results[0].Failed())
+	assert.False(t, log.Results[1].Failed())
+}
+
+func TestParseGrownDefectCount(t *testing.T) {
+	tests := map[string]struct {
+		buf     []byte
+		want    uint64
+		wantErr bool
+	}{
+		"grown defects": {
+			buf:  []byte{0x00, 0x0c, 0x00, 0x60},
+			want: 12,
+		},
+		"grown defect list not valid": {
+			buf:     []byte{0x00, 0x04, 0x00, 0x00},
+			wantErr: true,
+		},
+		"unexpected format": {
+			buf:     []byte{0x00, 0x0d, 0x00, 0x60},
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseGrownDefectCount(test.buf)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
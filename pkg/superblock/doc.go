@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package superblock detects the on-disk signatures written by storage systems
+which take ownership of a whole block device. The superblocks are read directly
+from the device (or an image file), so that the signatures are found even if
+the storage system is not active on the node.
+
+The following signatures are detected:
+  - Ceph BlueStore OSD label
+  - LVM2 physical volume label, along with the name of the volume group
+  - Linux md RAID member superblock (versions 0.90, 1.0, 1.1 and 1.2)
+  - LUKS1 and LUKS2 headers
+  - bcache backing and cache device superblock
+  - VDO geometry block
+
+Usage:
+
+	import "github.com/openebs/node-disk-manager/pkg/superblock"
+
+	sig, err := superblock.Probe("/dev/sdb")
+	if err != nil {
+		klog.Error(err)
+	}
+	if sig != nil {
+		fmt.Printf("Type: %s, UUID: %s, Label: %s\n", sig.Type, sig.UUID, sig.Label)
+	}
+*/
+package superblock
@@ -0,0 +1,281 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package superblock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openebs/node-disk-manager/pkg/lvm"
+)
+
+// Type is the type of the signature found on the device
+type Type string
+
+const (
+	// CephBlueStore is the label written by ceph at the start of a BlueStore OSD device
+	CephBlueStore Type = "ceph_bluestore"
+	// LVM2 is the label written on an LVM2 physical volume
+	LVM2 Type = "LVM2_member"
+	// MDRaid is the superblock written on a member of a Linux md RAID array
+	MDRaid Type = "linux_raid_member"
+	// LUKS is the header of a LUKS encrypted device
+	LUKS Type = "crypto_LUKS"
+	// BCache is the superblock of a bcache backing or cache device
+	BCache Type = "bcache"
+	// VDO is the geometry block of a VDO device
+	VDO Type = "vdo"
+)
+
+// Signature is an ownership signature found on a block device
+type Signature struct {
+	// Type of the signature
+	Type Type
+	// UUID of the device or of the entity to which the device belongs,
+	// eg: the OSD uuid for ceph or the array uuid for md RAID
+	UUID string
+	// Label is the name of the entity to which the device belongs, if it is
+	// stored in the superblock. eg: the volume group name for LVM or the array
+	// name for md RAID
+	Label string
+}
+
+const (
+	sectorSize = 512
+
+	bluestoreMagic = "bluestore block device\n"
+
+	mdMagic          = 0xa92b4efc
+	md090ReservedLen = 64 * 1024
+
+	bcacheSuperBlockOffset = 4096
+
+	vdoMagic = "dmvdo001"
+)
+
+var (
+	luksMagic   = []byte{'L', 'U', 'K', 'S', 0xba, 0xbe}
+	bcacheMagic = []byte{
+		0xc6, 0x85, 0x73, 0xf6, 0x4e, 0x1a, 0x45, 0xca,
+		0x82, 0x65, 0xf5, 0x7f, 0x48, 0xba, 0x6d, 0x81,
+	}
+)
+
+// prober checks for a signature on the device. nil is returned if the
+// signature is not present.
+type prober func(r io.ReaderAt, size int64) (*Signature, error)
+
+// probers is the order in which the signatures are checked. md RAID is checked
+// before LVM, since the data area of v0.90 and v1.0 members starts at the
+// beginning of the device and can contain the label of a PV created on the array.
+var probers = []prober{
+	probeLUKS,
+	probeVDO,
+	probeBlueStore,
+	probeBCache,
+	probeMDRaid,
+	probeLVM2,
+}
+
+// Probe reads the superblocks from the device or image file at the given path
+// and returns the ownership signature found on it. nil is returned if none of
+// the known signatures are present.
+func Probe(path string) (*Signature, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// stat does not report the size of block devices, seeking to the end works
+	// for both block devices and regular files
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting size of %s: %v", path, err)
+	}
+	return ProbeReader(f, size)
+}
+
+// ProbeReader returns the ownership signature found in the reader of the given size
+func ProbeReader(r io.ReaderAt, size int64) (*Signature, error) {
+	for _, probe := range probers {
+		sig, err := probe(r, size)
+		if err != nil {
+			return nil, err
+		}
+		if sig != nil {
+			return sig, nil
+		}
+	}
+	return nil, nil
+}
+
+// readAt reads length bytes at the given offset. nil is returned if the
+// range is outside the device, so that small devices are not reported as errors.
+func readAt(r io.ReaderAt, size, offset int64, length int) ([]byte, error) {
+	if offset < 0 || offset+int64(length) > size {
+		return nil, nil
+	}
+	buf := make([]byte, length)
+	n, err := r.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && n == length) {
+		return nil, fmt.Errorf("error reading %d bytes at offset %d: %v", length, offset, err)
+	}
+	return buf, nil
+}
+
+func probeBlueStore(r io.ReaderAt, size int64) (*Signature, error) {
+	// the label is "bluestore block device\n<osd uuid>\n"
+	buf, err := readAt(r, size, 0, len(bluestoreMagic)+37)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, []byte(bluestoreMagic)) {
+		return nil, nil
+	}
+	return &Signature{
+		Type: CephBlueStore,
+		UUID: cString(buf[len(bluestoreMagic) : len(bluestoreMagic)+36]),
+	}, nil
+}
+
+func probeLUKS(r io.ReaderAt, size int64) (*Signature, error) {
+	buf, err := readAt(r, size, 0, sectorSize)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, luksMagic) {
+		return nil, nil
+	}
+	sig := &Signature{
+		Type: LUKS,
+		UUID: cString(buf[168:208]),
+	}
+	// only LUKS2 headers have a label
+	if binary.BigEndian.Uint16(buf[6:8]) == 2 {
+		sig.Label = cString(buf[24:72])
+	}
+	return sig, nil
+}
+
+func probeVDO(r io.ReaderAt, size int64) (*Signature, error) {
+	buf, err := readAt(r, size, 0, 56)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, []byte(vdoMagic)) {
+		return nil, nil
+	}
+	return &Signature{
+		Type: VDO,
+		UUID: formatUUID(buf[40:56]),
+	}, nil
+}
+
+func probeBCache(r io.ReaderAt, size int64) (*Signature, error) {
+	buf, err := readAt(r, size, bcacheSuperBlockOffset, 104)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if !bytes.Equal(buf[24:40], bcacheMagic) {
+		return nil, nil
+	}
+	return &Signature{
+		Type:  BCache,
+		UUID:  formatUUID(buf[40:56]),
+		Label: cString(buf[72:104]),
+	}, nil
+}
+
+func probeMDRaid(r io.ReaderAt, size int64) (*Signature, error) {
+	// version 1.1 is at the start of the device and version 1.2 is 4K from the
+	// start. version 1.0 is at least 8K from the end, aligned to 4K.
+	v10Offset := ((size/sectorSize - 16) &^ 7) * sectorSize
+	for _, offset := range []int64{0, 4096, v10Offset} {
+		buf, err := readAt(r, size, offset, 64)
+		if err != nil {
+			return nil, err
+		}
+		if buf == nil || binary.LittleEndian.Uint32(buf[0:4]) != mdMagic ||
+			binary.LittleEndian.Uint32(buf[4:8]) != 1 {
+			continue
+		}
+		return &Signature{
+			Type:  MDRaid,
+			UUID:  formatMDUUID(buf[16:32]),
+			Label: cString(buf[32:64]),
+		}, nil
+	}
+
+	// version 0.90 is in the last 64K aligned block of the device
+	v090Offset := (size &^ (md090ReservedLen - 1)) - md090ReservedLen
+	buf, err := readAt(r, size, v090Offset, 64)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(buf[0:4]) != mdMagic ||
+		binary.LittleEndian.Uint32(buf[4:8]) != 0 {
+		return nil, nil
+	}
+	// the uuid is split into uuid0 at offset 20 and uuid1-3 at offset 52
+	uuid := make([]byte, 0, 16)
+	for _, off := range []int{20, 52, 56, 60} {
+		word := make([]byte, 4)
+		binary.BigEndian.PutUint32(word, binary.LittleEndian.Uint32(buf[off:off+4]))
+		uuid = append(uuid, word...)
+	}
+	return &Signature{
+		Type: MDRaid,
+		UUID: formatMDUUID(uuid),
+	}, nil
+}
+
+func probeLVM2(r io.ReaderAt, size int64) (*Signature, error) {
+	label, err := lvm.ProbeReader(r, size)
+	if err != nil || label == nil {
+		return nil, err
+	}
+	return &Signature{
+		Type:  LVM2,
+		UUID:  label.PVUUID,
+		Label: label.VGName,
+	}, nil
+}
+
+// cString converts a NUL padded byte array to a string
+func cString(buf []byte) string {
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+// formatUUID formats a 16 byte binary UUID in the canonical form
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// formatMDUUID formats a 16 byte binary UUID in the form used by mdadm
+func formatMDUUID(b []byte) string {
+	return fmt.Sprintf("%x:%x:%x:%x", b[0:4], b[4:8], b[8:12], b[12:16])
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package superblock
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbe(t *testing.T) {
+	tests := map[string]struct {
+		image string
+		want  *Signature
+	}{
+		"ceph bluestore osd": {
+			image: "bluestore.img",
+			want: &Signature{
+				Type: CephBlueStore,
+				UUID: "6b1b4a87-0d6c-4f5e-9c1b-3e6f2a7d9c41",
+			},
+		},
+		"lvm2 pv in a volume group": {
+			image: "lvm2-pv.img",
+			want: &Signature{
+				Type:  LVM2,
+				UUID:  "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+				Label: "vg_data",
+			},
+		},
+		"lvm2 pv used by a ceph osd": {
+			image: "lvm2-ceph-pv.img",
+			want: &Signature{
+				Type:  LVM2,
+				UUID:  "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+				Label: "ceph-0e7b6c4a-3f2d-4b1e-8a9c-5d6f7e8a9b0c",
+			},
+		},
+		"lvm2 pv without volume group": {
+			image: "lvm2-orphan-pv.img",
+			want: &Signature{
+				Type: LVM2,
+				UUID: "Kc3Ov1-aT9r-Fwl2-ZcQn-8xWe-Yd0p-Hs4mJu",
+			},
+		},
+		"md raid v1.2 member": {
+			image: "md-v1.2.img",
+			want: &Signature{
+				Type:  MDRaid,
+				UUID:  "5a9c2b1e:7f3d40a8:b6c1e2d3:f4a5b6c7",
+				Label: "node1:data",
+			},
+		},
+		"md raid v1.0 member": {
+			image: "md-v1.0.img",
+			want: &Signature{
+				Type:  MDRaid,
+				UUID:  "5a9c2b1e:7f3d40a8:b6c1e2d3:f4a5b6c7",
+				Label: "node1:data",
+			},
+		},
+		"md raid v0.90 member": {
+			image: "md-v0.90.img",
+			want: &Signature{
+				Type: MDRaid,
+				UUID: "5a9c2b1e:7f3d40a8:b6c1e2d3:f4a5b6c7",
+			},
+		},
+		"luks1 header": {
+			image: "luks1.img",
+			want: &Signature{
+				Type: LUKS,
+				UUID: "d3c7e1a2-9b4f-4c6e-8a2d-1f0e3b5c7a9d",
+			},
+		},
+		"luks2 header": {
+			image: "luks2.img",
+			want: &Signature{
+				Type:  LUKS,
+				UUID:  "d3c7e1a2-9b4f-4c6e-8a2d-1f0e3b5c7a9d",
+				Label: "secrets",
+			},
+		},
+		"bcache backing device": {
+			image: "bcache.img",
+			want: &Signature{
+				Type:  BCache,
+				UUID:  "8f2e4c1a-6b3d-4e7f-9a0c-2d5b8e1f4a7c",
+				Label: "backing0",
+			},
+		},
+		"vdo device": {
+			image: "vdo.img",
+			want: &Signature{
+				Type: VDO,
+				UUID: "a4b6c8d0-e2f4-4a6b-8c0d-2e4f6a8b0c1d",
+			},
+		},
+		"no signature": {
+			image: "empty.img",
+			want:  nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Probe(filepath.Join("testdata", test.image))
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestProbeErrors(t *testing.T) {
+	_, err := Probe(filepath.Join("testdata", "missing.img"))
+	assert.Error(t, err)
+
+	// devices smaller than the superblocks do not have any signature
+	got, err := ProbeReader(bytes.NewReader(make([]byte, 100)), 100)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// a label pointing to a PV header outside the sector is invalid
+	buf := make([]byte, 4096)
+	copy(buf[512:], "LABELONE")
+	copy(buf[512+24:], "LVM2 001")
+	buf[512+20] = 0xff
+	buf[512+21] = 0x01
+	_, err = ProbeReader(bytes.NewReader(buf), int64(len(buf)))
+	assert.Error(t, err)
+}
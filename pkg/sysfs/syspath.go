@@ -318,6 +318,128 @@ func (s Device) GetDeviceType(devType string) (string, error) {
 	return strings.ToLower(result), nil
 }
 
+// GetDMUUID gets the DM UUID of a device mapper device
+func (s Device) GetDMUUID() (string, error) {
+	return readSysFSFileAsString(s.sysPath + "dm/uuid")
+}
+
+// GetDMName gets the name of the map of a device mapper device, as shown
+// in /dev/mapper
+func (s Device) GetDMName() (string, error) {
+	return readSysFSFileAsString(s.sysPath + "dm/name")
+}
+
+// GetSCSIDeviceState gets the state of the SCSI device, like running or offline.
+// The state is available only for SCSI devices.
+func (s Device) GetSCSIDeviceState() (string, error) {
+	return readSysFSFileAsString(s.sysPath + "device/state")
+}
+
+// GetZonedModel gets the zoned model of the device. Can be none, host-aware or host-managed.
+// The zoned model is available only for the disk, and not for its partitions.
+func (s Device) GetZonedModel() (string, error) {
+	return readSysFSFileAsString(s.sysPath + "queue/zoned")
+}
+
+// GetNumberOfZones gets the number of zones of a zoned device
+func (s Device) GetNumberOfZones() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/nr_zones")
+}
+
+// GetZoneSize gets the size of a zone of a zoned device in bytes. The zone size
+// is reported in 512 byte sectors by queue/chunk_sectors for zoned devices.
+func (s Device) GetZoneSize() (int64, error) {
+	chunkSectors, err := readSysFSFileAsInt64(s.sysPath + "queue/chunk_sectors")
+	if err != nil {
+		return 0, err
+	}
+	return chunkSectors * sectorSize, nil
+}
+
+// GetMaxOpenZones gets the maximum number of zones that can be open at a time,
+// 0 if there is no limit
+func (s Device) GetMaxOpenZones() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/max_open_zones")
+}
+
+// GetMaxActiveZones gets the maximum number of zones that can be open or closed
+// at a time, 0 if there is no limit
+func (s Device) GetMaxActiveZones() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/max_active_zones")
+}
+
+// GetDiscardGranularity gets the size of the internal allocation unit of the
+// device in bytes, in which discard requests are handled. 0 if discard is not supported.
+func (s Device) GetDiscardGranularity() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/discard_granularity")
+}
+
+// GetDiscardMaxBytes gets the maximum size of a discard request in bytes.
+// 0 if discard is not supported.
+func (s Device) GetDiscardMaxBytes() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/discard_max_bytes")
+}
+
+// GetWriteCache gets the type of the write cache of the device. Can be
+// write back if the device has a volatile write cache, or write through.
+func (s Device) GetWriteCache() (string, error) {
+	return readSysFSFileAsString(s.sysPath + "queue/write_cache")
+}
+
+// GetOptimalIOSize gets the optimal IO size in bytes reported by the device,
+// 0 if the device does not report it
+func (s Device) GetOptimalIOSize() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/optimal_io_size")
+}
+
+// GetMinimumIOSize gets the minimum IO size in bytes preferred by the device
+func (s Device) GetMinimumIOSize() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/minimum_io_size")
+}
+
+// GetRotational checks whether the device is a rotational device
+func (s Device) GetRotational() (bool, error) {
+	return readSysFSFileAsBool(s.sysPath + "queue/rotational")
+}
+
+// GetScheduler gets the IO scheduler in use for the device. The scheduler file
+// lists the available schedulers with the one in use in brackets,
+// eg: mq-deadline kyber [bfq] none
+func (s Device) GetScheduler() (string, error) {
+	schedulers, err := readSysFSFileAsString(s.sysPath + "queue/scheduler")
+	if err != nil {
+		return "", err
+	}
+	for _, scheduler := range strings.Fields(schedulers) {
+		if strings.HasPrefix(scheduler, "[") && strings.HasSuffix(scheduler, "]") {
+			return strings.Trim(scheduler, "[]"), nil
+		}
+	}
+	// devices without an IO scheduler list only none
+	return strings.TrimSpace(schedulers), nil
+}
+
+// GetNumberOfRequests gets the number of requests that can be queued to the device
+func (s Device) GetNumberOfRequests() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/nr_requests")
+}
+
+// GetDAX checks whether the device supports direct access (DAX)
+func (s Device) GetDAX() (bool, error) {
+	return readSysFSFileAsBool(s.sysPath + "queue/dax")
+}
+
+// GetFUA checks whether the device supports forced unit access (FUA) writes
+func (s Device) GetFUA() (bool, error) {
+	return readSysFSFileAsBool(s.sysPath + "queue/fua")
+}
+
+// GetWriteZeroesMaxBytes gets the maximum size of a write zeroes request in bytes.
+// 0 if write zeroes is not supported.
+func (s Device) GetWriteZeroesMaxBytes() (int64, error) {
+	return readSysFSFileAsInt64(s.sysPath + "queue/write_zeroes_max_bytes")
+}
+
 func isDM(devName string) bool {
 	return devName[0:3] == "dm-"
 }
@@ -965,3 +965,255 @@ func TestSysFsDeviceGetDependents(t *testing.T) {
 		})
 	}
 }
+
+func TestSysFsDeviceGetSCSIDeviceState(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := map[string]struct {
+		sysfsDevice     *Device
+		createDeviceDir bool
+		state           string
+		want            string
+		wantErr         bool
+	}{
+		"device is not a SCSI device": {
+			sysfsDevice: &Device{
+				deviceName: "dm-0",
+				sysPath:    filepath.Join(tmpDir, "sys/devices/virtual/block/dm-0") + "/",
+				path:       "/dev/dm-0",
+			},
+			createDeviceDir: false,
+			want:            "",
+			wantErr:         true,
+		},
+		"SCSI device is running": {
+			sysfsDevice: &Device{
+				deviceName: "sdb",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/platform/host2/session1/target2:0:0/2:0:0:1/block/sdb") + "/",
+				path: "/dev/sdb",
+			},
+			createDeviceDir: true,
+			state:           "running\n",
+			want:            "running",
+			wantErr:         false,
+		},
+		"SCSI device is offline": {
+			sysfsDevice: &Device{
+				deviceName: "sdc",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/platform/host3/session2/target3:0:0/3:0:0:1/block/sdc") + "/",
+				path: "/dev/sdc",
+			},
+			createDeviceDir: true,
+			state:           "transport-offline\n",
+			want:            "transport-offline",
+			wantErr:         false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(tt.sysfsDevice.sysPath, 0700)
+			if tt.createDeviceDir {
+				os.MkdirAll(filepath.Join(tt.sysfsDevice.sysPath, "device"), 0700)
+				file, _ := os.Create(filepath.Join(tt.sysfsDevice.sysPath, "device", "state"))
+				file.Write([]byte(tt.state))
+				file.Close()
+			}
+			got, err := tt.sysfsDevice.GetSCSIDeviceState()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSCSIDeviceState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			os.RemoveAll(tt.sysfsDevice.sysPath)
+		})
+	}
+}
+
+func TestSysFsDeviceGetZonedModel(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := map[string]struct {
+		sysfsDevice    *Device
+		createQueueDir bool
+		zoned          string
+		want           string
+		wantErr        bool
+	}{
+		"no queue directory in syspath": {
+			sysfsDevice: &Device{
+				deviceName: "sda1",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda1") + "/",
+				path: "/dev/sda1",
+			},
+			createQueueDir: false,
+			want:           "",
+			wantErr:        true,
+		},
+		"device is not zoned": {
+			sysfsDevice: &Device{
+				deviceName: "sdb",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sdb") + "/",
+				path: "/dev/sdb",
+			},
+			createQueueDir: true,
+			zoned:          "none\n",
+			want:           blockdevice.ZonedModelNone,
+			wantErr:        false,
+		},
+		"host managed SMR disk": {
+			sysfsDevice: &Device{
+				deviceName: "sdc",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1f.2/ata2/host1/target1:0:0/1:0:0:0/block/sdc") + "/",
+				path: "/dev/sdc",
+			},
+			createQueueDir: true,
+			zoned:          "host-managed\n",
+			want:           blockdevice.ZonedModelHostManaged,
+			wantErr:        false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(tt.sysfsDevice.sysPath, 0700)
+			if tt.createQueueDir {
+				os.MkdirAll(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue"), 0700)
+				file, _ := os.Create(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue", "zoned"))
+				file.Write([]byte(tt.zoned))
+				file.Close()
+			}
+			got, err := tt.sysfsDevice.GetZonedModel()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetZonedModel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			os.RemoveAll(tt.sysfsDevice.sysPath)
+		})
+	}
+}
+
+func TestSysFsDeviceGetZoneSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := map[string]struct {
+		sysfsDevice    *Device
+		createQueueDir bool
+		chunkSectors   string
+		want           int64
+		wantErr        bool
+	}{
+		"no queue directory in syspath": {
+			sysfsDevice: &Device{
+				deviceName: "nvme0n2",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n2") + "/",
+				path: "/dev/nvme0n2",
+			},
+			createQueueDir: false,
+			want:           0,
+			wantErr:        true,
+		},
+		"ZNS namespace with 1GiB zones": {
+			sysfsDevice: &Device{
+				deviceName: "nvme1n2",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1d.0/0000:3e:00.0/nvme/nvme1/nvme1n2") + "/",
+				path: "/dev/nvme1n2",
+			},
+			createQueueDir: true,
+			chunkSectors:   "2097152\n",
+			want:           1073741824,
+			wantErr:        false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(tt.sysfsDevice.sysPath, 0700)
+			if tt.createQueueDir {
+				os.MkdirAll(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue"), 0700)
+				file, _ := os.Create(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue", "chunk_sectors"))
+				file.Write([]byte(tt.chunkSectors))
+				file.Close()
+			}
+			got, err := tt.sysfsDevice.GetZoneSize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetZoneSize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			os.RemoveAll(tt.sysfsDevice.sysPath)
+		})
+	}
+}
+
+func TestSysFsDeviceGetScheduler(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := map[string]struct {
+		sysfsDevice    *Device
+		createQueueDir bool
+		scheduler      string
+		want           string
+		wantErr        bool
+	}{
+		"no queue directory in syspath": {
+			sysfsDevice: &Device{
+				deviceName: "sda1",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda1") + "/",
+				path: "/dev/sda1",
+			},
+			createQueueDir: false,
+			want:           "",
+			wantErr:        true,
+		},
+		"scheduler selected among many": {
+			sysfsDevice: &Device{
+				deviceName: "sdb",
+				sysPath: filepath.Join(tmpDir,
+					"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sdb") + "/",
+				path: "/dev/sdb",
+			},
+			createQueueDir: true,
+			scheduler:      "mq-deadline kyber [bfq] none\n",
+			want:           "bfq",
+			wantErr:        false,
+		},
+		"device without scheduler": {
+			sysfsDevice: &Device{
+				deviceName: "dm-0",
+				sysPath:    filepath.Join(tmpDir, "sys/devices/virtual/block/dm-0") + "/",
+				path:       "/dev/dm-0",
+			},
+			createQueueDir: true,
+			scheduler:      "none\n",
+			want:           "none",
+			wantErr:        false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(tt.sysfsDevice.sysPath, 0700)
+			if tt.createQueueDir {
+				os.MkdirAll(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue"), 0700)
+				file, _ := os.Create(filepath.Join(tt.sysfsDevice.sysPath,
+					"queue", "scheduler"))
+				file.Write([]byte(tt.scheduler))
+				file.Close()
+			}
+			got, err := tt.sysfsDevice.GetScheduler()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetScheduler() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			os.RemoveAll(tt.sysfsDevice.sysPath)
+		})
+	}
+}
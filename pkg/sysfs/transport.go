@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysfs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+)
+
+const (
+	// NVMeFabricsSubSystem is the key used to represent the controllers of NVMe
+	// over fabrics devices in sysfs
+	NVMeFabricsSubSystem = "nvme-fabrics"
+
+	// nvmeTransportPCIe is the transport of an NVMe controller on the PCIe bus,
+	// as present in the transport file of the controller
+	nvmeTransportPCIe = "pcie"
+	// nvmeTransportFC is the transport of an NVMe over FC controller
+	nvmeTransportFC = "fc"
+)
+
+// transportMatcher matches a component of the syspath of a device to the
+// transport through which the device is attached
+type transportMatcher struct {
+	transport string
+	match     func(part string) bool
+}
+
+// scsiTransportMatchers are the matchers for the transports of SCSI and virtio
+// devices. The order is significant, since the syspath of a device attached to
+// a SAS or iSCSI host can also contain the components of the PCI or platform bus.
+var scsiTransportMatchers = []transportMatcher{
+	{blockdevice.TransportISCSI, func(part string) bool { return hasNumberedPrefix(part, "session") }},
+	{blockdevice.TransportFC, func(part string) bool { return strings.HasPrefix(part, "rport-") }},
+	{blockdevice.TransportSAS, func(part string) bool { return strings.HasPrefix(part, "end_device-") }},
+	{blockdevice.TransportUSB, func(part string) bool { return hasNumberedPrefix(part, "usb") }},
+	{blockdevice.TransportSATA, func(part string) bool { return hasNumberedPrefix(part, "ata") }},
+	{blockdevice.TransportVirtio, func(part string) bool { return hasNumberedPrefix(part, "virtio") }},
+}
+
+// GetTransport gets the transport through which the device is attached, based on
+// the components of the syspath of the device. eg: the syspath of a SATA disk is
+// /sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/
+//
+// The details of the target are filled for iSCSI, FC and NVMe over fabrics devices.
+// If the details cannot be read, the transport is returned along with the error.
+// An empty transport is returned if the transport cannot be determined, like for
+// device mapper and loop devices.
+func (s Device) GetTransport() (blockdevice.TransportInformation, error) {
+	if controllerPath, ok := s.getNVMeControllerPath(); ok {
+		return getNVMeTransport(controllerPath)
+	}
+
+	parts := strings.Split(s.sysPath, "/")
+	for _, matcher := range scsiTransportMatchers {
+		for _, part := range parts {
+			if !matcher.match(part) {
+				continue
+			}
+			switch matcher.transport {
+			case blockdevice.TransportISCSI:
+				return getISCSITransport(part)
+			case blockdevice.TransportFC:
+				return getFCTransport(part)
+			default:
+				return blockdevice.TransportInformation{Transport: matcher.transport}, nil
+			}
+		}
+	}
+	return blockdevice.TransportInformation{}, nil
+}
+
+// getNVMeControllerPath gets the syspath of the controller of an NVMe namespace.
+// The syspath of the namespace can be under the controller, like
+// /sys/devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1/ and
+// /sys/devices/virtual/nvme-fabrics/ctl/nvme1/nvme1n1/, or under the subsystem
+// if native NVMe multipath is enabled, like
+// /sys/devices/virtual/nvme-subsystem/nvme-subsys0/nvme0n1/. In the latter case,
+// the first controller of the subsystem is used.
+func (s Device) getNVMeControllerPath() (string, bool) {
+	parts := strings.Split(s.sysPath, "/")
+	for i, part := range parts {
+		switch {
+		case part == NVMeSubSystem && len(parts)-1 >= i+1:
+			return strings.Join(parts[:i+2], "/"), true
+		case part == NVMeFabricsSubSystem && len(parts)-1 >= i+2:
+			return strings.Join(parts[:i+3], "/"), true
+		case part == NVMeSubSysClass && len(parts)-1 >= i+1:
+			subsystemPath := strings.Join(parts[:i+2], "/")
+			files, err := ioutil.ReadDir(subsystemPath)
+			if err != nil {
+				return "", false
+			}
+			for _, file := range files {
+				if hasNumberedPrefix(file.Name(), "nvme") {
+					return filepath.Join(subsystemPath, file.Name()), true
+				}
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// getNVMeTransport gets the transport of an NVMe controller, along with the NQN
+// of the subsystem, the address and the controller ID for fabrics controllers
+func getNVMeTransport(controllerPath string) (blockdevice.TransportInformation, error) {
+	transportInfo := blockdevice.TransportInformation{}
+	transport, err := readSysFSFileAsString(filepath.Join(controllerPath, "transport"))
+	if err != nil {
+		return transportInfo, fmt.Errorf("unable to get NVMe transport, error: %v", err)
+	}
+	transportInfo.Transport = NVMeSubSystem + "-" + transport
+	if transport == nvmeTransportPCIe {
+		return transportInfo, nil
+	}
+
+	if transportInfo.Target, err = readSysFSFileAsString(filepath.Join(controllerPath, "subsysnqn")); err != nil {
+		return transportInfo, fmt.Errorf("unable to get NVMe subsystem NQN, error: %v", err)
+	}
+	if transportInfo.Portal, err = readSysFSFileAsString(filepath.Join(controllerPath, "address")); err != nil {
+		return transportInfo, fmt.Errorf("unable to get NVMe controller address, error: %v", err)
+	}
+	if transportInfo.SessionID, err = readSysFSFileAsString(filepath.Join(controllerPath, "cntlid")); err != nil {
+		return transportInfo, fmt.Errorf("unable to get NVMe controller ID, error: %v", err)
+	}
+	if transport == nvmeTransportFC {
+		transportInfo.WWPN = getNVMeFCPortName(transportInfo.Portal)
+	}
+	return transportInfo, nil
+}
+
+// getNVMeFCPortName gets the WWPN of the remote port from the address of an NVMe
+// over FC controller. eg: traddr=nn-0x200000109b579ef6:pn-0x100000109b579ef6,host_traddr=...
+func getNVMeFCPortName(address string) string {
+	for _, field := range strings.Split(address, ",") {
+		traddr := strings.TrimPrefix(field, "traddr=")
+		if traddr == field {
+			continue
+		}
+		for _, name := range strings.Split(traddr, ":") {
+			if strings.HasPrefix(name, "pn-") {
+				return strings.TrimPrefix(name, "pn-")
+			}
+		}
+	}
+	return ""
+}
+
+// getISCSITransport gets the IQN of the target and the portal of the iSCSI session.
+// eg: session1, whose details are in /sys/class/iscsi_session/session1 and the
+// connections of the session are /sys/class/iscsi_connection/connection1:0
+func getISCSITransport(session string) (blockdevice.TransportInformation, error) {
+	sessionID := strings.TrimPrefix(session, "session")
+	transportInfo := blockdevice.TransportInformation{
+		Transport: blockdevice.TransportISCSI,
+		SessionID: sessionID,
+	}
+	var err error
+	transportInfo.Target, err = readSysFSFileAsString(
+		sysFSDirectoryPath + "class/iscsi_session/" + session + "/targetname")
+	if err != nil {
+		return transportInfo, fmt.Errorf("unable to get iSCSI target name, error: %v", err)
+	}
+
+	connections, err := filepath.Glob(sysFSDirectoryPath + "class/iscsi_connection/connection" + sessionID + ":*")
+	if err != nil || len(connections) == 0 {
+		return transportInfo, fmt.Errorf("unable to find connection of iSCSI session %s", session)
+	}
+	address, err := readSysFSFileAsString(filepath.Join(connections[0], "persistent_address"))
+	if err != nil {
+		return transportInfo, fmt.Errorf("unable to get iSCSI portal address, error: %v", err)
+	}
+	port, err := readSysFSFileAsString(filepath.Join(connections[0], "persistent_port"))
+	if err != nil {
+		return transportInfo, fmt.Errorf("unable to get iSCSI portal port, error: %v", err)
+	}
+	transportInfo.Portal = net.JoinHostPort(address, port)
+	return transportInfo, nil
+}
+
+// getFCTransport gets the WWPN of the remote port of an FC device.
+// eg: rport-5:0-2, whose details are in /sys/class/fc_remote_ports/rport-5:0-2
+func getFCTransport(rport string) (blockdevice.TransportInformation, error) {
+	transportInfo := blockdevice.TransportInformation{
+		Transport: blockdevice.TransportFC,
+	}
+	var err error
+	transportInfo.WWPN, err = readSysFSFileAsString(sysFSDirectoryPath + "class/fc_remote_ports/" + rport + "/port_name")
+	if err != nil {
+		return transportInfo, fmt.Errorf("unable to get FC port name, error: %v", err)
+	}
+	return transportInfo, nil
+}
+
+// hasNumberedPrefix checks if the name is the prefix followed by a number. eg: ata1
+func hasNumberedPrefix(name, prefix string) bool {
+	number := strings.TrimPrefix(name, prefix)
+	if number == name || len(number) == 0 {
+		return false
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openebs/node-disk-manager/blockdevice"
+)
+
+func TestSysFsDeviceGetTransport(t *testing.T) {
+	tmp := sysFSDirectoryPath
+	sysFSDirectoryPath = filepath.Join(t.TempDir(), "sys") + "/"
+	defer func() {
+		sysFSDirectoryPath = tmp
+	}()
+
+	tests := map[string]struct {
+		devicePath string
+		// files are the sysfs files to be created, relative to the sysfs directory
+		files   map[string]string
+		want    blockdevice.TransportInformation
+		wantErr bool
+	}{
+		"SATA disk": {
+			devicePath: "devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			want:       blockdevice.TransportInformation{Transport: blockdevice.TransportSATA},
+		},
+		"partition of SATA disk": {
+			devicePath: "devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda/sda1",
+			want:       blockdevice.TransportInformation{Transport: blockdevice.TransportSATA},
+		},
+		"SAS disk": {
+			devicePath: "devices/pci0000:00/0000:00:03.0/0000:02:00.0/host0/port-0:0/end_device-0:0/target0:0:0/0:0:0:0/block/sdb",
+			want:       blockdevice.TransportInformation{Transport: blockdevice.TransportSAS},
+		},
+		"USB disk": {
+			devicePath: "devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdc",
+			want:       blockdevice.TransportInformation{Transport: blockdevice.TransportUSB},
+		},
+		"virtio-blk disk": {
+			devicePath: "devices/pci0000:00/0000:00:05.0/virtio2/block/vda",
+			want:       blockdevice.TransportInformation{Transport: blockdevice.TransportVirtio},
+		},
+		"iSCSI disk": {
+			devicePath: "devices/platform/host3/session1/target3:0:0/3:0:0:1/block/sdd",
+			files: map[string]string{
+				"class/iscsi_session/session1/targetname":                 "iqn.2016-09.com.openebs.cstor:vol1\n",
+				"class/iscsi_connection/connection1:0/persistent_address": "10.0.0.1\n",
+				"class/iscsi_connection/connection1:0/persistent_port":    "3260\n",
+			},
+			want: blockdevice.TransportInformation{
+				Transport: blockdevice.TransportISCSI,
+				Target:    "iqn.2016-09.com.openebs.cstor:vol1",
+				Portal:    "10.0.0.1:3260",
+				SessionID: "1",
+			},
+		},
+		"iSCSI disk without connection": {
+			devicePath: "devices/platform/host4/session2/target4:0:0/4:0:0:1/block/sde",
+			files: map[string]string{
+				"class/iscsi_session/session2/targetname": "iqn.2016-09.com.openebs.cstor:vol2\n",
+			},
+			want: blockdevice.TransportInformation{
+				Transport: blockdevice.TransportISCSI,
+				Target:    "iqn.2016-09.com.openebs.cstor:vol2",
+				SessionID: "2",
+			},
+			wantErr: true,
+		},
+		"FC disk": {
+			devicePath: "devices/pci0000:00/0000:00:02.0/0000:05:00.0/host5/rport-5:0-2/target5:0:0/5:0:0:0/block/sdf",
+			files: map[string]string{
+				"class/fc_remote_ports/rport-5:0-2/port_name": "0x500601663ee0025f\n",
+			},
+			want: blockdevice.TransportInformation{
+				Transport: blockdevice.TransportFC,
+				WWPN:      "0x500601663ee0025f",
+			},
+		},
+		"NVMe PCIe disk": {
+			devicePath: "devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1",
+			files: map[string]string{
+				"devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/transport": "pcie\n",
+			},
+			want: blockdevice.TransportInformation{Transport: blockdevice.TransportNVMePCIe},
+		},
+		"NVMe over TCP disk": {
+			devicePath: "devices/virtual/nvme-fabrics/ctl/nvme1/nvme1n1",
+			files: map[string]string{
+				"devices/virtual/nvme-fabrics/ctl/nvme1/transport": "tcp\n",
+				"devices/virtual/nvme-fabrics/ctl/nvme1/subsysnqn": "nqn.2019-05.io.openebs:vol1\n",
+				"devices/virtual/nvme-fabrics/ctl/nvme1/address":   "traddr=10.0.0.2,trsvcid=4420\n",
+				"devices/virtual/nvme-fabrics/ctl/nvme1/cntlid":    "1\n",
+			},
+			want: blockdevice.TransportInformation{
+				Transport: blockdevice.TransportNVMeTCP,
+				Target:    "nqn.2019-05.io.openebs:vol1",
+				Portal:    "traddr=10.0.0.2,trsvcid=4420",
+				SessionID: "1",
+			},
+		},
+		"NVMe over FC disk with native multipath": {
+			devicePath: "devices/virtual/nvme-subsystem/nvme-subsys2/nvme2n1",
+			files: map[string]string{
+				"devices/virtual/nvme-subsystem/nvme-subsys2/nvme2/transport": "fc\n",
+				"devices/virtual/nvme-subsystem/nvme-subsys2/nvme2/subsysnqn": "nqn.2019-05.io.openebs:vol2\n",
+				"devices/virtual/nvme-subsystem/nvme-subsys2/nvme2/address":   "traddr=nn-0x200000109b579ef6:pn-0x100000109b579ef6,host_traddr=nn-0x20000090fae0b5f5:pn-0x10000090fae0b5f5\n",
+				"devices/virtual/nvme-subsystem/nvme-subsys2/nvme2/cntlid":    "5\n",
+			},
+			want: blockdevice.TransportInformation{
+				Transport: blockdevice.TransportNVMeFC,
+				Target:    "nqn.2019-05.io.openebs:vol2",
+				Portal:    "traddr=nn-0x200000109b579ef6:pn-0x100000109b579ef6,host_traddr=nn-0x20000090fae0b5f5:pn-0x10000090fae0b5f5",
+				SessionID: "5",
+				WWPN:      "0x100000109b579ef6",
+			},
+		},
+		"NVMe disk without transport": {
+			devicePath: "devices/pci0000:00/0000:00:1d.0/0000:3e:00.0/nvme/nvme3/nvme3n1",
+			want:       blockdevice.TransportInformation{},
+			wantErr:    true,
+		},
+		"dm device": {
+			devicePath: "devices/virtual/block/dm-0",
+			want:       blockdevice.TransportInformation{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sysPath := filepath.Join(sysFSDirectoryPath, tt.devicePath) + "/"
+			os.MkdirAll(sysPath, 0700)
+			for file, content := range tt.files {
+				path := filepath.Join(sysFSDirectoryPath, file)
+				os.MkdirAll(filepath.Dir(path), 0700)
+				os.WriteFile(path, []byte(content), 0600)
+			}
+			device := Device{
+				deviceName: filepath.Base(tt.devicePath),
+				path:       "/dev/" + filepath.Base(tt.devicePath),
+				sysPath:    sysPath,
+			}
+			got, err := device.GetTransport()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTransport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
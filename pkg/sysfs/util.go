@@ -17,6 +17,7 @@ limitations under the License.
 package sysfs
 
 import (
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strconv"
@@ -34,6 +35,22 @@ func readSysFSFileAsInt64(sysFilePath string) (int64, error) {
 	return strconv.ParseInt(strings.TrimSuffix(string(b), "\n"), 10, 64)
 }
 
+// readSysFSFileAsBool reads a file containing 0 or 1
+// and converts that content into bool
+func readSysFSFileAsBool(sysFilePath string) (bool, error) {
+	value, err := readSysFSFileAsInt64(sysFilePath)
+	if err != nil {
+		return false, err
+	}
+	switch value {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return false, fmt.Errorf("undefined boolean value %d in %s", value, sysFilePath)
+}
+
 func readSysFSFileAsString(sysFilePath string) (string, error) {
 	b, err := ioutil.ReadFile(filepath.Clean(sysFilePath))
 	if err != nil {
@@ -125,3 +125,42 @@ func TestReadSysFSFileAsInt64(t *testing.T) {
 		})
 	}
 }
+
+func TestReadSysFSFileAsBool(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := map[string]struct {
+		fileContent string
+		want        bool
+		wantErr     bool
+	}{
+		"value is 1": {
+			fileContent: "1\n",
+			want:        true,
+			wantErr:     false,
+		},
+		"value is 0": {
+			fileContent: "0\n",
+			want:        false,
+			wantErr:     false,
+		},
+		"value is not a boolean": {
+			fileContent: "2\n",
+			want:        false,
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			filePath := tmpDir + "/fua"
+			if err := os.WriteFile(filePath, []byte(tt.fileContent), 0600); err != nil {
+				t.Fatalf("unable to write to file %s, %v", filePath, err)
+			}
+			got, err := readSysFSFileAsBool(filePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readSysFSFileAsBool() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
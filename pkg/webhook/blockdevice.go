@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/validate-openebs-io-v1alpha1-blockdevice,mutating=false,failurePolicy=ignore,sideEffects=None,groups=openebs.io,resources=blockdevices,verbs=update,versions=v1alpha1,name=vblockdevice.openebs.io,admissionReviewVersions=v1
+
+// BlockDeviceWebhook validates BlockDevices. The daemon updates BlockDevices
+// on every device event, so the webhook is registered with failurePolicy
+// Ignore to keep device discovery working while the webhook is unavailable.
+type BlockDeviceWebhook struct {
+	// NDMUsername is the user as which the NDM components run. Updates
+	// made by this user are not validated.
+	NDMUsername string
+}
+
+// ValidateCreate allows all BlockDevices to be created
+func (w *BlockDeviceWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// ValidateUpdate validates the changes made to a BlockDevice. The fields that
+// identify the device and the claim cannot be changed on a claimed BlockDevice.
+func (w *BlockDeviceWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldBD, ok := oldObj.(*apis.BlockDevice)
+	if !ok {
+		return fmt.Errorf("expected a BlockDevice but got a %T", oldObj)
+	}
+	newBD, ok := newObj.(*apis.BlockDevice)
+	if !ok {
+		return fmt.Errorf("expected a BlockDevice but got a %T", newObj)
+	}
+
+	// the daemon updates the path when the device name changes, and the
+	// operator updates the claim reference while claiming and releasing
+	if isRequestedBy(ctx, w.NDMUsername) {
+		return nil
+	}
+
+	if oldBD.Status.ClaimState != apis.BlockDeviceClaimed {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	if oldBD.Spec.Path != newBD.Spec.Path {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("path"),
+			"path of a claimed BlockDevice cannot be changed"))
+	}
+	if !equality.Semantic.DeepEqual(oldBD.Spec.ClaimRef, newBD.Spec.ClaimRef) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("claimRef"),
+			"claimRef of a claimed BlockDevice cannot be changed"))
+	}
+	if !equality.Semantic.DeepEqual(oldBD.Spec.NodeAttributes, newBD.Spec.NodeAttributes) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("nodeAttributes"),
+			"nodeAttributes of a claimed BlockDevice cannot be changed"))
+	}
+	if oldBD.Spec.Capacity != newBD.Spec.Capacity {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("capacity"),
+			"capacity of a claimed BlockDevice cannot be changed"))
+	}
+	return toInvalidError(apis.BlockDeviceResourceKind, newBD.Name, allErrs)
+}
+
+// ValidateDelete allows all BlockDevices to be deleted
+func (w *BlockDeviceWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+func TestBlockDeviceValidateUpdate(t *testing.T) {
+	claimedBD := &apis.BlockDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "bd1"},
+		Spec: apis.DeviceSpec{
+			Path: "/dev/sdb",
+			ClaimRef: &v1.ObjectReference{
+				Kind: apis.BlockDeviceClaimResourceKind,
+				Name: "bdc1",
+			},
+		},
+		Status: apis.DeviceStatus{ClaimState: apis.BlockDeviceClaimed},
+	}
+	unclaimedBD := claimedBD.DeepCopy()
+	unclaimedBD.Spec.ClaimRef = nil
+	unclaimedBD.Status.ClaimState = apis.BlockDeviceUnclaimed
+
+	tests := map[string]struct {
+		oldBD    *apis.BlockDevice
+		update   func(bd *apis.BlockDevice)
+		username string
+		wantErr  bool
+	}{
+		"changing path of an unclaimed device": {
+			oldBD: unclaimedBD,
+			update: func(bd *apis.BlockDevice) {
+				bd.Spec.Path = "/dev/sdc"
+			},
+		},
+		"changing path of a claimed device": {
+			oldBD: claimedBD,
+			update: func(bd *apis.BlockDevice) {
+				bd.Spec.Path = "/dev/sdc"
+			},
+			wantErr: true,
+		},
+		"changing path of a claimed device by NDM": {
+			oldBD: claimedBD,
+			update: func(bd *apis.BlockDevice) {
+				bd.Spec.Path = "/dev/sdc"
+			},
+			username: fakeNDMUsername,
+		},
+		"changing claimRef of a claimed device": {
+			oldBD: claimedBD,
+			update: func(bd *apis.BlockDevice) {
+				bd.Spec.ClaimRef.Name = "bdc2"
+			},
+			wantErr: true,
+		},
+		"changing labels of a claimed device": {
+			oldBD: claimedBD,
+			update: func(bd *apis.BlockDevice) {
+				bd.Labels = map[string]string{"key": "value"}
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			newBD := test.oldBD.DeepCopy()
+			test.update(newBD)
+			err := (&BlockDeviceWebhook{NDMUsername: fakeNDMUsername}).
+				ValidateUpdate(newContextWithUser(test.username), test.oldBD, newBD)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
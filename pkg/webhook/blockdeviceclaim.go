@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/pkg/select/verify"
+)
+
+//+kubebuilder:webhook:path=/mutate-openebs-io-v1alpha1-blockdeviceclaim,mutating=true,failurePolicy=fail,sideEffects=None,groups=openebs.io,resources=blockdeviceclaims,verbs=create;update,versions=v1alpha1,name=mblockdeviceclaim.openebs.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-openebs-io-v1alpha1-blockdeviceclaim,mutating=false,failurePolicy=fail,sideEffects=None,groups=openebs.io,resources=blockdeviceclaims,verbs=create;update,versions=v1alpha1,name=vblockdeviceclaim.openebs.io,admissionReviewVersions=v1
+
+// supportedDeviceFormats are the filesystems that can be requested in a claim
+var supportedDeviceFormats = []string{"ext2", "ext3", "ext4", "xfs", "btrfs"}
+
+// BlockDeviceClaimWebhook defaults and validates BlockDeviceClaims
+type BlockDeviceClaimWebhook struct {
+	// NDMUsername is the user as which the NDM components run. Updates
+	// made by this user are not validated.
+	NDMUsername string
+}
+
+// Default sets the default values on a BlockDeviceClaim
+func (w *BlockDeviceClaimWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	bdc, ok := obj.(*apis.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a BlockDeviceClaim but got a %T", obj)
+	}
+	defaultClaimSpec(&bdc.Spec)
+	return nil
+}
+
+// defaultClaimSpec copies the deprecated fields in the claim spec to
+// the fields that replace them, and sets the reclaim policy
+func defaultClaimSpec(spec *apis.DeviceClaimSpec) {
+	if spec.HostName != "" && spec.BlockDeviceNodeAttributes.HostName == "" {
+		spec.BlockDeviceNodeAttributes.HostName = spec.HostName
+	}
+	// the cleanup method requested by the claim is used only while recycling
+	if spec.CleanupMethod != "" && spec.ReclaimPolicy == "" {
+		spec.ReclaimPolicy = apis.ReclaimPolicyRecycle
+	}
+}
+
+// ValidateCreate validates the spec of a new BlockDeviceClaim
+func (w *BlockDeviceClaimWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	bdc, ok := obj.(*apis.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a BlockDeviceClaim but got a %T", obj)
+	}
+	allErrs := validateClaimSpec(&bdc.Spec, field.NewPath("spec"))
+	return toInvalidError(apis.BlockDeviceClaimResourceKind, bdc.Name, allErrs)
+}
+
+// ValidateUpdate validates the changes made to a BlockDeviceClaim. The spec of
+// a bound claim cannot be changed.
+func (w *BlockDeviceClaimWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldBDC, ok := oldObj.(*apis.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a BlockDeviceClaim but got a %T", oldObj)
+	}
+	newBDC, ok := newObj.(*apis.BlockDeviceClaim)
+	if !ok {
+		return fmt.Errorf("expected a BlockDeviceClaim but got a %T", newObj)
+	}
+
+	// the operator sets the blockdevice name on the claim while binding it
+	if isRequestedBy(ctx, w.NDMUsername) {
+		return nil
+	}
+
+	// claims created before the webhook was enabled may not have the defaults set
+	oldSpec := oldBDC.Spec.DeepCopy()
+	defaultClaimSpec(oldSpec)
+	if equality.Semantic.DeepEqual(oldSpec, &newBDC.Spec) {
+		// changes to the metadata of a claim are not blocked by a spec
+		// that was accepted when the claim was created
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if oldBDC.Status.Phase == apis.BlockDeviceClaimStatusDone {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"),
+			"spec of a bound BlockDeviceClaim cannot be changed"))
+	} else {
+		allErrs = validateClaimSpec(&newBDC.Spec, field.NewPath("spec"))
+	}
+	return toInvalidError(apis.BlockDeviceClaimResourceKind, newBDC.Name, allErrs)
+}
+
+// ValidateDelete allows all BlockDeviceClaims to be deleted
+func (w *BlockDeviceClaimWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateClaimSpec validates the spec of a claim, that is yet to be bound
+func validateClaimSpec(spec *apis.DeviceClaimSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// capacity is used only if the blockdevice is selected by the operator
+	if spec.BlockDeviceName == "" {
+		if _, err := verify.GetRequestedCapacity(spec.Resources.Requests); err != nil {
+			storage := spec.Resources.Requests[apis.ResourceStorage]
+			allErrs = append(allErrs, field.Invalid(
+				fldPath.Child("resources", "requests").Key(string(apis.ResourceStorage)),
+				storage.String(), "storage must be greater than zero"))
+		}
+	}
+
+	if spec.BlockDeviceName != "" && spec.Selector != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("selector"),
+			"selector cannot be used along with blockDeviceName"))
+	}
+
+	if spec.BlockDeviceName != "" && spec.Count > 1 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("count"),
+			"count cannot be used along with blockDeviceName"))
+	}
+
+	if spec.HostName != "" && spec.BlockDeviceNodeAttributes.HostName != "" &&
+		spec.HostName != spec.BlockDeviceNodeAttributes.HostName {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hostName"), spec.HostName,
+			fmt.Sprintf("must be same as blockDeviceNodeAttributes.hostName: %s",
+				spec.BlockDeviceNodeAttributes.HostName)))
+	}
+
+	if spec.Placement == apis.PlacementSpreadAcrossZones && spec.BlockDeviceNodeAttributes.Zone != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("placement"),
+			fmt.Sprintf("%s placement cannot be used along with blockDeviceNodeAttributes.zone",
+				apis.PlacementSpreadAcrossZones)))
+	}
+
+	if spec.CleanupMethod != "" && spec.ReclaimPolicy != "" && spec.ReclaimPolicy != apis.ReclaimPolicyRecycle {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cleanupMethod"),
+			fmt.Sprintf("cleanupMethod cannot be used with %s reclaim policy", spec.ReclaimPolicy)))
+	}
+
+	details := spec.Details
+	if details.DeviceFormat != "" {
+		if !isSupportedDeviceFormat(details.DeviceFormat) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("deviceClaimDetails", "formatType"),
+				details.DeviceFormat, supportedDeviceFormats))
+		}
+		if details.BlockVolumeMode == apis.VolumeModeBlock {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("deviceClaimDetails", "formatType"),
+				fmt.Sprintf("formatType cannot be used with %s volume mode", apis.VolumeModeBlock)))
+		}
+	}
+
+	return allErrs
+}
+
+// isSupportedDeviceFormat checks whether the filesystem can be requested in a claim
+func isSupportedDeviceFormat(format string) bool {
+	for _, f := range supportedDeviceFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
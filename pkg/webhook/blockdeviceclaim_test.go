@@ -0,0 +1,310 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+const (
+	fakeNDMUsername = "system:serviceaccount:openebs:openebs-maya-operator"
+)
+
+func TestBlockDeviceClaimDefault(t *testing.T) {
+	tests := map[string]struct {
+		spec apis.DeviceClaimSpec
+		want apis.DeviceClaimSpec
+	}{
+		"hostname is copied to node attributes": {
+			spec: apis.DeviceClaimSpec{HostName: "host1"},
+			want: apis.DeviceClaimSpec{
+				HostName:                  "host1",
+				BlockDeviceNodeAttributes: apis.BlockDeviceNodeAttributes{HostName: "host1"},
+			},
+		},
+		"node attributes hostname is not overwritten": {
+			spec: apis.DeviceClaimSpec{
+				HostName:                  "host1",
+				BlockDeviceNodeAttributes: apis.BlockDeviceNodeAttributes{HostName: "host2"},
+			},
+			want: apis.DeviceClaimSpec{
+				HostName:                  "host1",
+				BlockDeviceNodeAttributes: apis.BlockDeviceNodeAttributes{HostName: "host2"},
+			},
+		},
+		"no hostname": {
+			spec: apis.DeviceClaimSpec{},
+			want: apis.DeviceClaimSpec{},
+		},
+		"cleanup method defaults the reclaim policy to recycle": {
+			spec: apis.DeviceClaimSpec{CleanupMethod: apis.CleanupMethodZero},
+			want: apis.DeviceClaimSpec{
+				CleanupMethod: apis.CleanupMethodZero,
+				ReclaimPolicy: apis.ReclaimPolicyRecycle,
+			},
+		},
+		"reclaim policy is not overwritten": {
+			spec: apis.DeviceClaimSpec{
+				CleanupMethod: apis.CleanupMethodZero,
+				ReclaimPolicy: apis.ReclaimPolicyRetain,
+			},
+			want: apis.DeviceClaimSpec{
+				CleanupMethod: apis.CleanupMethodZero,
+				ReclaimPolicy: apis.ReclaimPolicyRetain,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bdc := &apis.BlockDeviceClaim{Spec: test.spec}
+			err := (&BlockDeviceClaimWebhook{}).Default(context.TODO(), bdc)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, bdc.Spec)
+		})
+	}
+}
+
+func TestValidateClaimSpec(t *testing.T) {
+	tests := map[string]struct {
+		spec       apis.DeviceClaimSpec
+		wantFields []string
+	}{
+		"valid claim": {
+			spec: newFakeClaimSpec("10Gi"),
+		},
+		"zero storage": {
+			spec:       newFakeClaimSpec("0"),
+			wantFields: []string{"spec.resources.requests[storage]"},
+		},
+		"negative storage": {
+			spec:       newFakeClaimSpec("-1Gi"),
+			wantFields: []string{"spec.resources.requests[storage]"},
+		},
+		"storage is not required with blockdevice name": {
+			spec: apis.DeviceClaimSpec{BlockDeviceName: "bd1"},
+		},
+		"selector along with blockdevice name": {
+			spec: apis.DeviceClaimSpec{
+				BlockDeviceName: "bd1",
+				Selector:        &metav1.LabelSelector{},
+			},
+			wantFields: []string{"spec.selector"},
+		},
+		"count along with blockdevice name": {
+			spec: apis.DeviceClaimSpec{
+				BlockDeviceName: "bd1",
+				Count:           2,
+			},
+			wantFields: []string{"spec.count"},
+		},
+		"conflicting hostnames": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.HostName = "host1"
+				spec.BlockDeviceNodeAttributes.HostName = "host2"
+				return spec
+			}(),
+			wantFields: []string{"spec.hostName"},
+		},
+		"spread across zones within a zone": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.Count = 2
+				spec.Placement = apis.PlacementSpreadAcrossZones
+				spec.BlockDeviceNodeAttributes.Zone = "zone-a"
+				return spec
+			}(),
+			wantFields: []string{"spec.placement"},
+		},
+		"unsupported device format": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.Details.BlockVolumeMode = apis.VolumeModeFileSystem
+				spec.Details.DeviceFormat = "ntfs"
+				return spec
+			}(),
+			wantFields: []string{"spec.deviceClaimDetails.formatType"},
+		},
+		"cleanup method with recycle reclaim policy": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.CleanupMethod = apis.CleanupMethodDiscard
+				spec.ReclaimPolicy = apis.ReclaimPolicyRecycle
+				return spec
+			}(),
+		},
+		"cleanup method with retain reclaim policy": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.CleanupMethod = apis.CleanupMethodDiscard
+				spec.ReclaimPolicy = apis.ReclaimPolicyRetain
+				return spec
+			}(),
+			wantFields: []string{"spec.cleanupMethod"},
+		},
+		"device format with block volume mode": {
+			spec: func() apis.DeviceClaimSpec {
+				spec := newFakeClaimSpec("10Gi")
+				spec.Details.BlockVolumeMode = apis.VolumeModeBlock
+				spec.Details.DeviceFormat = "ext4"
+				return spec
+			}(),
+			wantFields: []string{"spec.deviceClaimDetails.formatType"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			allErrs := validateClaimSpec(&test.spec, field.NewPath("spec"))
+			gotFields := make([]string, 0)
+			for _, err := range allErrs {
+				gotFields = append(gotFields, err.Field)
+			}
+			if test.wantFields == nil {
+				test.wantFields = []string{}
+			}
+			assert.Equal(t, test.wantFields, gotFields)
+		})
+	}
+}
+
+func TestBlockDeviceClaimValidateUpdate(t *testing.T) {
+	boundBDC := &apis.BlockDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "bdc1"},
+		Spec:       newFakeClaimSpec("10Gi"),
+		Status:     apis.DeviceClaimStatus{Phase: apis.BlockDeviceClaimStatusDone},
+	}
+	boundBDC.Spec.HostName = "host1"
+	boundBDC.Spec.BlockDeviceName = "bd1"
+
+	pendingBDC := boundBDC.DeepCopy()
+	pendingBDC.Spec.BlockDeviceName = ""
+	pendingBDC.Status.Phase = apis.BlockDeviceClaimStatusPending
+
+	// claim created before the spec was validated
+	invalidBDC := pendingBDC.DeepCopy()
+	invalidBDC.Spec.Resources.Requests[apis.ResourceStorage] = resource.MustParse("0")
+
+	tests := map[string]struct {
+		oldBDC   *apis.BlockDeviceClaim
+		update   func(bdc *apis.BlockDeviceClaim)
+		username string
+		wantErr  bool
+	}{
+		"changing labels of a bound claim": {
+			oldBDC: boundBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Labels = map[string]string{"key": "value"}
+			},
+		},
+		"changing the deprecated hostname of a bound claim": {
+			oldBDC: boundBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.HostName = "host2"
+			},
+			wantErr: true,
+		},
+		"changing the spec of a bound claim": {
+			oldBDC: boundBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.BlockDeviceName = "bd2"
+			},
+			wantErr: true,
+		},
+		"changing the spec of a pending claim": {
+			oldBDC: pendingBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.DeviceType = "SSD"
+			},
+		},
+		"invalid spec on a pending claim": {
+			oldBDC: pendingBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.Resources.Requests[apis.ResourceStorage] = resource.MustParse("0")
+			},
+			wantErr: true,
+		},
+		"changing labels of a pending claim with an invalid spec": {
+			oldBDC: invalidBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Labels = map[string]string{"key": "value"}
+			},
+		},
+		"changing the spec of a pending claim with an invalid spec": {
+			oldBDC: invalidBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.DeviceType = "SSD"
+			},
+			wantErr: true,
+		},
+		"binding a claim with selector by the operator": {
+			oldBDC: pendingBDC,
+			update: func(bdc *apis.BlockDeviceClaim) {
+				bdc.Spec.Selector = &metav1.LabelSelector{}
+				bdc.Spec.BlockDeviceName = "bd1"
+				bdc.Status.Phase = apis.BlockDeviceClaimStatusDone
+			},
+			username: fakeNDMUsername,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := &BlockDeviceClaimWebhook{NDMUsername: fakeNDMUsername}
+			newBDC := test.oldBDC.DeepCopy()
+			test.update(newBDC)
+			ctx := newContextWithUser(test.username)
+			// the mutating webhook is called before the validating webhook
+			assert.NoError(t, w.Default(ctx, newBDC))
+			err := w.ValidateUpdate(ctx, test.oldBDC, newBDC)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func newFakeClaimSpec(storage string) apis.DeviceClaimSpec {
+	return apis.DeviceClaimSpec{
+		Resources: apis.DeviceClaimResources{
+			Requests: v1.ResourceList{
+				apis.ResourceStorage: resource.MustParse(storage),
+			},
+		},
+	}
+}
+
+// newContextWithUser creates a context with an admission request made by the user
+func newContextWithUser(username string) context.Context {
+	return admission.NewContextWithRequest(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: username},
+		},
+	})
+}
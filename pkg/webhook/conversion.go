@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+// ConversionWebhookPath is the path at which the conversion webhook is served
+const ConversionWebhookPath = "/convert"
+
+// SetupConversionWebhookWithManager registers the conversion webhook for
+// BlockDevice and BlockDeviceClaim with the manager. All the versions of
+// the resources must be registered in the scheme of the manager.
+func SetupConversionWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1beta1.BlockDevice{}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1beta1.BlockDeviceClaim{}).
+		Complete()
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+var (
+	// storageVersionPollInterval is the interval at which the CRD is polled
+	// while waiting for the API server to start using the new storage version
+	storageVersionPollInterval = 2 * time.Second
+
+	// storageVersionTimeout is the time to wait for the API server to start
+	// using the new storage version
+	storageVersionTimeout = 2 * time.Minute
+)
+
+// StorageVersionMigrator migrates the BlockDevice and BlockDeviceClaim objects
+// stored in the older versions to v1beta1. The CRDs are configured to store the
+// objects in v1beta1 using the conversion webhook by the manifests in
+// deploy/conversion, the migrator only rewrites the objects once that is done.
+type StorageVersionMigrator struct {
+	// Client is used to update the CRDs and the objects
+	Client client.Client
+
+	// Reader is used to read the CRDs and the objects directly from the API server
+	Reader client.Reader
+}
+
+// Start migrates the storage version of the CRDs. It implements manager.Runnable
+// so that the migration is performed by the leader, once the webhook server has
+// been started.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	if err := m.Migrate(ctx, apis.BlockDeviceResourceName, &v1beta1.BlockDeviceList{}); err != nil {
+		return err
+	}
+	return m.Migrate(ctx, apis.BlockDeviceClaimResourceName, &v1beta1.BlockDeviceClaimList{})
+}
+
+// Migrate migrates the objects of the CRD with the given name to v1beta1. The
+// objects of the CRD, which are listed into list, are rewritten so that they are
+// stored in v1beta1, after which the older versions are removed from the stored
+// versions of the CRD. Nothing is done if the CRD does not store the objects in
+// v1beta1 using the conversion webhook.
+func (m *StorageVersionMigrator) Migrate(ctx context.Context, crdName string, list client.ObjectList) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.Reader.Get(ctx, client.ObjectKey{Name: crdName}, crd); err != nil {
+		return fmt.Errorf("unable to get CRD %s: %v", crdName, err)
+	}
+
+	if !isConfiguredForHubVersion(crd) {
+		klog.Infof("CRD %s does not store the objects in %s using the conversion webhook, "+
+			"skipping the migration", crdName, v1beta1.GroupVersion.Version)
+		return nil
+	}
+
+	if isStoredOnlyInHubVersion(crd) {
+		klog.V(4).Infof("objects of CRD %s are already stored in %s", crdName, v1beta1.GroupVersion.Version)
+		return nil
+	}
+
+	// the API server adds the new storage version to the stored versions
+	// once it starts storing the objects in that version
+	err := wait.PollImmediateWithContext(ctx, storageVersionPollInterval, storageVersionTimeout,
+		func(ctx context.Context) (bool, error) {
+			if err := m.Reader.Get(ctx, client.ObjectKey{Name: crdName}, crd); err != nil {
+				return false, err
+			}
+			for _, version := range crd.Status.StoredVersions {
+				if version == v1beta1.GroupVersion.Version {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	if err != nil {
+		return fmt.Errorf("storage version of CRD %s was not updated: %v", crdName, err)
+	}
+
+	if err := m.rewriteObjects(ctx, list); err != nil {
+		return fmt.Errorf("unable to migrate objects of CRD %s: %v", crdName, err)
+	}
+
+	crd.Status.StoredVersions = []string{v1beta1.GroupVersion.Version}
+	if err := m.Client.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("unable to update stored versions of CRD %s: %v", crdName, err)
+	}
+	klog.Infof("migrated objects of CRD %s to %s", crdName, v1beta1.GroupVersion.Version)
+	return nil
+}
+
+// rewriteObjects updates all the objects without any change, so that the API
+// server stores them in the current storage version
+func (m *StorageVersionMigrator) rewriteObjects(ctx context.Context, list client.ObjectList) error {
+	if err := m.Reader.List(ctx, list); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected object of type %T in the list", item)
+		}
+		// an object which was deleted or updated after it was listed has
+		// already been stored in the current storage version
+		err := m.Client.Update(ctx, obj)
+		if err != nil && !errors.IsNotFound(err) && !errors.IsConflict(err) {
+			return fmt.Errorf("unable to update %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// isStoredOnlyInHubVersion checks whether all the objects of the CRD are
+// stored in v1beta1
+func isStoredOnlyInHubVersion(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	return len(crd.Status.StoredVersions) == 1 &&
+		crd.Status.StoredVersions[0] == v1beta1.GroupVersion.Version
+}
+
+// isConfiguredForHubVersion checks whether the CRD uses the conversion webhook
+// and stores the objects in v1beta1
+func isConfiguredForHubVersion(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	if crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != apiextensionsv1.WebhookConverter {
+		return false
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name == v1beta1.GroupVersion.Version
+		}
+	}
+	return false
+}
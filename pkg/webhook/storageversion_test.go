@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+func newFakeCRD(storageVersion string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	path := ConversionWebhookPath
+	port := int32(443)
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: apis.BlockDeviceResourceName},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: apis.GroupVersion.Group,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: apis.GroupVersion.Version, Served: true, Storage: storageVersion == apis.GroupVersion.Version},
+				{Name: v1beta1.GroupVersion.Version, Served: true, Storage: storageVersion == v1beta1.GroupVersion.Version},
+			},
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{
+							Namespace: "openebs",
+							Name:      "node-disk-operator-webhook",
+							Path:      &path,
+							Port:      &port,
+						},
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func TestStorageVersionMigratorMigrate(t *testing.T) {
+	storageVersionTimeout = 100 * time.Millisecond
+	storageVersionPollInterval = 10 * time.Millisecond
+
+	tests := map[string]struct {
+		crd                *apiextensionsv1.CustomResourceDefinition
+		wantErr            bool
+		wantStoredVersions []string
+		wantRewritten      bool
+	}{
+		"objects stored in v1alpha1 are migrated": {
+			crd:                newFakeCRD(v1beta1.GroupVersion.Version, "v1alpha1", "v1beta1"),
+			wantStoredVersions: []string{"v1beta1"},
+			wantRewritten:      true,
+		},
+		"already migrated": {
+			crd:                newFakeCRD(v1beta1.GroupVersion.Version, "v1beta1"),
+			wantStoredVersions: []string{"v1beta1"},
+		},
+		"storage version not updated by the API server": {
+			crd:                newFakeCRD(v1beta1.GroupVersion.Version, "v1alpha1"),
+			wantErr:            true,
+			wantStoredVersions: []string{"v1alpha1"},
+		},
+		"CRD stores the objects in v1alpha1": {
+			crd:                newFakeCRD(apis.GroupVersion.Version, "v1alpha1"),
+			wantStoredVersions: []string{"v1alpha1"},
+		},
+		"CRD does not use the conversion webhook": {
+			crd: func() *apiextensionsv1.CustomResourceDefinition {
+				crd := newFakeCRD(v1beta1.GroupVersion.Version, "v1alpha1", "v1beta1")
+				crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter}
+				return crd
+			}(),
+			wantStoredVersions: []string{"v1alpha1", "v1beta1"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+			require.NoError(t, apis.AddToScheme(scheme))
+			require.NoError(t, v1beta1.AddToScheme(scheme))
+
+			bd := &v1beta1.BlockDevice{
+				ObjectMeta: metav1.ObjectMeta{Name: "blockdevice-1", Namespace: "openebs"},
+				Spec:       v1beta1.DeviceSpec{Path: "/dev/nvme0n1"},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.crd, bd).Build()
+			beforeBD := &v1beta1.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), beforeBD))
+
+			m := &StorageVersionMigrator{
+				Client: cl,
+				Reader: cl,
+			}
+			err := m.Migrate(context.TODO(), apis.BlockDeviceResourceName, &v1beta1.BlockDeviceList{})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotCRD := &apiextensionsv1.CustomResourceDefinition{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: apis.BlockDeviceResourceName}, gotCRD))
+			assert.Equal(t, test.wantStoredVersions, gotCRD.Status.StoredVersions)
+			// the spec of the CRD is managed by the manifests
+			assert.Equal(t, test.crd.Spec, gotCRD.Spec)
+
+			gotBD := &v1beta1.BlockDevice{}
+			require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bd), gotBD))
+			assert.Equal(t, test.wantRewritten, gotBD.ResourceVersion != beforeBD.ResourceVersion)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains the admission webhooks for the BlockDevice and
+// BlockDeviceClaim resources, which are served by the NDM operator.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+)
+
+// SetupWebhookWithManager registers the admission webhooks for BlockDevice and
+// BlockDeviceClaim with the manager. Requests made by ndmUsername, the user as which
+// the NDM components run, are exempted from the immutability checks.
+func SetupWebhookWithManager(mgr ctrl.Manager, ndmUsername string) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&apis.BlockDeviceClaim{}).
+		WithDefaulter(&BlockDeviceClaimWebhook{NDMUsername: ndmUsername}).
+		WithValidator(&BlockDeviceClaimWebhook{NDMUsername: ndmUsername}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&apis.BlockDevice{}).
+		WithValidator(&BlockDeviceWebhook{NDMUsername: ndmUsername}).
+		Complete()
+}
+
+// ServiceAccountUsername returns the username with which a service account
+// is authenticated by the kubernetes API server
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// isRequestedBy checks whether the admission request in the context was made
+// by the given user
+func isRequestedBy(ctx context.Context, username string) bool {
+	if username == "" {
+		return false
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	return req.UserInfo.Username == username
+}
+
+// toInvalidError converts the list of field errors to an Invalid API error
+func toInvalidError(kind, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	klog.Infof("rejecting %s %s: %v", kind, name, allErrs.ToAggregate())
+	return errors.NewInvalid(apis.GroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}
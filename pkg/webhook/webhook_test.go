@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	apis "github.com/openebs/node-disk-manager/api/v1alpha1"
+	"github.com/openebs/node-disk-manager/api/v1beta1"
+)
+
+// TestWebhooks runs the webhooks against a local API server started using envtest.
+// The test is skipped if the envtest binaries are not available, use `make envtest`
+// to install the binaries and run the test.
+func TestWebhooks(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping envtest based webhook tests")
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apis.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	// envtest configures the CRDs of the convertible types in the scheme
+	// to use the conversion webhook
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "deploy", "crds")},
+		ErrorIfCRDPathMissing: true,
+		CRDInstallOptions: envtest.CRDInstallOptions{
+			Scheme: scheme,
+		},
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "deploy", "yamls", "node-disk-operator-webhook.yaml")},
+		},
+	}
+	cfg, err := testEnv.Start()
+	require.NoError(t, err)
+	defer func() {
+		_ = testEnv.Stop()
+	}()
+
+	webhookOptions := &testEnv.WebhookInstallOptions
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:             scheme,
+		Host:               webhookOptions.LocalServingHost,
+		Port:               webhookOptions.LocalServingPort,
+		CertDir:            webhookOptions.LocalServingCertDir,
+		MetricsBindAddress: "0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetupWebhookWithManager(mgr, fakeNDMUsername))
+	require.NoError(t, SetupConversionWebhookWithManager(mgr))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go func() {
+		_ = mgr.Start(ctx)
+	}()
+	require.NoError(t, waitForWebhookServer(webhookOptions))
+
+	cl, err := client.New(cfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+
+	// the NDM components are impersonated to verify that their updates are allowed
+	ndmCfg := rest.CopyConfig(cfg)
+	ndmCfg.Impersonate = rest.ImpersonationConfig{
+		UserName: fakeNDMUsername,
+		Groups:   []string{"system:masters"},
+	}
+	ndmClient, err := client.New(ndmCfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+
+	t.Run("invalid claim is rejected", func(t *testing.T) {
+		bdc := newFakeClaim("bdc-invalid", "0")
+		err := cl.Create(context.TODO(), bdc)
+		assert.True(t, errors.IsInvalid(err), "expected invalid error, got: %v", err)
+	})
+
+	t.Run("deprecated hostname is defaulted", func(t *testing.T) {
+		bdc := newFakeClaim("bdc-hostname", "10Gi")
+		bdc.Spec.HostName = "host1"
+		require.NoError(t, cl.Create(context.TODO(), bdc))
+		assert.Equal(t, "host1", bdc.Spec.BlockDeviceNodeAttributes.HostName)
+	})
+
+	t.Run("v1alpha1 claim is converted to v1beta1", func(t *testing.T) {
+		bdc := newFakeClaim("bdc-conversion", "10Gi")
+		bdc.Spec.HostName = "host1"
+		require.NoError(t, cl.Create(context.TODO(), bdc))
+
+		betaBDC := &v1beta1.BlockDeviceClaim{}
+		require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bdc), betaBDC))
+		assert.Equal(t, "host1", betaBDC.Spec.BlockDeviceNodeAttributes.HostName)
+
+		// v1alpha1 clients still see the deprecated hostname after an
+		// update made using v1beta1
+		betaBDC.Spec.DeviceType = "SSD"
+		require.NoError(t, cl.Update(context.TODO(), betaBDC))
+		alphaBDC := &apis.BlockDeviceClaim{}
+		require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(bdc), alphaBDC))
+		assert.Equal(t, "host1", alphaBDC.Spec.HostName)
+		assert.Equal(t, "SSD", alphaBDC.Spec.DeviceType)
+	})
+
+	t.Run("spec of a bound claim cannot be changed", func(t *testing.T) {
+		bdc := newFakeClaim("bdc-bound", "10Gi")
+		require.NoError(t, cl.Create(context.TODO(), bdc))
+
+		bdc.Spec.BlockDeviceName = "bd1"
+		bdc.Status.Phase = apis.BlockDeviceClaimStatusDone
+		require.NoError(t, ndmClient.Update(context.TODO(), bdc))
+
+		bdc.Spec.BlockDeviceName = "bd2"
+		err := cl.Update(context.TODO(), bdc)
+		assert.True(t, errors.IsInvalid(err), "expected invalid error, got: %v", err)
+	})
+
+	t.Run("path of a claimed device can be changed only by NDM", func(t *testing.T) {
+		bd := &apis.BlockDevice{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-claimed", Namespace: "default"},
+			Spec: apis.DeviceSpec{
+				Path: "/dev/sdb",
+				ClaimRef: &v1.ObjectReference{
+					Kind: apis.BlockDeviceClaimResourceKind,
+					Name: "bdc-bound",
+				},
+			},
+			Status: apis.DeviceStatus{
+				ClaimState: apis.BlockDeviceClaimed,
+				State:      apis.BlockDeviceActive,
+			},
+		}
+		require.NoError(t, cl.Create(context.TODO(), bd))
+
+		bd.Spec.Path = "/dev/sdc"
+		err := cl.Update(context.TODO(), bd)
+		assert.True(t, errors.IsInvalid(err), "expected invalid error, got: %v", err)
+
+		assert.NoError(t, ndmClient.Update(context.TODO(), bd))
+	})
+}
+
+func newFakeClaim(name, storage string) *apis.BlockDeviceClaim {
+	return &apis.BlockDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       newFakeClaimSpec(storage),
+	}
+}
+
+// waitForWebhookServer waits till the webhook server started by the manager
+// starts accepting connections
+func waitForWebhookServer(options *envtest.WebhookInstallOptions) error {
+	addr := net.JoinHostPort(options.LocalServingHost, fmt.Sprintf("%d", options.LocalServingPort))
+	dialer := &net.Dialer{Timeout: time.Second}
+	var err error
+	for i := 0; i < 30; i++ {
+		var conn *tls.Conn
+		// #nosec G402 -- the certificate is generated by envtest for the test
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err == nil {
+			return conn.Close()
+		}
+		time.Sleep(time.Second)
+	}
+	return err
+}